@@ -0,0 +1,191 @@
+package codeowners
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jpmcb/gopherlogs/pkg/colors"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+)
+
+// isMember reports whether the login is a member of the org
+func (r *orgMemberResolver) isMember(login string) (bool, error) {
+	if r.members == nil {
+		if err := r.loadMembers(); err != nil {
+			return false, err
+		}
+	}
+
+	_, ok := r.members[strings.ToLower(login)]
+	return ok, nil
+}
+
+// membersCachePath gets the path the org's members are cached at in the cache directory.
+// Public members are cached apart from every member, since they're listed without a token.
+func (r *orgMemberResolver) membersCachePath(cacheDir string) string {
+	name := "members"
+	if r.client.token == "" {
+		name = "public_members"
+	}
+
+	return filepath.Join(cacheDir, "github-orgs", strings.ToLower(r.org), name+".json")
+}
+
+// readCachedMembers reads the org's members cached in the cache directory when
+// they're younger than the cache TTL
+func (r *orgMemberResolver) readCachedMembers(cacheDir string, cacheTTL time.Duration) (map[string]string, bool) {
+	cachePath := r.membersCachePath(cacheDir)
+
+	info, err := os.Stat(cachePath)
+	if err != nil || time.Since(info.ModTime()) >= cacheTTL {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, false
+	}
+
+	var members map[string]string
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, false
+	}
+
+	return members, true
+}
+
+// loadCachedMembers loads the org's members from the cache directory, or fetches and
+// caches them when they aren't cached or the cache is older than the cache TTL. Caching
+// is best effort: a failed write only means the members are fetched again next time.
+func (r *orgMemberResolver) loadCachedMembers(cacheDir string, cacheTTL time.Duration) error {
+	if members, ok := r.readCachedMembers(cacheDir, cacheTTL); ok {
+		r.members = members
+		return nil
+	}
+
+	if err := r.loadMembers(); err != nil {
+		return err
+	}
+
+	cachePath := r.membersCachePath(cacheDir)
+	if data, err := json.Marshal(r.members); err == nil {
+		if err := os.MkdirAll(filepath.Dir(cachePath), os.ModePerm); err == nil {
+			_ = os.WriteFile(cachePath, data, 0600)
+		}
+	}
+
+	return nil
+}
+
+// newActiveMembersResolver creates the resolver of the org's active members for
+// --active-members-only with the GitHub token in the environment, loading the members
+// from the pizza config directory's cache when they were fetched within the cache TTL
+func newActiveMembersResolver(org string, cacheTTL time.Duration) (*orgMemberResolver, error) {
+	r := newOrgMemberResolver(newGitHubClient(&http.Client{Timeout: githubAPITimeout}, githubAPIEndpoint, githubToken()), org)
+
+	configDir, err := config.GetConfigDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.loadCachedMembers(filepath.Join(configDir, "cache"), cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// removeInactiveMembers removes the stats of the contributors attributed to a GitHub user
+// who isn't a member of the org, i.e., who has since left it, so the next ranked active
+// contributor, or the fallback, owns their files instead. Teams, emails, groups, and
+// unattributed contributors are kept. The number of inactive users is returned.
+func (fs FileStats) removeInactiveMembers(spec *config.Spec, r *orgMemberResolver) (int, error) {
+	// whether each attributed login is active, looked up once
+	active := make(map[string]bool)
+	isActive := func(login string) (bool, error) {
+		if ok, cached := active[login]; cached {
+			return ok, nil
+		}
+
+		ok, err := r.isMember(login)
+		if err != nil {
+			return false, err
+		}
+
+		active[login] = ok
+		return ok, nil
+	}
+
+	for _, authorStats := range fs {
+		for author, stat := range authorStats {
+			login, ok := attributedLogin(stat.Email, spec)
+			if !ok {
+				continue
+			}
+
+			memberActive, err := isActive(login)
+			if err != nil {
+				return 0, err
+			}
+
+			if !memberActive {
+				delete(authorStats, author)
+			}
+		}
+	}
+
+	inactive := 0
+	for _, ok := range active {
+		if !ok {
+			inactive++
+		}
+	}
+
+	return inactive, nil
+}
+
+// attributedLogin gets the GitHub login the email is attributed to in the config. Emails
+// attributed to a team, an email, or a group aren't attributed to a login.
+func attributedLogin(email string, spec *config.Spec) (string, bool) {
+	username, ok := exactAttribution(email, spec)
+	if !ok {
+		username, ok = regexAttribution(email, spec)
+		if !ok {
+			return "", false
+		}
+	}
+
+	if _, isGroup := config.GroupReference(username); isGroup {
+		return "", false
+	}
+
+	login := strings.TrimPrefix(spec.CanonicalLogin(username), "@")
+	if login == "" || strings.ContainsAny(login, "/@") {
+		return "", false
+	}
+
+	return login, true
+}
+
+// applyActiveMembers removes the contributors who aren't active members of the org
+// set with --active-members-only
+func applyActiveMembers(fs FileStats, opts *Options) error {
+	if opts.activeMembers == nil {
+		return nil
+	}
+
+	count, err := fs.removeInactiveMembers(opts.config, opts.activeMembers)
+	if err != nil {
+		return err
+	}
+
+	opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Removed %d contributors who aren't active members of %s\n", count, opts.activeMembers.org)
+	return nil
+}
@@ -0,0 +1,154 @@
+package codeowners
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+func newActiveMembersServer(t *testing.T, requests *int) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/open-sauced/members" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		*requests++
+		_ = json.NewEncoder(w).Encode([]githubUser{{Login: "jpmcb"}, {Login: "NickyTonline"}})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestRemoveInactiveMembers(t *testing.T) {
+	var requests int
+	server := newActiveMembersServer(t, &requests)
+	r := newOrgMemberResolver(newGitHubClient(server.Client(), server.URL, "token"), "open-sauced")
+
+	spec := &config.Spec{
+		Attributions: map[string][]string{
+			"brandonroberts":   {"brandon@opensauced.pizza"},
+			"jpmcb":            {"john@opensauced.pizza"},
+			"nickytonline":     {"nick@opensauced.pizza"},
+			"open-sauced/docs": {"docs@opensauced.pizza"},
+		},
+		RegexAttributions:   map[string][]string{"zeucapua": {"^zeu@"}},
+		AttributionFallback: []string{"open-sauced/engineering"},
+	}
+
+	fileStats := FileStats{
+		"main.go": {
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 30, Commits: 3},
+			"john":    {Email: "john@opensauced.pizza", Lines: 20, Commits: 2},
+			"nick":    {Email: "nick@opensauced.pizza", Lines: 10, Commits: 1},
+		},
+		"docs/README.md": {
+			"docs":    {Email: "docs@opensauced.pizza", Lines: 5, Commits: 1},
+			"zeu":     {Email: "zeu@example.com", Lines: 3, Commits: 1},
+			"unknown": {Email: "unknown@example.com", Lines: 1, Commits: 1},
+		},
+		"old.go": {
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 10, Commits: 1},
+		},
+	}
+
+	inactive, err := fileStats.removeInactiveMembers(spec, r)
+	require.NoError(t, err)
+	assert.Equal(t, 2, inactive)
+	assert.Equal(t, 1, requests)
+
+	authors := func(authorStats AuthorStats) []string {
+		var names []string
+		for author := range authorStats {
+			names = append(names, author)
+		}
+		return names
+	}
+
+	// teams and unattributed contributors are kept
+	assert.ElementsMatch(t, []string{"john", "nick"}, authors(fileStats["main.go"]))
+	assert.ElementsMatch(t, []string{"docs", "unknown"}, authors(fileStats["docs/README.md"]))
+	assert.Empty(t, fileStats["old.go"])
+
+	// the next ranked active contributor, or the fallback, owns the files instead
+	owners := getTopContributorAttributions("main.go", fileStats["main.go"], LinesRanker, 1, spec)
+	require.Len(t, owners, 1)
+	assert.Equal(t, "jpmcb", owners[0].GitHubAlias)
+
+	owners = getTopContributorAttributions("old.go", fileStats["old.go"], LinesRanker, 1, spec)
+	require.Len(t, owners, 1)
+	assert.Equal(t, "open-sauced/engineering", owners[0].GitHubAlias)
+	assert.Equal(t, ownerSourceFallback, owners[0].Source)
+}
+
+func TestLoadCachedMembers(t *testing.T) {
+	var requests int
+	server := newActiveMembersServer(t, &requests)
+	cacheDir := t.TempDir()
+
+	newResolver := func() *orgMemberResolver {
+		return newOrgMemberResolver(newGitHubClient(server.Client(), server.URL, "token"), "open-sauced")
+	}
+
+	r := newResolver()
+	require.NoError(t, r.loadCachedMembers(cacheDir, time.Hour))
+	assert.Equal(t, 1, requests)
+	assert.FileExists(t, r.membersCachePath(cacheDir))
+
+	t.Run("cached", func(t *testing.T) {
+		r := newResolver()
+		require.NoError(t, r.loadCachedMembers(cacheDir, time.Hour))
+		assert.Equal(t, 1, requests)
+
+		member, err := r.isMember("NICKYTONLINE")
+		require.NoError(t, err)
+		assert.True(t, member)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		past := time.Now().Add(-2 * time.Hour)
+		require.NoError(t, os.Chtimes(r.membersCachePath(cacheDir), past, past))
+
+		require.NoError(t, newResolver().loadCachedMembers(cacheDir, time.Hour))
+		assert.Equal(t, 2, requests)
+	})
+}
+
+func TestAttributedLogin(t *testing.T) {
+	spec := &config.Spec{
+		Attributions: map[string][]string{
+			"@jpmcb":           {"john@opensauced.pizza"},
+			"open-sauced/docs": {"docs@opensauced.pizza"},
+			"@@frontend":       {"frontend@opensauced.pizza"},
+		},
+		Groups: map[string][]string{"frontend": {"@alice"}},
+	}
+
+	login, ok := attributedLogin("john@opensauced.pizza", spec)
+	assert.True(t, ok)
+	assert.Equal(t, "jpmcb", login)
+
+	for _, email := range []string{"docs@opensauced.pizza", "frontend@opensauced.pizza", "unknown@example.com"} {
+		_, ok := attributedLogin(email, spec)
+		assert.False(t, ok, email)
+	}
+
+	t.Run("renamed login", func(t *testing.T) {
+		spec.Aliases = map[string]string{"jpmcb": "john-mcbride"}
+
+		login, ok := attributedLogin("john@opensauced.pizza", spec)
+		assert.True(t, ok)
+		assert.Equal(t, "john-mcbride", login)
+	})
+}
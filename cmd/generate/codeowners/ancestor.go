@@ -0,0 +1,82 @@
+package codeowners
+
+import (
+	"path"
+	"strings"
+)
+
+// ancestorStats are the author stats of every file beneath each directory, keyed by the
+// directory. The repository root is keyed by ".". Used to inherit the owners of a file's
+// nearest ancestor directory when nobody qualifies as an owner of the file itself.
+// Example: { "src": { Author stats of every file in src/ and its subdirectories }}
+type ancestorStats map[string]AuthorStats
+
+// newAncestorStats aggregates the author stats of each file into each of its ancestor directories
+func newAncestorStats(fs FileStats) ancestorStats {
+	as := make(ancestorStats)
+
+	for filename, authorStats := range fs {
+		for dir := ancestorDir(filename); ; dir = path.Dir(dir) {
+			if _, ok := as[dir]; !ok {
+				as[dir] = make(AuthorStats)
+			}
+			as[dir].merge(authorStats)
+
+			if dir == "." {
+				break
+			}
+		}
+	}
+
+	return as
+}
+
+// ancestorDir gets the directory of a file or of a directory granularity rule.
+// Example: "src/main.go" and "src/pkg/" are in "src"
+func ancestorDir(rule string) string {
+	if rule == rootDirectoryRule {
+		return "."
+	}
+
+	return path.Dir(strings.TrimSuffix(rule, "/"))
+}
+
+// inheritOwners gets the owners of the nearest ancestor directory of a file with
+// qualifying contributors, walking up to the repository root. The contributors of every
+// file beneath a directory are ranked together. Nothing is inherited when no ancestor has
+// qualifying contributors, or when the rule is the root directory rule itself.
+func (as ancestorStats) inheritOwners(filename string, opts *Options) (AuthorStatSlice, bool) {
+	if filename == rootDirectoryRule {
+		return nil, false
+	}
+
+	for dir := ancestorDir(filename); ; dir = path.Dir(dir) {
+		contributors := attributeContributors(mergeLogins(as[dir], opts.ranker, opts.config).ToRankedSlice(opts.ranker), opts.maxOwners, opts.config.MinCommits, opts.config)
+		if len(contributors) > 0 {
+			inherited := make(AuthorStatSlice, 0, len(contributors))
+			for _, contributor := range contributors {
+				owner := *contributor
+				owner.Source = ownerSourceAncestor
+				inherited = append(inherited, &owner)
+			}
+
+			return prioritizeOwners(filename, inherited, opts.config), true
+		}
+
+		if dir == "." {
+			return nil, false
+		}
+	}
+}
+
+// isFallbackOnly returns true if none of the owners were attributed from the
+// file itself: it has no owners or only the fallback owners
+func isFallbackOnly(owners AuthorStatSlice) bool {
+	for _, owner := range owners {
+		if owner.Source != ownerSourceFallback {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,99 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+func TestInheritOwners(t *testing.T) {
+	fileStats := FileStats{
+		"main.go":                    {"john": {Email: "john@opensauced.pizza", Lines: 5, Commits: 1}},
+		"src/app.go":                 {"brandon": {Email: "brandon@opensauced.pizza", Lines: 20, Commits: 2}},
+		"src/pkg/util.go":            {"nick": {Email: "nick@opensauced.pizza", Lines: 10, Commits: 1}},
+		"src/pkg/deep/nested/a.go":   {"unknown": {Email: "unknown@opensauced.pizza", Lines: 50, Commits: 1}},
+		"src/pkg/deep/nested/b.go":   {},
+		"docs/guide/unattributed.md": {"unknown": {Email: "unknown@opensauced.pizza", Lines: 1, Commits: 1}},
+	}
+
+	opts := &Options{
+		ranker:        LinesRanker,
+		maxOwners:     3,
+		inheritOwners: true,
+		ancestorStats: newAncestorStats(fileStats),
+		config: &config.Spec{
+			Attributions: map[string][]string{
+				"jpmcb":          {"john@opensauced.pizza"},
+				"brandonroberts": {"brandon@opensauced.pizza"},
+				"nickytonline":   {"nick@opensauced.pizza"},
+			},
+			AttributionFallback: []string{"open-sauced/engineering"},
+		},
+	}
+
+	aliases := func(owners AuthorStatSlice) []string {
+		var result []string
+		for _, owner := range owners {
+			result = append(result, owner.GitHubAlias)
+		}
+
+		return result
+	}
+
+	t.Run("own contributors", func(t *testing.T) {
+		owners := getOwners("src/app.go", fileStats["src/app.go"], opts)
+		assert.Equal(t, []string{"brandonroberts"}, aliases(owners))
+		assert.Equal(t, ownerSourceTopContributors, owners[0].Source)
+	})
+
+	t.Run("nearest ancestor with owners", func(t *testing.T) {
+		for _, filename := range []string{"src/pkg/deep/nested/a.go", "src/pkg/deep/nested/b.go"} {
+			owners := getOwners(filename, fileStats[filename], opts)
+			assert.Equal(t, []string{"nickytonline"}, aliases(owners), filename)
+			require.NotEmpty(t, owners)
+			assert.Equal(t, ownerSourceAncestor, owners[0].Source)
+		}
+	})
+
+	t.Run("ranked across the ancestor's files", func(t *testing.T) {
+		owners, ok := opts.ancestorStats.inheritOwners("src/new.go", opts)
+		require.True(t, ok)
+		assert.Equal(t, []string{"brandonroberts", "nickytonline"}, aliases(owners))
+	})
+
+	t.Run("repository root", func(t *testing.T) {
+		owners := getOwners("docs/guide/unattributed.md", fileStats["docs/guide/unattributed.md"], opts)
+		// the unattributed author is ranked first, so only 2 of the top 3 are attributed
+		assert.Equal(t, []string{"brandonroberts", "nickytonline"}, aliases(owners))
+	})
+
+	t.Run("fallback without an ancestor with owners", func(t *testing.T) {
+		unattributed := FileStats{"docs/a.md": {"unknown": {Email: "unknown@opensauced.pizza", Lines: 1, Commits: 1}}}
+		withoutAncestors := *opts
+		withoutAncestors.ancestorStats = newAncestorStats(unattributed)
+
+		owners := getOwners("docs/a.md", unattributed["docs/a.md"], &withoutAncestors)
+		assert.Equal(t, []string{"open-sauced/engineering"}, aliases(owners))
+		assert.Equal(t, ownerSourceFallback, owners[0].Source)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		disabled := *opts
+		disabled.ancestorStats = nil
+
+		owners := getOwners("src/pkg/deep/nested/a.go", fileStats["src/pkg/deep/nested/a.go"], &disabled)
+		assert.Equal(t, []string{"open-sauced/engineering"}, aliases(owners))
+	})
+
+	t.Run("directory rules", func(t *testing.T) {
+		assert.Equal(t, "src", ancestorDir("src/pkg/"))
+		assert.Equal(t, ".", ancestorDir("src/"))
+		assert.Equal(t, ".", ancestorDir(rootDirectoryRule))
+
+		_, ok := opts.ancestorStats.inheritOwners(rootDirectoryRule, opts)
+		assert.False(t, ok)
+	})
+}
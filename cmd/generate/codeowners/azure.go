@@ -0,0 +1,189 @@
+package codeowners
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/jpmcb/gopherlogs/pkg/colors"
+	"github.com/spf13/cobra"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+)
+
+// azureFileType is the name of the file of Azure Repos required reviewer policies
+const azureFileType = "azure-reviewers.json"
+
+// azurePolicy is an Azure Repos required reviewer branch policy: the reviewers required on
+// pull requests changing the files matched by its path filter. Azure Repos has no CODEOWNERS
+// file, so each rule becomes a policy. The fields are formatted as the arguments of
+// "az repos policy required-reviewer create" they're passed to: semicolon separated.
+type azurePolicy struct {
+	PathFilter          string `json:"pathFilter"`
+	RequiredReviewerIDs string `json:"requiredReviewerIds"`
+	Message             string `json:"message"`
+}
+
+// azurePathFilter gets the Azure Repos path filter matching a rule's files. Azure's "*"
+// matches across directories, so a directory rule matches everything beneath it.
+// Example: "docs/" is "/docs/*"
+func azurePathFilter(rule string) string {
+	if rule == rootDirectoryRule {
+		return "/*"
+	}
+
+	if isDirectoryRule(rule) {
+		return "/" + rule + "*"
+	}
+
+	return "/" + rule
+}
+
+// azureEnclosingRule gets the nearest directory rule enclosing a rule, if any
+func azureEnclosingRule(rule string, rules map[string]bool) (string, bool) {
+	dir := path.Dir(strings.TrimSuffix(rule, "/"))
+	for dir != "." && dir != "/" {
+		if rules[dir+"/"] {
+			return dir + "/", true
+		}
+
+		dir = path.Dir(dir)
+	}
+
+	if rule != rootDirectoryRule && rules[rootDirectoryRule] {
+		return rootDirectoryRule, true
+	}
+
+	return "", false
+}
+
+// getAzureIdentity gets the identity Azure DevOps expects for an owner: a configured identity
+// for the GitHub alias, i.e., a "[Project]\Group" for a team, then the commit email. Owners
+// with neither, i.e., teams and fallback owners without a configured identity, have none.
+func getAzureIdentity(owner *CodeownerStat, config *config.Spec) (string, bool) {
+	if identity, ok := config.AzureIdentities[owner.GitHubAlias]; ok {
+		return identity, true
+	}
+
+	if owner.Email != "" {
+		return owner.Email, true
+	}
+
+	return "", false
+}
+
+// buildAzurePolicies attributes owners to every rule, the same as the other formats, and
+// builds a required reviewer policy for each. Azure requires the reviewers of every policy
+// matching a changed file, unlike CODEOWNERS where the last matching rule wins, so each
+// policy excludes the rules nested beneath it. Owners without an Azure identity and rules
+// without any reviewers are skipped with a warning.
+func buildAzurePolicies(fileStats FileStats, opts *Options) ([]azurePolicy, error) {
+	if opts.granularity == granularityDirectory {
+		fileStats = fileStats.aggregateDirectories(opts)
+	}
+
+	rules := make([]string, 0, len(fileStats))
+	ruleSet := make(map[string]bool, len(fileStats))
+	for rule := range fileStats {
+		if strings.Contains(rule, ";") {
+			opts.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("Skipping %s since Azure path filters can't contain a semicolon\n", rule)
+			continue
+		}
+
+		rules = append(rules, rule)
+		ruleSet[rule] = true
+	}
+	sortRules(rules)
+
+	// the rules nested directly beneath each directory rule
+	nested := make(map[string][]string)
+	for _, rule := range rules {
+		if enclosing, ok := azureEnclosingRule(rule, ruleSet); ok {
+			nested[enclosing] = append(nested[enclosing], rule)
+		}
+	}
+
+	policies := make([]azurePolicy, 0, len(rules))
+	for _, rule := range rules {
+		owners, err := expandOwnerGroups(getOwners(rule, fileStats[rule], opts), opts.config)
+		if err != nil {
+			return nil, fmt.Errorf("error expanding the owners of %s: %w", rule, err)
+		}
+
+		var reviewers []string
+		for _, owner := range sortOwners(owners, opts) {
+			identity, ok := getAzureIdentity(owner, opts.config)
+			if !ok {
+				opts.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("Skipping owner %s of %s with no Azure identity, see azure-identities in the config\n", owner.GitHubAlias, rule)
+				continue
+			}
+
+			reviewers = append(reviewers, identity)
+		}
+
+		if len(reviewers) == 0 {
+			opts.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("Skipping %s since none of its owners have an Azure identity\n", rule)
+			continue
+		}
+
+		filters := []string{azurePathFilter(rule)}
+		for _, child := range nested[rule] {
+			filters = append(filters, "!"+azurePathFilter(child))
+		}
+
+		policies = append(policies, azurePolicy{
+			PathFilter:          strings.Join(filters, ";"),
+			RequiredReviewerIDs: strings.Join(reviewers, ";"),
+			Message:             fmt.Sprintf("Owners of %s", rule),
+		})
+	}
+
+	return policies, nil
+}
+
+// writeAzurePolicies writes the policies as a JSON array. Example:
+//
+//	[
+//	  {
+//	    "pathFilter": "/docs/*;!/docs/api/*",
+//	    "requiredReviewerIds": "brandon@opensauced.pizza;[OpenSauced]\\Docs",
+//	    "message": "Owners of docs/"
+//	  }
+//	]
+func writeAzurePolicies(w io.Writer, policies []azurePolicy) error {
+	data, err := json.MarshalIndent(policies, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling Azure policies: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// generateAzureFile writes the Azure Repos required reviewer policies to the output file,
+// or their diff with --dry-run
+func generateAzureFile(fileStats FileStats, outputPath string, opts *Options, cmd *cobra.Command) error {
+	stopAttribute := opts.profiler.phase(phaseAttribute)
+	policies, err := buildAzurePolicies(fileStats, opts)
+	stopAttribute()
+	if err != nil {
+		return err
+	}
+
+	defer opts.profiler.phase(phaseWrite)()
+
+	var buf bytes.Buffer
+	if err := writeAzurePolicies(&buf, policies); err != nil {
+		return err
+	}
+
+	if opts.dryRun {
+		return writeDryRun(cmd.OutOrStdout(), buf.Bytes(), outputPath, useColor(cmd.OutOrStdout(), opts.noColor))
+	}
+
+	return writeOutputIfChanged(outputPath, buf.Bytes(), opts)
+}
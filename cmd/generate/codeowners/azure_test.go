@@ -0,0 +1,100 @@
+package codeowners
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzurePathFilter(t *testing.T) {
+	assert.Equal(t, "/*", azurePathFilter(rootDirectoryRule))
+	assert.Equal(t, "/docs/api/*", azurePathFilter("docs/api/"))
+	assert.Equal(t, "/docs/README.md", azurePathFilter("docs/README.md"))
+}
+
+func TestBuildAzurePolicies(t *testing.T) {
+	t.Run("directories exclude their nested rules", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+
+		policies, err := buildAzurePolicies(fileStats, opts)
+		require.NoError(t, err)
+		assert.Equal(t, []azurePolicy{
+			{PathFilter: "/*;!/docs/*", RequiredReviewerIDs: "brandon@opensauced.pizza", Message: "Owners of *"},
+			{PathFilter: "/docs/*;!/docs/api/*", RequiredReviewerIDs: "brandon@opensauced.pizza;john@opensauced.pizza", Message: "Owners of docs/"},
+			{PathFilter: "/docs/api/*", RequiredReviewerIDs: "john@opensauced.pizza", Message: "Owners of docs/api/"},
+		}, policies)
+	})
+
+	t.Run("files", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.granularity = granularityFile
+
+		policies, err := buildAzurePolicies(fileStats, opts)
+		require.NoError(t, err)
+		require.Len(t, policies, 4)
+		assert.Equal(t, azurePolicy{PathFilter: "/docs/README.md", RequiredReviewerIDs: "brandon@opensauced.pizza;john@opensauced.pizza", Message: "Owners of docs/README.md"}, policies[0])
+	})
+
+	t.Run("configured identities", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.granularity = granularityFile
+		opts.config.AttributionFallback = []string{"open-sauced/docs"}
+		opts.config.AzureIdentities = map[string]string{
+			"jpmcb":            "john@microsoft.com",
+			"open-sauced/docs": `[OpenSauced]\Docs`,
+		}
+		fileStats["docs/unowned.md"] = AuthorStats{}
+
+		policies, err := buildAzurePolicies(fileStats, opts)
+		require.NoError(t, err)
+		require.Len(t, policies, 5)
+		assert.Equal(t, "brandon@opensauced.pizza;john@microsoft.com", policies[0].RequiredReviewerIDs)
+		assert.Equal(t, azurePolicy{PathFilter: "/docs/unowned.md", RequiredReviewerIDs: `[OpenSauced]\Docs`, Message: "Owners of docs/unowned.md"}, policies[3])
+	})
+
+	t.Run("owners without an identity are skipped", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.granularity = granularityFile
+		opts.config.AttributionFallback = []string{"open-sauced/docs"}
+		fileStats["docs/unowned.md"] = AuthorStats{}
+
+		policies, err := buildAzurePolicies(fileStats, opts)
+		require.NoError(t, err)
+		assert.Len(t, policies, 4)
+		for _, policy := range policies {
+			assert.NotEqual(t, "/docs/unowned.md", policy.PathFilter)
+		}
+	})
+}
+
+func TestGenerateAzureFile(t *testing.T) {
+	fileStats, opts := newGranularityTestData()
+	outputPath := filepath.Join(t.TempDir(), azureFileType)
+
+	require.NoError(t, generateAzureFile(fileStats, outputPath, opts, &cobra.Command{}))
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var policies []azurePolicy
+	require.NoError(t, json.Unmarshal(data, &policies))
+	assert.Len(t, policies, 3)
+
+	t.Run("dry run", func(t *testing.T) {
+		opts.dryRun = true
+		opts.noColor = true
+
+		var out bytes.Buffer
+		cmd := &cobra.Command{}
+		cmd.SetOut(&out)
+
+		require.NoError(t, generateAzureFile(fileStats, filepath.Join(t.TempDir(), azureFileType), opts, cmd))
+		assert.Contains(t, out.String(), `"pathFilter": "/docs/api/*"`)
+	})
+}
@@ -0,0 +1,210 @@
+package codeowners
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// blameIdentities gets the identity attributed with each blamed line: the author or, with
+// --attribute-by committer, the committer of the commit which last changed it. The identity
+// of each commit is cached since a file's lines are mostly changed by a few commits.
+// A nil blameIdentities attributes each line to its author.
+type blameIdentities struct {
+	repo     *git.Repository
+	identity func(*object.Commit) *object.Signature
+	cache    map[plumbing.Hash]*object.Signature
+}
+
+func newBlameIdentities(repo *git.Repository, identity func(*object.Commit) *object.Signature) *blameIdentities {
+	return &blameIdentities{
+		repo:     repo,
+		identity: identity,
+		cache:    make(map[plumbing.Hash]*object.Signature),
+	}
+}
+
+// of gets the identity attributed with the line
+func (bi *blameIdentities) of(line *git.Line) (*object.Signature, error) {
+	if bi == nil {
+		return &object.Signature{Name: line.AuthorName, Email: line.Author}, nil
+	}
+
+	if identity, ok := bi.cache[line.Hash]; ok {
+		return identity, nil
+	}
+
+	commit, err := bi.repo.CommitObject(line.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("could not get commit %s: %w", line.Hash, err)
+	}
+
+	identity := bi.identity(commit)
+	bi.cache[line.Hash] = identity
+	return identity, nil
+}
+
+// blameResult is the number of lines of a file last changed by each author
+type blameResult struct {
+	filename string
+	lines    map[string]*CodeownerStat
+	err      error
+}
+
+// loadBlameLines runs git blame on each file as of the given commit and records the
+// number of its lines last changed by each author as their BlameLines. This measures
+// present day ownership: lines which were since rewritten don't count. Authors whose
+// lines survive, but who made no commits in the analyzed range, are added to the file's
+// stats when allowed. Files which don't exist in the commit are skipped.
+//
+// Lines moved or copied from elsewhere are attributed to their original authors
+// at the given detection level, see moveDetector.
+//
+// Each line is attributed to the identity the given function gets from the commit which
+// last changed it, the same identity its commits are attributed to.
+//
+// Blame is expensive, so files are blamed in parallel by the given number of workers.
+// Each worker gets its own repository from openRepo since a go-git repository isn't
+// safe for concurrent use.
+func loadBlameLines(fs FileStats, from plumbing.Hash, workers int, detect string, openRepo func() (*git.Repository, error), identity func(*object.Commit) *object.Signature, allowed func(*object.Signature) bool) error {
+	filenames := make([]string, 0, len(fs))
+	for filename := range fs {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	var (
+		waitGroup = new(sync.WaitGroup)
+		jobs      = make(chan string)
+		results   = make(chan blameResult)
+		allErrors error
+	)
+
+	for i := 0; i < max(workers, 1); i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+
+			repo, err := openRepo()
+			if err != nil {
+				for filename := range jobs {
+					results <- blameResult{filename: filename, err: err}
+				}
+				return
+			}
+
+			detector := newMoveDetector(repo, detect)
+			identities := newBlameIdentities(repo, identity)
+
+			commit, err := repo.CommitObject(from)
+			for filename := range jobs {
+				if err != nil {
+					results <- blameResult{filename: filename, err: fmt.Errorf("could not get commit %s: %w", from, err)}
+					continue
+				}
+
+				lines, blameErr := blameFile(commit, filename, detector, identities, allowed)
+				results <- blameResult{filename: filename, lines: lines, err: blameErr}
+			}
+		}()
+	}
+
+	go func() {
+		for _, filename := range filenames {
+			jobs <- filename
+		}
+		close(jobs)
+
+		waitGroup.Wait()
+		close(results)
+	}()
+
+	for result := range results {
+		if result.err != nil {
+			allErrors = errors.Join(allErrors, result.err)
+			continue
+		}
+
+		for author, blamed := range result.lines {
+			stat, ok := fs[result.filename][author]
+			if !ok {
+				fs[result.filename][author] = blamed
+				continue
+			}
+
+			stat.BlameLines = blamed.BlameLines
+		}
+	}
+
+	return allErrors
+}
+
+// blameFile counts the lines of a file last changed by each allowed identity, with the lines
+// the detector finds were moved or copied attributed to their original authors. The identities
+// are keyed the same way as AuthorStats. Example: { "First Last <name@domain.com>": { BlameLines: 10 }}
+func blameFile(commit *object.Commit, filename string, detector *moveDetector, identities *blameIdentities, allowed func(*object.Signature) bool) (map[string]*CodeownerStat, error) {
+	blame, err := git.Blame(commit, filename)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not blame %s: %w", filename, err)
+	}
+
+	blamed, err := detector.detect(filename, blame.Lines)
+	if err != nil {
+		return nil, fmt.Errorf("could not detect moved lines in %s: %w", filename, err)
+	}
+
+	lines := make(map[string]*CodeownerStat)
+	for _, line := range blamed {
+		identity, err := identities.of(line)
+		if err != nil {
+			return nil, fmt.Errorf("could not attribute the lines of %s: %w", filename, err)
+		}
+
+		if !allowed(identity) {
+			continue
+		}
+
+		author := authorKey(identity)
+		if _, ok := lines[author]; !ok {
+			lines[author] = &CodeownerStat{
+				Name:  identity.Name,
+				Email: identity.Email,
+			}
+		}
+
+		lines[author].BlameLines++
+	}
+
+	return lines, nil
+}
+
+// blameFileStats loads the blame lines of each file for --rank-by blame, as of the processed ref
+func blameFileStats(po *ProcessOptions, fs FileStats, opts *Options) error {
+	from, err := po.resolveRef()
+	if err != nil {
+		return err
+	}
+
+	workers := opts.blameWorkers
+	openRepo := func() (*git.Repository, error) {
+		return git.PlainOpen(opts.path)
+	}
+
+	// a remote repository cloned into memory can't be opened again
+	if opts.remoteURL != "" {
+		workers = 1
+		openRepo = func() (*git.Repository, error) {
+			return po.repo, nil
+		}
+	}
+
+	return loadBlameLines(fs, from, workers, opts.blameDetect, openRepo, po.identity, po.isAllowedAuthor)
+}
@@ -0,0 +1,108 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBlameLines(t *testing.T) {
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "a\n", "old.go": "old\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"main.go": "a\nj1\nj2\nj3\n", "util.go": "u\n"}},
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "b\nj1\nj2\nj3\n", "old.go": ""}},
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "c\nj1\nj2\nj3\n"}},
+	)
+
+	po := &ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, logger: newTestLogger(t)}
+	fs, err := po.process()
+	require.NoError(t, err)
+
+	// Brandon changed more lines, but John wrote most of the surviving lines
+	assert.Equal(t, 5, fs["main.go"]["Brandon <brandon@opensauced.pizza>"].Lines)
+	assert.Equal(t, 3, fs["main.go"]["John <john@opensauced.pizza>"].Lines)
+
+	opts := &Options{path: dir, blameWorkers: 2}
+	require.NoError(t, blameFileStats(po, fs, opts))
+
+	assert.Equal(t, 1, fs["main.go"]["Brandon <brandon@opensauced.pizza>"].BlameLines)
+	assert.Equal(t, 3, fs["main.go"]["John <john@opensauced.pizza>"].BlameLines)
+	assert.Equal(t, 1, fs["util.go"]["John <john@opensauced.pizza>"].BlameLines)
+
+	// deleted files have no surviving lines
+	assert.Equal(t, 0, fs["old.go"]["Brandon <brandon@opensauced.pizza>"].BlameLines)
+
+	ranked := fs["main.go"].ToRankedSlice(BlameRanker)
+	assert.Equal(t, "john@opensauced.pizza", ranked[0].Email)
+	assert.Equal(t, "brandon@opensauced.pizza", ranked[1].Email)
+}
+
+func TestLoadBlameLinesAddsAuthors(t *testing.T) {
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "a\nb\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"main.go": "a\nc\n"}},
+	)
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	// only John's commit is in the analyzed range, but Brandon's line survives
+	fs := FileStats{"main.go": {"John <john@opensauced.pizza>": {Name: "John", Email: "john@opensauced.pizza", Lines: 2, Commits: 1}}}
+
+	openRepo := func() (*git.Repository, error) {
+		return git.PlainOpen(dir)
+	}
+	authorIdentity := func(commit *object.Commit) *object.Signature { return &commit.Author }
+
+	t.Run("allowed", func(t *testing.T) {
+		allowAll := func(*object.Signature) bool { return true }
+		require.NoError(t, loadBlameLines(fs, head.Hash(), 1, blameDetectNone, openRepo, authorIdentity, allowAll))
+
+		require.Contains(t, fs["main.go"], "Brandon <brandon@opensauced.pizza>")
+		assert.Equal(t, 1, fs["main.go"]["Brandon <brandon@opensauced.pizza>"].BlameLines)
+		assert.Equal(t, 0, fs["main.go"]["Brandon <brandon@opensauced.pizza>"].Commits)
+		assert.Equal(t, 1, fs["main.go"]["John <john@opensauced.pizza>"].BlameLines)
+	})
+
+	t.Run("not allowed", func(t *testing.T) {
+		delete(fs["main.go"], "Brandon <brandon@opensauced.pizza>")
+
+		onlyJohn := func(identity *object.Signature) bool { return identity.Name == "John" }
+		require.NoError(t, loadBlameLines(fs, head.Hash(), 1, blameDetectNone, openRepo, authorIdentity, onlyJohn))
+
+		assert.NotContains(t, fs["main.go"], "Brandon <brandon@opensauced.pizza>")
+	})
+}
+
+func TestBlameFileStatsAttributeBy(t *testing.T) {
+	dir, repo := newTestRepo(t)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("a\nb\n"), 0600))
+	_, err = worktree.Add("main.go")
+	require.NoError(t, err)
+
+	_, err = worktree.Commit("rebased commit", &git.CommitOptions{
+		Author:    &object.Signature{Name: "John", Email: "john@opensauced.pizza", When: time.Now()},
+		Committer: &object.Signature{Name: "Brandon", Email: "brandon@opensauced.pizza", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	// the surviving lines are credited to the committers the commits are attributed to
+	po := &ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, attributeBy: attributeByCommitter, logger: newTestLogger(t)}
+	fs, err := po.process()
+	require.NoError(t, err)
+
+	require.NoError(t, blameFileStats(po, fs, &Options{path: dir, blameWorkers: 1}))
+
+	assert.Len(t, fs["main.go"], 1)
+	assert.Equal(t, 2, fs["main.go"]["Brandon <brandon@opensauced.pizza>"].BlameLines)
+}
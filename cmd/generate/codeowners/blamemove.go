@@ -0,0 +1,237 @@
+package codeowners
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"unicode"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// The levels of detecting lines moved or copied from elsewhere with --rank-by blame,
+// like git blame's -M and -C
+const (
+	blameDetectNone = "none"
+	blameDetectMove = "move"
+	blameDetectCopy = "copy"
+)
+
+var blameDetections = []string{blameDetectNone, blameDetectMove, blameDetectCopy}
+
+// The minimum number of alphanumeric characters a block of lines must have to be
+// detected as moved or copied, the same as git blame's defaults. Shorter blocks,
+// like a lone closing brace, match too often to credit their original author.
+const (
+	blameMoveScore = 20
+	blameCopyScore = 40
+)
+
+// blameSourceKey identifies the blame of a file as of a commit
+type blameSourceKey struct {
+	commit   plumbing.Hash
+	filename string
+}
+
+// moveDetector reattributes the lines of a blame which were moved or copied from elsewhere
+// to their original authors. With blameDetectMove, lines moved within a file are detected.
+// With blameDetectCopy, lines moved or copied from the other files changed in the same
+// commit are detected too. Each blame worker has its own, since it caches the blames of
+// the files lines were moved from and a go-git repository isn't safe for concurrent use.
+type moveDetector struct {
+	repo  *git.Repository
+	level string
+
+	// the lines of each file blamed as of a commit, by their text
+	sources map[blameSourceKey]map[string]*git.Line
+}
+
+// newMoveDetector creates a moveDetector, or nil when detection is disabled
+func newMoveDetector(repo *git.Repository, level string) *moveDetector {
+	if level == "" || level == blameDetectNone {
+		return nil
+	}
+
+	return &moveDetector{
+		repo:    repo,
+		level:   level,
+		sources: make(map[blameSourceKey]map[string]*git.Line),
+	}
+}
+
+// detect gets the lines of a file's blame with the moved or copied lines attributed to
+// their original authors. Each block of consecutive lines introduced by the same commit
+// is looked up in the commit's parent, following moves through history.
+func (d *moveDetector) detect(filename string, lines []*git.Line) ([]*git.Line, error) {
+	if d == nil {
+		return lines, nil
+	}
+
+	detected := slices.Clone(lines)
+	for start := 0; start < len(detected); {
+		end := start + 1
+		for end < len(detected) && detected[end].Hash == detected[start].Hash {
+			end++
+		}
+
+		err := d.reattribute(filename, detected[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		start = end
+	}
+
+	return detected, nil
+}
+
+// reattribute attributes the lines of a block introduced by the same commit to the authors of
+// the lines with the same text in the commit's parent, for each run of matching lines long enough
+func (d *moveDetector) reattribute(filename string, block []*git.Line) error {
+	commit, err := d.repo.CommitObject(block[0].Hash)
+	if err != nil {
+		return fmt.Errorf("could not get commit %s: %w", block[0].Hash, err)
+	}
+
+	// lines introduced by the first commit can't have been moved
+	if commit.NumParents() == 0 {
+		return nil
+	}
+
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return fmt.Errorf("could not get the parent of commit %s: %w", commit.Hash, err)
+	}
+
+	sources, err := d.sourceFiles(filename, commit, parent)
+	if err != nil {
+		return err
+	}
+
+	origins := make([]*git.Line, len(block))
+	for i, line := range block {
+		for _, source := range sources {
+			lines, err := d.sourceLines(parent, source)
+			if err != nil {
+				return err
+			}
+
+			if origin, ok := lines[line.Text]; ok {
+				origins[i] = origin
+				break
+			}
+		}
+	}
+
+	// each run of consecutive matching lines is scored on its own, so common lines
+	// scattered through the block, like closing braces, aren't credited along with a moved run
+	for start := 0; start < len(origins); {
+		if origins[start] == nil {
+			start++
+			continue
+		}
+
+		end, score := start, 0
+		for end < len(origins) && origins[end] != nil {
+			score += alphanumericCount(block[end].Text)
+			end++
+		}
+
+		if score >= d.minScore() {
+			copy(block[start:end], origins[start:end])
+		}
+
+		start = end
+	}
+
+	return nil
+}
+
+// sourceFiles gets the files of the parent lines of the commit may have been moved from:
+// the same file, followed by the other files changed in the commit when detecting copies
+func (d *moveDetector) sourceFiles(filename string, commit, parent *object.Commit) ([]string, error) {
+	sources := []string{filename}
+	if d.level != blameDetectCopy {
+		return sources, nil
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("could not get the tree of commit %s: %w", commit.Hash, err)
+	}
+
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("could not get the tree of commit %s: %w", parent.Hash, err)
+	}
+
+	changes, err := parentTree.Diff(tree)
+	if err != nil {
+		return nil, fmt.Errorf("could not diff commit %s: %w", commit.Hash, err)
+	}
+
+	for _, change := range changes {
+		if change.From.Name != "" && !slices.Contains(sources, change.From.Name) {
+			sources = append(sources, change.From.Name)
+		}
+	}
+
+	return sources, nil
+}
+
+// sourceLines gets the lines of a file as of a commit by their text, with any lines it
+// moved or copied itself attributed to their original authors. When several lines have
+// the same text, the first is used. Files which don't exist in the commit have no lines.
+func (d *moveDetector) sourceLines(commit *object.Commit, filename string) (map[string]*git.Line, error) {
+	key := blameSourceKey{commit: commit.Hash, filename: filename}
+	if lines, ok := d.sources[key]; ok {
+		return lines, nil
+	}
+
+	blame, err := git.Blame(commit, filename)
+	if errors.Is(err, object.ErrFileNotFound) {
+		d.sources[key] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not blame %s at commit %s: %w", filename, commit.Hash, err)
+	}
+
+	detected, err := d.detect(filename, blame.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string]*git.Line, len(detected))
+	for _, line := range detected {
+		if _, ok := lines[line.Text]; !ok {
+			lines[line.Text] = line
+		}
+	}
+
+	d.sources[key] = lines
+	return lines, nil
+}
+
+// minScore gets the minimum number of alphanumeric characters of a moved or copied block
+func (d *moveDetector) minScore() int {
+	if d.level == blameDetectCopy {
+		return blameCopyScore
+	}
+
+	return blameMoveScore
+}
+
+// alphanumericCount counts the letters and digits in a line
+func alphanumericCount(text string) int {
+	count := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,97 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	blameMoveMain   = "func main() {\n\tfmt.Println(1)\n\tfmt.Println(2)\n\tfmt.Println(3)\n}\n"
+	blameMoveAnswer = "func answer() int {\n\treturn theAnswerToLifeTheUniverseAndEverything\n}\n"
+)
+
+func TestMoveDetector(t *testing.T) {
+	brandon := "Brandon <brandon@opensauced.pizza>"
+	john := "John <john@opensauced.pizza>"
+	allowAll := func(*object.Signature) bool { return true }
+
+	blameLines := func(t *testing.T, commits []testCommit, filename string, level string) map[string]int {
+		t.Helper()
+
+		_, repo := newTestRepo(t, commits...)
+		head, err := repo.Head()
+		require.NoError(t, err)
+		commit, err := repo.CommitObject(head.Hash())
+		require.NoError(t, err)
+
+		lines, err := blameFile(commit, filename, newMoveDetector(repo, level), nil, allowAll)
+		require.NoError(t, err)
+
+		counts := make(map[string]int, len(lines))
+		for author, stat := range lines {
+			counts[author] = stat.BlameLines
+		}
+
+		return counts
+	}
+
+	written := testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{
+		"main.go": "package main\n\n" + blameMoveAnswer + "\n" + blameMoveMain,
+		"util.go": "package main\n",
+	}}
+
+	// John moves the answer below main
+	moved := testCommit{"John", "john@opensauced.pizza", map[string]string{
+		"main.go": "package main\n\n" + blameMoveMain + "\n" + blameMoveAnswer,
+	}}
+
+	// John moves the answer from main.go into util.go
+	copied := testCommit{"John", "john@opensauced.pizza", map[string]string{
+		"main.go": "package main\n\n" + blameMoveMain,
+		"util.go": "package main\n\n" + blameMoveAnswer,
+	}}
+
+	t.Run("moved within a file", func(t *testing.T) {
+		commits := []testCommit{written, moved}
+
+		assert.Equal(t, map[string]int{brandon: 7, john: 4}, blameLines(t, commits, "main.go", blameDetectNone))
+		assert.Equal(t, map[string]int{brandon: 11}, blameLines(t, commits, "main.go", blameDetectMove))
+		assert.Equal(t, map[string]int{brandon: 11}, blameLines(t, commits, "main.go", blameDetectCopy))
+	})
+
+	t.Run("moved between files", func(t *testing.T) {
+		commits := []testCommit{written, moved, copied}
+
+		assert.Equal(t, map[string]int{brandon: 1, john: 4}, blameLines(t, commits, "util.go", blameDetectNone))
+		assert.Equal(t, map[string]int{brandon: 1, john: 4}, blameLines(t, commits, "util.go", blameDetectMove))
+		assert.Equal(t, map[string]int{brandon: 5}, blameLines(t, commits, "util.go", blameDetectCopy))
+	})
+
+	t.Run("short blocks are not detected", func(t *testing.T) {
+		commits := []testCommit{
+			{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "package main\n\nvar a = 1\n\n" + blameMoveMain}},
+			{"John", "john@opensauced.pizza", map[string]string{"main.go": "package main\n\n" + blameMoveMain + "\nvar a = 1\n"}},
+		}
+
+		assert.Equal(t, map[string]int{brandon: 7, john: 2}, blameLines(t, commits, "main.go", blameDetectMove))
+	})
+
+	t.Run("common lines outside a moved run are not detected", func(t *testing.T) {
+		// John moves the answer below main, followed by a new function whose closing brace
+		// matches one of Brandon's but isn't part of the moved lines
+		commits := []testCommit{written, {"John", "john@opensauced.pizza", map[string]string{
+			"main.go": "package main\n\n" + blameMoveMain + "\n" + blameMoveAnswer + "func other() {\n}\n",
+		}}}
+
+		assert.Equal(t, map[string]int{brandon: 7, john: 6}, blameLines(t, commits, "main.go", blameDetectNone))
+		assert.Equal(t, map[string]int{brandon: 11, john: 2}, blameLines(t, commits, "main.go", blameDetectMove))
+	})
+}
+
+func TestAlphanumericCount(t *testing.T) {
+	assert.Equal(t, 0, alphanumericCount("\t}"))
+	assert.Equal(t, 13, alphanumericCount("func answer() int {"))
+}
@@ -1,12 +1,22 @@
 package codeowners
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/jpmcb/gopherlogs"
 	"github.com/jpmcb/gopherlogs/pkg/colors"
 	"github.com/spf13/cobra"
@@ -22,28 +32,393 @@ type Options struct {
 	// the path to the git repository on disk to generate a codeowners file for
 	path string
 
-	// whether to generate an agnostic "OWENRS" style codeowners file.
-	// The default should be to generate a GitHub style "CODEOWNERS" file.
-	ownersStyleFile bool
+	// the URL of a remote repository to analyze without a working tree.
+	// When set, path is the remote URL instead of a path on disk.
+	remoteURL string
+
+	// literal file paths and glob patterns, relative to the repository root,
+	// used to scope which files are analyzed. Empty means every file.
+	pathPatterns []string
+
+	// paths and globs, relative to the repository root, which are pruned from
+	// analysis entirely: their git history is never read
+	excludePaths []string
+
+	// the file extensions analyzed and pruned from analysis, i.e., ".go".
+	// Excluded extensions take precedence over included ones.
+	includeExtensions []string
+	excludeExtensions []string
+
+	// the maximum directory depth of analyzed files. Files at the top level
+	// have a depth of 0. A negative depth analyzes every file.
+	maxDepth int
+
+	// paths and globs, relative to the repository root, which are analyzed
+	// but removed from the output
+	ignorePatterns []string
+
+	// the regex matched against the top lines of each file to detect generated
+	// files, which are removed from the output. Nil when not set.
+	generatedRegex *regexp.Regexp
+
+	// the format of the generated file. The default is a GitHub style "CODEOWNERS" file.
+	// An agnostic "OWNERS" style codeowners file may also be generated.
+	format string
+
+	// the granularity of the generated rules: one rule per file (default)
+	// or one rule per directory
+	granularity string
+
+	// the name of the strategy ranking the authors of each file and the ranker itself,
+	// which breaks ties with the --seed when set
+	rankBy string
+	ranker Ranker
+
+	// the number of files blamed in parallel with --rank-by blame
+	blameWorkers int
+
+	// the level of detecting lines moved or copied from elsewhere with --rank-by blame,
+	// attributing them to their original authors like git blame's -M and -C
+	blameDetect string
+
+	// whether to group files with the same owners into directory and extension
+	// patterns, and the tracked files which guard against a pattern matching
+	// files with different owners
+	patternMode  bool
+	trackedFiles map[string]struct{}
+
+	// the patterns of an existing CODEOWNERS file with --patterns-from, whose
+	// owners are recomputed from the files each matches, keeping the patterns
+	sourcePatterns []string
+
+	// the layout of each owner in an "OWNERS" style file: either the name and email
+	// nested on separate lines (default) or combined as "Name <email>" on one line
+	ownersLayout string
+
+	// the order of the owners of each rule: by rank (default) or alphabetically by alias
+	ownerSort string
+
+	// whether to write owners without the "@" which mentions them, for informational
+	// files which shouldn't notify anyone. Not valid for CODEOWNERS files.
+	noMention bool
+
+	// the identity of each owner in an "OWNERS" style file: their name and email (default),
+	// only their email, or their GitHub alias. Owners without the identity fall back to the others.
+	ownersIdentity string
+
+	// the preferred source of each owner's name in an "OWNERS" style file: their git name
+	// (default), their GitHub login, or their display name in the config. Owners without
+	// a name from the source fall back to the others.
+	ownersName string
+
+	// the identity of each owner in the GitHub and GitLab formats: their @alias or their email
+	githubIdentity string
 
 	// where the output file will go
 	outputPath string
 
+	// the path of the output file, overriding the output path. May be a template
+	// with "{format}" and "{dir}" placeholders
+	outputFile string
+
+	// whether to normalize each path, i.e., "./src//main.go" to "src/main.go",
+	// before its rule is written
+	normalizePaths bool
+
+	// whether to annotate each rule with a trailing comment noting
+	// how its owners were derived. Example: "# source: override"
+	annotateSource bool
+
+	// whether to keep the order of the rules in an existing output file, and the
+	// patterns of its rules, so regenerating it produces a minimal diff
+	preserveOrder    bool
+	existingPatterns []string
+
+	// whether to fail instead of writing a line GitHub would reject or ignore
+	strict bool
+
+	// the line ending of the output files: "lf" (default), "crlf",
+	// or "auto" to keep the line ending of an existing output file
+	lineEnding string
+
 	// the number of days to look back
 	previousDays int
 
-	logger   gopherlogs.Logger
-	tty      bool
-	loglevel int
+	// the maximum number of the most recent commits to walk. 0 walks every commit in the range.
+	maxCommits int
+
+	// the minimum number of commits which changed a file in the range for it to be
+	// included in the output, focusing it on frequently changed files
+	minChurn int
+
+	// the maximum number of owners to attribute to each file
+	maxOwners int
+
+	// the maximum number of owners written on a single rule in the github and gitlab
+	// formats. The owners are still attributed, i.e., for --stats-only. 0 doesn't cap them.
+	maxOwnersPerLine int
+
+	// the identity of each commit which is attributed: its author (default) or its
+	// committer, who did the integration work in rebase heavy workflows
+	attributeBy string
+
+	// whether to only walk the first parent of each commit, following the mainline history
+	firstParent bool
+
+	// the share of credit, from 0 to 1, given for the changes of a merge commit
+	// when weighMerges is set. Merge commits get full credit otherwise.
+	mergeWeight float64
+	weighMerges bool
+
+	// whether to net commits which were later reverted, and their reverts, out of the stats
+	netReverts bool
+
+	// how renames are credited, one of renameCredits
+	creditRenameTo string
+
+	// the multiplier of the credit given to the author who created each file
+	// when boostCreator is set
+	creatorBoost float64
+	boostCreator bool
+
+	// only credit the changes of commits confined to each directory rule's directory,
+	// in directory granularity, so tree-wide sweeps don't rank as ownership
+	pathLocalOnly bool
+
+	// the number of lines of each file in the analyzed tree, which weights its contribution
+	// to its directory rule, in directory granularity, with --weight-by-size. Nil when not set.
+	fileSizes map[string]int
+
+	// whether to skip the directory rules, in directory granularity, of directories with
+	// no file left in the analyzed tree, i.e., whose files have all since been deleted
+	skipEmptyDirectories bool
+
+	// whether to attribute the files of each submodule from its own history
+	followSubmodules bool
+
+	// whether to walk the history from the repository's default branch
+	// (origin/HEAD) instead of a detached HEAD, such as in CI checkouts, and
+	// the default branch's ref, resolved when the repository is opened. The ref
+	// is empty when HEAD isn't detached, or the default branch isn't known.
+	useDefaultBranch bool
+	defaultBranchRef string
+
+	// whether to proceed with a shallow clone, whose truncated history attributes
+	// most files to the committers of its most recent commits
+	allowShallow bool
+
+	// the revision, such as a commit SHA, to generate the ownership as of: both the
+	// history and the file list are bounded by its commit, which is resolved when the
+	// repository is opened
+	at       string
+	atCommit *object.Commit
+
+	// whether files without qualifying contributors inherit the owners of their
+	// nearest ancestor directory instead of the fallback attribution, and the
+	// aggregated stats of each directory they're inherited from
+	inheritOwners bool
+	ancestorStats ancestorStats
+
+	// whether to read owners pinned inline in each file with a
+	// "pizza-owners:" directive, and the owners pinned in each file
+	readInlineOwners bool
+	inlineOwners     map[string][]string
+
+	// whether to generate a hierarchy of Kubernetes style "OWNERS" files,
+	// one per directory, instead of a single file
+	ownersHierarchy bool
+
+	// the phase timings printed with --profile, nil when disabled,
+	// and where to write a pprof CPU profile
+	profiler   *profiler
+	cpuProfile string
+
+	// history derives the file stats from the repository's history.
+	// Defaults to walking the git log with go-git when unset.
+	history HistoryProvider
+
+	// the path of a CSV or JSON contributions manifest to read the file stats
+	// from instead of the git log, for code without git metadata
+	manifestPath string
+
+	// whether to process and write the output one top level directory at a time
+	// to reduce peak memory usage
+	stream bool
+
+	// the path of a single file, relative to the repository root, to print
+	// a trace of the ownership of instead of generating a file
+	explain string
+
+	// the path of a CSV sidecar file to write the number of distinct contributors
+	// to each file to, sorted by descending count. Empty skips the sidecar.
+	contributorCountFile string
+
+	// the path of a Markdown file to also write a table of each rule and its owners to,
+	// for humans to read. Empty skips the summary.
+	markdownSummary string
+
+	// the directory to also write a CODEOWNERS file of the rules each team owns to,
+	// for team-scoped review routing. Empty skips splitting the rules by team.
+	splitByTeam string
+
+	// the owners of an explicit rule of the output file itself, written last so it overrides
+	// the generated rules. Empty warns when a generated rule owns the output file instead.
+	outputFileOwners []string
+
+	// whether to regenerate the output whenever a tracked file, the git history, or the
+	// config changes, and whether the command is already rerunning to regenerate it
+	watch    bool
+	watching bool
+
+	// whether to print the generated file, or a diff against the existing file, instead
+	// of writing it, and whether to disable the diff's color
+	dryRun  bool
+	noColor bool
+
+	// the GitHub repository, "owner/repo", whose file the generated file is compared
+	// against instead of writing it, and the client the file is fetched with
+	checkAgainstRemote string
+	remoteCheckClient  *githubClient
+
+	// whether to write the output file even when its contents are unchanged,
+	// updating its modification time
+	alwaysWrite bool
+
+	// whether to leave out the rules only the fallback owns, which a "*" catch-all
+	// rule with the fallback owners covers instead
+	omitFallbackOnly bool
+
+	// whether to write the UTF-8 byte order mark at the start of the output files
+	bom bool
+
+	// whether to fail instead of only warning when every file is filtered out,
+	// leaving the generated file with only its header
+	failOnEmpty bool
+
+	// the command which weighs each commit, reading its metadata as JSON on stdin
+	// and writing its weight to stdout
+	scoreCommand string
+
+	// whether to replace the owners in the config's team-map with their teams
+	useTeams bool
+
+	// the path of an existing CODEOWNERS file to lint instead of generating a file,
+	// and the format of its findings
+	lintPath   string
+	lintFormat string
+
+	// the GitHub org whose members are attributed by their login when their emails
+	// aren't attributed in the config, and the resolver of their logins
+	attributeOrgMembers string
+	orgMembers          *orgMemberResolver
+
+	// the GitHub org whose departed members are removed from the owners, how long
+	// its members are cached, and the resolver of its members
+	activeMembersOnly string
+	membersCacheTTL   time.Duration
+	activeMembers     *orgMemberResolver
+
+	// whether to verify that every owner listed in the config exists on GitHub
+	// instead of generating a file
+	verifyConfig bool
+
+	// whether to only print aggregate per owner ownership stats
+	// instead of generating a file, and the format to print them in
+	statsOnly   bool
+	statsFormat string
+
+	// the format of the owner index with --format owner-index: text or JSON
+	ownerIndexFormat string
+
+	logger    gopherlogs.Logger
+	tty       bool
+	loglevel  int
+	logFormat string
 
 	// telemetry for capturing CLI events via PostHog
 	telemetry *utils.PosthogCliClient
 
 	config           *config.Spec
 	configLoadedPath string
+
+	// the user's global config merged under the config, if one was loaded
+	globalConfigPath string
+
+	// the configs whose owners are compared against the same history with
+	// --config-a and --config-b, instead of generating a file
+	configA *config.Spec
+	configB *config.Spec
 }
 
-const codeownersLongDesc string = `Generates a CODEOWNERS file for a given git repository. The generated file specifies up to 3 owners for EVERY file in the git tree based on the number of lines touched in that specific file over the specified range of time.
+// The supported output formats
+const (
+	formatGitHub    = "github"
+	formatOwners    = "owners"
+	formatBitbucket = "bitbucket"
+	formatGitea     = "gitea"
+	formatGitLab    = "gitlab"
+
+	// formatOwnerIndex is the inverse of the other formats, listing the files of each owner
+	formatOwnerIndex = "owner-index"
+
+	// formatAzure is a JSON file of Azure Repos required reviewer policies, since
+	// Azure DevOps has no CODEOWNERS file
+	formatAzure = "azure"
+)
+
+var outputFormats = []string{formatGitHub, formatOwners, formatBitbucket, formatGitea, formatGitLab, formatOwnerIndex, formatAzure}
+
+// The identities of a commit which may be attributed
+const (
+	attributeByAuthor    = "author"
+	attributeByCommitter = "committer"
+)
+
+var attributeByIdentities = []string{attributeByAuthor, attributeByCommitter}
+
+// The supported layouts of owners in the OWNERS format
+const (
+	ownersLayoutNested   = "nested"
+	ownersLayoutCombined = "combined"
+)
+
+var ownersLayouts = []string{ownersLayoutNested, ownersLayoutCombined}
+
+// The supported orders of the owners of each rule
+const (
+	ownerSortRank  = "rank"
+	ownerSortAlpha = "alpha"
+)
+
+var ownerSorts = []string{ownerSortRank, ownerSortAlpha}
+
+// The supported identities of owners in the OWNERS format
+const (
+	ownersIdentityName  = "name"
+	ownersIdentityEmail = "email"
+	ownersIdentityAlias = "alias"
+)
+
+var ownersIdentities = []string{ownersIdentityName, ownersIdentityEmail, ownersIdentityAlias}
+
+// The supported sources of owners' names in the OWNERS format
+const (
+	ownersNameGit     = "git"
+	ownersNameLogin   = "login"
+	ownersNameDisplay = "display"
+)
+
+var ownersNames = []string{ownersNameGit, ownersNameLogin, ownersNameDisplay}
+
+// The supported identities of owners in the GitHub and GitLab formats
+const (
+	githubIdentityAlias = "alias"
+	githubIdentityEmail = "email"
+)
+
+var githubIdentities = []string{githubIdentityAlias, githubIdentityEmail}
+
+const codeownersLongDesc string = `Generates a CODEOWNERS file for a given git repository. The generated file specifies up to 3 owners (configurable with --max-owners) for EVERY file in the git tree based on the number of lines touched in that specific file over the specified range of time.
 
 Configuration:
 The command requires a .sauced.yaml file for accurate attribution. This file maps 
@@ -53,13 +428,23 @@ commit email addresses to GitHub usernames. The command looks for this file in t
 2. In the user's home directory (~/.sauced.yaml) if not found in the repository
 
 If you run the command on a specific path, it will first look for .sauced.yaml in that 
-path. If not found, it will fall back to ~/.sauced.yaml.`
+path. If not found, it will fall back to ~/.sauced.yaml.
+
+The --config flag may also be an HTTP(S) URL to a central config. Remote configs are
+cached for --config-cache-ttl. Set the PIZZA_CONFIG_AUTH_HEADER environment variable
+to send an "Authorization" header when fetching it.
+
+Organization wide defaults, like common attributions or the fallback, may be kept in a
+global config at ~/.config/pizza/codeowners.yaml ($XDG_CONFIG_HOME/pizza/codeowners.yaml
+when set). It's merged under the repository's config: values in the repository's config
+take precedence over the global config's, which take precedence over the built-in defaults.
+Attributions and other mappings are merged by key. Pass --no-global-config to ignore it.`
 
 func NewCodeownersCommand() *cobra.Command {
 	opts := &Options{}
 
 	cmd := &cobra.Command{
-		Use:   "codeowners path/to/repo [flags]",
+		Use:   "codeowners path/to/repo | 'path/glob/**' [flags]",
 		Short: "Generate a CODEOWNERS file for a GitHub repository using a \"~/.sauced.yaml\" config",
 		Long:  codeownersLongDesc,
 		Example: `
@@ -69,149 +454,1551 @@ pizza generate codeowners .
 # Generate CODEOWNERS file for a specific repository
 pizza generate codeowners /path/to/your/repo
 
+# Generate CODEOWNERS file for a bare clone or a remote repository
+pizza generate codeowners /path/to/repo.git
+pizza generate codeowners https://github.com/open-sauced/pizza-cli.git --output-path .
+
+# Generate CODEOWNERS file for only the Go files in a repository
+pizza generate codeowners 'src/**/*.go'
+
 # Generate CODEOWNERS file analyzing the last 180 days
 pizza generate codeowners . --range 180
 
+# Only walk the 1000 most recent commits of a large repository, for approximate results
+pizza generate codeowners . --max-commits 1000
+
 # Generate an OWNERS style file instead of CODEOWNERS
-pizza generate codeowners . --owners-style-file
+pizza generate codeowners . --format owners
+
+# Only include files changed by 10 or more commits
+pizza generate codeowners . --min-churn 10
+
+# Require at least 2 owners with 3 or more commits for each file
+pizza generate codeowners . --min-owners 2 --min-commits 3
+
+# Use the fallback attribution unless the top author changed at least half of a file's lines
+pizza generate codeowners . --min-confidence 0.5
+
+# Generate a BitBucket code owners file, identifying owners by email or account UUID
+pizza generate codeowners . --format bitbucket
+
+# Generate a Gitea / Forgejo CODEOWNERS file
+pizza generate codeowners . --format gitea
+
+# Generate a GitLab CODEOWNERS file, grouped into the config's gitlab-sections with their required approvals
+pizza generate codeowners . --format gitlab --output-path .gitlab
+
+# Generate Azure Repos required reviewer policies, to create with "az repos policy required-reviewer create"
+pizza generate codeowners . --format azure
+
+# Generate an OWNERS style file with each owner as "Name <email>" on a single line
+pizza generate codeowners . --format owners --owners-layout combined
+
+# List the owners of each rule alphabetically instead of by rank
+pizza generate codeowners . --owner-sort alpha
+
+# Generate an index of each owner's files, listing bare usernames which don't notify anyone
+pizza generate codeowners . --format owner-index --no-mention
+
+# Generate an OWNERS style file listing each owner's GitHub @alias
+pizza generate codeowners . --format owners --owners-identity alias
+
+# Generate an OWNERS style file naming each owner by the config's display-names
+pizza generate codeowners . --format owners --owners-name display
+
+# Generate a CODEOWNERS file listing each owner's commit email instead of their @alias
+pizza generate codeowners . --github-identity email
+
+# Files nobody qualifies as an owner of inherit the owners of their nearest ancestor directory
+pizza generate codeowners . --inherit-owners
+
+# Generate a rule for each directory instead of each file
+pizza generate codeowners . --granularity directory
+
+# Only credit commits confined to each directory when ranking its owners
+pizza generate codeowners . --granularity directory --path-local-only
+
+# Don't write rules for directories whose files have all been deleted
+pizza generate codeowners . --granularity directory --skip-empty-directories
+
+# Annotate each rule with how its owners were derived, i.e., "# source: override"
+pizza generate codeowners . --annotate-source
+
+# Regenerate an existing CODEOWNERS file keeping its order of rules
+pizza generate codeowners . --preserve-order
+
+# Rank the authors of each file by their most recent commit instead of lines changed
+pizza generate codeowners . --rank-by recency
+
+# Rank the authors of each file by how many of its current lines they last changed
+pizza generate codeowners . --rank-by blame --blame-workers 4
+
+# Credit moved or copied code to its original authors when ranking by blame, like git blame -C
+pizza generate codeowners . --rank-by blame --blame-detect copy
+
+# Break ties between equally ranked authors randomly, but the same way on every run
+pizza generate codeowners . --seed 42
+
+# Rank authors by the number of distinct days they committed on, so a single burst of commits counts less
+pizza generate codeowners . --rank-by commit-days
+
+# Group files with the same owners into patterns like "/docs/" and "/src/*.go"
+pizza generate codeowners . --pattern-mode
+
+# Refresh the owners of a hand-written CODEOWNERS file, keeping its patterns
+pizza generate codeowners . --patterns-from .github/CODEOWNERS
+
+# Honor owners pinned in files with a "// pizza-owners: @alice @bob" comment
+pizza generate codeowners . --read-inline-owners
+
+# Attribute changes to the committer who applied them instead of their author
+pizza generate codeowners . --attribute-by committer
+
+# Only follow the mainline history, ignoring commits from merged branches
+pizza generate codeowners . --first-parent
+
+# Give merge commits a quarter of the credit of regular commits
+pizza generate codeowners . --count-merges-as 0.25
+
+# Double the credit of the author who created each file
+pizza generate codeowners . --boost-creator 2
+
+# Don't credit commits which were later reverted, nor their reverts
+pizza generate codeowners . --net-reverts
+
+# Follow renames, crediting a file's authors before it was renamed instead of the renamer
+pizza generate codeowners . --credit-rename-to none
+
+# Weigh each commit with a script, e.g., to ignore formatting-only commits
+pizza generate codeowners . --score-command ./scripts/score-commit.sh
+
+# Attribute the files in submodules from each submodule's own history
+pizza generate codeowners . --follow-submodules
+
+# Generate from a shallow CI checkout, accepting its truncated history
+pizza generate codeowners . --allow-shallow
+
+# Regenerate the ownership exactly as it was at a past commit
+pizza generate codeowners . --at 3f2c1e9
+
+# Walk the default branch's history in a CI checkout with a detached HEAD
+pizza generate codeowners . --use-default-branch
+
+# Only attribute commits from authors with a company email
+pizza generate codeowners . --only-authors '*@opensauced.pizza'
+
+# Skip analyzing a vendored directory entirely to speed up generation
+pizza generate codeowners . --exclude-path vendor
+
+# Only analyze Go and TypeScript files, skipping generated protobuf code
+pizza generate codeowners . --include-ext .go,.ts --exclude-path '**/*.pb.go'
+
+# Leave out files marked "// Code generated ... DO NOT EDIT.", or matching a custom header
+pizza generate codeowners . --exclude-generated
+pizza generate codeowners . --exclude-generated --generated-regex '@generated'
+
+# Only analyze files in the top level and its direct subdirectories
+pizza generate codeowners . --max-depth 1
+
+# Trace how the owners of a single file are derived
+pizza generate codeowners . --explain src/main.go
+
+# Print how many files each owner would own and the bus factor of each
+# top level directory, without generating a file
+pizza generate codeowners . --stats-only --stats-format json
+
+# Generate an index of the files each owner owns, as JSON
+pizza generate codeowners . --format owner-index --owner-index-format json
+
+# Generate a Kubernetes style OWNERS file in each directory
+pizza generate codeowners . --format owners --owners-hierarchy
+
+# Generate each directory's OWNERS file beneath a separate directory
+pizza generate codeowners . --format owners --owners-hierarchy --output-file 'owners/{dir}/OWNERS'
+
+# Also write the number of contributors to each file, most first, to find coordination hotspots
+pizza generate codeowners . --contributor-count-file contributors.csv
+
+# Also write a human-readable table of the owners for onboarding docs
+pizza generate codeowners . --markdown-summary OWNERS.md
+
+# Also write the rules each team owns to its own file, i.e., teams/open-sauced/engineering.CODEOWNERS
+pizza generate codeowners . --use-teams --split-by-team teams
+
+# Own the generated file explicitly instead of by whoever last regenerated it
+pizza generate codeowners . --output-file-owner open-sauced/maintainers
+
+# Regenerate the CODEOWNERS file whenever a tracked file, a commit, or the config changes
+pizza generate codeowners . --watch
+
+# Print how long each phase took and write a CPU profile for "go tool pprof"
+pizza generate codeowners . --profile --cpu-profile cpu.pprof
+
+# Generate CODEOWNERS file for vendored code without git metadata from a contributions manifest
+pizza generate codeowners ./vendor/lib --from-manifest contributions.csv
+
+# Bootstrap a .sauced.yaml from the authors in the last year of history
+pizza generate codeowners . --init-config --range 365
+
+# Print the JSON schema of the .sauced.yaml file, i.e., for editor validation
+pizza generate codeowners --config-schema > sauced.schema.json
+
+# Attribute contributors missing from the .sauced.yaml file who are members of a GitHub org
+GITHUB_TOKEN=<token> pizza generate codeowners . --attribute-org-members open-sauced
+
+# Leave out owners who have since left the GitHub org, caching its members for a day
+GITHUB_TOKEN=<token> pizza generate codeowners . --active-members-only open-sauced --members-cache-ttl 24h
+
+# Lint an existing CODEOWNERS file, printing the findings as JSON
+pizza generate codeowners --lint .github/CODEOWNERS --lint-format json
+
+# Generate the owners for an internal context from the "internal" profile of the config
+pizza generate codeowners . --config-env internal
+
+# Compare the owners attributed with a proposed config against the current one
+pizza generate codeowners . --config-a .sauced.yaml --config-b .sauced.new.yaml
+
+# Verify that every user and team listed in the .sauced.yaml file exists on GitHub
+GITHUB_TOKEN=<token> pizza generate codeowners . --verify-config
 
 # Specify a custom location for the .sauced.yaml file
 pizza generate codeowners . --config /path/to/.sauced.yaml
 
+# Use a central .sauced.yaml file fetched over HTTPS
+pizza generate codeowners . --config https://example.com/attributions.yaml
+
+# Leave out the files only the fallback owns, which a "*" catch-all rule covers instead
+pizza generate codeowners . --omit-fallback-only
+
+# Write the teams in the config's team-map instead of their members
+pizza generate codeowners . --use-teams
+
+# Preview what regenerating the CODEOWNERS file would change without writing it
+pizza generate codeowners . --dry-run
+
+# Fail CI when the repository's .github/CODEOWNERS on GitHub is out of date
+GITHUB_TOKEN=<token> pizza generate codeowners . --output-path .github --check-against-remote open-sauced/pizza-cli
+
 # Specify a custom output location for the CODEOWNERS file
 pizza generate codeowners . --output-path /path/to/directory
 		`,
-		Args: func(_ *cobra.Command, args []string) error {
-			if len(args) != 1 {
-				return errors.New("you must provide exactly one argument: the path to the repository")
+		Args: func(cmd *cobra.Command, args []string) error {
+			// the config schema doesn't depend on a repository
+			if configSchema, _ := cmd.Flags().GetBool("config-schema"); configSchema {
+				return nil
+			}
+
+			// verifying the config or linting a file only needs a path to find the config in
+			verifyConfig, _ := cmd.Flags().GetBool("verify-config")
+			lintPath, _ := cmd.Flags().GetString("lint")
+			if (verifyConfig || lintPath != "") && len(args) == 0 {
+				return nil
 			}
 
-			path := args[0]
+			if len(args) == 0 {
+				return errors.New("you must provide at least one argument: the path to the repository or a glob of files")
+			}
 
-			// Validate that the path is a real path on disk and accessible by the user
-			absPath, err := filepath.Abs(path)
-			if err != nil {
-				return err
+			if len(args) == 1 && isRemoteURL(args[0]) {
+				opts.path = args[0]
+				opts.remoteURL = args[0]
+				return nil
+			}
+
+			// code analyzed from a manifest has no ".git" directory to mark its root
+			var rootMarkers []string
+			if manifestPath, _ := cmd.Flags().GetString("from-manifest"); manifestPath != "" {
+				rootMarkers = append(rootMarkers, manifestRootMarker)
 			}
 
-			if _, err := os.Stat(absPath); os.IsNotExist(err) {
-				return fmt.Errorf("the provided path does not exist: %w", err)
+			var err error
+			opts.path, opts.pathPatterns, err = resolvePathArgs(args, rootMarkers...)
+			if err != nil {
+				return utils.NewCLIError(constants.ErrorCodePath, strings.Join(args, " "), err)
 			}
 
-			opts.path = absPath
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			var err error
 
+			if configSchema, _ := cmd.Flags().GetBool("config-schema"); configSchema {
+				return writeConfigSchema(cmd.OutOrStdout())
+			}
+
 			disableTelem, _ := cmd.Flags().GetBool(constants.FlagNameTelemetry)
 
 			opts.telemetry = utils.NewPosthogCliClient(!disableTelem)
 
+			// remote repositories are cloned without a working tree to read a ".sauced.yaml" from
 			configPath, _ := cmd.Flags().GetString("config")
-			if configPath == "" {
+			if configPath == "" && opts.remoteURL == "" {
 				configPath = filepath.Join(opts.path, ".sauced.yaml")
 			}
 
-			opts.config, opts.configLoadedPath, err = config.LoadConfig(configPath)
+			if initConfigFlag, _ := cmd.Flags().GetBool("init-config"); initConfigFlag {
+				if opts.remoteURL != "" || config.IsRemotePath(configPath) {
+					return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--init-config can only be used with a local repository and config path")).WithField("init-config")
+				}
+
+				opts.previousDays, _ = cmd.Flags().GetInt("range")
+				force, _ := cmd.Flags().GetBool("force")
+
+				authors, err := initConfig(opts, configPath, force)
+				if err != nil {
+					return utils.NewCLIError(constants.ErrorCodeConfig, configPath, err).WithField("init-config")
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s with %d author emails. Replace each name with a GitHub username before generating.\n", configPath, authors)
+				return nil
+			}
+
+			switch {
+			case configPath == "":
+				opts.config, opts.configLoadedPath, err = config.LoadHomeConfig()
+				if err != nil {
+					err = fmt.Errorf("the .sauced.yaml of a remote repository can't be read, pass --config or create one in your home directory: %w", err)
+				}
+			case config.IsRemotePath(configPath):
+				cacheTTL, _ := cmd.Flags().GetDuration("config-cache-ttl")
+				opts.config, opts.configLoadedPath, err = config.LoadRemoteConfig(configPath, cacheTTL)
+			default:
+				opts.config, opts.configLoadedPath, err = config.LoadConfig(configPath)
+			}
 			if err != nil {
-				return err
+				return utils.NewCLIError(constants.ErrorCodeConfig, configPath, err)
 			}
 
-			opts.ownersStyleFile, _ = cmd.Flags().GetBool("owners-style-file")
-			opts.outputPath, _ = cmd.Flags().GetString("output-path")
+			if noGlobalConfig, _ := cmd.Flags().GetBool("no-global-config"); !noGlobalConfig {
+				if err := mergeGlobalConfig(opts); err != nil {
+					return utils.NewCLIError(constants.ErrorCodeConfig, opts.globalConfigPath, err)
+				}
+			}
 
-			// Default the outputPath to the base path if no flag value is given
-			if opts.outputPath == "" {
-				opts.outputPath = opts.path
+			if configEnv, _ := cmd.Flags().GetString("config-env"); configEnv != "" {
+				opts.config, err = opts.config.Profile(configEnv)
+				if err != nil {
+					return utils.NewCLIError(constants.ErrorCodeConfig, opts.configLoadedPath, err).WithField("config-env")
+				}
 			}
 
-			opts.previousDays, _ = cmd.Flags().GetInt("range")
-			opts.tty, _ = cmd.Flags().GetBool("tty-disable")
+			resolveConfigDefaults(cmd, opts)
 
-			loglevelS, _ := cmd.Flags().GetString("log-level")
+			if !slices.Contains(outputFormats, opts.format) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid format %q, must be one of: %s", opts.format, strings.Join(outputFormats, ", "))).WithField("format")
+			}
 
-			switch loglevelS {
-			case "error":
-				opts.loglevel = logging.LogError
-			case "warn":
-				opts.loglevel = logging.LogWarn
-			case "info":
-				opts.loglevel = logging.LogInfo
-			case "debug":
-				opts.loglevel = logging.LogDebug
+			opts.ownersLayout, _ = cmd.Flags().GetString("owners-layout")
+			if !slices.Contains(ownersLayouts, opts.ownersLayout) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid owners layout %q, must be one of: %s", opts.ownersLayout, strings.Join(ownersLayouts, ", "))).WithField("owners-layout")
 			}
 
-			err = run(opts, cmd)
+			opts.ownerSort, _ = cmd.Flags().GetString("owner-sort")
+			if !slices.Contains(ownerSorts, opts.ownerSort) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid owner sort %q, must be one of: %s", opts.ownerSort, strings.Join(ownerSorts, ", "))).WithField("owner-sort")
+			}
 
-			_ = opts.telemetry.Done()
+			opts.noMention, _ = cmd.Flags().GetBool("no-mention")
+			if opts.noMention && opts.format != formatOwners && opts.format != formatOwnerIndex {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--no-mention can only be used with --format owners or owner-index since the owners of CODEOWNERS files must be @mentions")).WithField("no-mention")
+			}
 
-			return err
-		},
-	}
+			if !slices.Contains(ownersIdentities, opts.ownersIdentity) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid owners identity %q, must be one of: %s", opts.ownersIdentity, strings.Join(ownersIdentities, ", "))).WithField("owners-identity")
+			}
 
-	cmd.PersistentFlags().IntP("range", "r", 90, "The number of days to analyze commit history (default 90)")
-	cmd.PersistentFlags().Bool("owners-style-file", false, "Generate an agnostic OWNERS style file instead of CODEOWNERS.")
-	cmd.PersistentFlags().StringP("output-path", "o", "", "Directory to create the output file.")
+			if !slices.Contains(ownersNames, opts.ownersName) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid owners name %q, must be one of: %s", opts.ownersName, strings.Join(ownersNames, ", "))).WithField("owners-name")
+			}
 
-	return cmd
-}
+			opts.githubIdentity, _ = cmd.Flags().GetString("github-identity")
+			if !slices.Contains(githubIdentities, opts.githubIdentity) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid GitHub identity %q, must be one of: %s", opts.githubIdentity, strings.Join(githubIdentities, ", "))).WithField("github-identity")
+			}
+			if opts.githubIdentity == githubIdentityEmail && opts.format != formatGitHub && opts.format != formatGitLab {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--github-identity email can only be used with --format github or gitlab")).WithField("github-identity")
+			}
 
-func run(opts *Options, cmd *cobra.Command) error {
-	var err error
-	opts.logger, err = gopherlogs.NewLogger(
-		gopherlogs.WithLogVerbosity(opts.loglevel),
-		gopherlogs.WithTty(!opts.tty),
-	)
-	if err != nil {
-		return fmt.Errorf("could not build logger: %w", err)
-	}
-	opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Built logger with log level: %d\n", opts.loglevel)
-	opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Loaded config from: %s\n", opts.configLoadedPath)
+			opts.granularity, _ = cmd.Flags().GetString("granularity")
+			if !slices.Contains(granularities, opts.granularity) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid granularity %q, must be one of: %s", opts.granularity, strings.Join(granularities, ", "))).WithField("granularity")
+			}
 
-	repo, err := git.PlainOpen(opts.path)
-	if err != nil {
-		_ = opts.telemetry.CaptureFailedCodeownersGenerate()
-		return fmt.Errorf("error opening repo: %w", err)
-	}
-	opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Opened repo at: %s\n", opts.path)
+			opts.annotateSource, _ = cmd.Flags().GetBool("annotate-source")
+			opts.normalizePaths, _ = cmd.Flags().GetBool("normalize-paths")
+			opts.strict, _ = cmd.Flags().GetBool("strict")
 
-	processOptions := ProcessOptions{
-		repo,
-		opts.previousDays,
-		opts.path,
-		opts.logger,
-	}
-	opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Looking back %d days\n", opts.previousDays)
+			opts.preserveOrder, _ = cmd.Flags().GetBool("preserve-order")
+			if opts.preserveOrder && opts.granularity == granularityDirectory {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--preserve-order cannot be used with --granularity directory")).WithField("preserve-order")
+			}
 
-	codeowners, err := processOptions.process()
-	if err != nil {
-		_ = opts.telemetry.CaptureFailedCodeownersGenerate()
-		return fmt.Errorf("error traversing git log: %w", err)
-	}
+			opts.lineEnding, _ = cmd.Flags().GetString("line-ending")
+			if !slices.Contains(lineEndings, opts.lineEnding) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid line ending %q, must be one of: %s", opts.lineEnding, strings.Join(lineEndings, ", "))).WithField("line-ending")
+			}
 
-	// Define which file to generate based on a flag
-	var fileType string
-	if opts.ownersStyleFile {
-		fileType = "OWNERS"
-	} else {
-		fileType = "CODEOWNERS"
-	}
+			opts.rankBy, _ = cmd.Flags().GetString("rank-by")
+			ranker, ok := GetRanker(opts.rankBy)
+			if !ok {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid ranker %q, must be one of: %s", opts.rankBy, strings.Join(RankerNames(), ", "))).WithField("rank-by")
+			}
+			opts.ranker = ranker
+			if cmd.Flags().Changed("seed") {
+				seed, _ := cmd.Flags().GetInt64("seed")
+				opts.ranker = seededRanker{ranker: ranker, seed: seed}
+			}
+			opts.blameWorkers, _ = cmd.Flags().GetInt("blame-workers")
 
-	opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Processing codeowners file at: %s\n", opts.outputPath)
+			opts.blameDetect, _ = cmd.Flags().GetString("blame-detect")
+			if !slices.Contains(blameDetections, opts.blameDetect) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid blame detection %q, must be one of: %s", opts.blameDetect, strings.Join(blameDetections, ", "))).WithField("blame-detect")
+			}
+			if opts.blameDetect != blameDetectNone && opts.rankBy != RankByBlame {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--blame-detect can only be used with --rank-by blame")).WithField("blame-detect")
+			}
 
-	err = generateOutputFile(codeowners, filepath.Join(opts.outputPath, fileType), opts, cmd)
-	if err != nil {
-		_ = opts.telemetry.CaptureFailedCodeownersGenerate()
-		return fmt.Errorf("error generating github style codeowners file: %w", err)
-	}
+			opts.patternMode, _ = cmd.Flags().GetBool("pattern-mode")
+			if opts.patternMode && opts.granularity == granularityDirectory {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--pattern-mode cannot be used with --granularity directory")).WithField("pattern-mode")
+			}
+			if opts.patternMode && opts.preserveOrder {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--pattern-mode cannot be used with --preserve-order")).WithField("pattern-mode")
+			}
 
-	opts.logger.V(logging.LogInfo).Style(0, colors.FgGreen).Infof("Finished generating file: %s\n", filepath.Join(opts.outputPath, fileType))
-	_ = opts.telemetry.CaptureCodeownersGenerate()
+			opts.excludePaths, _ = cmd.Flags().GetStringSlice("exclude-path")
+			opts.ignorePatterns, _ = cmd.Flags().GetStringSlice("ignore")
+			opts.includeExtensions, _ = cmd.Flags().GetStringSlice("include-ext")
+			opts.excludeExtensions, _ = cmd.Flags().GetStringSlice("exclude-ext")
+			opts.maxDepth, _ = cmd.Flags().GetInt("max-depth")
 
-	opts.logger.V(logging.LogInfo).Style(0, colors.FgCyan).Infof("\nCreate an OpenSauced Contributor Insight to get metrics and insights on these codeowners:\n")
-	opts.logger.V(logging.LogInfo).Style(0, colors.FgCyan).Infof("$ pizza generate insight " + opts.path + "\n")
-	_ = opts.telemetry.CaptureCodeownersGenerateContributorInsight()
+			opts.ownersHierarchy, _ = cmd.Flags().GetBool("owners-hierarchy")
+			if opts.ownersHierarchy && opts.format != formatOwners {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--owners-hierarchy can only be used with --format owners")).WithField("owners-hierarchy")
+			}
 
-	return nil
+			opts.outputFile, _ = cmd.Flags().GetString("output-file")
+			if err := validateOutputFile(opts.outputFile, opts); err != nil {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", err).WithField("output-file")
+			}
+
+			opts.readInlineOwners, _ = cmd.Flags().GetBool("read-inline-owners")
+			opts.inheritOwners, _ = cmd.Flags().GetBool("inherit-owners")
+			opts.useDefaultBranch, _ = cmd.Flags().GetBool("use-default-branch")
+			opts.firstParent, _ = cmd.Flags().GetBool("first-parent")
+			opts.followSubmodules, _ = cmd.Flags().GetBool("follow-submodules")
+
+			opts.allowShallow, _ = cmd.Flags().GetBool("allow-shallow")
+
+			opts.at, _ = cmd.Flags().GetString("at")
+			if opts.at != "" && (opts.useDefaultBranch || opts.followSubmodules) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--at cannot be used with --use-default-branch or --follow-submodules")).WithField("at")
+			}
+
+			opts.mergeWeight, _ = cmd.Flags().GetFloat64("count-merges-as")
+			opts.weighMerges = cmd.Flags().Changed("count-merges-as")
+			if opts.mergeWeight < 0 || opts.mergeWeight > 1 {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid merge weight %g, must be between 0 and 1", opts.mergeWeight)).WithField("count-merges-as")
+			}
+
+			opts.attributeBy, _ = cmd.Flags().GetString("attribute-by")
+			if !slices.Contains(attributeByIdentities, opts.attributeBy) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid identity %q, must be one of: %s", opts.attributeBy, strings.Join(attributeByIdentities, ", "))).WithField("attribute-by")
+			}
+			opts.stream, _ = cmd.Flags().GetBool("stream")
+			// Flags take precedence over the allowed authors in the config
+			if cmd.Flags().Changed("only-authors") {
+				opts.config.AllowedAuthors, _ = cmd.Flags().GetStringSlice("only-authors")
+			}
+
+			opts.statsOnly, _ = cmd.Flags().GetBool("stats-only")
+			if cmd.Flags().Changed("explain") {
+				explain, _ := cmd.Flags().GetString("explain")
+				opts.explain = explainPath(explain)
+			}
+			if opts.explain != "" && (opts.statsOnly || opts.ownersHierarchy) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--explain cannot be used with --stats-only or --owners-hierarchy")).WithField("explain")
+			}
+			if opts.stream && (opts.statsOnly || opts.ownersHierarchy || opts.preserveOrder || opts.explain != "") {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--stream cannot be used with --stats-only, --owners-hierarchy, --preserve-order, or --explain")).WithField("stream")
+			}
+			opts.manifestPath, _ = cmd.Flags().GetString("from-manifest")
+			if opts.manifestPath != "" && (opts.remoteURL != "" || opts.stream || opts.patternMode || opts.followSubmodules || opts.readInlineOwners || opts.useDefaultBranch || opts.at != "" || opts.rankBy == RankByBlame || opts.rankBy == RankByCommitDays) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--from-manifest cannot be used with a remote repository, --stream, --pattern-mode, --follow-submodules, --read-inline-owners, --use-default-branch, --at, or --rank-by blame or commit-days since they read the git repository")).WithField("from-manifest")
+			}
+			if opts.stream && opts.format == formatGitLab && len(opts.config.GitLabSections) > 0 {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--stream cannot be used with --format gitlab and gitlab-sections since each section's rules must be written together")).WithField("stream")
+			}
+			opts.verifyConfig, _ = cmd.Flags().GetBool("verify-config")
+			opts.lintPath, _ = cmd.Flags().GetString("lint")
+			opts.lintFormat, _ = cmd.Flags().GetString("lint-format")
+			if !slices.Contains(lintFormats, opts.lintFormat) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid lint format %q, must be one of: %s", opts.lintFormat, strings.Join(lintFormats, ", "))).WithField("lint-format")
+			}
+			opts.attributeOrgMembers, _ = cmd.Flags().GetString("attribute-org-members")
+			opts.activeMembersOnly, _ = cmd.Flags().GetString("active-members-only")
+			opts.membersCacheTTL, _ = cmd.Flags().GetDuration("members-cache-ttl")
+			if opts.activeMembersOnly != "" && githubToken() == "" {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("--active-members-only requires a GitHub token in %s to list the org's private members", strings.Join(githubTokenEnvs, " or "))).WithField("active-members-only")
+			}
+			opts.contributorCountFile, _ = cmd.Flags().GetString("contributor-count-file")
+			if opts.contributorCountFile != "" && opts.explain != "" {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--contributor-count-file cannot be used with --explain")).WithField("contributor-count-file")
+			}
+			if opts.maxOwnersPerLine < 0 {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid max owners per line %d, must not be negative", opts.maxOwnersPerLine)).WithField("max-owners-per-line")
+			}
+
+			opts.omitFallbackOnly, _ = cmd.Flags().GetBool("omit-fallback-only")
+			if opts.omitFallbackOnly && (opts.stream || opts.preserveOrder || opts.patternMode) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--omit-fallback-only cannot be used with --stream, --preserve-order, or --pattern-mode since they may write the catch-all rule after the rules it covers")).WithField("omit-fallback-only")
+			}
+
+			opts.useTeams, _ = cmd.Flags().GetBool("use-teams")
+
+			opts.creatorBoost, _ = cmd.Flags().GetFloat64("boost-creator")
+			opts.boostCreator = cmd.Flags().Changed("boost-creator")
+			if opts.boostCreator && (opts.creatorBoost <= 0 || math.IsInf(opts.creatorBoost, 0) || math.IsNaN(opts.creatorBoost)) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid creator boost %g, must be a positive number", opts.creatorBoost)).WithField("boost-creator")
+			}
+			opts.pathLocalOnly, _ = cmd.Flags().GetBool("path-local-only")
+			if opts.pathLocalOnly && opts.granularity != granularityDirectory {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--path-local-only can only be used with --granularity directory")).WithField("path-local-only")
+			}
+			if opts.pathLocalOnly && (opts.stream || opts.manifestPath != "") {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--path-local-only cannot be used with --stream or --from-manifest since they don't see every file a commit changes")).WithField("path-local-only")
+			}
+			if weightBySize, _ := cmd.Flags().GetBool("weight-by-size"); weightBySize {
+				if opts.granularity != granularityDirectory {
+					return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--weight-by-size can only be used with --granularity directory")).WithField("weight-by-size")
+				}
+				if opts.stream || opts.manifestPath != "" {
+					return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--weight-by-size cannot be used with --stream or --from-manifest since they don't read the repository's files")).WithField("weight-by-size")
+				}
+
+				opts.fileSizes = make(map[string]int)
+			}
+			opts.skipEmptyDirectories, _ = cmd.Flags().GetBool("skip-empty-directories")
+			if opts.skipEmptyDirectories && opts.granularity != granularityDirectory {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--skip-empty-directories can only be used with --granularity directory")).WithField("skip-empty-directories")
+			}
+			if excludeGenerated, _ := cmd.Flags().GetBool("exclude-generated"); excludeGenerated {
+				if opts.stream || opts.manifestPath != "" {
+					return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--exclude-generated cannot be used with --stream or --from-manifest")).WithField("exclude-generated")
+				}
+
+				generatedRegex, _ := cmd.Flags().GetString("generated-regex")
+				opts.generatedRegex, err = regexp.Compile(generatedRegex)
+				if err != nil {
+					return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid generated file regex %q: %w", generatedRegex, err)).WithField("generated-regex")
+				}
+			} else if cmd.Flags().Changed("generated-regex") {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--generated-regex can only be used with --exclude-generated")).WithField("generated-regex")
+			}
+			if opts.boostCreator && opts.manifestPath != "" {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--boost-creator cannot be used with --from-manifest since the manifest doesn't record who created each file")).WithField("boost-creator")
+			}
+
+			opts.netReverts, _ = cmd.Flags().GetBool("net-reverts")
+			if opts.netReverts && opts.manifestPath != "" {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--net-reverts cannot be used with --from-manifest since the manifest has no commits to detect reverts in")).WithField("net-reverts")
+			}
+
+			opts.creditRenameTo, _ = cmd.Flags().GetString("credit-rename-to")
+			if !slices.Contains(renameCredits, opts.creditRenameTo) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid rename credit %q, must be one of: %s", opts.creditRenameTo, strings.Join(renameCredits, ", "))).WithField("credit-rename-to")
+			}
+			if opts.creditRenameTo != creditRenameToRenamer && (opts.stream || opts.manifestPath != "") {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--credit-rename-to cannot be used with --stream or --from-manifest since they don't follow a file's history across renames")).WithField("credit-rename-to")
+			}
+
+			opts.scoreCommand, _ = cmd.Flags().GetString("score-command")
+			if opts.scoreCommand != "" && opts.manifestPath != "" {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--score-command cannot be used with --from-manifest since the manifest has no commits to score")).WithField("score-command")
+			}
+			opts.failOnEmpty, _ = cmd.Flags().GetBool("fail-on-empty")
+			opts.bom, _ = cmd.Flags().GetBool("bom")
+
+			opts.dryRun, _ = cmd.Flags().GetBool("dry-run")
+			opts.noColor, _ = cmd.Flags().GetBool("no-color")
+			opts.alwaysWrite, _ = cmd.Flags().GetBool("always-write")
+
+			configAPath, _ := cmd.Flags().GetString("config-a")
+			configBPath, _ := cmd.Flags().GetString("config-b")
+			if (configAPath == "") != (configBPath == "") {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--config-a and --config-b must be used together")).WithField("config-a")
+			}
+			if configAPath != "" && (opts.stream || opts.statsOnly || opts.ownersHierarchy || opts.explain != "" || opts.dryRun || opts.attributeOrgMembers != "" || opts.activeMembersOnly != "") {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--config-a and --config-b cannot be used with --stream, --stats-only, --owners-hierarchy, --explain, --dry-run, --attribute-org-members, or --active-members-only")).WithField("config-a")
+			}
+			if configAPath != "" {
+				cacheTTL, _ := cmd.Flags().GetDuration("config-cache-ttl")
+				opts.configA, err = loadConfigFile(configAPath, cacheTTL)
+				if err != nil {
+					return utils.NewCLIError(constants.ErrorCodeConfig, configAPath, err).WithField("config-a")
+				}
+
+				opts.configB, err = loadConfigFile(configBPath, cacheTTL)
+				if err != nil {
+					return utils.NewCLIError(constants.ErrorCodeConfig, configBPath, err).WithField("config-b")
+				}
+			}
+			opts.markdownSummary, _ = cmd.Flags().GetString("markdown-summary")
+			if opts.markdownSummary != "" && (opts.stream || opts.statsOnly || opts.explain != "" || opts.dryRun || opts.configA != nil || cmd.Flags().Changed("check-against-remote")) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--markdown-summary cannot be used with --stream, --stats-only, --explain, --dry-run, --config-a, or --check-against-remote")).WithField("markdown-summary")
+			}
+
+			opts.splitByTeam, _ = cmd.Flags().GetString("split-by-team")
+			if opts.splitByTeam != "" && opts.format != formatGitHub && opts.format != formatGitLab {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--split-by-team can only be used with --format github or gitlab, which have team owners")).WithField("split-by-team")
+			}
+			if opts.splitByTeam != "" && (opts.stream || opts.statsOnly || opts.explain != "" || opts.dryRun || opts.configA != nil || cmd.Flags().Changed("check-against-remote")) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--split-by-team cannot be used with --stream, --stats-only, --explain, --dry-run, --config-a, or --check-against-remote")).WithField("split-by-team")
+			}
+
+			opts.outputFileOwners, _ = cmd.Flags().GetStringSlice("output-file-owner")
+			if len(opts.outputFileOwners) > 0 && opts.format != formatGitHub && opts.format != formatGitLab {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--output-file-owner can only be used with --format github or gitlab")).WithField("output-file-owner")
+			}
+			if len(opts.outputFileOwners) > 0 && opts.stream {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--output-file-owner cannot be used with --stream since the streamed file's rules are never all known")).WithField("output-file-owner")
+			}
+
+			opts.watch, _ = cmd.Flags().GetBool("watch")
+			if opts.watch && (opts.remoteURL != "" || opts.lintPath != "" || opts.verifyConfig || cmd.Flags().Changed("check-against-remote")) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--watch cannot be used with a remote repository, --lint, --verify-config, or --check-against-remote")).WithField("watch")
+			}
+
+			opts.checkAgainstRemote, _ = cmd.Flags().GetString("check-against-remote")
+			if opts.checkAgainstRemote != "" {
+				if !githubRepoPattern.MatchString(opts.checkAgainstRemote) {
+					return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid GitHub repository %q, must be owner/repo", opts.checkAgainstRemote)).WithField("check-against-remote")
+				}
+				if opts.remoteURL != "" || opts.stream || opts.ownersHierarchy || opts.dryRun || opts.statsOnly || opts.explain != "" || opts.format == formatOwnerIndex || opts.format == formatAzure {
+					return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--check-against-remote cannot be used with a remote repository, --stream, --owners-hierarchy, --dry-run, --stats-only, --explain, or --format owner-index or azure")).WithField("check-against-remote")
+				}
+			}
+			if opts.dryRun && (opts.stream || opts.ownersHierarchy || opts.contributorCountFile != "") {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--dry-run cannot be used with --stream, --owners-hierarchy, or --contributor-count-file since they write files directly")).WithField("dry-run")
+			}
+
+			opts.ownerIndexFormat, _ = cmd.Flags().GetString("owner-index-format")
+			if !slices.Contains(ownerIndexFormats, opts.ownerIndexFormat) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid owner index format %q, must be one of: %s", opts.ownerIndexFormat, strings.Join(ownerIndexFormats, ", "))).WithField("owner-index-format")
+			}
+			if (opts.format == formatOwnerIndex || opts.format == formatAzure) && (opts.stream || opts.preserveOrder || opts.patternMode) {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("--format %s cannot be used with --stream, --preserve-order, or --pattern-mode", opts.format)).WithField("format")
+			}
+
+			if patternsFrom, _ := cmd.Flags().GetString("patterns-from"); patternsFrom != "" {
+				if opts.format != formatGitHub && opts.format != formatGitLab {
+					return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--patterns-from can only be used with --format github or gitlab")).WithField("patterns-from")
+				}
+				if opts.stream || opts.patternMode || opts.preserveOrder || opts.granularity == granularityDirectory {
+					return utils.NewCLIError(constants.ErrorCodeConfig, "", errors.New("--patterns-from cannot be used with --stream, --pattern-mode, --preserve-order, or --granularity directory since the patterns are kept as they are")).WithField("patterns-from")
+				}
+
+				opts.sourcePatterns, err = readSourcePatterns(patternsFrom)
+				if err != nil {
+					return utils.NewCLIError(constants.ErrorCodePath, patternsFrom, err).WithField("patterns-from")
+				}
+				if len(opts.sourcePatterns) == 0 {
+					return utils.NewCLIError(constants.ErrorCodeConfig, patternsFrom, errors.New("--patterns-from file has no patterns")).WithField("patterns-from")
+				}
+			}
+
+			opts.statsFormat, _ = cmd.Flags().GetString("stats-format")
+			if opts.statsFormat != constants.OutputTable && opts.statsFormat != constants.OutputJSON {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid stats format %q, must be one of: %s, %s", opts.statsFormat, constants.OutputTable, constants.OutputJSON)).WithField("stats-format")
+			}
+
+			opts.previousDays, _ = cmd.Flags().GetInt("range")
+			opts.minChurn, _ = cmd.Flags().GetInt("min-churn")
+			if opts.minChurn < 0 {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid min churn %d, must not be negative", opts.minChurn)).WithField("min-churn")
+			}
+			opts.maxCommits, _ = cmd.Flags().GetInt("max-commits")
+			if opts.maxCommits < 0 {
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid max commits %d, must not be negative", opts.maxCommits)).WithField("max-commits")
+			}
+
+			if profile, _ := cmd.Flags().GetBool("profile"); profile {
+				opts.profiler = newProfiler()
+			}
+			opts.cpuProfile, _ = cmd.Flags().GetString("cpu-profile")
+
+			// Flags take precedence over the minimum owners and commits in the config
+			if cmd.Flags().Changed("min-owners") {
+				opts.config.MinOwners, _ = cmd.Flags().GetInt("min-owners")
+			}
+			if cmd.Flags().Changed("min-commits") {
+				opts.config.MinCommits, _ = cmd.Flags().GetInt("min-commits")
+			}
+			if cmd.Flags().Changed("min-confidence") {
+				opts.config.MinConfidence, _ = cmd.Flags().GetFloat64("min-confidence")
+				if opts.config.MinConfidence < 0 || opts.config.MinConfidence > 1 {
+					return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid min confidence %g, must be between 0 and 1", opts.config.MinConfidence)).WithField("min-confidence")
+				}
+			}
+			opts.tty, _ = cmd.Flags().GetBool("tty-disable")
+			opts.logFormat, _ = cmd.Flags().GetString(constants.FlagNameLogFormat)
+
+			loglevelS, _ := cmd.Flags().GetString("log-level")
+
+			switch loglevelS {
+			case "error":
+				opts.loglevel = logging.LogError
+			case "warn":
+				opts.loglevel = logging.LogWarn
+			case "info":
+				opts.loglevel = logging.LogInfo
+			case "debug":
+				opts.loglevel = logging.LogDebug
+			}
+
+			if opts.watch && !opts.watching {
+				err = runWatch(opts, cmd)
+			} else {
+				err = run(opts, cmd)
+			}
+
+			_ = opts.telemetry.Done()
+
+			return err
+		},
+	}
+
+	cmd.PersistentFlags().IntP("range", "r", 90, "The number of days to analyze commit history (default 90)")
+	cmd.PersistentFlags().Int("max-commits", 0, "Stop walking the history after this many of the most recent commits in the --range, whichever is reached first. Speeds up large repositories, but the results are approximate since older changes aren't attributed. Ranking by recency is unaffected for the authors still attributed, since their most recent commits are walked. 0 walks every commit")
+	cmd.PersistentFlags().String("format", formatGitHub, fmt.Sprintf("The format of the generated file. Options: %s", strings.Join(outputFormats, ", ")))
+	cmd.PersistentFlags().String("granularity", granularityFile, fmt.Sprintf("The granularity of the generated rules. Options: %s. With --max-depth, directory rules are only generated for the analyzed files", strings.Join(granularities, ", ")))
+	cmd.PersistentFlags().Bool("omit-fallback-only", false, "Leave out the rules of files only the fallback attribution owns, shrinking the file. A \"*\" catch-all rule with the fallback owners is written first instead, so they keep their owners")
+	cmd.PersistentFlags().Bool("bom", false, "Write the UTF-8 byte order mark at the start of the generated CODEOWNERS or OWNERS files, which some Windows tools expect. The output is always UTF-8, with any invalid characters replaced")
+	cmd.PersistentFlags().Bool("fail-on-empty", false, "Fail when every file is filtered out, e.g., by --ignore, --exclude-path, or --min-churn, instead of only warning that the generated file would be empty")
+	cmd.PersistentFlags().Bool("use-teams", false, "Replace the owners in the config's team-map with their teams, which outlast the individuals on them. Owners mapped to the same team are written once")
+	cmd.PersistentFlags().Bool("annotate-source", false, "Annotate each rule with a trailing comment noting how its owners were derived: top-contributors, override, fallback, ancestor, or inline")
+	cmd.PersistentFlags().Bool("normalize-paths", false, "Collapse \"./\", \"..\", and redundant separators in each path, and convert Windows backslashes to forward slashes, before writing its rule. Files whose paths normalize to the same path are merged")
+	cmd.PersistentFlags().Bool("strict", false, "Fail instead of writing a rule GitHub would reject or ignore, i.e., an unsupported pattern or invalid owner. Such rules are reported either way. Only applies to the github format")
+	cmd.PersistentFlags().Bool("path-local-only", false, "With --granularity directory, only credit the changes of commits confined to each rule's directory, so contributors whose only changes are tree-wide sweeps, e.g., reformatting the whole repository, don't rank as its owners")
+	cmd.PersistentFlags().Bool("weight-by-size", false, "With --granularity directory, weight each file's contribution to its directory by its number of lines, so large files dominate the directory's owners over small ones. Each author is credited with their share of a file's changed lines times its size")
+	cmd.PersistentFlags().Bool("skip-empty-directories", false, "With --granularity directory, don't write rules for directories with no files left in the repository, i.e., whose files have all been deleted or are removed by --ignore, --exclude-path, and the other filters, instead of attributing them from their history")
+	cmd.PersistentFlags().Bool("preserve-order", false, "Keep the order of the rules in an existing output file when regenerating it, appending new rules and removing obsolete ones, to minimize the diff. Can't be used with --granularity directory, --pattern-mode, or --stream since rule order decides which rule applies")
+	cmd.PersistentFlags().String("line-ending", lineEndingLF, fmt.Sprintf("The line ending of the generated files. Options: %s. auto keeps the line ending of an existing file", strings.Join(lineEndings, ", ")))
+	cmd.PersistentFlags().String("rank-by", RankByLines, fmt.Sprintf("How to rank the authors of each file. Options: %s", strings.Join(RankerNames(), ", ")))
+	cmd.PersistentFlags().Int64("seed", 0, "Break ties between authors who rank equally randomly, but reproducibly for the same seed, instead of alphabetically by email. Only matters for ties: authors who don't rank equally keep their order")
+	cmd.PersistentFlags().String("blame-detect", blameDetectNone, "With --rank-by blame, attribute lines moved or copied from elsewhere to their original authors: \"none\", \"move\" to detect lines moved within a file like git blame -M, or \"copy\" to also detect lines moved or copied from other files changed in the same commit like git blame -C")
+	cmd.PersistentFlags().Int("blame-workers", runtime.NumCPU(), "The number of files to blame in parallel with --rank-by blame")
+	cmd.PersistentFlags().Bool("pattern-mode", false, "Group files into directory and extension patterns when every file the pattern matches has the same owners")
+	cmd.PersistentFlags().String("patterns-from", "", "Keep the patterns of an existing CODEOWNERS file, in order, recomputing the owners of each from every file it matches. Patterns which no longer match any files are reported")
+	cmd.PersistentFlags().String("owners-layout", ownersLayoutNested, fmt.Sprintf("The layout of owners in the OWNERS format. Options: %s", strings.Join(ownersLayouts, ", ")))
+	cmd.PersistentFlags().Bool("no-mention", false, "Write owners without the \"@\" which mentions them, for a file used as a reference rather than to request reviews. Can only be used with --format owners or owner-index")
+	cmd.PersistentFlags().String("owner-sort", ownerSortRank, fmt.Sprintf("The order of the owners of each rule: by rank, the highest ranked owner first, or alphabetically by alias for stable diffs. Options: %s", strings.Join(ownerSorts, ", ")))
+	cmd.PersistentFlags().String("owners-name", ownersNameGit, fmt.Sprintf("The preferred source of each owner's name in the OWNERS format: their git name, their GitHub login, or their name in the config's display-names. Owners without a name from the source fall back to their display name, git name, then login. Defaults to the config's owners-name. Options: %s", strings.Join(ownersNames, ", ")))
+	cmd.PersistentFlags().String("owners-identity", ownersIdentityName, fmt.Sprintf("The identity of each owner in the OWNERS format: their name and email, only their email, or their GitHub @alias. Owners without the identity fall back to the others. Defaults to the config's owners-identity. Options: %s", strings.Join(ownersIdentities, ", ")))
+	cmd.PersistentFlags().String("github-identity", githubIdentityAlias, fmt.Sprintf("The identity of each owner in the github and gitlab formats: their @alias or their commit email, i.e., for GitHub Enterprise with SAML. Emails GitHub won't accept, such as noreply addresses, fall back to an email attributed to the owner in the config, then their @alias. Options: %s", strings.Join(githubIdentities, ", ")))
+	cmd.PersistentFlags().Bool("owners-hierarchy", false, "Generate a Kubernetes style OWNERS file in each directory which inherits the owners of its parent directories. Use with --format owners")
+	cmd.PersistentFlags().Bool("owners-style-file", false, "Generate an agnostic OWNERS style file instead of CODEOWNERS.")
+	cmd.PersistentFlags().StringP("output-path", "o", "", "Directory to create the output file. Defaults to the config's output-path, then the repository")
+	cmd.PersistentFlags().String("output-file", "", "Path of the output file, relative to the repository, overriding --output-path. May include the {format} placeholder, and must include the {dir} placeholder with --owners-hierarchy. Example: .github/{dir}/OWNERS")
+	cmd.PersistentFlags().Int("max-owners", 3, "The maximum number of owners to attribute to each file")
+	cmd.PersistentFlags().Int("max-owners-per-line", defaultMaxOwnersPerLine, "The maximum number of owners written on a single rule in the github and gitlab formats, where very long lines may be rejected. The lowest ranked owners past it are dropped with a warning. Defaults to the config's max-owners-per-line. 0 doesn't cap them")
+	cmd.PersistentFlags().Int("min-owners", 0, "The minimum number of owners for each file. The --min-commits threshold is relaxed to meet it before using the fallback attribution")
+	cmd.PersistentFlags().Int("min-commits", 0, "The minimum number of commits to a file for a contributor to be attributed as an owner")
+	cmd.PersistentFlags().Float64("min-confidence", 0, "The minimum share of a file's lines changed, from 0 to 1, its top ranked author must have made for its contributors to be attributed. Files below it use the fallback attribution")
+	cmd.PersistentFlags().Int("min-churn", 0, "Only include files changed by at least this many commits in the --range, focusing the output on frequently changed files. Stable files below it are left out")
+	cmd.PersistentFlags().StringSlice("exclude-path", []string{}, "Paths or globs to exclude from analysis entirely. Their git history is never read, which speeds up large repositories. Unlike --ignore, excluded files are never analyzed")
+	cmd.PersistentFlags().StringSlice("include-ext", []string{}, "Only analyze files with these extensions, i.e., .go,.ts. Files must also match the path arguments and not be excluded by --exclude-path")
+	cmd.PersistentFlags().StringSlice("exclude-ext", []string{}, "Prune files with these extensions from analysis, i.e., .md. Takes precedence over --include-ext")
+	cmd.PersistentFlags().Int("max-depth", -1, "The maximum directory depth of analyzed files. 0 only analyzes files in the top level. Deeper files are pruned before their history is read. A negative depth analyzes every file")
+	cmd.PersistentFlags().StringSlice("ignore", []string{}, "Paths or globs to remove from the output. Unlike --exclude-path, ignored files are still analyzed")
+	cmd.PersistentFlags().Bool("exclude-generated", false, fmt.Sprintf("Remove the files with a line matching --generated-regex within their first %d lines from the output, detecting generated files", generatedMaxLines))
+	cmd.PersistentFlags().String("generated-regex", defaultGeneratedRegex, "The regex detecting generated files with --exclude-generated. The default matches Go's convention")
+	cmd.PersistentFlags().StringSlice("only-authors", []string{}, "Only attribute commits from authors whose email or name matches one of these globs. Files with no allowed authors use the fallback attribution")
+	cmd.PersistentFlags().String("attribute-by", attributeByAuthor, fmt.Sprintf("The identity of each commit to attribute: the author who wrote the change or the committer who applied it, i.e., in rebase heavy workflows. Options: %s", strings.Join(attributeByIdentities, ", ")))
+	cmd.PersistentFlags().Bool("first-parent", false, "Only follow the first parent of each commit, like \"git log --first-parent\". Commits from merged branches are then credited to the merge commit's author")
+	cmd.PersistentFlags().Bool("net-reverts", false, "Don't credit commits which were later reverted, nor their reverts, since their changes didn't survive. Reverts are detected by the \"This reverts commit <sha>\" line \"git revert\" adds to their message")
+	cmd.PersistentFlags().String("credit-rename-to", creditRenameToRenamer, fmt.Sprintf("Who is credited for a renamed file: the renamer, as if they added the file, without following the rename; none, following the rename so its authors before the rename keep their credit; or both, following the rename and crediting the renamer's changes too. Options: %s", strings.Join(renameCredits, ", ")))
+	cmd.PersistentFlags().Float64("boost-creator", 1, "Multiply the credit of the author who created each file, by adding it within the --range, to surface original authors even after heavy edits by others, e.g., 2 for double credit")
+	cmd.PersistentFlags().String("score-command", "", "A command, run with the system shell, which weighs each commit: it reads the commit's hash, author, committer, message, parent count, and changed files as JSON on stdin, and writes its weight to stdout, e.g., 0 to ignore formatting-only commits or 0.5 for half credit. Commits the command fails to score get full credit")
+	cmd.PersistentFlags().Float64("count-merges-as", 1, "The share of credit, from 0 to 1, given for the changes of a merge commit. 0 ignores merge commits while 1 gives them full credit")
+	cmd.PersistentFlags().Bool("follow-submodules", false, "Attribute the files of each submodule from the submodule's own history, with paths relative to this repository. Submodules which aren't initialized are skipped")
+	cmd.PersistentFlags().Bool("allow-shallow", false, "Generate from a shallow clone, e.g., a CI checkout with --depth 1, instead of failing. The truncated history attributes most files to the authors of the most recent commits")
+	cmd.PersistentFlags().String("at", "", "Generate the ownership as it would have been at this commit SHA, or any other revision: only its history is walked, the --range is counted back from its commit date, and only the files in its tree are attributed")
+	cmd.PersistentFlags().Bool("use-default-branch", false, "When HEAD is detached, such as in CI checkouts, walk the history of the default branch (origin/HEAD) instead. Falls back to HEAD if the default branch can't be detected")
+	cmd.PersistentFlags().Bool("inherit-owners", false, "Files without qualifying contributors inherit the top contributors of their nearest ancestor directory instead of the fallback attribution. With --stream, only directories within the same top level directory are inherited from")
+	cmd.PersistentFlags().Bool("read-inline-owners", false, "Honor owners pinned in a file with a comment directive (i.e., \"// pizza-owners: @alice @bob\") over the computed owners")
+	cmd.PersistentFlags().Bool("config-schema", false, "Print the JSON schema of the .sauced.yaml config, generated from the config the CLI loads, instead of generating a file. No path is needed")
+	cmd.PersistentFlags().String("attribute-org-members", "", fmt.Sprintf("Attribute contributors whose emails aren't in the config to their GitHub login when they're members of this GitHub org. Logins are read from noreply emails or looked up by email. Uses the GitHub token in %s. Without a token, only public members with noreply emails are attributed", strings.Join(githubTokenEnvs, " or ")))
+	cmd.PersistentFlags().String("active-members-only", "", fmt.Sprintf("Leave out owners attributed to GitHub users who aren't members of this GitHub org, i.e., who left it. Their files are owned by the next ranked active contributor or the fallback instead. Uses the GitHub token in %s", strings.Join(githubTokenEnvs, " or ")))
+	cmd.PersistentFlags().Duration("members-cache-ttl", time.Hour, "How long the members of the --active-members-only org are cached before fetching them again")
+	cmd.PersistentFlags().String("lint", "", "Check an existing GitHub CODEOWNERS file for duplicate patterns, rules shadowed by a later broader rule, unescaped or unsupported patterns, and owners not listed in the config, instead of generating a file. The file isn't changed. No path is needed")
+	cmd.PersistentFlags().String("lint-format", lintFormatText, fmt.Sprintf("The format of the --lint findings. Options: %s", strings.Join(lintFormats, ", ")))
+	cmd.PersistentFlags().String("config-a", "", "With --config-b, compare the owners two configs attribute against the same history instead of generating a file, listing each rule whose owners change and the owners it loses (-) and gains (+)")
+	cmd.PersistentFlags().String("config-b", "", "The config to compare against --config-a")
+	cmd.PersistentFlags().Bool("verify-config", false, fmt.Sprintf("Verify that every user and team listed in the config exists on GitHub, reporting the ones that don't, instead of generating a file. Uses the GitHub token in %s. Without a token, teams are skipped and the rate limit is lower", strings.Join(githubTokenEnvs, " or ")))
+	cmd.PersistentFlags().String("config-env", "", "The name of a profile in the config's \"profiles\" to merge over the rest of the config, i.e., to generate owners for a different context")
+	cmd.PersistentFlags().Bool("init-config", false, "Bootstrap a config by listing the distinct author emails in the --range of history under the name each most frequently committed with, for you to replace with GitHub usernames, instead of generating a file. Written to the --config path, or the repository's .sauced.yaml")
+	cmd.PersistentFlags().Bool("force", false, "With --init-config, overwrite an existing config")
+	cmd.PersistentFlags().Bool("no-global-config", false, "Don't merge the global config at ~/.config/pizza/codeowners.yaml under the repository's config")
+	cmd.PersistentFlags().Duration("config-cache-ttl", time.Hour, "How long a config fetched from a remote --config URL is cached before fetching it again")
+	cmd.PersistentFlags().Bool("stream", false, "Process and write the output one top level directory at a time to reduce memory usage on large repositories. Files are sorted within each directory")
+	cmd.PersistentFlags().String("from-manifest", "", "Read the contributions to each file from a CSV or JSON manifest instead of the git log, for code without git metadata. Each record has a file, relative to the path, and the name, email, lines, and commits of its author. The path need not be a git repository")
+	cmd.PersistentFlags().String("explain", "", "Print the ranked contributors, evaluated config rules, and final owners of a single file, relative to the repository root, instead of generating a file")
+	cmd.PersistentFlags().String("contributor-count-file", "", "Also write the number of distinct contributors to each file, whether or not they're owners, to this CSV file, sorted by descending count, to find coordination hotspots")
+	cmd.PersistentFlags().String("markdown-summary", "", "Also write a Markdown table of each path and its owners, from the same attributions as the output file, to this file for humans to read, i.e., OWNERS.md for onboarding docs")
+	cmd.PersistentFlags().String("split-by-team", "", "Also write a CODEOWNERS file of the rules each team owns, from the same attributions as the output file, beneath this directory, named after the team, i.e., <dir>/open-sauced/engineering.CODEOWNERS. A rule owned by several teams is written to each of their files")
+	cmd.PersistentFlags().StringSlice("output-file-owner", nil, "The owners of an explicit rule for the generated file itself, written last so it overrides the generated rules, i.e., a rule like .github/ making whoever last regenerated the file its owner. Without it, a warning is printed when a generated rule owns the file")
+	cmd.PersistentFlags().Bool("watch", false, "Keep running and regenerate the output whenever a tracked file, the git history, or the config changes, waiting for changes to settle first. Stop with Ctrl+C")
+	cmd.PersistentFlags().String("check-against-remote", "", fmt.Sprintf("Compare the generated file against the file at the same path in this GitHub repository's default branch, \"owner/repo\", instead of writing it. Prints a diff and fails when it's out of date, without needing it checked out. Uses the GitHub token in %s, which private repositories require", strings.Join(githubTokenEnvs, " or ")))
+	cmd.PersistentFlags().Bool("always-write", false, "Write the output file even when its contents are unchanged. By default, an unchanged file isn't rewritten to preserve its modification time")
+	cmd.PersistentFlags().Bool("dry-run", false, "Print the generated file instead of writing it. When the output file already exists, print a unified diff of what would change instead")
+	cmd.PersistentFlags().Bool("no-color", false, "Disable the color of the --dry-run diff. Color is also disabled when the output isn't a terminal or NO_COLOR is set")
+	cmd.PersistentFlags().Bool("stats-only", false, "Print the number and share of files each owner would own and the bus factor of each top level directory instead of generating a file")
+	cmd.PersistentFlags().Bool("profile", false, "Print how long each phase of generation took: opening the repository, walking its history, attributing owners, and writing the output")
+	cmd.PersistentFlags().String("cpu-profile", "", "Write a pprof CPU profile of generation to this file")
+	cmd.PersistentFlags().String("owner-index-format", ownerIndexFormatText, fmt.Sprintf("The format of the --format owner-index output, listing the files each owner owns. Options: %s", strings.Join(ownerIndexFormats, ", ")))
+	cmd.PersistentFlags().String("stats-format", constants.OutputTable, fmt.Sprintf("The format of the --stats-only output. Options: %s, %s", constants.OutputTable, constants.OutputJSON))
+
+	_ = cmd.PersistentFlags().MarkDeprecated("owners-style-file", "use --format owners instead")
+
+	// Shell completions for flags with a fixed set of values
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(outputFormats, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("attribute-by", cobra.FixedCompletions(attributeByIdentities, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("blame-detect", cobra.FixedCompletions(blameDetections, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("line-ending", cobra.FixedCompletions(lineEndings, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("rank-by", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return RankerNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("granularity", cobra.FixedCompletions(granularities, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("owners-name", cobra.FixedCompletions(ownersNames, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("owners-identity", cobra.FixedCompletions(ownersIdentities, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("github-identity", cobra.FixedCompletions(githubIdentities, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("owners-layout", cobra.FixedCompletions(ownersLayouts, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("credit-rename-to", cobra.FixedCompletions(renameCredits, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("owner-sort", cobra.FixedCompletions(ownerSorts, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("lint-format", cobra.FixedCompletions(lintFormats, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("owner-index-format", cobra.FixedCompletions(ownerIndexFormats, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("stats-format", cobra.FixedCompletions([]string{constants.OutputTable, constants.OutputJSON}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.MarkPersistentFlagDirname("output-path")
+	_ = cmd.MarkPersistentFlagFilename("output-file")
+	_ = cmd.MarkPersistentFlagFilename("cpu-profile")
+	_ = cmd.MarkPersistentFlagFilename("lint")
+	_ = cmd.MarkPersistentFlagFilename("patterns-from")
+	_ = cmd.MarkPersistentFlagFilename("config-a", "yaml", "yml")
+	_ = cmd.MarkPersistentFlagFilename("config-b", "yaml", "yml")
+	_ = cmd.MarkPersistentFlagFilename("contributor-count-file", "csv")
+	_ = cmd.MarkPersistentFlagFilename("markdown-summary", "md")
+	_ = cmd.MarkPersistentFlagDirname("split-by-team")
+	_ = cmd.MarkPersistentFlagFilename("from-manifest", "csv", "json")
+
+	return cmd
+}
+
+func run(opts *Options, cmd *cobra.Command) error {
+	var err error
+	opts.logger, err = logging.NewLogger(opts.logFormat, opts.loglevel, opts.tty)
+	if err != nil {
+		return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("could not build logger: %w", err)).WithField(constants.FlagNameLogFormat)
+	}
+	opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Built logger with log level: %d\n", opts.loglevel)
+	opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Loaded config from: %s\n", opts.configLoadedPath)
+	if opts.globalConfigPath != "" {
+		opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Merged global config from: %s\n", opts.globalConfigPath)
+	}
+
+	if opts.lintPath != "" {
+		err = lintFile(cmd.OutOrStdout(), opts.lintPath, opts.config, opts.lintFormat)
+		if errors.Is(err, errLintFindings) {
+			return utils.NewCLIError(constants.ErrorCodeLint, opts.lintPath, err).WithField("lint")
+		}
+		if err != nil {
+			return utils.NewCLIError(constants.ErrorCodeOutput, opts.lintPath, err).WithField("lint")
+		}
+
+		return nil
+	}
+
+	if opts.verifyConfig {
+		return runVerifyConfig(opts, cmd.OutOrStdout())
+	}
+
+	if opts.attributeOrgMembers != "" {
+		opts.orgMembers = newOrgMemberResolverFromEnv(opts.attributeOrgMembers, opts)
+	}
+
+	if opts.checkAgainstRemote != "" {
+		opts.remoteCheckClient = newRemoteCheckClient(opts)
+	}
+
+	if opts.activeMembersOnly != "" {
+		opts.activeMembers, err = newActiveMembersResolver(opts.activeMembersOnly, opts.membersCacheTTL)
+		if err != nil {
+			return utils.NewCLIError(constants.ErrorCodeConfig, opts.activeMembersOnly, err).WithField("active-members-only")
+		}
+	}
+
+	if opts.cpuProfile != "" {
+		stopCPUProfile, err := startCPUProfile(opts.cpuProfile)
+		if err != nil {
+			return utils.NewCLIError(constants.ErrorCodeOutput, opts.cpuProfile, err).WithField("cpu-profile")
+		}
+		defer stopCPUProfile()
+	}
+
+	// the timings are printed to stderr to keep them out of the --stats-only output
+	defer func() {
+		_ = opts.profiler.write(cmd.ErrOrStderr())
+	}()
+
+	// A manifest replaces the git repository entirely
+	var (
+		repo      *git.Repository
+		treeFiles map[string]struct{}
+	)
+	if opts.manifestPath == "" {
+		repo, treeFiles, err = openRepoFiles(opts)
+		if err != nil {
+			_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+			return err
+		}
+	}
+
+	matcher, err := newPathMatcher(opts.pathPatterns)
+	if err != nil {
+		return utils.NewCLIError(constants.ErrorCodePath, strings.Join(opts.pathPatterns, " "), err)
+	}
+
+	excludeMatcher, err := newPrefixMatcher(opts.excludePaths)
+	if err != nil {
+		return utils.NewCLIError(constants.ErrorCodePath, strings.Join(opts.excludePaths, ","), err).WithField("exclude-path")
+	}
+
+	extensionFilter, err := newExtensionFilter(opts.includeExtensions, opts.excludeExtensions)
+	if err != nil {
+		return utils.NewCLIError(constants.ErrorCodeConfig, "", err)
+	}
+
+	ignoreMatcher, err := newPrefixMatcher(opts.ignorePatterns)
+	if err != nil {
+		return utils.NewCLIError(constants.ErrorCodePath, strings.Join(opts.ignorePatterns, ","), err).WithField("ignore")
+	}
+
+	processOptions := ProcessOptions{
+		repo:             repo,
+		previousDays:     opts.previousDays,
+		maxCommits:       opts.maxCommits,
+		dirPath:          opts.path,
+		matcher:          matcher,
+		excludeMatcher:   excludeMatcher,
+		extensionFilter:  extensionFilter,
+		maxDepth:         opts.maxDepth,
+		limitDepth:       opts.maxDepth >= 0,
+		firstParent:      opts.firstParent,
+		mergeWeight:      opts.mergeWeight,
+		weighMerges:      opts.weighMerges,
+		creatorBoost:     opts.creatorBoost,
+		boostCreator:     opts.boostCreator,
+		netReverts:       opts.netReverts,
+		creditRenameTo:   opts.creditRenameTo,
+		pathLocalOnly:    opts.pathLocalOnly,
+		followSubmodules: opts.followSubmodules,
+		attributeBy:      opts.attributeBy,
+		allowedAuthors:   opts.config.AllowedAuthors,
+		treeFiles:        treeFiles,
+		logger:           opts.logger,
+	}
+	opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Looking back %d days\n", opts.previousDays)
+
+	if opts.useDefaultBranch {
+		processOptions.ref = opts.defaultBranchRef
+	}
+
+	// the authors each config allows are filtered once the history is walked
+	if opts.configA != nil {
+		processOptions.allowedAuthors = nil
+	}
+
+	if opts.scoreCommand != "" {
+		processOptions.scorer = newCommitScorer(opts.scoreCommand, opts.logger)
+	}
+
+	if opts.atCommit != nil {
+		processOptions.ref = opts.atCommit.Hash.String()
+		processOptions.asOf = opts.atCommit.Committer.When
+	}
+
+	// Only the history of the explained file is read
+	if opts.explain != "" {
+		processOptions.scope = func(filename string) bool {
+			return filename == opts.explain
+		}
+	}
+
+	// Define which file to generate based on a flag
+	fileType := outputFileType(opts)
+
+	if opts.stream {
+		opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Streaming codeowners file at: %s\n", opts.outputPath)
+
+		err = streamOutputFile(processOptions, ignoreMatcher, opts.outputFilePath(fileType, ""), opts, cmd)
+		if errors.Is(err, errEmptyOutput) {
+			_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+			return utils.NewCLIError(constants.ErrorCodeEmpty, "", err).WithField("fail-on-empty")
+		}
+		if err != nil {
+			_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+			return utils.NewCLIError(constants.ErrorCodeOutput, opts.outputFilePath(fileType, ""), fmt.Errorf("error streaming codeowners file: %w", err))
+		}
+
+		return finishGenerate(opts, fileType)
+	}
+
+	history := opts.history
+	if history == nil {
+		history = processOptions
+	}
+
+	if opts.manifestPath != "" {
+		opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Reading contributions from manifest: %s\n", opts.manifestPath)
+		history = manifestHistory{manifestPath: opts.manifestPath, po: processOptions}
+	}
+
+	stopWalk := opts.profiler.phase(phaseWalkHistory)
+	codeowners, err := history.FileStats(opts.path, processOptions.ref)
+	stopWalk()
+	if err != nil && opts.manifestPath != "" {
+		_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+		return utils.NewCLIError(constants.ErrorCodeConfig, opts.manifestPath, err).WithField("from-manifest")
+	}
+	if err != nil {
+		_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+		return utils.NewCLIError(constants.ErrorCodeGit, opts.path, fmt.Errorf("error traversing git log: %w", err))
+	}
+
+	if opts.normalizePaths {
+		codeowners = codeowners.normalizePaths()
+	}
+
+	codeowners.removeMatching(ignoreMatcher)
+	codeowners.removeBelowChurn(opts.minChurn)
+
+	if opts.generatedRegex != nil {
+		err = codeowners.removeGenerated(repo, opts)
+		if err != nil {
+			_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+			return utils.NewCLIError(constants.ErrorCodeGit, opts.path, fmt.Errorf("error detecting generated files: %w", err))
+		}
+	}
+
+	if opts.fileSizes != nil {
+		err = loadFileSizes(repo, codeowners, opts)
+		if err != nil {
+			_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+			return utils.NewCLIError(constants.ErrorCodeGit, opts.path, fmt.Errorf("error reading file sizes: %w", err))
+		}
+	}
+
+	err = applyOrgMembers(codeowners, opts)
+	if err != nil {
+		_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+		return utils.NewCLIError(constants.ErrorCodeConfig, opts.attributeOrgMembers, err).WithField("attribute-org-members")
+	}
+
+	err = applyActiveMembers(codeowners, opts)
+	if err != nil {
+		_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+		return utils.NewCLIError(constants.ErrorCodeConfig, opts.activeMembersOnly, err).WithField("active-members-only")
+	}
+
+	if opts.inheritOwners {
+		opts.ancestorStats = newAncestorStats(codeowners)
+	}
+
+	if opts.rankBy == RankByBlame {
+		err = blameFileStats(&processOptions, codeowners, opts)
+		if err != nil {
+			_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+			return utils.NewCLIError(constants.ErrorCodeGit, opts.path, fmt.Errorf("error blaming files: %w", err))
+		}
+	}
+
+	if opts.readInlineOwners {
+		err = loadInlineOwners(repo, codeowners, opts)
+		if err != nil {
+			_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+			return utils.NewCLIError(constants.ErrorCodeGit, opts.path, fmt.Errorf("error reading inline owners: %w", err))
+		}
+	}
+
+	if opts.configA != nil {
+		err = runConfigDiff(cmd.OutOrStdout(), codeowners, opts, cmd)
+		if err != nil {
+			return utils.NewCLIError(constants.ErrorCodeConfig, "", err).WithField("config-a")
+		}
+
+		return nil
+	}
+
+	if opts.contributorCountFile != "" {
+		err = writeContributorCounts(opts.contributorCountFile, countContributors(codeowners))
+		if err != nil {
+			return utils.NewCLIError(constants.ErrorCodeOutput, opts.contributorCountFile, err).WithField("contributor-count-file")
+		}
+	}
+
+	if opts.explain != "" {
+		err = explainOwnership(cmd.OutOrStdout(), opts.explain, codeowners, opts)
+		if err != nil {
+			return utils.NewCLIError(constants.ErrorCodeOutput, "", err)
+		}
+
+		return nil
+	}
+
+	err = checkEmptyOutput(len(codeowners), opts)
+	if err != nil {
+		_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+		return utils.NewCLIError(constants.ErrorCodeEmpty, "", err).WithField("fail-on-empty")
+	}
+
+	if opts.statsOnly {
+		stopAttribute := opts.profiler.phase(phaseAttribute)
+		report, err := computeStatsReport(codeowners, opts)
+		stopAttribute()
+		if err != nil {
+			_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+			return utils.NewCLIError(constants.ErrorCodeConfig, "", err)
+		}
+
+		output, err := report.BuildOutput(opts.statsFormat)
+		if err != nil {
+			return utils.NewCLIError(constants.ErrorCodeOutput, "", err)
+		}
+
+		fmt.Println(output)
+		return nil
+	}
+
+	if opts.markdownSummary != "" {
+		opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Processing Markdown summary at: %s\n", opts.markdownSummary)
+
+		err = generateMarkdownSummary(codeowners, opts.markdownSummary, opts)
+		if err != nil {
+			_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+			return utils.NewCLIError(constants.ErrorCodeOutput, opts.markdownSummary, fmt.Errorf("error generating Markdown summary: %w", err)).WithField("markdown-summary")
+		}
+	}
+
+	if opts.splitByTeam != "" {
+		opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Processing CODEOWNERS files of each team at: %s\n", opts.splitByTeam)
+
+		err = generateTeamSplit(codeowners, opts.splitByTeam, opts, cmd)
+		if err != nil {
+			_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+			return utils.NewCLIError(constants.ErrorCodeOutput, opts.splitByTeam, fmt.Errorf("error generating CODEOWNERS files of each team: %w", err)).WithField("split-by-team")
+		}
+	}
+
+	if opts.ownersHierarchy {
+		opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Processing OWNERS files hierarchy at: %s\n", opts.outputPath)
+
+		stopAttribute := opts.profiler.phase(phaseAttribute)
+		files := buildOwnersHierarchy(codeowners, opts)
+		stopAttribute()
+
+		stopWrite := opts.profiler.phase(phaseWrite)
+		err = writeOwnersHierarchy(files, opts, cmd)
+		stopWrite()
+		if err != nil {
+			_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+			return utils.NewCLIError(constants.ErrorCodeOutput, opts.outputPath, fmt.Errorf("error generating OWNERS files hierarchy: %w", err))
+		}
+
+		return finishGenerate(opts, fileType)
+	}
+
+	if opts.format == formatOwnerIndex {
+		opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Processing owner index at: %s\n", opts.outputPath)
+
+		err = generateOwnerIndexFile(codeowners, opts.outputFilePath(fileType, ""), opts, cmd)
+		if err != nil {
+			_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+			return utils.NewCLIError(constants.ErrorCodeOutput, opts.outputFilePath(fileType, ""), fmt.Errorf("error generating owner index: %w", err))
+		}
+
+		if opts.dryRun {
+			return nil
+		}
+
+		return finishGenerate(opts, fileType)
+	}
+
+	if opts.format == formatAzure {
+		opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Processing Azure required reviewer policies at: %s\n", opts.outputPath)
+
+		err = generateAzureFile(codeowners, opts.outputFilePath(fileType, ""), opts, cmd)
+		if err != nil {
+			_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+			return utils.NewCLIError(constants.ErrorCodeOutput, opts.outputFilePath(fileType, ""), fmt.Errorf("error generating Azure required reviewer policies: %w", err))
+		}
+
+		if opts.dryRun {
+			return nil
+		}
+
+		return finishGenerate(opts, fileType)
+	}
+
+	opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Processing codeowners file at: %s\n", opts.outputPath)
+
+	err = generateOutputFile(codeowners, opts.outputFilePath(fileType, ""), opts, cmd)
+	if errors.Is(err, errRemoteStale) {
+		return utils.NewCLIError(constants.ErrorCodeStale, opts.checkAgainstRemote, err).WithField("check-against-remote")
+	}
+	if err != nil {
+		_ = opts.telemetry.CaptureFailedCodeownersGenerate()
+		return utils.NewCLIError(constants.ErrorCodeOutput, opts.outputFilePath(fileType, ""), fmt.Errorf("error generating github style codeowners file: %w", err))
+	}
+
+	if opts.dryRun || opts.checkAgainstRemote != "" {
+		return nil
+	}
+
+	return finishGenerate(opts, fileType)
+}
+
+// outputFileType gets the name of the file generated in the --format
+func outputFileType(opts *Options) string {
+	switch opts.format {
+	case formatOwners:
+		return "OWNERS"
+	case formatOwnerIndex:
+		return ownerIndexFileType(opts.ownerIndexFormat)
+	case formatAzure:
+		return azureFileType
+	default:
+		return "CODEOWNERS"
+	}
+}
+
+// openRepoFiles opens the repository and, for a bare repository without a working tree,
+// lists the files of its HEAD tree. With --at, the files of its commit's tree are listed
+// instead. The tracked files are also listed for --pattern-mode.
+func openRepoFiles(opts *Options) (*git.Repository, map[string]struct{}, error) {
+	stopOpen := opts.profiler.phase(phaseOpenRepo)
+	repo, err := openRepo(opts)
+	stopOpen()
+	if err != nil {
+		return nil, nil, utils.NewCLIError(constants.ErrorCodeGit, opts.path, fmt.Errorf("error opening repo: %w", err))
+	}
+	opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Opened repo at: %s\n", opts.path)
+
+	err = checkShallow(repo, opts)
+	if err != nil {
+		return nil, nil, utils.NewCLIError(constants.ErrorCodeGit, opts.path, err).WithField("allow-shallow")
+	}
+
+	if opts.useDefaultBranch {
+		opts.defaultBranchRef = resolveDefaultBranchRef(repo, opts)
+	}
+
+	// Bare repositories have no working tree on disk: the file list
+	// must be read from the HEAD tree object instead
+	var treeFiles map[string]struct{}
+	if opts.at != "" {
+		opts.atCommit, err = resolveCommit(repo, opts.at)
+		if err != nil {
+			return nil, nil, utils.NewCLIError(constants.ErrorCodeGit, opts.path, err).WithField("at")
+		}
+		opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Generating as of commit: %s\n", opts.atCommit.Hash)
+
+		treeFiles, err = listCommitFiles(opts.atCommit)
+		if err != nil {
+			return nil, nil, utils.NewCLIError(constants.ErrorCodeGit, opts.path, err)
+		}
+	} else if _, err := repo.Worktree(); errors.Is(err, git.ErrIsBareRepository) {
+		opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Repo has no working tree, reading files from HEAD tree\n")
+
+		treeFiles, err = listTreeFiles(repo, opts.defaultBranchRef)
+		if err != nil {
+			return nil, nil, utils.NewCLIError(constants.ErrorCodeGit, opts.path, err)
+		}
+	}
+
+	// the tracked files guard pattern mode, count as matches of --patterns-from, and
+	// tell which directories are empty with --skip-empty-directories
+	if opts.patternMode || opts.sourcePatterns != nil || opts.skipEmptyDirectories {
+		opts.trackedFiles = treeFiles
+		if opts.trackedFiles == nil {
+			opts.trackedFiles, err = listTreeFiles(repo, opts.defaultBranchRef)
+			if err != nil {
+				return nil, nil, utils.NewCLIError(constants.ErrorCodeGit, opts.path, err)
+			}
+		}
+	}
+
+	return repo, treeFiles, nil
+}
+
+// resolveConfigDefaults resolves the options which may be defaulted in the config:
+// the format, the identity and name of owners in the OWNERS format, the maximum owners, and the output path.
+// Flags take precedence over the config, which takes precedence over the built in defaults.
+func resolveConfigDefaults(cmd *cobra.Command, opts *Options) {
+	opts.format, _ = cmd.Flags().GetString("format")
+	if !cmd.Flags().Changed("format") && opts.config.Format != "" {
+		opts.format = opts.config.Format
+	}
+
+	if ownersStyleFile, _ := cmd.Flags().GetBool("owners-style-file"); ownersStyleFile {
+		opts.format = formatOwners
+	}
+
+	opts.ownersIdentity, _ = cmd.Flags().GetString("owners-identity")
+	if !cmd.Flags().Changed("owners-identity") && opts.config.OwnersIdentity != "" {
+		opts.ownersIdentity = opts.config.OwnersIdentity
+	}
+
+	opts.ownersName, _ = cmd.Flags().GetString("owners-name")
+	if !cmd.Flags().Changed("owners-name") && opts.config.OwnersName != "" {
+		opts.ownersName = opts.config.OwnersName
+	}
+
+	opts.maxOwners, _ = cmd.Flags().GetInt("max-owners")
+	if !cmd.Flags().Changed("max-owners") && opts.config.MaxOwners > 0 {
+		opts.maxOwners = opts.config.MaxOwners
+	}
+
+	opts.maxOwnersPerLine, _ = cmd.Flags().GetInt("max-owners-per-line")
+	if !cmd.Flags().Changed("max-owners-per-line") && opts.config.MaxOwnersPerLine != nil {
+		opts.maxOwnersPerLine = *opts.config.MaxOwnersPerLine
+	}
+
+	// Default the outputPath to the base path if no flag value is given.
+	// Remote repositories have no base path on disk, so use the current directory.
+	basePath := opts.path
+	if opts.remoteURL != "" {
+		basePath = "."
+	}
+
+	opts.outputPath, _ = cmd.Flags().GetString("output-path")
+	if opts.outputPath == "" {
+		opts.outputPath = basePath
+
+		if opts.config.OutputPath != "" {
+			opts.outputPath = opts.config.OutputPath
+			if !filepath.IsAbs(opts.outputPath) {
+				opts.outputPath = filepath.Join(basePath, opts.outputPath)
+			}
+		}
+	}
+}
+
+// resolveDefaultBranchRef gets the ref to walk the history from when using the default branch.
+// The default branch is only used when HEAD is detached. An empty ref, meaning HEAD,
+// is returned otherwise or if the default branch can't be detected.
+func resolveDefaultBranchRef(repo *git.Repository, opts *Options) string {
+	detached, err := isDetachedHead(repo)
+	if err != nil || !detached {
+		return ""
+	}
+
+	ref, err := detectDefaultBranch(repo)
+	if err != nil {
+		opts.logger.V(logging.LogDebug).Style(0, colors.FgYellow).Warnf("Could not detect the default branch, using HEAD: %s\n", err)
+		return ""
+	}
+
+	opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("HEAD is detached, using the default branch: %s\n", ref)
+	return ref
+}
+
+// mergeGlobalConfig merges the user's global config, if it exists, under the config
+func mergeGlobalConfig(opts *Options) error {
+	globalPath, err := config.GlobalConfigPath()
+	if err != nil {
+		return err
+	}
+
+	global, loadedPath, err := config.LoadGlobalConfig(globalPath)
+	if err != nil {
+		opts.globalConfigPath = globalPath
+		return err
+	}
+
+	merged, err := opts.config.MergeGlobal(global)
+	if err != nil {
+		opts.globalConfigPath = loadedPath
+		return err
+	}
+
+	opts.config = merged
+	opts.globalConfigPath = loadedPath
+
+	return nil
+}
+
+// loadConfigFile loads the config at the local path or URL, without falling back to the home directory
+func loadConfigFile(configPath string, cacheTTL time.Duration) (*config.Spec, error) {
+	var spec *config.Spec
+	var err error
+	if config.IsRemotePath(configPath) {
+		spec, _, err = config.LoadRemoteConfig(configPath, cacheTTL)
+	} else {
+		spec, _, err = config.LoadConfigFile(configPath)
+	}
+
+	return spec, err
+}
+
+// writeConfigSchema writes the JSON schema of the config
+func writeConfigSchema(w io.Writer) error {
+	schema, err := json.MarshalIndent(config.JSONSchema(), "", "  ")
+	if err != nil {
+		return utils.NewCLIError(constants.ErrorCodeOutput, "", fmt.Errorf("could not marshal config schema: %w", err))
+	}
+
+	_, err = fmt.Fprintln(w, string(schema))
+	return err
+}
+
+// finishGenerate reports a successfully generated file
+func finishGenerate(opts *Options, fileType string) error {
+	opts.logger.V(logging.LogInfo).Style(0, colors.FgGreen).Infof("Finished generating file: %s\n", opts.outputFilePath(fileType, ""))
+	_ = opts.telemetry.CaptureCodeownersGenerate()
+
+	opts.logger.V(logging.LogInfo).Style(0, colors.FgCyan).Infof("\nCreate an OpenSauced Contributor Insight to get metrics and insights on these codeowners:\n")
+	opts.logger.V(logging.LogInfo).Style(0, colors.FgCyan).Infof("$ pizza generate insight " + opts.path + "\n")
+	_ = opts.telemetry.CaptureCodeownersGenerateContributorInsight()
+
+	return nil
+}
+
+// openRepo opens the git repository to generate codeowners for. Remote repositories
+// are cloned into memory without a working tree.
+func openRepo(opts *Options) (*git.Repository, error) {
+	if opts.remoteURL != "" {
+		return git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
+			URL: opts.remoteURL,
+		})
+	}
+
+	return git.PlainOpen(opts.path)
+}
+
+// isRemoteURL returns true if the argument is a remote git URL instead of a path on disk
+func isRemoteURL(arg string) bool {
+	for _, prefix := range []string{"https://", "http://", "ssh://", "git://", "git@"} {
+		if strings.HasPrefix(arg, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolvePathArgs resolves the positional arguments into the repository root
+// and the set of path patterns, relative to that root, to scope generation to.
+// The root of a glob is found by its ".git" directory, or any of the given root markers.
+//
+// A single directory argument is the repository itself and yields no patterns.
+// Otherwise, each argument is either a literal file path (i.e., a glob already
+// expanded by the user's shell), a directory which matches everything beneath it,
+// or a quoted glob pattern that pizza expands itself.
+func resolvePathArgs(args []string, rootMarkers ...string) (string, []string, error) {
+	if len(args) == 1 && !hasGlobMeta(args[0]) {
+		absPath, err := filepath.Abs(args[0])
+		if err != nil {
+			return "", nil, err
+		}
+
+		info, err := os.Stat(absPath)
+		if os.IsNotExist(err) {
+			return "", nil, fmt.Errorf("the provided path does not exist: %w", err)
+		}
+
+		if err == nil && info.IsDir() {
+			return absPath, nil, nil
+		}
+	}
+
+	var root string
+	patterns := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		absArg, err := filepath.Abs(arg)
+		if err != nil {
+			return "", nil, err
+		}
+
+		isDir := false
+		if !hasGlobMeta(arg) {
+			info, err := os.Stat(absArg)
+			if os.IsNotExist(err) {
+				return "", nil, fmt.Errorf("the provided path does not exist: %w", err)
+			}
+
+			isDir = err == nil && info.IsDir()
+		}
+
+		if root == "" {
+			baseDir := globBaseDir(absArg)
+			if isDir {
+				baseDir = absArg
+			}
+
+			root, err = findRepoRoot(baseDir, rootMarkers...)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+
+		relPath, err := filepath.Rel(root, absArg)
+		if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			return "", nil, fmt.Errorf("the provided path %s is not within repository %s", arg, root)
+		}
+
+		// a directory scopes generation to everything beneath it
+		pattern := filepath.ToSlash(relPath)
+		switch {
+		case isDir && pattern == ".":
+			pattern = "**"
+		case isDir:
+			pattern += "/**"
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return root, patterns, nil
+}
+
+// globBaseDir returns the longest leading directory of an absolute path
+// which contains no glob meta characters
+func globBaseDir(absPath string) string {
+	if !hasGlobMeta(absPath) {
+		return filepath.Dir(absPath)
+	}
+
+	dir := absPath[:strings.IndexAny(absPath, "*?[")]
+	return filepath.Dir(dir + "x")
+}
+
+// findRepoRoot walks up from the given directory until it finds the
+// root of a git repository, or a directory containing one of the given root markers
+func findRepoRoot(dir string, rootMarkers ...string) (string, error) {
+	current := dir
+	for {
+		for _, marker := range append([]string{".git"}, rootMarkers...) {
+			if _, err := os.Stat(filepath.Join(current, marker)); err == nil {
+				return current, nil
+			}
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", fmt.Errorf("could not find a git repository containing: %s", dir)
+		}
+
+		current = parent
+	}
 }
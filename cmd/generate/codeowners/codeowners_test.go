@@ -0,0 +1,243 @@
+package codeowners
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
+)
+
+func setupRepoDir(t *testing.T, files ...string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+
+	for _, file := range files {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("test"), 0600))
+	}
+
+	return root
+}
+
+func TestResolvePathArgs(t *testing.T) {
+	root := setupRepoDir(t, "src/main.go", "src/pkg/util.go", "README.md")
+
+	t.Run("repository directory", func(t *testing.T) {
+		path, patterns, err := resolvePathArgs([]string{root})
+		require.NoError(t, err)
+		assert.Equal(t, root, path)
+		assert.Empty(t, patterns)
+	})
+
+	t.Run("quoted glob expanded by pizza", func(t *testing.T) {
+		path, patterns, err := resolvePathArgs([]string{filepath.Join(root, "src", "**", "*.go")})
+		require.NoError(t, err)
+		assert.Equal(t, root, path)
+		assert.Equal(t, []string{"src/**/*.go"}, patterns)
+	})
+
+	t.Run("glob already expanded by the shell", func(t *testing.T) {
+		path, patterns, err := resolvePathArgs([]string{
+			filepath.Join(root, "src", "main.go"),
+			filepath.Join(root, "src", "pkg", "util.go"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, root, path)
+		assert.Equal(t, []string{"src/main.go", "src/pkg/util.go"}, patterns)
+	})
+
+	t.Run("directory mixed with a glob", func(t *testing.T) {
+		path, patterns, err := resolvePathArgs([]string{
+			filepath.Join(root, "src"),
+			filepath.Join(root, "*.md"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, root, path)
+		assert.Equal(t, []string{"src/**", "*.md"}, patterns)
+
+		matcher, err := newPathMatcher(patterns)
+		require.NoError(t, err)
+		assert.True(t, matcher.matches("src/main.go"))
+		assert.True(t, matcher.matches("src/pkg/util.go"))
+		assert.True(t, matcher.matches("README.md"))
+		assert.False(t, matcher.matches("main.go"))
+	})
+
+	t.Run("repository directory mixed with a file", func(t *testing.T) {
+		path, patterns, err := resolvePathArgs([]string{root, filepath.Join(root, "README.md")})
+		require.NoError(t, err)
+		assert.Equal(t, root, path)
+		assert.Equal(t, []string{"**", "README.md"}, patterns)
+
+		matcher, err := newPathMatcher(patterns)
+		require.NoError(t, err)
+		assert.True(t, matcher.matches("src/pkg/util.go"))
+	})
+
+	t.Run("non-existent literal path", func(t *testing.T) {
+		_, _, err := resolvePathArgs([]string{
+			filepath.Join(root, "src", "main.go"),
+			filepath.Join(root, "src", "missing.go"),
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("path outside of a repository", func(t *testing.T) {
+		_, _, err := resolvePathArgs([]string{filepath.Join(t.TempDir(), "*.go")})
+		require.Error(t, err)
+	})
+}
+
+func TestResolveConfigDefaults(t *testing.T) {
+	resolve := func(t *testing.T, spec *config.Spec, args ...string) *Options {
+		t.Helper()
+
+		cmd := NewCodeownersCommand()
+		require.NoError(t, cmd.ParseFlags(args))
+
+		opts := &Options{path: "/repo", config: spec}
+		resolveConfigDefaults(cmd, opts)
+
+		return opts
+	}
+
+	t.Run("built in defaults", func(t *testing.T) {
+		opts := resolve(t, &config.Spec{})
+		assert.Equal(t, formatGitHub, opts.format)
+		assert.Equal(t, 3, opts.maxOwners)
+		assert.Equal(t, "/repo", opts.outputPath)
+		assert.Equal(t, ownersIdentityName, opts.ownersIdentity)
+	})
+
+	t.Run("max owners per line", func(t *testing.T) {
+		opts := resolve(t, &config.Spec{})
+		assert.Equal(t, defaultMaxOwnersPerLine, opts.maxOwnersPerLine)
+
+		ten, zero := 10, 0
+		opts = resolve(t, &config.Spec{MaxOwnersPerLine: &ten})
+		assert.Equal(t, 10, opts.maxOwnersPerLine)
+
+		opts = resolve(t, &config.Spec{MaxOwnersPerLine: &ten}, "--max-owners-per-line", "0")
+		assert.Equal(t, 0, opts.maxOwnersPerLine)
+
+		// an explicit 0 in the config disables the cap too
+		opts = resolve(t, &config.Spec{MaxOwnersPerLine: &zero})
+		assert.Equal(t, 0, opts.maxOwnersPerLine)
+	})
+
+	t.Run("owners identity", func(t *testing.T) {
+		opts := resolve(t, &config.Spec{OwnersIdentity: ownersIdentityAlias})
+		assert.Equal(t, ownersIdentityAlias, opts.ownersIdentity)
+
+		opts = resolve(t, &config.Spec{OwnersIdentity: ownersIdentityAlias}, "--owners-identity", ownersIdentityEmail)
+		assert.Equal(t, ownersIdentityEmail, opts.ownersIdentity)
+	})
+
+	t.Run("config over defaults", func(t *testing.T) {
+		opts := resolve(t, &config.Spec{Format: formatGitea, MaxOwners: 5, OutputPath: ".github"})
+		assert.Equal(t, formatGitea, opts.format)
+		assert.Equal(t, 5, opts.maxOwners)
+		assert.Equal(t, filepath.Join("/repo", ".github"), opts.outputPath)
+	})
+
+	t.Run("flags over config", func(t *testing.T) {
+		opts := resolve(t, &config.Spec{Format: formatGitea, MaxOwners: 5, OutputPath: ".github"},
+			"--format", formatBitbucket, "--max-owners", "1", "--output-path", "out")
+		assert.Equal(t, formatBitbucket, opts.format)
+		assert.Equal(t, 1, opts.maxOwners)
+		assert.Equal(t, "out", opts.outputPath)
+	})
+
+	t.Run("flag set to the default value", func(t *testing.T) {
+		opts := resolve(t, &config.Spec{Format: formatGitea, MaxOwners: 5}, "--format", formatGitHub, "--max-owners", "3")
+		assert.Equal(t, formatGitHub, opts.format)
+		assert.Equal(t, 3, opts.maxOwners)
+	})
+}
+
+func TestConfigSchemaFlag(t *testing.T) {
+	cmd := NewCodeownersCommand()
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--config-schema"})
+	require.NoError(t, cmd.Execute())
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &schema))
+	assert.Equal(t, config.JSONSchemaDraft, schema["$schema"])
+	assert.Contains(t, schema["properties"], "attribution")
+}
+
+func TestRemoteRepositoryConfig(t *testing.T) {
+	if _, _, err := config.LoadHomeConfig(); err == nil {
+		t.Skip("a home config would clone the remote repository")
+	}
+
+	cmd := NewCodeownersCommand()
+	cmd.PersistentFlags().Bool(constants.FlagNameTelemetry, true, "")
+	cmd.PersistentFlags().String("config", "", "")
+	cmd.SetArgs([]string{"https://example.com/owner/repo.git"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	// the home config is loaded instead of a path within the remote URL
+	err := cmd.Execute()
+	require.ErrorContains(t, err, "the .sauced.yaml of a remote repository can't be read")
+	assert.NotContains(t, err.Error(), "example.com")
+	assert.Equal(t, constants.ExitCodeConfig, utils.ExitCode(err))
+}
+
+func TestExitCodes(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".sauced.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("attribution:\n  jpmcb: [john@opensauced.pizza]\n"), 0600))
+
+	execute := func(t *testing.T, args ...string) int {
+		t.Helper()
+
+		// the config and telemetry flags are inherited from the root command
+		cmd := NewCodeownersCommand()
+		cmd.PersistentFlags().Bool(constants.FlagNameTelemetry, true, "")
+		cmd.PersistentFlags().String("config", "", "")
+		cmd.SetArgs(append(args, "--config", configPath))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+
+		return utils.ExitCode(cmd.Execute())
+	}
+
+	t.Run("bad config", func(t *testing.T) {
+		assert.Equal(t, constants.ExitCodeConfig, execute(t, t.TempDir(), "--format", "unknown"))
+	})
+
+	t.Run("git error", func(t *testing.T) {
+		assert.Equal(t, constants.ExitCodeGit, execute(t, t.TempDir()))
+	})
+
+	t.Run("bad path", func(t *testing.T) {
+		assert.Equal(t, constants.ExitCodePath, execute(t, filepath.Join(t.TempDir(), "missing")))
+	})
+
+	t.Run("empty output", func(t *testing.T) {
+		dir, _ := newTestRepo(t, testCommit{"John", "john@opensauced.pizza", map[string]string{"main.go": "package main\n"}})
+		assert.Equal(t, constants.ExitCodeEmpty, execute(t, dir, "--output-path", t.TempDir(), "--ignore", "main.go", "--fail-on-empty"))
+	})
+
+	t.Run("lint findings", func(t *testing.T) {
+		codeownersPath := filepath.Join(t.TempDir(), "CODEOWNERS")
+		require.NoError(t, os.WriteFile(codeownersPath, []byte("main.go @jpmcb\nmain.go @jpmcb\n"), 0600))
+		assert.Equal(t, constants.ExitCodeLint, execute(t, t.TempDir(), "--lint", codeownersPath))
+	})
+}
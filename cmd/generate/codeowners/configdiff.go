@@ -0,0 +1,156 @@
+package codeowners
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+// configOwnersChange is a rule whose owners change between two configs
+type configOwnersChange struct {
+	rule    string
+	removed []string
+	added   []string
+}
+
+// filterAllowedAuthors copies the stats keeping only the authors the config's allowed-authors
+// allow, the same as filtering them while walking the history. Files whose authors are all
+// filtered out are kept without any, so the fallback applies to them.
+func filterAllowedAuthors(fileStats FileStats, spec *config.Spec) FileStats {
+	po := ProcessOptions{allowedAuthors: spec.AllowedAuthors}
+
+	filtered := make(FileStats, len(fileStats))
+	for filename, authorStats := range fileStats {
+		filtered[filename] = make(AuthorStats, len(authorStats))
+		for author, stat := range authorStats {
+			if po.isAllowedAuthor(&object.Signature{Name: stat.Name, Email: stat.Email}) {
+				filtered[filename][author] = stat
+			}
+		}
+	}
+
+	return filtered
+}
+
+// configRuleOwners attributes the owners of every rule with the config, the same as
+// generating a file with it. The config's defaults are resolved like the main config's.
+func configRuleOwners(fileStats FileStats, spec *config.Spec, opts *Options, cmd *cobra.Command) (map[string][]string, error) {
+	specOpts := *opts
+	specOpts.config = spec
+	resolveConfigDefaults(cmd, &specOpts)
+
+	fileStats = filterAllowedAuthors(fileStats, spec)
+	if specOpts.inheritOwners {
+		specOpts.ancestorStats = newAncestorStats(fileStats)
+	}
+
+	if specOpts.granularity == granularityDirectory {
+		fileStats = fileStats.aggregateDirectories(&specOpts)
+	}
+
+	// the stats are copied since attributing owners sets their aliases
+	ruleOwners := make(map[string][]string, len(fileStats))
+	for filename, authorStats := range fileStats {
+		copied := make(AuthorStats, len(authorStats))
+		for author, stat := range authorStats {
+			statCopy := *stat
+			copied[author] = &statCopy
+		}
+
+		owners, err := expandOwnerGroups(getOwners(filename, copied, &specOpts), spec)
+		if err != nil {
+			return nil, fmt.Errorf("error expanding the owners of %s: %w", filename, err)
+		}
+
+		for _, owner := range owners {
+			ruleOwners[filename] = append(ruleOwners[filename], "@"+owner.GitHubAlias)
+		}
+	}
+
+	return ruleOwners, nil
+}
+
+// diffRuleOwners lists the rules whose set of owners differs, in the order the rules are written
+func diffRuleOwners(a, b map[string][]string) []configOwnersChange {
+	rules := make([]string, 0, len(a))
+	for rule := range a {
+		rules = append(rules, rule)
+	}
+	for rule := range b {
+		if _, ok := a[rule]; !ok {
+			rules = append(rules, rule)
+		}
+	}
+	sortRules(rules)
+
+	var changes []configOwnersChange
+	for _, rule := range rules {
+		change := configOwnersChange{rule: rule}
+		for _, owner := range a[rule] {
+			if !slices.Contains(b[rule], owner) {
+				change.removed = append(change.removed, owner)
+			}
+		}
+		for _, owner := range b[rule] {
+			if !slices.Contains(a[rule], owner) {
+				change.added = append(change.added, owner)
+			}
+		}
+
+		if len(change.removed) > 0 || len(change.added) > 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+// writeConfigDiff writes each rule whose owners change, with the owners it loses
+// and gains, followed by a summary of how many rules change
+func writeConfigDiff(w io.Writer, changes []configOwnersChange, rules int) error {
+	for _, change := range changes {
+		var parts []string
+		for _, owner := range change.removed {
+			parts = append(parts, "-"+owner)
+		}
+		for _, owner := range change.added {
+			parts = append(parts, "+"+owner)
+		}
+
+		if _, err := fmt.Fprintf(w, "%s: %s\n", change.rule, strings.Join(parts, " ")); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d of %d rules change owners\n", len(changes), rules)
+	return err
+}
+
+// runConfigDiff attributes the owners of every rule with both the --config-a and --config-b
+// configs against the same history, and writes the rules whose owners change
+func runConfigDiff(w io.Writer, fileStats FileStats, opts *Options, cmd *cobra.Command) error {
+	ownersA, err := configRuleOwners(fileStats, opts.configA, opts, cmd)
+	if err != nil {
+		return fmt.Errorf("error attributing owners with --config-a: %w", err)
+	}
+
+	ownersB, err := configRuleOwners(fileStats, opts.configB, opts, cmd)
+	if err != nil {
+		return fmt.Errorf("error attributing owners with --config-b: %w", err)
+	}
+
+	rules := len(ownersA)
+	for rule := range ownersB {
+		if _, ok := ownersA[rule]; !ok {
+			rules++
+		}
+	}
+
+	return writeConfigDiff(w, diffRuleOwners(ownersA, ownersB), rules)
+}
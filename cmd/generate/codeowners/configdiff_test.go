@@ -0,0 +1,118 @@
+package codeowners
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
+)
+
+func TestDiffRuleOwners(t *testing.T) {
+	a := map[string][]string{
+		"main.go":    {"@jpmcb", "@brandonroberts"},
+		"README.md":  {"@jpmcb"},
+		"removed.go": {"@jpmcb"},
+	}
+	b := map[string][]string{
+		"main.go":   {"@brandonroberts", "@jpmcb"},
+		"README.md": {"@brandonroberts"},
+		"added.go":  {"@zeucapua"},
+	}
+
+	assert.Equal(t, []configOwnersChange{
+		{rule: "README.md", removed: []string{"@jpmcb"}, added: []string{"@brandonroberts"}},
+		{rule: "added.go", added: []string{"@zeucapua"}},
+		{rule: "removed.go", removed: []string{"@jpmcb"}},
+	}, diffRuleOwners(a, b))
+
+	var buf bytes.Buffer
+	require.NoError(t, writeConfigDiff(&buf, diffRuleOwners(a, b), 4))
+	assert.Equal(t, "README.md: -@jpmcb +@brandonroberts\nadded.go: +@zeucapua\nremoved.go: -@jpmcb\n3 of 4 rules change owners\n", buf.String())
+}
+
+func TestFilterAllowedAuthors(t *testing.T) {
+	fileStats := FileStats{
+		"main.go": {
+			"brandon@opensauced.pizza": {Name: "Brandon", Email: "brandon@opensauced.pizza", Lines: 10},
+			"john@opensauced.pizza":    {Name: "John", Email: "john@opensauced.pizza", Lines: 5},
+		},
+	}
+
+	filtered := filterAllowedAuthors(fileStats, &config.Spec{AllowedAuthors: []string{"john@opensauced.pizza"}})
+	assert.Len(t, filtered["main.go"], 1)
+	assert.Contains(t, filtered["main.go"], "john@opensauced.pizza")
+
+	// the original stats are left as they were
+	assert.Len(t, fileStats["main.go"], 2)
+}
+
+func TestGenerateConfigDiff(t *testing.T) {
+	dir, _ := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "package main\n", "README.md": "# readme\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"main.go": "package main\n\nfunc main() {}\n"}},
+	)
+
+	writeConfig := func(t *testing.T, contents string) string {
+		t.Helper()
+
+		configPath := filepath.Join(t.TempDir(), ".sauced.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte(contents), 0600))
+		return configPath
+	}
+
+	configA := writeConfig(t, "attribution:\n  brandonroberts: [brandon@opensauced.pizza]\n  jpmcb: [john@opensauced.pizza]\n")
+	configB := writeConfig(t, "attribution:\n  brandonroberts: [brandon@opensauced.pizza]\n  jpmcb: [john@opensauced.pizza]\nallowed-authors: [brandon@opensauced.pizza]\n")
+
+	generate := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+
+		var out bytes.Buffer
+		outputPath := t.TempDir()
+		cmd := NewCodeownersCommand()
+		cmd.PersistentFlags().Bool(constants.FlagNameTelemetry, true, "")
+		cmd.PersistentFlags().String("config", "", "")
+		cmd.SetArgs(append([]string{dir, "--config", configA, "--output-path", outputPath}, args...))
+		cmd.SetOut(&out)
+		cmd.SetErr(io.Discard)
+
+		err := cmd.Execute()
+
+		// nothing is generated when comparing configs
+		_, statErr := os.Stat(filepath.Join(outputPath, "CODEOWNERS"))
+		assert.True(t, os.IsNotExist(statErr))
+
+		return out.String(), err
+	}
+
+	t.Run("changes", func(t *testing.T) {
+		out, err := generate(t, "--config-a", configA, "--config-b", configB)
+		require.NoError(t, err)
+		assert.Equal(t, "main.go: -@jpmcb\n1 of 2 rules change owners\n", out)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		out, err := generate(t, "--config-a", configA, "--config-b", configA)
+		require.NoError(t, err)
+		assert.Equal(t, "0 of 2 rules change owners\n", out)
+	})
+
+	t.Run("both required", func(t *testing.T) {
+		_, err := generate(t, "--config-a", configA)
+		require.Error(t, err)
+		assert.Equal(t, constants.ExitCodeConfig, utils.ExitCode(err))
+	})
+
+	t.Run("missing config", func(t *testing.T) {
+		_, err := generate(t, "--config-a", configA, "--config-b", filepath.Join(t.TempDir(), "missing.yaml"))
+		require.Error(t, err)
+		assert.Equal(t, constants.ExitCodeConfig, utils.ExitCode(err))
+	})
+}
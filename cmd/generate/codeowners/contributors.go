@@ -0,0 +1,61 @@
+package codeowners
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// contributorCount is the number of distinct authors who changed a file.
+// Files changed by many people are coordination hotspots.
+type contributorCount struct {
+	filename     string
+	contributors int
+}
+
+// countContributors counts the distinct authors of each file, regardless of whether they
+// qualify as its owners. The counts are sorted by descending contributors, then filename.
+func countContributors(fs FileStats) []contributorCount {
+	counts := make([]contributorCount, 0, len(fs))
+	for filename, authorStats := range fs {
+		counts = append(counts, contributorCount{filename: filename, contributors: len(authorStats)})
+	}
+
+	sortContributorCounts(counts)
+	return counts
+}
+
+func sortContributorCounts(counts []contributorCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].contributors != counts[j].contributors {
+			return counts[i].contributors > counts[j].contributors
+		}
+
+		return counts[i].filename < counts[j].filename
+	})
+}
+
+// writeContributorCounts writes the contributor counts to a CSV sidecar file
+// with a "file,contributors" header row
+func writeContributorCounts(outputPath string, counts []contributorCount) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("could not create contributor count file: %w", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	_ = w.Write([]string{"file", "contributors"})
+	for _, count := range counts {
+		_ = w.Write([]string{count.filename, strconv.Itoa(count.contributors)})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error writing to %s file: %w", outputPath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountContributors(t *testing.T) {
+	fileStats := FileStats{
+		"main.go": {
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 10},
+			"john":    {Email: "john@opensauced.pizza", Lines: 1},
+			"nick":    {Email: "nick@opensauced.pizza", Lines: 1},
+		},
+		"b.go":     {"john": {Email: "john@opensauced.pizza", Lines: 5}},
+		"a.go":     {"john": {Email: "john@opensauced.pizza", Lines: 5}},
+		"empty.go": {},
+	}
+
+	assert.Equal(t, []contributorCount{
+		{filename: "main.go", contributors: 3},
+		{filename: "a.go", contributors: 1},
+		{filename: "b.go", contributors: 1},
+		{filename: "empty.go", contributors: 0},
+	}, countContributors(fileStats))
+}
+
+func TestWriteContributorCounts(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "contributors.csv")
+
+	require.NoError(t, writeContributorCounts(outputPath, []contributorCount{
+		{filename: "main.go", contributors: 3},
+		{filename: "docs/a, b.md", contributors: 1},
+	}))
+
+	contents, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, "file,contributors\nmain.go,3\n\"docs/a, b.md\",1\n", string(contents))
+}
@@ -0,0 +1,117 @@
+package codeowners
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/jpmcb/gopherlogs/pkg/colors"
+	"github.com/pmezard/go-difflib/difflib"
+	"golang.org/x/term"
+)
+
+// noColorEnv is the environment variable which, when set, disables colored output.
+// See https://no-color.org
+const noColorEnv = "NO_COLOR"
+
+// writeDryRun writes the generated output to w instead of the output file. When the output
+// file already exists, only a unified diff of what would change is written, which is empty
+// when nothing would change.
+func writeDryRun(w io.Writer, generated []byte, outputPath string, color bool) error {
+	existing, err := os.ReadFile(outputPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		_, err = w.Write(generated)
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("could not read the existing %s file: %w", outputPath, err)
+	}
+
+	err = writeDiff(w, existing, generated, outputPath, outputPath+" (generated)", color)
+	if err != nil {
+		return fmt.Errorf("could not diff the existing %s file: %w", outputPath, err)
+	}
+
+	return nil
+}
+
+// writeDiff writes a unified diff of the existing content against the generated content,
+// which is empty when they're the same
+func writeDiff(w io.Writer, existing []byte, generated []byte, fromFile string, toFile string, color bool) error {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitDiffLines(existing),
+		B:        splitDiffLines(generated),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if color {
+			line = colorDiffLine(line)
+		}
+
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitDiffLines splits the content into lines, keeping their line endings. Unlike
+// difflib.SplitLines, no empty line is added after the final newline.
+func splitDiffLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// colorDiffLine colors a line of a unified diff: removed lines red, added lines
+// green, and hunk headers cyan. The file headers are left uncolored.
+func colorDiffLine(line string) string {
+	var color colors.Attribute
+	switch {
+	case line == "",
+		strings.HasPrefix(line, "---"),
+		strings.HasPrefix(line, "+++"):
+		return line
+	case strings.HasPrefix(line, "@@"):
+		color = colors.FgCyan
+	case strings.HasPrefix(line, "-"):
+		color = colors.FgRed
+	case strings.HasPrefix(line, "+"):
+		color = colors.FgGreen
+	default:
+		return line
+	}
+
+	content, newline := strings.CutSuffix(line, "\n")
+	colored := fmt.Sprintf("%s[%dm%s%s[%dm", colors.Escape, color, content, colors.Escape, colors.Reset)
+	if newline {
+		colored += "\n"
+	}
+
+	return colored
+}
+
+// useColor returns true if output to w should be colored: it's a terminal
+// and color wasn't disabled with --no-color or the NO_COLOR environment variable
+func useColor(w io.Writer, noColor bool) bool {
+	if noColor || os.Getenv(noColorEnv) != "" {
+		return false
+	}
+
+	file, ok := w.(*os.File)
+	//nolint:gosec
+	return ok && term.IsTerminal(int(file.Fd()))
+}
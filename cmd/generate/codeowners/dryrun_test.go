@@ -0,0 +1,82 @@
+package codeowners
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDryRun(t *testing.T) {
+	generated := []byte("main.go @brandonroberts\nREADME.md @jpmcb\n")
+
+	t.Run("new file", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "CODEOWNERS")
+
+		var buf bytes.Buffer
+		require.NoError(t, writeDryRun(&buf, generated, outputPath, true))
+		assert.Equal(t, string(generated), buf.String())
+
+		_, err := os.Stat(outputPath)
+		assert.True(t, os.IsNotExist(err), "a dry run should not write the file")
+	})
+
+	t.Run("existing file", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "CODEOWNERS")
+		require.NoError(t, os.WriteFile(outputPath, []byte("main.go @jpmcb\nREADME.md @jpmcb\n"), 0600))
+
+		var buf bytes.Buffer
+		require.NoError(t, writeDryRun(&buf, generated, outputPath, false))
+		assert.Equal(t, "--- "+outputPath+"\n"+
+			"+++ "+outputPath+" (generated)\n"+
+			"@@ -1,2 +1,2 @@\n"+
+			"-main.go @jpmcb\n"+
+			"+main.go @brandonroberts\n"+
+			" README.md @jpmcb\n", buf.String())
+
+		existing, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		assert.Equal(t, "main.go @jpmcb\nREADME.md @jpmcb\n", string(existing))
+	})
+
+	t.Run("unchanged file", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "CODEOWNERS")
+		require.NoError(t, os.WriteFile(outputPath, generated, 0600))
+
+		var buf bytes.Buffer
+		require.NoError(t, writeDryRun(&buf, generated, outputPath, true))
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestColorDiffLine(t *testing.T) {
+	assert.Equal(t, "\x1b[32m+main.go @jpmcb\x1b[0m\n", colorDiffLine("+main.go @jpmcb\n"))
+	assert.Equal(t, "\x1b[31m-main.go @jpmcb\x1b[0m\n", colorDiffLine("-main.go @jpmcb\n"))
+	assert.Equal(t, "\x1b[36m@@ -1 +1 @@\x1b[0m\n", colorDiffLine("@@ -1 +1 @@\n"))
+	assert.Equal(t, "--- CODEOWNERS\n", colorDiffLine("--- CODEOWNERS\n"))
+	assert.Equal(t, " README.md @jpmcb\n", colorDiffLine(" README.md @jpmcb\n"))
+}
+
+func TestUseColor(t *testing.T) {
+	// buffers and files which aren't terminals are never colored
+	assert.False(t, useColor(&bytes.Buffer{}, false))
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	require.NoError(t, err)
+	defer file.Close()
+	assert.False(t, useColor(file, false))
+	assert.False(t, useColor(os.Stdout, true))
+}
+
+func TestWriteHeaderOmitsPreviewFlags(t *testing.T) {
+	cmd := NewCodeownersCommand()
+	require.NoError(t, cmd.ParseFlags([]string{"--dry-run", "--no-color", "--max-owners", "2"}))
+
+	var buf bytes.Buffer
+	require.NoError(t, writeHeader(&buf, "CODEOWNERS", &Options{path: "/repo"}, cmd))
+	assert.Contains(t, buf.String(), "# $ pizza generate codeowners repo/ --max-owners 2\n")
+	assert.NotContains(t, buf.String(), "dry-run")
+}
@@ -0,0 +1,42 @@
+package codeowners
+
+import (
+	"bytes"
+	"io"
+)
+
+// utf8BOM is the UTF-8 byte order mark some Windows tools expect at the start of a file
+const utf8BOM = "\xef\xbb\xbf"
+
+// validUTF8Writer replaces the invalid UTF-8 written to it, e.g., from a git name in
+// another encoding, with the Unicode replacement character so the output is always
+// valid UTF-8. Each write is expected to contain whole lines.
+type validUTF8Writer struct {
+	w io.Writer
+}
+
+func (vw *validUTF8Writer) Write(p []byte) (int, error) {
+	_, err := vw.w.Write(bytes.ToValidUTF8(p, []byte("\uFFFD")))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// newOutputWriter wraps the writer of an output file to write valid UTF-8 with the
+// given line ending. With bom, the UTF-8 byte order mark is written first.
+func newOutputWriter(w io.Writer, lineEnding string, bom bool) (io.Writer, error) {
+	if bom {
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return nil, err
+		}
+	}
+
+	return &validUTF8Writer{w: newLineEndingWriter(w, lineEnding)}, nil
+}
+
+// trimBOM removes the UTF-8 byte order mark from the start of an existing file's contents
+func trimBOM(contents []byte) []byte {
+	return bytes.TrimPrefix(contents, []byte(utf8BOM))
+}
@@ -0,0 +1,66 @@
+package codeowners
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBOM(t *testing.T) {
+	generate := func(t *testing.T, outputPath string, bom bool) string {
+		t.Helper()
+
+		fileStats, opts := newGranularityTestData()
+		opts.granularity = granularityFile
+		opts.lineEnding = lineEndingCRLF
+		opts.bom = bom
+
+		require.NoError(t, generateOutputFile(fileStats, outputPath, opts, &cobra.Command{}))
+
+		contents, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+
+		return string(contents)
+	}
+
+	t.Run("absent by default", func(t *testing.T) {
+		contents := generate(t, filepath.Join(t.TempDir(), "CODEOWNERS"), false)
+		assert.False(t, strings.HasPrefix(contents, utf8BOM))
+		assert.True(t, strings.HasPrefix(contents, "#"))
+	})
+
+	t.Run("present with --bom", func(t *testing.T) {
+		contents := generate(t, filepath.Join(t.TempDir(), "CODEOWNERS"), true)
+		assert.True(t, strings.HasPrefix(contents, utf8BOM+"#"))
+		assert.Equal(t, 1, strings.Count(contents, utf8BOM))
+		assert.Contains(t, contents, "main.go @brandonroberts\r\n")
+	})
+
+	t.Run("existing patterns are read past the BOM", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "CODEOWNERS")
+		require.NoError(t, os.WriteFile(outputPath, []byte(utf8BOM+"main.go @jpmcb\n"), 0600))
+
+		patterns, err := readExistingPatterns(outputPath, &Options{format: formatGitHub})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"main.go"}, patterns)
+	})
+}
+
+func TestValidUTF8Writer(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newOutputWriter(&buf, lineEndingLF, false)
+	require.NoError(t, err)
+
+	n, err := w.Write([]byte("  - Jos\xe9\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 9, n)
+	assert.True(t, utf8.Valid(buf.Bytes()))
+	assert.Equal(t, "  - Jos�\n", buf.String())
+}
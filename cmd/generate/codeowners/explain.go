@@ -0,0 +1,165 @@
+package codeowners
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+// explainOwnership writes a human readable trace of how the owners of a single file
+// are derived: its ranked contributors, the config rules evaluated for it, and its
+// final owners along with the reason each was chosen.
+func explainOwnership(w io.Writer, filename string, fileStats FileStats, opts *Options) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Ownership of %s\n", filename)
+
+	authorStats, tracked := fileStats[filename]
+	if !tracked {
+		fmt.Fprintf(&b, "\nNo commits changed this file in the last %d days, or it was excluded or ignored.\n", opts.previousDays)
+	}
+
+	rankBy := opts.rankBy
+	if rankBy == "" {
+		rankBy = RankByLines
+	}
+
+	// the contributors attributed to the same login are ranked together, like the owners are
+	ranked := mergeLogins(authorStats, opts.ranker, opts.config).ToRankedSlice(opts.ranker)
+	fmt.Fprintf(&b, "\nContributors, ranked by %s:\n", rankBy)
+	if len(ranked) == 0 {
+		fmt.Fprintf(&b, "  (none)\n")
+	}
+	for i, stat := range ranked {
+		fmt.Fprintf(&b, "  %d. %s <%s>: %d lines, %d commits, last commit %s, %s\n",
+			i+1, stat.Name, stat.Email, stat.Lines, stat.Commits, stat.LastCommit.Format(time.DateOnly), explainAttribution(stat, opts.config))
+	}
+
+	fmt.Fprintf(&b, "  confidence: %.0f%% of the lines changed were by the top author\n", attributionConfidence(ranked)*100)
+
+	fmt.Fprintf(&b, "\nConfig rules:\n")
+	fmt.Fprintf(&b, "  max-owners: %d\n", opts.maxOwners)
+	fmt.Fprintf(&b, "  min-owners: %d\n", opts.config.MinOwners)
+	fmt.Fprintf(&b, "  min-commits: %d\n", opts.config.MinCommits)
+	fmt.Fprintf(&b, "  min-confidence: %.0f%%\n", opts.config.MinConfidence*100)
+	if len(opts.config.AllowedAuthors) > 0 {
+		fmt.Fprintf(&b, "  allowed-authors: %s\n", strings.Join(opts.config.AllowedAuthors, ", "))
+	}
+	if len(opts.config.AttributionFallbackTiers) > 0 {
+		fmt.Fprintf(&b, "  attribution-fallback-tiers:\n")
+		for i, tier := range opts.config.AttributionFallbackTiers {
+			fmt.Fprintf(&b, "    %d. @%s\n", i+1, strings.Join(tier, " @"))
+		}
+	}
+	if len(opts.config.AttributionFallback) > 0 {
+		fmt.Fprintf(&b, "  attribution-fallback: @%s\n", strings.Join(opts.config.AttributionFallback, " @"))
+	}
+	explainGlobs(&b, "overrides", filename, opts.config.Overrides)
+	explainGlobs(&b, "priority-owners", filename, opts.config.PriorityOwners)
+
+	owners, err := expandOwnerGroups(getOwners(filename, authorStats, opts), opts.config)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(&b, "\nOwners:\n")
+	if len(owners) == 0 {
+		fmt.Fprintf(&b, "  (none) the rule is written without owners\n")
+	}
+	for _, owner := range owners {
+		fmt.Fprintf(&b, "  @%s: %s\n", owner.GitHubAlias, explainOwnerSource(owner, ranked, opts))
+	}
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// explainAttribution describes the GitHub handle a contributor is attributed to, if any,
+// resolved the same way as the owners: an exact attribution, then a regex attribution,
+// followed through the aliases of renamed logins
+func explainAttribution(stat *CodeownerStat, config *config.Spec) string {
+	handle, ok := exactAttribution(stat.Email, config)
+	if !ok {
+		handle, ok = regexAttribution(stat.Email, config)
+	}
+	if !ok {
+		return "not attributed to a GitHub handle"
+	}
+
+	canonical := config.CanonicalLogin(handle)
+	if canonical != handle {
+		return fmt.Sprintf("attributed to @%s, renamed from @%s", canonical, handle)
+	}
+
+	return "attributed to @" + handle
+}
+
+// explainGlobs writes the globs of a config rule and whether each matches the file.
+// The last matching override wins while the owners of every matching priority rule are combined.
+func explainGlobs(b *strings.Builder, rule string, filename string, globs map[string][]string) {
+	if len(globs) == 0 {
+		return
+	}
+
+	sorted := make([]string, 0, len(globs))
+	for glob := range globs {
+		sorted = append(sorted, glob)
+	}
+	sort.Strings(sorted)
+
+	fmt.Fprintf(b, "  %s:\n", rule)
+	for _, glob := range sorted {
+		matched, err := matchGlob(glob, filename)
+		status := "no match"
+		switch {
+		case err != nil:
+			status = fmt.Sprintf("invalid glob: %s", err)
+		case matched:
+			status = "matched"
+		}
+
+		fmt.Fprintf(b, "    %s @%s: %s\n", glob, strings.Join(globs[glob], " @"), status)
+	}
+}
+
+// explainOwnerSource describes why an owner was chosen
+func explainOwnerSource(owner *CodeownerStat, ranked AuthorStatSlice, opts *Options) string {
+	switch owner.Source {
+	case ownerSourceInline:
+		return "pinned inline in the file"
+	case ownerSourceOverride:
+		return "an override replaces the computed owners"
+	case ownerSourceAncestor:
+		return "too few contributors met the thresholds, so the owners of the nearest ancestor directory are inherited"
+	case ownerSourceFallback:
+		if attributionConfidence(ranked) < opts.config.MinConfidence {
+			return "the confidence is below the min-confidence, so the fallback attribution is used"
+		}
+
+		return "too few contributors met the thresholds, so the fallback attribution is used"
+	}
+
+	// the owners are ranked separately, so they're found by their identity
+	rank := slices.IndexFunc(ranked, func(stat *CodeownerStat) bool {
+		return stat.Name == owner.Name && stat.Email == owner.Email
+	}) + 1
+	reason := fmt.Sprintf("top contributor ranked #%d with %d lines and %d commits", rank, owner.Lines, owner.Commits)
+	if opts.rankBy != "" && opts.rankBy != RankByLines {
+		reason = fmt.Sprintf("top contributor ranked #%d by %s", rank, opts.rankBy)
+	}
+
+	return reason
+}
+
+// explainPath cleans the path of the file to explain into a slash separated path
+// relative to the repository root. Example: "./src//main.go" is "src/main.go"
+func explainPath(filename string) string {
+	return path.Clean(filepath.ToSlash(filename))
+}
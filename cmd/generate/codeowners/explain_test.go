@@ -0,0 +1,135 @@
+package codeowners
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
+)
+
+func TestExplainOwnership(t *testing.T) {
+	t.Run("top contributors", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.rankBy = RankByLines
+		opts.config.PriorityOwners = map[string][]string{"docs/**": {"jpmcb"}}
+
+		var buf bytes.Buffer
+		require.NoError(t, explainOwnership(&buf, "docs/README.md", fileStats, opts))
+
+		output := buf.String()
+		assert.Contains(t, output, "Ownership of docs/README.md")
+		assert.Contains(t, output, "1.  <brandon@opensauced.pizza>: 30 lines, 1 commits")
+		assert.Contains(t, output, "attributed to @brandonroberts")
+		assert.Contains(t, output, "priority-owners:\n    docs/** @jpmcb: matched")
+		assert.Contains(t, output, "Owners:\n  @jpmcb: top contributor ranked #2 with 5 lines and 1 commits\n  @brandonroberts: top contributor ranked #1")
+	})
+
+	t.Run("aliased logins", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.rankBy = RankByLines
+		opts.config.Attributions["old-login"] = []string{"old@opensauced.pizza"}
+		opts.config.RegexAttributions = map[string][]string{"old-login": {"^old-.*@opensauced.pizza$"}}
+		opts.config.Aliases = map[string]string{"old-login": "jpmcb"}
+		fileStats["docs/README.md"]["old"] = &CodeownerStat{Email: "old@opensauced.pizza", Lines: 20, Commits: 1}
+		fileStats["docs/README.md"]["older"] = &CodeownerStat{Email: "old-2@opensauced.pizza", Lines: 10, Commits: 1}
+
+		var buf bytes.Buffer
+		require.NoError(t, explainOwnership(&buf, "docs/README.md", fileStats, opts))
+
+		// jpmcb's emails are ranked together, ahead of brandonroberts
+		output := buf.String()
+		assert.Contains(t, output, "1.  <old@opensauced.pizza>: 35 lines, 3 commits")
+		assert.Contains(t, output, "attributed to @jpmcb, renamed from @old-login")
+		assert.Contains(t, output, "Owners:\n  @jpmcb: top contributor ranked #1 with 35 lines and 3 commits\n  @brandonroberts: top contributor ranked #2")
+	})
+
+	t.Run("override", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.rankBy = RankByLines
+		opts.config.Overrides = map[string][]string{"*.go": {"open-sauced/engineering"}, "docs/**": {"open-sauced/docs"}}
+
+		var buf bytes.Buffer
+		require.NoError(t, explainOwnership(&buf, "main.go", fileStats, opts))
+
+		output := buf.String()
+		assert.Contains(t, output, "    *.go @open-sauced/engineering: matched\n    docs/** @open-sauced/docs: no match")
+		assert.Contains(t, output, "@open-sauced/engineering: an override replaces the computed owners")
+	})
+
+	t.Run("below the min confidence", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.config.MinConfidence = 0.9
+		opts.config.AttributionFallback = []string{"open-sauced/maintainers"}
+
+		var buf bytes.Buffer
+		require.NoError(t, explainOwnership(&buf, "docs/README.md", fileStats, opts))
+
+		output := buf.String()
+		assert.Contains(t, output, "confidence: 86% of the lines changed were by the top author")
+		assert.Contains(t, output, "min-confidence: 90%")
+		assert.Contains(t, output, "@open-sauced/maintainers: the confidence is below the min-confidence")
+	})
+
+	t.Run("untracked file", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.rankBy = RankByLines
+		opts.previousDays = 30
+		opts.config.AttributionFallback = []string{"open-sauced/maintainers"}
+
+		var buf bytes.Buffer
+		require.NoError(t, explainOwnership(&buf, "missing.go", fileStats, opts))
+
+		output := buf.String()
+		assert.Contains(t, output, "No commits changed this file in the last 30 days")
+		assert.Contains(t, output, "Contributors, ranked by lines:\n  (none)")
+		assert.Contains(t, output, "@open-sauced/maintainers: too few contributors met the thresholds")
+	})
+}
+
+func TestExplainPath(t *testing.T) {
+	assert.Equal(t, "src/main.go", explainPath("./src//main.go"))
+	assert.Equal(t, "main.go", explainPath("main.go"))
+}
+
+func TestRunExplain(t *testing.T) {
+	dir, _ := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "a\n", "util.go": "b\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"main.go": "a\nc\nd\n"}},
+	)
+
+	opts := &Options{
+		path:         dir,
+		outputPath:   t.TempDir(),
+		format:       formatGitHub,
+		maxOwners:    3,
+		previousDays: 30,
+		explain:      "main.go",
+		telemetry:    utils.NewPosthogCliClient(false),
+		config: &config.Spec{
+			Attributions: map[string][]string{"jpmcb": {"john@opensauced.pizza"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&buf)
+	require.NoError(t, run(opts, cmd))
+
+	output := buf.String()
+	assert.Contains(t, output, "1. John <john@opensauced.pizza>: 2 lines, 1 commits")
+	assert.Contains(t, output, "2. Brandon <brandon@opensauced.pizza>: 1 lines, 1 commits")
+	assert.Contains(t, output, "not attributed to a GitHub handle")
+	assert.Contains(t, output, "@jpmcb: top contributor ranked #1")
+	assert.NotContains(t, output, "util.go")
+
+	_, err := os.Stat(filepath.Join(opts.outputPath, "CODEOWNERS"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,81 @@
+package codeowners
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// extensionFilter scopes analysis to files by their extension. An excluded extension
+// takes precedence over an included one. An empty filter matches every file.
+type extensionFilter struct {
+	include map[string]struct{}
+	exclude map[string]struct{}
+}
+
+func newExtensionFilter(include, exclude []string) (*extensionFilter, error) {
+	ef := &extensionFilter{}
+
+	var err error
+	ef.include, err = parseExtensions(include)
+	if err != nil {
+		return nil, fmt.Errorf("--include-ext: %w", err)
+	}
+
+	ef.exclude, err = parseExtensions(exclude)
+	if err != nil {
+		return nil, fmt.Errorf("--exclude-ext: %w", err)
+	}
+
+	return ef, nil
+}
+
+// parseExtensions parses a set of extensions, with or without their leading ".",
+// into the lowercase form returned by extensionOf. Example: "GO" and ".go" are ".go"
+func parseExtensions(extensions []string) (map[string]struct{}, error) {
+	parsed := make(map[string]struct{}, len(extensions))
+	for _, extension := range extensions {
+		trimmed := strings.TrimPrefix(extension, ".")
+		if trimmed == "" || strings.ContainsAny(trimmed, "/.*") {
+			return nil, fmt.Errorf("invalid extension %q, must be a single extension such as .go", extension)
+		}
+
+		parsed["."+strings.ToLower(trimmed)] = struct{}{}
+	}
+
+	return parsed, nil
+}
+
+// extensionOf gets the lowercase extension of a file. Files without an extension,
+// including dotfiles such as ".gitignore", have none.
+func extensionOf(filename string) string {
+	base := path.Base(filename)
+	if strings.LastIndex(base, ".") <= 0 {
+		return ""
+	}
+
+	return strings.ToLower(path.Ext(base))
+}
+
+func (ef *extensionFilter) isEmpty() bool {
+	return ef == nil || (len(ef.include) == 0 && len(ef.exclude) == 0)
+}
+
+func (ef *extensionFilter) matches(filename string) bool {
+	if ef.isEmpty() {
+		return true
+	}
+
+	extension := extensionOf(filename)
+
+	if _, ok := ef.exclude[extension]; ok {
+		return false
+	}
+
+	if len(ef.include) == 0 {
+		return true
+	}
+
+	_, ok := ef.include[extension]
+	return ok
+}
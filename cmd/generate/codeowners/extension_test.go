@@ -0,0 +1,45 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtensionFilter(t *testing.T) {
+	var tests = []struct {
+		name     string
+		include  []string
+		exclude  []string
+		filename string
+		expected bool
+	}{
+		{"empty", nil, nil, "README", true},
+		{"included", []string{".go", "ts"}, nil, "src/main.go", true},
+		{"included without a dot", []string{".go", "ts"}, nil, "web/app.ts", true},
+		{"not included", []string{".go"}, nil, "docs/guide.md", false},
+		{"included case insensitively", []string{".GO"}, nil, "main.Go", true},
+		{"no extension is not included", []string{".go"}, nil, "Makefile", false},
+		{"dotfile is not an extension", []string{".gitignore"}, nil, ".gitignore", false},
+		{"excluded", nil, []string{".md"}, "docs/guide.md", false},
+		{"not excluded", nil, []string{".md"}, "main.go", true},
+		{"exclude takes precedence", []string{".go"}, []string{".go"}, "main.go", false},
+		{"only the last extension", []string{".go"}, nil, "api.pb.go", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ef, err := newExtensionFilter(tt.include, tt.exclude)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ef.matches(tt.filename))
+		})
+	}
+}
+
+func TestExtensionFilterInvalid(t *testing.T) {
+	for _, extension := range []string{"", ".", "*.go", "pb.go", "src/.go"} {
+		_, err := newExtensionFilter([]string{extension}, nil)
+		require.Error(t, err, extension)
+	}
+}
@@ -0,0 +1,58 @@
+package codeowners
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// weightBySize copies the author stats with each author's lines replaced by their share of
+// the file's changed lines times its size, so a file's contribution to its directory grows
+// with its number of lines rather than how much it churned. Example: an author who made half
+// the changes to a 5000 line file is credited with 2500 lines. Files no longer in the tree
+// have no size, so they contribute no lines.
+func (as AuthorStats) weightBySize(size int) AuthorStats {
+	total := 0
+	for _, stat := range as {
+		total += stat.Lines
+	}
+
+	weighted := make(AuthorStats, len(as))
+	for author, stat := range as {
+		copied := *stat
+		copied.Lines = 0
+		if total > 0 {
+			copied.Lines = int(math.Round(float64(stat.Lines) / float64(total) * float64(size)))
+		}
+
+		weighted[author] = &copied
+	}
+
+	return weighted
+}
+
+// loadFileSizes counts the lines of each file for --weight-by-size, reading them from the
+// repository's HEAD tree, or the tree of the --at commit. Files no longer in the tree are left out.
+func loadFileSizes(repo *git.Repository, fs FileStats, opts *Options) error {
+	tree, err := analyzedTree(repo, opts)
+	if err != nil {
+		return err
+	}
+
+	for filename := range fs {
+		file, err := tree.File(filename)
+		if err != nil {
+			continue
+		}
+
+		lines, err := file.Lines()
+		if err != nil {
+			return fmt.Errorf("could not read %s: %w", filename, err)
+		}
+
+		opts.fileSizes[filename] = len(lines)
+	}
+
+	return nil
+}
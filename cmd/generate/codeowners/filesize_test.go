@@ -0,0 +1,66 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeightBySize(t *testing.T) {
+	authorStats := AuthorStats{
+		"brandon": {Email: "brandon@opensauced.pizza", Lines: 30, Commits: 3},
+		"john":    {Email: "john@opensauced.pizza", Lines: 10, Commits: 1},
+	}
+
+	weighted := authorStats.weightBySize(100)
+	assert.Equal(t, 75, weighted["brandon"].Lines)
+	assert.Equal(t, 25, weighted["john"].Lines)
+
+	// commits aren't weighted and the stats aren't modified
+	assert.Equal(t, 3, weighted["brandon"].Commits)
+	assert.Equal(t, 30, authorStats["brandon"].Lines)
+
+	// files no longer in the tree contribute no lines
+	assert.Equal(t, 0, authorStats.weightBySize(0)["brandon"].Lines)
+}
+
+func TestAggregateDirectoriesWeightedBySize(t *testing.T) {
+	_, opts := newGranularityTestData()
+
+	// Brandon keeps tweaking a small config, John wrote most of a large module
+	fileStats := FileStats{
+		"pkg/config.yaml": {
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 200, Commits: 20},
+		},
+		"pkg/module.go": {
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 10, Commits: 1},
+			"john":    {Email: "john@opensauced.pizza", Lines: 90, Commits: 3},
+		},
+	}
+
+	unweighted := fileStats.aggregateDirectories(opts)
+	ranked := unweighted["pkg/"].ToRankedSlice(opts.ranker)
+	assert.Equal(t, "brandon@opensauced.pizza", ranked[0].Email)
+
+	opts.fileSizes = map[string]int{"pkg/config.yaml": 10, "pkg/module.go": 5000}
+	weighted := fileStats.aggregateDirectories(opts)
+	assert.Equal(t, 510, weighted["pkg/"]["brandon"].Lines)
+	assert.Equal(t, 4500, weighted["pkg/"]["john"].Lines)
+
+	ranked = weighted["pkg/"].ToRankedSlice(opts.ranker)
+	assert.Equal(t, "john@opensauced.pizza", ranked[0].Email)
+}
+
+func TestLoadFileSizes(t *testing.T) {
+	_, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "a\nb\nc\n", "old.go": "a\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"old.go": ""}},
+	)
+
+	fileStats := FileStats{"main.go": {}, "old.go": {}}
+	opts := &Options{fileSizes: make(map[string]int)}
+
+	require.NoError(t, loadFileSizes(repo, fileStats, opts))
+	assert.Equal(t, map[string]int{"main.go": 3}, opts.fileSizes)
+}
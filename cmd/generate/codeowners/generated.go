@@ -0,0 +1,67 @@
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/jpmcb/gopherlogs/pkg/colors"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+)
+
+const (
+	// defaultGeneratedRegex matches Go's convention for marking generated files.
+	// See https://go.dev/s/generatedcode
+	defaultGeneratedRegex = `^// Code generated .* DO NOT EDIT\.$`
+
+	// generatedMaxLines is the number of lines at the top of a file matched against the regex
+	generatedMaxLines = 20
+)
+
+// isGenerated returns true if any of the lines at the top of the file match the regex
+func isGenerated(r io.Reader, re *regexp.Regexp) bool {
+	scanner := bufio.NewScanner(r)
+
+	for lines := 0; lines < generatedMaxLines && scanner.Scan(); lines++ {
+		if re.MatchString(scanner.Text()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeGenerated removes the files detected as generated with --exclude-generated,
+// reading them from the repository's HEAD tree, or the tree of the --at commit. Files no
+// longer in the tree can't be read, so they're kept.
+func (fs FileStats) removeGenerated(repo *git.Repository, opts *Options) error {
+	tree, err := analyzedTree(repo, opts)
+	if err != nil {
+		return err
+	}
+
+	for filename := range fs {
+		file, err := tree.File(filename)
+		if err != nil {
+			continue
+		}
+
+		reader, err := file.Reader()
+		if err != nil {
+			return fmt.Errorf("could not read %s: %w", filename, err)
+		}
+
+		generated := isGenerated(reader, opts.generatedRegex)
+		reader.Close()
+
+		if generated {
+			opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Excluding generated file: %s\n", filename)
+			delete(fs, filename)
+		}
+	}
+
+	return nil
+}
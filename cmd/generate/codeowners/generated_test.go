@@ -0,0 +1,106 @@
+package codeowners
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
+)
+
+func TestIsGenerated(t *testing.T) {
+	re := regexp.MustCompile(defaultGeneratedRegex)
+
+	tests := []struct {
+		name     string
+		contents string
+		want     bool
+	}{
+		{"go generated", "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage pb\n", true},
+		{"after a license", "// Copyright 2024\n// License: MIT\n\n// Code generated by mockgen. DO NOT EDIT.\npackage mocks\n", true},
+		{"mentioned in code", "package main\n\n// the header is \"// Code generated ... DO NOT EDIT.\"\n", false},
+		{"too far down", strings.Repeat("\n", generatedMaxLines) + "// Code generated by hand. DO NOT EDIT.\n", false},
+		{"handwritten", "package main\n\nfunc main() {}\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isGenerated(strings.NewReader(tt.contents), re))
+		})
+	}
+}
+
+func TestGenerateExcludingGenerated(t *testing.T) {
+	dir, _ := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{
+			"main.go":         "package main\n",
+			"pb/api.pb.go":    "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage pb\n",
+			"web/schema.ts":   "// @generated by graphql-codegen\nexport {}\n",
+			"web/handmade.ts": "export {}\n",
+		}},
+	)
+
+	configPath := filepath.Join(t.TempDir(), ".sauced.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("attribution:\n  brandonroberts: [brandon@opensauced.pizza]\n"), 0600))
+
+	generate := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+
+		outputPath := t.TempDir()
+		cmd := NewCodeownersCommand()
+		cmd.PersistentFlags().Bool(constants.FlagNameTelemetry, true, "")
+		cmd.PersistentFlags().String("config", "", "")
+		cmd.SetArgs(append([]string{dir, "--config", configPath, "--output-path", outputPath}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+
+		if err := cmd.Execute(); err != nil {
+			return "", err
+		}
+
+		contents, err := os.ReadFile(filepath.Join(outputPath, "CODEOWNERS"))
+		require.NoError(t, err)
+		return string(contents), nil
+	}
+
+	t.Run("kept by default", func(t *testing.T) {
+		contents, err := generate(t)
+		require.NoError(t, err)
+		assert.Contains(t, contents, "pb/api.pb.go @brandonroberts")
+	})
+
+	t.Run("default regex", func(t *testing.T) {
+		contents, err := generate(t, "--exclude-generated")
+		require.NoError(t, err)
+		assert.Contains(t, contents, "main.go @brandonroberts")
+		assert.NotContains(t, contents, "pb/api.pb.go @")
+		assert.Contains(t, contents, "web/schema.ts @brandonroberts")
+	})
+
+	t.Run("custom regex", func(t *testing.T) {
+		contents, err := generate(t, "--exclude-generated", "--generated-regex", "@generated")
+		require.NoError(t, err)
+		assert.Contains(t, contents, "pb/api.pb.go @brandonroberts")
+		assert.NotContains(t, contents, "web/schema.ts @")
+		assert.Contains(t, contents, "web/handmade.ts @brandonroberts")
+	})
+
+	t.Run("invalid regex", func(t *testing.T) {
+		_, err := generate(t, "--exclude-generated", "--generated-regex", "(")
+		require.Error(t, err)
+		assert.Equal(t, constants.ExitCodeConfig, utils.ExitCode(err))
+	})
+
+	t.Run("regex without --exclude-generated", func(t *testing.T) {
+		_, err := generate(t, "--generated-regex", "@generated")
+		require.ErrorContains(t, err, "--generated-regex can only be used with --exclude-generated")
+		assert.Equal(t, constants.ExitCodeConfig, utils.ExitCode(err))
+	})
+}
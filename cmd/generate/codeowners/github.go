@@ -0,0 +1,133 @@
+package codeowners
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// githubAPIEndpoint is the GitHub REST API endpoint owners are looked up with.
+// It's a variable so tests of the whole command can use a fake API.
+var githubAPIEndpoint = "https://api.github.com"
+
+// githubAPITimeout is the timeout of each GitHub API request
+const githubAPITimeout = 10 * time.Second
+
+// githubTokenEnvs are the environment variables, in order, the GitHub token is read from
+var githubTokenEnvs = []string{"GITHUB_TOKEN", "GH_TOKEN"}
+
+// maxRateLimitWait is the longest a rate limited request waits for the limit to reset
+// before giving up, so a lookup never hangs for the hour a limit may last
+const maxRateLimitWait = time.Minute
+
+// errRateLimited is returned when GitHub's rate limit doesn't reset within maxRateLimitWait
+var errRateLimited = errors.New("GitHub API rate limit exceeded")
+
+// githubToken gets the GitHub token from the environment, or "" when none is set
+func githubToken() string {
+	for _, env := range githubTokenEnvs {
+		if token := os.Getenv(env); token != "" {
+			return token
+		}
+	}
+
+	return ""
+}
+
+// githubClient makes requests to the GitHub REST API, authenticated with the token when set
+type githubClient struct {
+	httpClient *http.Client
+	endpoint   string
+	token      string
+
+	// sleep waits for a rate limit to reset, replaced in tests
+	sleep func(time.Duration)
+}
+
+func newGitHubClient(httpClient *http.Client, endpoint string, token string) *githubClient {
+	return &githubClient{
+		httpClient: httpClient,
+		endpoint:   endpoint,
+		token:      token,
+		sleep:      time.Sleep,
+	}
+}
+
+// get requests the API path, waiting for the rate limit to reset once if it's exceeded.
+// It returns true when the path exists, decoding the JSON response into v when it's not nil,
+// and false when it's not found.
+func (c *githubClient) get(apiPath string, v any) (bool, error) {
+	for retried := false; ; retried = true {
+		req, err := http.NewRequest(http.MethodGet, c.endpoint+apiPath, nil)
+		if err != nil {
+			return false, fmt.Errorf("error building request: %w", err)
+		}
+
+		req.Header.Set("Accept", "application/vnd.github+json")
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return false, fmt.Errorf("error making request: %w", err)
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			defer resp.Body.Close()
+			if v != nil {
+				if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+					return false, fmt.Errorf("error decoding response: %w", err)
+				}
+			}
+
+			return true, nil
+		case resp.StatusCode == http.StatusNotFound:
+			resp.Body.Close()
+			return false, nil
+		case isRateLimited(resp):
+			resp.Body.Close()
+			wait := rateLimitWait(resp, time.Now())
+			if retried || wait > maxRateLimitWait {
+				return false, fmt.Errorf("%w, resets in %s", errRateLimited, wait.Round(time.Second))
+			}
+
+			c.sleep(wait)
+		default:
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			return false, fmt.Errorf("API request failed with status code: %d", resp.StatusCode)
+		}
+	}
+}
+
+// isRateLimited returns true if the response is GitHub's primary or secondary rate limit
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+
+	return resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// rateLimitWait gets how long to wait before retrying a rate limited request
+// from the Retry-After or X-RateLimit-Reset headers
+func rateLimitWait(resp *http.Response, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		if wait := time.Unix(reset, 0).Sub(now); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
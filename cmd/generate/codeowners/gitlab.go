@@ -0,0 +1,65 @@
+package codeowners
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+// gitLabSectionIndex gets the index of the first GitLab section with a path glob matching
+// the rule, or -1 if the rule isn't in a section. Directory rules keep their trailing slash,
+// so "docs/**" matches the rule "docs/".
+func gitLabSectionIndex(rule string, sections []config.GitLabSection) int {
+	for i, section := range sections {
+		for _, glob := range section.Paths {
+			if matched, err := matchGlob(glob, rule); err == nil && matched {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// groupGitLabSections groups the rules by their GitLab section, keeping the order of the
+// rules within each section. Rules without a section come first: rules written after a
+// section header would otherwise belong to that section.
+func groupGitLabSections(rules []string, sections []config.GitLabSection) []string {
+	grouped := make([]string, len(rules))
+	copy(grouped, rules)
+
+	sort.SliceStable(grouped, func(i, j int) bool {
+		return gitLabSectionIndex(grouped[i], sections) < gitLabSectionIndex(grouped[j], sections)
+	})
+
+	return grouped
+}
+
+// gitLabSectionHeader gets the header of a GitLab section, including its required
+// approvals when set. Example: "[Docs][2]"
+func gitLabSectionHeader(section config.GitLabSection) string {
+	header := "[" + section.Name + "]"
+	if section.RequiredApprovals > 0 {
+		header += fmt.Sprintf("[%d]", section.RequiredApprovals)
+	}
+
+	return header
+}
+
+// writeGitLabSectionHeader writes the header of the rule's GitLab section when the rule
+// starts a new section. The index of the section is returned to compare the next rule to.
+func writeGitLabSectionHeader(file io.Writer, rule string, previous int, sections []config.GitLabSection, outputPath string) (int, error) {
+	current := gitLabSectionIndex(rule, sections)
+	if current == previous || current < 0 {
+		return current, nil
+	}
+
+	_, err := fmt.Fprintf(file, "\n%s\n", gitLabSectionHeader(sections[current]))
+	if err != nil {
+		return current, fmt.Errorf("error writing to %s file: %w", outputPath, err)
+	}
+
+	return current, nil
+}
@@ -0,0 +1,64 @@
+package codeowners
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+func TestGitLabSectionHeader(t *testing.T) {
+	assert.Equal(t, "[Docs]", gitLabSectionHeader(config.GitLabSection{Name: "Docs"}))
+	assert.Equal(t, "[Docs][2]", gitLabSectionHeader(config.GitLabSection{Name: "Docs", RequiredApprovals: 2}))
+}
+
+func TestWriteGitLabSections(t *testing.T) {
+	sections := []config.GitLabSection{
+		{Name: "API docs", Paths: []string{"docs/api/**"}, RequiredApprovals: 2},
+		{Name: "Docs", Paths: []string{"docs/**"}},
+	}
+
+	t.Run("files", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.granularity = granularityFile
+		opts.format = formatGitLab
+		opts.config.GitLabSections = sections
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+		assert.Equal(t, "/main.go @brandonroberts\n"+
+			"\n[API docs][2]\n"+
+			"/docs/api/index.md @jpmcb\n"+
+			"\n[Docs]\n"+
+			"/docs/README.md @brandonroberts @jpmcb\n"+
+			"/docs/guide.md @jpmcb\n", buf.String())
+	})
+
+	t.Run("directories", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.format = formatGitLab
+		opts.config.GitLabSections = sections
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+		assert.Equal(t, "* @brandonroberts\n"+
+			"\n[API docs][2]\n"+
+			"/docs/api/ @jpmcb\n"+
+			"\n[Docs]\n"+
+			"/docs/ @brandonroberts @jpmcb\n", buf.String())
+	})
+
+	t.Run("without sections", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.granularity = granularityFile
+		opts.format = formatGitLab
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+		assert.NotContains(t, buf.String(), "[")
+		assert.Contains(t, buf.String(), "/main.go @brandonroberts\n")
+	})
+}
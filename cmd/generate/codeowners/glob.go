@@ -0,0 +1,191 @@
+package codeowners
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// globCache caches compiled glob patterns since the same config patterns
+// are matched against every file in the repository
+var globCache sync.Map
+
+// hasGlobMeta returns true if the given pattern contains any glob
+// meta characters and should be treated as a pattern instead of a literal path.
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// compileGlob converts a slash separated glob pattern into a regular expression.
+// Along with the usual "*", "?", and "[...]" matchers, a "**" path segment
+// matches zero or more directories. Example: "src/**/*.go" matches
+// "src/main.go" and "src/pkg/util/util.go"
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				// consume the "**" and an optional trailing slash
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			class, end, err := compileGlobClass(pattern, i)
+			if err != nil {
+				return nil, err
+			}
+
+			sb.WriteString(class)
+			i = end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not compile glob %s: %w", pattern, err)
+	}
+
+	return re, nil
+}
+
+// compileGlobClass converts the "[...]" character class starting at the index of its "["
+// into a regular expression class, and gets the index of its closing "]". A leading "!" or
+// "^" negates the class, which never matches a "/". A "]" right after the opening bracket
+// and any character escaped with a backslash are literals. Every other character besides
+// the "-" of a range is escaped, so "[\]" or "[a^]" can't change the meaning of the regex.
+func compileGlobClass(pattern string, start int) (string, int, error) {
+	var sb strings.Builder
+	sb.WriteString("[")
+
+	i := start + 1
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		sb.WriteString("^/")
+		i++
+	}
+
+	for first := true; i < len(pattern); i, first = i+1, false {
+		c := pattern[i]
+
+		switch {
+		case c == ']' && !first:
+			sb.WriteString("]")
+			return sb.String(), i, nil
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			sb.WriteString(escapeClassChar(pattern[i]))
+		case c == '-' && !first && i+1 < len(pattern) && pattern[i+1] != ']':
+			sb.WriteString("-")
+		default:
+			sb.WriteString(escapeClassChar(c))
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated character class in glob: %s", pattern)
+}
+
+// escapeClassChar escapes an ASCII punctuation character for use within a regex class.
+// Letters, digits, and the bytes of multi-byte characters are written as is.
+func escapeClassChar(c byte) string {
+	if c < 0x80 && !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+		return "\\" + string(c)
+	}
+
+	return string(c)
+}
+
+// matchGlob reports whether the slash separated name matches the given glob pattern
+func matchGlob(pattern, name string) (bool, error) {
+	if cached, ok := globCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp).MatchString(name), nil
+	}
+
+	re, err := compileGlob(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	globCache.Store(pattern, re)
+	return re.MatchString(name), nil
+}
+
+// pathMatcher matches filenames against a set of literal paths and glob patterns.
+// An empty matcher matches every filename.
+type pathMatcher struct {
+	literals map[string]struct{}
+	globs    []*regexp.Regexp
+}
+
+func newPathMatcher(patterns []string) (*pathMatcher, error) {
+	pm := &pathMatcher{
+		literals: make(map[string]struct{}),
+	}
+
+	for _, pattern := range patterns {
+		if !hasGlobMeta(pattern) {
+			pm.literals[pattern] = struct{}{}
+			continue
+		}
+
+		re, err := compileGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		pm.globs = append(pm.globs, re)
+	}
+
+	return pm, nil
+}
+
+func (pm *pathMatcher) isEmpty() bool {
+	return pm == nil || (len(pm.literals) == 0 && len(pm.globs) == 0)
+}
+
+func (pm *pathMatcher) matches(name string) bool {
+	if pm.isEmpty() {
+		return true
+	}
+
+	if _, ok := pm.literals[name]; ok {
+		return true
+	}
+
+	for _, re := range pm.globs {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newPrefixMatcher builds a matcher for the given paths and globs which also
+// matches everything beneath them. Example: "vendor" matches "vendor/pkg/file.go"
+func newPrefixMatcher(patterns []string) (*pathMatcher, error) {
+	expanded := make([]string, 0, len(patterns)*2)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(pattern, "/")
+		expanded = append(expanded, pattern, pattern+"/**")
+	}
+
+	return newPathMatcher(expanded)
+}
@@ -0,0 +1,76 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchGlob(t *testing.T) {
+	var tests = []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"src/**/*.go", "src/main.go", true},
+		{"src/**/*.go", "src/pkg/util/util.go", true},
+		{"src/**/*.go", "src/pkg/util/util.ts", false},
+		{"src/**/*.go", "other/main.go", false},
+		{"*.go", "main.go", true},
+		{"*.go", "pkg/main.go", false},
+		{"**/*.go", "pkg/main.go", true},
+		{"docs/**", "docs/a/b/c.md", true},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"file[0-9].txt", "file7.txt", true},
+		{"file[!0-9].txt", "file7.txt", false},
+		{"path/(home).go", "path/(home).go", true},
+		{"file[^0-9].txt", "fileA.txt", true},
+		{"file[^0-9].txt", "file7.txt", false},
+		{"a[!b]c", "a/c", false},
+		{`file[\]].txt`, "file].txt", true},
+		{`file[\\].txt`, `file\.txt`, true},
+		{"file[]].txt", "file].txt", true},
+		{"file[a^].txt", "file^.txt", true},
+		{"file[a^].txt", "fileb.txt", false},
+		{"file[[].txt", "file[.txt", true},
+		{"file[a-].txt", "file-.txt", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			matched, err := matchGlob(tt.pattern, tt.name)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+}
+
+func TestCompileGlobInvalidClass(t *testing.T) {
+	for _, pattern := range []string{"file[", "file[]", `file[\]`, "file[^"} {
+		_, err := compileGlob(pattern)
+		assert.ErrorContains(t, err, "unterminated character class", pattern)
+	}
+}
+
+func TestPathMatcher(t *testing.T) {
+	t.Run("empty matcher matches everything", func(t *testing.T) {
+		pm, err := newPathMatcher(nil)
+		require.NoError(t, err)
+		assert.True(t, pm.matches("any/file.go"))
+	})
+
+	t.Run("literals and globs", func(t *testing.T) {
+		pm, err := newPathMatcher([]string{"README.md", "src/**/*.go"})
+		require.NoError(t, err)
+		assert.True(t, pm.matches("README.md"))
+		assert.True(t, pm.matches("src/a/b.go"))
+		assert.False(t, pm.matches("docs/README.md"))
+	})
+
+	t.Run("invalid glob", func(t *testing.T) {
+		_, err := newPathMatcher([]string{"src/[abc"})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,142 @@
+package codeowners
+
+import (
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// The supported granularities of the generated rules
+const (
+	granularityFile      = "file"
+	granularityDirectory = "directory"
+)
+
+var granularities = []string{granularityFile, granularityDirectory}
+
+// rootDirectoryRule is the directory granularity rule for files in the repository root.
+// It matches every file, so it's always the first rule.
+const rootDirectoryRule = "*"
+
+// isDirectoryRule returns true if the rule matches a directory and everything beneath it
+func isDirectoryRule(rule string) bool {
+	return rule == rootDirectoryRule || strings.HasSuffix(rule, "/")
+}
+
+// directoryRule gets the directory granularity rule of a file
+func directoryRule(filename string) string {
+	dir := path.Dir(filename)
+	if dir == "." {
+		return rootDirectoryRule
+	}
+
+	return dir + "/"
+}
+
+// pathLocal returns true if every changed file is beneath the directory rule of the file,
+// i.e., the changes are local to the path the file's contributions are credited to
+func pathLocal(filename string, changed []string) bool {
+	rule := directoryRule(filename)
+	if rule == rootDirectoryRule {
+		return true
+	}
+
+	for _, name := range changed {
+		if !strings.HasPrefix(name, rule) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// aggregateDirectories merges the author stats of each file into a rule for its directory.
+// With --weight-by-size, each file's lines are weighted by its size first (see weightBySize).
+// Files whose owners wouldn't be resolved by their directory rule keep their own file rule:
+// files with owners pinned inline and files matched by an override which doesn't match
+// their directory. The file rules are sorted after their directory rule (see sortRules)
+// so the last matching rule on GitHub is the file rule. With --skip-empty-directories,
+// directories with none of their files in the tracked files are skipped, though the history
+// of deleted files still counts toward the owners of the directories which aren't empty.
+func (fs FileStats) aggregateDirectories(opts *Options) FileStats {
+	aggregated := make(FileStats)
+
+	// the directory rules with a file in the tracked files
+	nonEmpty := make(map[string]bool)
+
+	for filename, authorStats := range fs {
+		rule := directoryRule(filename)
+
+		if keepFileRule(filename, rule, opts) {
+			aggregated[filename] = authorStats
+			continue
+		}
+
+		if _, ok := aggregated[rule]; !ok {
+			aggregated[rule] = make(AuthorStats)
+		}
+
+		if _, ok := opts.trackedFiles[filename]; ok {
+			nonEmpty[rule] = true
+		}
+
+		if opts.fileSizes != nil {
+			authorStats = authorStats.weightBySize(opts.fileSizes[filename])
+		}
+		aggregated[rule].merge(authorStats)
+	}
+
+	if opts.skipEmptyDirectories {
+		for rule := range aggregated {
+			if isDirectoryRule(rule) && !nonEmpty[rule] {
+				delete(aggregated, rule)
+			}
+		}
+	}
+
+	return aggregated
+}
+
+func keepFileRule(filename, rule string, opts *Options) bool {
+	if _, ok := opts.inlineOwners[filename]; ok {
+		return true
+	}
+
+	fileOverrides, ok := getOverrideOwners(filename, opts.config)
+	if !ok {
+		return false
+	}
+
+	ruleOverrides, ok := getOverrideOwners(rule, opts.config)
+	return !ok || !slices.Equal(fileOverrides, ruleOverrides)
+}
+
+// sortRules sorts rules so each directory rule precedes every rule beneath it.
+// CODEOWNERS rules are last match wins, so more specific rules must come later to take precedence.
+// Otherwise, rules are sorted by name.
+func sortRules(rules []string) {
+	sort.Slice(rules, func(i, j int) bool {
+		return ruleSortKey(rules[i]) < ruleSortKey(rules[j])
+	})
+}
+
+// ruleSortKey replaces the trailing slash of directory rules with a character
+// which sorts before any other. A directory rule then sorts before both its
+// contents and any sibling sharing its name as a prefix. Extension patterns
+// sort alongside the files in their directory.
+func ruleSortKey(rule string) string {
+	if rule == rootDirectoryRule {
+		return ""
+	}
+
+	if isExtensionPattern(rule) {
+		return strings.TrimPrefix(rule, "/")
+	}
+
+	if strings.HasSuffix(rule, "/") {
+		return strings.TrimSuffix(rule, "/") + "\x00"
+	}
+
+	return rule
+}
@@ -0,0 +1,221 @@
+package codeowners
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jpmcb/gopherlogs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
+)
+
+// resolveGitHubOwners resolves the owners of a file from a generated CODEOWNERS file
+// the way GitHub does: the last rule whose pattern matches the file wins
+func resolveGitHubOwners(codeowners string, filename string) string {
+	var owners string
+	for _, line := range strings.Split(codeowners, "\n") {
+		fields := splitUnescaped(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if !codeownersPatternMatches(fields[0], filename, false) {
+			continue
+		}
+
+		ruleOwners := fields[1:]
+		if i := slices.IndexFunc(ruleOwners, func(owner string) bool { return strings.HasPrefix(owner, "#") }); i >= 0 {
+			ruleOwners = ruleOwners[:i]
+		}
+		owners = strings.Join(ruleOwners, " ")
+	}
+
+	return owners
+}
+
+func newGranularityTestData() (FileStats, *Options) {
+	logger, _ := gopherlogs.NewLogger(gopherlogs.WithOutputWriter(io.Discard))
+
+	opts := &Options{
+		maxOwners:   3,
+		granularity: granularityDirectory,
+		logger:      logger,
+		config: &config.Spec{
+			Attributions: map[string][]string{
+				"brandonroberts": {"brandon@opensauced.pizza"},
+				"jpmcb":          {"john@opensauced.pizza"},
+			},
+		},
+	}
+
+	fileStats := FileStats{
+		"main.go": {
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 10, Commits: 1},
+		},
+		"docs/guide.md": {
+			"john": {Email: "john@opensauced.pizza", Lines: 10, Commits: 1},
+		},
+		"docs/api/index.md": {
+			"john": {Email: "john@opensauced.pizza", Lines: 10, Commits: 1},
+		},
+		"docs/README.md": {
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 30, Commits: 1},
+			"john":    {Email: "john@opensauced.pizza", Lines: 5, Commits: 1},
+		},
+	}
+
+	return fileStats, opts
+}
+
+func TestAggregateDirectories(t *testing.T) {
+	fileStats, opts := newGranularityTestData()
+
+	aggregated := fileStats.aggregateDirectories(opts)
+	assert.Len(t, aggregated, 3)
+	assert.Equal(t, 10, aggregated[rootDirectoryRule]["brandon"].Lines)
+	assert.Equal(t, 30, aggregated["docs/"]["brandon"].Lines)
+	assert.Equal(t, 15, aggregated["docs/"]["john"].Lines)
+	assert.Equal(t, 2, aggregated["docs/"]["john"].Commits)
+	assert.Contains(t, aggregated, "docs/api/")
+
+	// the original stats are left untouched
+	assert.Equal(t, 5, fileStats["docs/README.md"]["john"].Lines)
+}
+
+func TestSkipEmptyDirectories(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".sauced.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("attribution:\n  brandonroberts: [brandon@opensauced.pizza]\n"), 0600))
+
+	t.Run("aggregated", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.skipEmptyDirectories = true
+
+		// docs/api/index.md has been deleted
+		opts.trackedFiles = map[string]struct{}{"main.go": {}, "docs/README.md": {}}
+
+		aggregated := fileStats.aggregateDirectories(opts)
+		assert.Len(t, aggregated, 2)
+		assert.NotContains(t, aggregated, "docs/api/")
+
+		// the history of docs/guide.md, which has been deleted too, still counts
+		assert.Equal(t, 15, aggregated["docs/"]["john"].Lines)
+	})
+
+	t.Run("directory with only ignored files", func(t *testing.T) {
+		dir, _ := newTestRepo(t,
+			testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{
+				"main.go":          "package main\n",
+				"legacy/old.go":    "package legacy\n",
+				"legacy/old.pb.go": "package legacy\n",
+			}},
+			testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{
+				"legacy/old.go": "",
+			}},
+		)
+
+		generate := func(t *testing.T, args ...string) string {
+			t.Helper()
+
+			outputPath := t.TempDir()
+			cmd := NewCodeownersCommand()
+			cmd.PersistentFlags().Bool(constants.FlagNameTelemetry, true, "")
+			cmd.PersistentFlags().String("config", "", "")
+			cmd.SetArgs(append([]string{dir, "--config", configPath, "--output-path", outputPath, "--granularity", "directory", "--ignore", "legacy/old.pb.go"}, args...))
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			require.NoError(t, cmd.Execute())
+
+			contents, err := os.ReadFile(filepath.Join(outputPath, "CODEOWNERS"))
+			require.NoError(t, err)
+			return string(contents)
+		}
+
+		assert.Contains(t, generate(t), "/legacy/ @brandonroberts\n")
+
+		contents := generate(t, "--skip-empty-directories")
+		assert.Contains(t, contents, "* @brandonroberts\n")
+		assert.NotContains(t, contents, "/legacy/")
+	})
+
+	t.Run("requires directory granularity", func(t *testing.T) {
+		cmd := NewCodeownersCommand()
+		cmd.PersistentFlags().Bool(constants.FlagNameTelemetry, true, "")
+		cmd.PersistentFlags().String("config", "", "")
+		cmd.SetArgs([]string{t.TempDir(), "--config", configPath, "--skip-empty-directories"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+
+		err := cmd.Execute()
+		require.ErrorContains(t, err, "--skip-empty-directories can only be used with --granularity directory")
+		assert.Equal(t, constants.ExitCodeConfig, utils.ExitCode(err))
+	})
+}
+
+func TestSortRules(t *testing.T) {
+	rules := []string{"docs/guide.md", "docs-old.md", "docs/api/", "docs/", "*", "docs/api/index.md", "a.go"}
+	sortRules(rules)
+
+	assert.Equal(t, []string{"*", "a.go", "docs/", "docs-old.md", "docs/api/", "docs/api/index.md", "docs/guide.md"}, rules)
+}
+
+func TestDirectoryGranularityPrecedence(t *testing.T) {
+	t.Run("file override beneath a directory rule", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.config.Overrides = map[string][]string{"docs/*.md": {"open-sauced/docs"}}
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+
+		assert.Equal(t, "@brandonroberts", resolveGitHubOwners(buf.String(), "main.go"))
+		assert.Equal(t, "@open-sauced/docs", resolveGitHubOwners(buf.String(), "docs/guide.md"))
+		assert.Equal(t, "@open-sauced/docs", resolveGitHubOwners(buf.String(), "docs/README.md"))
+		assert.Equal(t, "@jpmcb", resolveGitHubOwners(buf.String(), "docs/api/index.md"))
+	})
+
+	t.Run("directory override", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.config.Overrides = map[string][]string{"docs/**": {"open-sauced/docs"}}
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+
+		assert.Equal(t, "* @brandonroberts\n/docs/ @open-sauced/docs\n/docs/api/ @open-sauced/docs\n", buf.String())
+		assert.Equal(t, "@open-sauced/docs", resolveGitHubOwners(buf.String(), "docs/api/index.md"))
+		assert.Equal(t, "@open-sauced/docs", resolveGitHubOwners(buf.String(), "docs/README.md"))
+	})
+
+	t.Run("inline owners", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.inlineOwners = map[string][]string{"docs/README.md": {"alice"}}
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+
+		assert.Equal(t, "@alice", resolveGitHubOwners(buf.String(), "docs/README.md"))
+		assert.Equal(t, "@jpmcb", resolveGitHubOwners(buf.String(), "docs/guide.md"))
+	})
+
+	t.Run("same file name in the root and a subdirectory", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.granularity = granularityFile
+		fileStats["cmd/main.go"] = AuthorStats{"john": {Email: "john@opensauced.pizza", Lines: 10, Commits: 1}}
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+
+		// the root file's rule is anchored, so it doesn't also match cmd/main.go
+		assert.Contains(t, buf.String(), "/cmd/main.go @jpmcb\n")
+		assert.Contains(t, buf.String(), "/main.go @brandonroberts\n")
+		assert.Equal(t, "@brandonroberts", resolveGitHubOwners(buf.String(), "main.go"))
+		assert.Equal(t, "@jpmcb", resolveGitHubOwners(buf.String(), "cmd/main.go"))
+	})
+}
@@ -0,0 +1,43 @@
+package codeowners
+
+import (
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+// expandOwnerGroups expands the config group references among the owners of a rule,
+// i.e., "@@frontend", into the group's members. Each member keeps the source of the
+// owner which referenced its group, and owners listed more than once are only kept first.
+func expandOwnerGroups(owners AuthorStatSlice, spec *config.Spec) (AuthorStatSlice, error) {
+	expanded := make(AuthorStatSlice, 0, len(owners))
+	seen := make(map[string]bool, len(owners))
+
+	for _, owner := range owners {
+		if _, ok := config.GroupReference(owner.GitHubAlias); !ok {
+			if !seen[owner.GitHubAlias] {
+				expanded = append(expanded, owner)
+				seen[owner.GitHubAlias] = true
+			}
+
+			continue
+		}
+
+		members, err := spec.ExpandGroups([]string{owner.GitHubAlias})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			if seen[member] {
+				continue
+			}
+
+			expanded = append(expanded, &CodeownerStat{
+				GitHubAlias: member,
+				Source:      owner.Source,
+			})
+			seen[member] = true
+		}
+	}
+
+	return expanded, nil
+}
@@ -0,0 +1,61 @@
+package codeowners
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+func TestExpandOwnerGroups(t *testing.T) {
+	spec := &config.Spec{
+		Groups: map[string][]string{
+			"frontend": {"@alice", "@bob"},
+			"web":      {"@@frontend", "@carol"},
+		},
+	}
+
+	t.Run("nested groups", func(t *testing.T) {
+		owners := AuthorStatSlice{
+			{GitHubAlias: "bob", Source: ownerSourceTopContributors},
+			{GitHubAlias: "@@web", Source: ownerSourceOverride},
+		}
+
+		expanded, err := expandOwnerGroups(owners, spec)
+		require.NoError(t, err)
+
+		require.Len(t, expanded, 3)
+		assert.Equal(t, owners[0], expanded[0])
+		assert.Equal(t, &CodeownerStat{GitHubAlias: "alice", Source: ownerSourceOverride}, expanded[1])
+		assert.Equal(t, &CodeownerStat{GitHubAlias: "carol", Source: ownerSourceOverride}, expanded[2])
+	})
+
+	t.Run("undefined group", func(t *testing.T) {
+		_, err := expandOwnerGroups(AuthorStatSlice{{GitHubAlias: "@@backend"}}, spec)
+		require.ErrorContains(t, err, "undefined group")
+	})
+}
+
+func TestWriteFileStatsExpandsGroups(t *testing.T) {
+	fileStats, opts := newGranularityTestData()
+	opts.granularity = granularityFile
+	opts.config.Groups = map[string][]string{"docs": {"@alice", "@bob"}}
+	opts.config.Overrides = map[string][]string{"docs/**": {"@@docs"}}
+	opts.config.AttributionFallback = []string{"@@docs"}
+	fileStats["unowned.go"] = AuthorStats{}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+
+	assert.Equal(t, "/docs/README.md @alice @bob\n/docs/api/index.md @alice @bob\n/docs/guide.md @alice @bob\n/main.go @brandonroberts\n/unowned.go @alice @bob\n", buf.String())
+
+	t.Run("undefined group", func(t *testing.T) {
+		opts.config.Overrides = map[string][]string{"docs/**": {"@@missing"}}
+
+		var buf bytes.Buffer
+		require.ErrorContains(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts), `undefined group "@@missing"`)
+	})
+}
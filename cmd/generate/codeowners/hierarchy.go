@@ -0,0 +1,199 @@
+package codeowners
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// ownersFile is a single Kubernetes style OWNERS file in a hierarchy of OWNERS files.
+// Owners are inherited from parent directories unless NoParentOwners is set.
+type ownersFile struct {
+	Dir       string
+	Approvers []string
+
+	// NoParentOwners disables inheriting the owners of parent directories
+	NoParentOwners bool
+}
+
+// kubernetesOwners is the YAML layout of a Kubernetes style OWNERS file
+type kubernetesOwners struct {
+	Options   *kubernetesOwnersOptions `yaml:"options,omitempty"`
+	Approvers []string                 `yaml:"approvers"`
+}
+
+type kubernetesOwnersOptions struct {
+	NoParentOwners bool `yaml:"no_parent_owners"`
+}
+
+// buildOwnersHierarchy builds per directory OWNERS files from the owners of the files
+// directly within each directory. A directory's owners are its most frequent file owners.
+// Owners already inherited from a parent directory are not repeated, and directories
+// with no owners of their own are skipped since they inherit everything.
+// Directories matching the config's "no-parent-owners" globs list all of their owners
+// and disable inheritance, even those with only subdirectories. The result is sorted by directory.
+func buildOwnersHierarchy(fileStats FileStats, opts *Options) []ownersFile {
+	ownerCounts := make(map[string]map[string]int)
+	for filename, authorStats := range fileStats {
+		dir := path.Dir(filename)
+		if _, ok := ownerCounts[dir]; !ok {
+			ownerCounts[dir] = make(map[string]int)
+		}
+
+		for _, owner := range getOwners(filename, authorStats, opts) {
+			ownerCounts[dir][owner.GitHubAlias]++
+		}
+	}
+
+	dirOwners := make(map[string][]string, len(ownerCounts))
+	for dir, counts := range ownerCounts {
+		owners := make([]string, 0, len(counts))
+		for owner := range counts {
+			owners = append(owners, owner)
+		}
+
+		sort.Slice(owners, func(i, j int) bool {
+			if counts[owners[i]] != counts[owners[j]] {
+				return counts[owners[i]] > counts[owners[j]]
+			}
+
+			return owners[i] < owners[j]
+		})
+
+		dirOwners[dir] = owners[:min(len(owners), opts.maxOwners)]
+		if opts.ownerSort == ownerSortAlpha {
+			slices.SortFunc(dirOwners[dir], compareAliases)
+		}
+	}
+
+	// effective owners of every directory, including those inherited from parents
+	effective := make(map[string]map[string]bool)
+	var effectiveOwners func(dir string) map[string]bool
+	effectiveOwners = func(dir string) map[string]bool {
+		if owners, ok := effective[dir]; ok {
+			return owners
+		}
+
+		owners := make(map[string]bool)
+		if dir != "." && !isNoParentOwners(dir, opts) {
+			for owner := range effectiveOwners(path.Dir(dir)) {
+				owners[owner] = true
+			}
+		}
+
+		for _, owner := range dirOwners[dir] {
+			owners[owner] = true
+		}
+
+		effective[dir] = owners
+		return owners
+	}
+
+	// directories with only subdirectories have no owners of their own, but are
+	// listed too since their OWNERS file is still needed to disable inheritance
+	seen := make(map[string]bool, len(dirOwners))
+	var dirs []string
+	for dir := range dirOwners {
+		for ; !seen[dir]; dir = path.Dir(dir) {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+
+			if dir == "." {
+				break
+			}
+		}
+	}
+	sort.Strings(dirs)
+
+	var files []ownersFile
+	for _, dir := range dirs {
+		noParentOwners := dir != "." && isNoParentOwners(dir, opts)
+
+		var inherited map[string]bool
+		if dir != "." && !noParentOwners {
+			inherited = effectiveOwners(path.Dir(dir))
+		}
+
+		var approvers []string
+		for _, owner := range dirOwners[dir] {
+			if !inherited[owner] {
+				approvers = append(approvers, owner)
+			}
+		}
+
+		if len(approvers) == 0 && !noParentOwners {
+			continue
+		}
+
+		files = append(files, ownersFile{
+			Dir:            dir,
+			Approvers:      approvers,
+			NoParentOwners: noParentOwners,
+		})
+	}
+
+	return files
+}
+
+func isNoParentOwners(dir string, opts *Options) bool {
+	for _, glob := range opts.config.NoParentOwners {
+		if matched, err := matchGlob(glob, dir); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeOwnersHierarchy writes each OWNERS file of the hierarchy into its
+// directory beneath the output path, or to its expanded --output-file
+func writeOwnersHierarchy(files []ownersFile, opts *Options, cmd *cobra.Command) error {
+	for _, ownersFile := range files {
+		filePath := opts.outputFilePath("OWNERS", ownersFile.Dir)
+
+		approvers, err := opts.config.ExpandGroups(ownersFile.Approvers)
+		if err != nil {
+			return fmt.Errorf("error expanding the owners of %s: %w", filePath, err)
+		}
+
+		contents := kubernetesOwners{
+			Approvers: approvers,
+		}
+
+		if ownersFile.NoParentOwners {
+			contents.Options = &kubernetesOwnersOptions{NoParentOwners: true}
+		}
+
+		data, err := yaml.Marshal(contents)
+		if err != nil {
+			return fmt.Errorf("error marshaling %s: %w", filePath, err)
+		}
+
+		var buf bytes.Buffer
+		w, err := newOutputWriter(&buf, resolveLineEnding(filePath, opts.lineEnding), opts.bom)
+		if err != nil {
+			return err
+		}
+
+		err = writeHeader(w, filePath, opts, cmd)
+		if err == nil {
+			_, err = w.Write(data)
+		}
+
+		if err != nil {
+			return fmt.Errorf("error writing to %s file: %w", filePath, err)
+		}
+
+		err = writeOutputIfChanged(filePath, buf.Bytes(), opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+func newHierarchyTestData(noParentOwners []string) (FileStats, *Options) {
+	opts := &Options{
+		maxOwners: 3,
+		config: &config.Spec{
+			Attributions: map[string][]string{
+				"brandonroberts": {"brandon@opensauced.pizza"},
+				"jpmcb":          {"john@opensauced.pizza"},
+			},
+			NoParentOwners: noParentOwners,
+		},
+	}
+
+	fileStats := FileStats{
+		"main.go": {
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 10},
+		},
+		"cmd/a.go": {
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 10},
+			"john":    {Email: "john@opensauced.pizza", Lines: 5},
+		},
+		"docs/readme.md": {
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 10},
+		},
+	}
+
+	return fileStats, opts
+}
+
+func TestBuildOwnersHierarchy(testRunner *testing.T) {
+	testRunner.Run("inherits parent owners", func(tester *testing.T) {
+		fileStats, opts := newHierarchyTestData(nil)
+
+		files := buildOwnersHierarchy(fileStats, opts)
+		assert.Equal(tester, []ownersFile{
+			{Dir: ".", Approvers: []string{"brandonroberts"}},
+			{Dir: "cmd", Approvers: []string{"jpmcb"}},
+		}, files)
+	})
+
+	testRunner.Run("no parent owners", func(tester *testing.T) {
+		fileStats, opts := newHierarchyTestData([]string{"docs", "cmd"})
+
+		files := buildOwnersHierarchy(fileStats, opts)
+		assert.Equal(tester, []ownersFile{
+			{Dir: ".", Approvers: []string{"brandonroberts"}},
+			{Dir: "cmd", Approvers: []string{"brandonroberts", "jpmcb"}, NoParentOwners: true},
+			{Dir: "docs", Approvers: []string{"brandonroberts"}, NoParentOwners: true},
+		}, files)
+	})
+
+	testRunner.Run("no parent owners with only subdirectories", func(tester *testing.T) {
+		fileStats, opts := newHierarchyTestData([]string{"pkg"})
+		fileStats["pkg/api/api.go"] = AuthorStats{
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 10},
+			"john":    {Email: "john@opensauced.pizza", Lines: 5},
+		}
+
+		files := buildOwnersHierarchy(fileStats, opts)
+		assert.Equal(tester, []ownersFile{
+			{Dir: ".", Approvers: []string{"brandonroberts"}},
+			{Dir: "cmd", Approvers: []string{"jpmcb"}},
+			{Dir: "pkg", NoParentOwners: true},
+			{Dir: "pkg/api", Approvers: []string{"brandonroberts", "jpmcb"}},
+		}, files)
+	})
+}
+
+func TestWriteOwnersHierarchy(testRunner *testing.T) {
+	outputPath := testRunner.TempDir()
+	opts := &Options{outputPath: outputPath, config: &config.Spec{}}
+
+	files := []ownersFile{
+		{Dir: ".", Approvers: []string{"brandonroberts"}},
+		{Dir: "docs", Approvers: []string{"jpmcb"}, NoParentOwners: true},
+	}
+
+	err := writeOwnersHierarchy(files, opts, &cobra.Command{})
+	require.NoError(testRunner, err)
+
+	root, err := os.ReadFile(filepath.Join(outputPath, "OWNERS"))
+	require.NoError(testRunner, err)
+	assert.Contains(testRunner, string(root), "approvers:\n    - brandonroberts\n")
+	assert.NotContains(testRunner, string(root), "no_parent_owners")
+
+	docs, err := os.ReadFile(filepath.Join(outputPath, "docs", "OWNERS"))
+	require.NoError(testRunner, err)
+	assert.Contains(testRunner, string(docs), "options:\n    no_parent_owners: true\napprovers:\n    - jpmcb\n")
+}
@@ -0,0 +1,130 @@
+package codeowners
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/jpmcb/gopherlogs/pkg/colors"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+)
+
+// HistoryProvider derives the FileStats of a repository from its history.
+// The default provider walks the git log with go-git via ProcessOptions.
+type HistoryProvider interface {
+	// FileStats returns the stats of every file in the history reachable from ref
+	// of the repository at path, keyed by their path relative to the repository's
+	// root. The path is the repository itself, not a subdirectory to limit the
+	// results to. An empty ref uses the repository's HEAD.
+	FileStats(path, ref string) (FileStats, error)
+}
+
+// FileStats implements HistoryProvider by walking the git log reachable from ref
+func (po ProcessOptions) FileStats(path, ref string) (FileStats, error) {
+	po.dirPath = path
+	po.ref = ref
+
+	return po.process()
+}
+
+// resolveRef resolves the processing ref to a commit hash, defaulting to HEAD
+func (po *ProcessOptions) resolveRef() (plumbing.Hash, error) {
+	if po.ref == "" {
+		head, err := po.repo.Head()
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("could not get repo head: %w", err)
+		}
+
+		return head.Hash(), nil
+	}
+
+	hash, err := po.repo.ResolveRevision(plumbing.Revision(po.ref))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("could not resolve ref %s: %w", po.ref, err)
+	}
+
+	return *hash, nil
+}
+
+// resolveCommit resolves a revision, such as a commit SHA, to its commit,
+// failing when it doesn't exist in the repository
+func resolveCommit(repo *git.Repository, rev string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve %s: %w", rev, err)
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("could not get commit %s: %w", hash, err)
+	}
+
+	return commit, nil
+}
+
+// errShallowClone is returned when generating from a shallow clone without --allow-shallow
+var errShallowClone = errors.New("the repository is a shallow clone, so its truncated history would attribute most files to the authors of its most recent commits. Fetch the full history with \"git fetch --unshallow\", e.g., \"fetch-depth: 0\" with actions/checkout, or pass --allow-shallow to generate anyway")
+
+// isShallow returns true if the repository is a shallow clone, i.e., it has shallow commits
+// whose parents weren't fetched, the same commits listed in ".git/shallow"
+func isShallow(repo *git.Repository) (bool, error) {
+	shallowStorer, ok := repo.Storer.(storer.ShallowStorer)
+	if !ok {
+		return false, nil
+	}
+
+	shallow, err := shallowStorer.Shallow()
+	if err != nil {
+		return false, fmt.Errorf("could not read the shallow commits: %w", err)
+	}
+
+	return len(shallow) > 0, nil
+}
+
+// checkShallow fails for a shallow clone unless --allow-shallow is set, which only warns
+func checkShallow(repo *git.Repository, opts *Options) error {
+	shallow, err := isShallow(repo)
+	if err != nil {
+		return err
+	}
+	if !shallow {
+		return nil
+	}
+
+	if !opts.allowShallow {
+		return errShallowClone
+	}
+
+	opts.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("Warning: the repository is a shallow clone, so most files are attributed to the authors of its most recent commits. Fetch the full history with \"git fetch --unshallow\"\n")
+	return nil
+}
+
+// detectDefaultBranch detects the repository's default branch from the symbolic
+// origin/HEAD reference, the same reference read by "git symbolic-ref refs/remotes/origin/HEAD".
+// The full name of the branch is returned. Example: "refs/remotes/origin/main"
+func detectDefaultBranch(repo *git.Repository) (string, error) {
+	ref, err := repo.Reference(plumbing.NewRemoteHEADReferenceName("origin"), false)
+	if err != nil {
+		return "", fmt.Errorf("could not get origin/HEAD: %w", err)
+	}
+
+	if ref.Type() != plumbing.SymbolicReference {
+		return "", fmt.Errorf("origin/HEAD is not a symbolic reference")
+	}
+
+	return ref.Target().String(), nil
+}
+
+// isDetachedHead returns true if the repository's HEAD is a commit instead of a branch
+func isDetachedHead(repo *git.Repository) (bool, error) {
+	head, err := repo.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return false, fmt.Errorf("could not get repo head: %w", err)
+	}
+
+	return head.Type() == plumbing.HashReference, nil
+}
@@ -0,0 +1,223 @@
+package codeowners
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
+)
+
+// fakeHistory is a HistoryProvider returning fixed stats
+type fakeHistory struct {
+	fileStats FileStats
+	path      string
+}
+
+func (fh *fakeHistory) FileStats(path, _ string) (FileStats, error) {
+	fh.path = path
+	return fh.fileStats, nil
+}
+
+func TestProcessOptionsFileStats(t *testing.T) {
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "package main\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"util.go": "package main\n"}},
+	)
+
+	po := ProcessOptions{repo: repo, previousDays: 30, logger: newTestLogger(t)}
+
+	t.Run("head", func(t *testing.T) {
+		fileStats, err := po.FileStats(dir, "")
+		require.NoError(t, err)
+		assert.Contains(t, fileStats, "main.go")
+		assert.Contains(t, fileStats, "util.go")
+	})
+
+	t.Run("ref", func(t *testing.T) {
+		fileStats, err := po.FileStats(dir, "HEAD~1")
+		require.NoError(t, err)
+		assert.Contains(t, fileStats, "main.go")
+		assert.NotContains(t, fileStats, "util.go")
+	})
+
+	t.Run("unknown ref", func(t *testing.T) {
+		_, err := po.FileStats(dir, "does-not-exist")
+		require.Error(t, err)
+	})
+}
+
+func TestRunWithHistoryProvider(t *testing.T) {
+	dir, _ := newTestRepo(t, testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "package main\n"}})
+
+	history := &fakeHistory{fileStats: FileStats{
+		"fake.go": {"john": {Email: "john@opensauced.pizza", Lines: 10}},
+	}}
+
+	opts := &Options{
+		path:       dir,
+		outputPath: t.TempDir(),
+		format:     formatGitHub,
+		maxOwners:  3,
+		history:    history,
+		telemetry:  utils.NewPosthogCliClient(false),
+		config: &config.Spec{
+			Attributions: map[string][]string{"jpmcb": {"john@opensauced.pizza"}},
+		},
+	}
+
+	require.NoError(t, run(opts, &cobra.Command{}))
+	assert.Equal(t, dir, history.path)
+
+	contents, err := os.ReadFile(filepath.Join(opts.outputPath, "CODEOWNERS"))
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "fake.go @jpmcb\n")
+	assert.NotContains(t, string(contents), "main.go")
+}
+
+func TestResolveDefaultBranchRef(t *testing.T) {
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "package main\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"feature.go": "package main\n"}},
+	)
+
+	opts := &Options{logger: newTestLogger(t)}
+
+	t.Run("on a branch", func(t *testing.T) {
+		assert.Equal(t, "", resolveDefaultBranchRef(repo, opts))
+	})
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Hash: head.Hash()}))
+
+	t.Run("detached without origin/HEAD", func(t *testing.T) {
+		assert.Equal(t, "", resolveDefaultBranchRef(repo, opts))
+	})
+
+	t.Run("detached with origin/HEAD", func(t *testing.T) {
+		first, err := repo.ResolveRevision("HEAD~1")
+		require.NoError(t, err)
+
+		mainRef := plumbing.NewRemoteReferenceName("origin", "main")
+		require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(mainRef, *first)))
+		require.NoError(t, repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.NewRemoteHEADReferenceName("origin"), mainRef)))
+
+		ref := resolveDefaultBranchRef(repo, opts)
+		assert.Equal(t, "refs/remotes/origin/main", ref)
+
+		po := ProcessOptions{repo: repo, previousDays: 30, logger: newTestLogger(t)}
+		fileStats, err := po.FileStats(dir, ref)
+		require.NoError(t, err)
+		assert.Contains(t, fileStats, "main.go")
+		assert.NotContains(t, fileStats, "feature.go")
+
+		// the file list and the analyzed tree are the default branch's too
+		treeFiles, err := listTreeFiles(repo, ref)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]struct{}{"main.go": {}}, treeFiles)
+
+		tree, err := analyzedTree(repo, &Options{defaultBranchRef: ref})
+		require.NoError(t, err)
+		_, err = tree.File("feature.go")
+		require.ErrorIs(t, err, object.ErrFileNotFound)
+	})
+}
+
+func TestGenerateAt(t *testing.T) {
+	// the commits are a day apart, the last made a day ago
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "package main\n", "old.go": "package main\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"main.go": "package main\n\nfunc main() {}\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"old.go": "", "new.go": "package main\n"}},
+	)
+
+	configPath := filepath.Join(t.TempDir(), ".sauced.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("attribution:\n  brandonroberts: [brandon@opensauced.pizza]\n  jpmcb: [john@opensauced.pizza]\n"), 0600))
+
+	generate := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+
+		outputPath := t.TempDir()
+		cmd := NewCodeownersCommand()
+		cmd.PersistentFlags().Bool(constants.FlagNameTelemetry, true, "")
+		cmd.PersistentFlags().String("config", "", "")
+		cmd.SetArgs(append([]string{dir, "--config", configPath, "--output-path", outputPath}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+
+		if err := cmd.Execute(); err != nil {
+			return "", err
+		}
+
+		contents, err := os.ReadFile(filepath.Join(outputPath, "CODEOWNERS"))
+		require.NoError(t, err)
+		return string(contents), nil
+	}
+
+	t.Run("bounds the history and the files by the commit", func(t *testing.T) {
+		second, err := repo.ResolveRevision("HEAD~1")
+		require.NoError(t, err)
+
+		contents, err := generate(t, "--at", second.String())
+		require.NoError(t, err)
+		assert.Contains(t, contents, "main.go @jpmcb @brandonroberts\n")
+		assert.Contains(t, contents, "old.go @brandonroberts\n")
+		assert.NotContains(t, contents, "new.go")
+	})
+
+	t.Run("counts the range back from the commit", func(t *testing.T) {
+		first, err := repo.ResolveRevision("HEAD~2")
+		require.NoError(t, err)
+
+		// the first commit is outside the last day, but not the day before it
+		contents, err := generate(t, "--at", first.String(), "--range", "1")
+		require.NoError(t, err)
+		assert.Contains(t, contents, "main.go @brandonroberts\n")
+		assert.Contains(t, contents, "old.go @brandonroberts\n")
+	})
+
+	t.Run("unknown commit", func(t *testing.T) {
+		_, err := generate(t, "--at", "0123456789abcdef0123456789abcdef01234567")
+		require.Error(t, err)
+		assert.Equal(t, constants.ExitCodeGit, utils.ExitCode(err))
+	})
+}
+
+func TestCheckShallow(t *testing.T) {
+	_, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "package main\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"main.go": "package main\n\nfunc main() {}\n"}},
+	)
+
+	t.Run("full clone", func(t *testing.T) {
+		require.NoError(t, checkShallow(repo, &Options{logger: newTestLogger(t)}))
+	})
+
+	// a "git clone --depth 1" lists its only commit in .git/shallow
+	head, err := repo.Head()
+	require.NoError(t, err)
+	require.NoError(t, repo.Storer.(storer.ShallowStorer).SetShallow([]plumbing.Hash{head.Hash()}))
+
+	t.Run("shallow clone", func(t *testing.T) {
+		require.ErrorIs(t, checkShallow(repo, &Options{logger: newTestLogger(t)}), errShallowClone)
+	})
+
+	t.Run("allowed shallow clone", func(t *testing.T) {
+		require.NoError(t, checkShallow(repo, &Options{allowShallow: true, logger: newTestLogger(t)}))
+	})
+}
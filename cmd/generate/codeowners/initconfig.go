@@ -0,0 +1,176 @@
+package codeowners
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"gopkg.in/yaml.v3"
+)
+
+// initConfigHeader is the comment at the top of a config bootstrapped with --init-config
+const initConfigHeader = `Configuration for attributing commits with emails to GitHub user profiles.
+Generated from the git history with "pizza generate codeowners --init-config".
+
+Each username is a guess from the most frequent name its emails committed with.
+Replace each with the GitHub username or team to attribute the emails to, and
+combine the emails of anyone listed under several names.`
+
+// historyAuthor is a distinct author email found in the git history
+type historyAuthor struct {
+	email   string
+	commits int
+
+	// the number of commits made with each name
+	names map[string]int
+}
+
+// nameGuess gets the name the author most frequently committed with. Ties are broken
+// alphabetically so the guess is stable.
+func (a *historyAuthor) nameGuess() string {
+	guess := ""
+	for name, commits := range a.names {
+		if guess == "" || commits > a.names[guess] || (commits == a.names[guess] && name < guess) {
+			guess = name
+		}
+	}
+
+	return guess
+}
+
+// scanHistoryAuthors gets the distinct author emails of the commits reachable from HEAD
+// within the given number of days, sorted by email. Bots are skipped the same as
+// with "pizza generate config".
+func scanHistoryAuthors(repo *git.Repository, previousDays int) ([]*historyAuthor, error) {
+	commitIter, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get repo commits: %w", err)
+	}
+
+	since := time.Now().AddDate(0, 0, -previousDays)
+	authors := make(map[string]*historyAuthor)
+
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		if c.Author.When.Before(since) || strings.Contains(c.Author.Name, "[bot]") {
+			return nil
+		}
+
+		email := c.Author.Email
+		author, ok := authors[email]
+		if !ok {
+			author = &historyAuthor{email: email, names: make(map[string]int)}
+			authors[email] = author
+		}
+
+		author.commits++
+		author.names[c.Author.Name]++
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error iterating over repo commits: %w", err)
+	}
+
+	sorted := make([]*historyAuthor, 0, len(authors))
+	for _, author := range authors {
+		sorted = append(sorted, author)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].email < sorted[j].email
+	})
+
+	return sorted, nil
+}
+
+// writeInitConfig writes a skeleton config attributing the emails of each author to the
+// name guessed for them, with a comment of how many commits they made, for the user to
+// replace with GitHub usernames. Example:
+//
+//	attribution:
+//	  # 12 commits
+//	  Brandon Roberts:
+//	    - robertsbt@gmail.com
+func writeInitConfig(w io.Writer, authors []*historyAuthor) error {
+	emails := make(map[string][]string)
+	commits := make(map[string]int)
+	for _, author := range authors {
+		guess := author.nameGuess()
+		emails[guess] = append(emails[guess], author.email)
+		commits[guess] += author.commits
+	}
+
+	guesses := make([]string, 0, len(emails))
+	for guess := range emails {
+		guesses = append(guesses, guess)
+	}
+	sort.Strings(guesses)
+
+	attributions := &yaml.Node{Kind: yaml.MappingNode}
+	for _, guess := range guesses {
+		comment := fmt.Sprintf("%d commits", commits[guess])
+		if commits[guess] == 1 {
+			comment = "1 commit"
+		}
+
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: guess, HeadComment: comment}
+
+		value := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, email := range emails[guess] {
+			value.Content = append(value.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: email})
+		}
+
+		attributions.Content = append(attributions.Content, key, value)
+	}
+
+	root := &yaml.Node{
+		Kind:        yaml.MappingNode,
+		HeadComment: initConfigHeader,
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: "attribution"},
+			attributions,
+		},
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}); err != nil {
+		return fmt.Errorf("error encoding config: %w", err)
+	}
+
+	return encoder.Close()
+}
+
+// initConfig bootstraps a config at the given path from the authors in the repository's
+// history, for --init-config. An existing config is only overwritten when forced.
+func initConfig(opts *Options, configPath string, force bool) (int, error) {
+	if _, err := os.Stat(configPath); err == nil && !force {
+		return 0, fmt.Errorf("%s already exists, pass --force to overwrite it", configPath)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, fmt.Errorf("could not check %s: %w", configPath, err)
+	}
+
+	repo, err := openRepo(opts)
+	if err != nil {
+		return 0, fmt.Errorf("error opening repo: %w", err)
+	}
+
+	authors, err := scanHistoryAuthors(repo, opts.previousDays)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := createOutputFile(configPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return len(authors), writeInitConfig(file, authors)
+}
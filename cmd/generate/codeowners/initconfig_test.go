@@ -0,0 +1,90 @@
+package codeowners
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
+)
+
+func TestNameGuess(t *testing.T) {
+	author := &historyAuthor{names: map[string]int{"brandon": 1, "Brandon Roberts": 3}}
+	assert.Equal(t, "Brandon Roberts", author.nameGuess())
+
+	tied := &historyAuthor{names: map[string]int{"john": 2, "John McBride": 2}}
+	assert.Equal(t, "John McBride", tied.nameGuess())
+}
+
+func TestInitConfig(t *testing.T) {
+	dir, _ := newTestRepo(t,
+		testCommit{"Brandon Roberts", "brandon@opensauced.pizza", map[string]string{"main.go": "a\n"}},
+		testCommit{"brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "b\n"}},
+		testCommit{"Brandon Roberts", "robertsbt@gmail.com", map[string]string{"main.go": "c\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"docs.md": "d\n"}},
+		testCommit{"dependabot[bot]", "bot@github.com", map[string]string{"go.mod": "e\n"}},
+	)
+
+	initConfigCmd := func(args ...string) (string, error) {
+		cmd := NewCodeownersCommand()
+		cmd.PersistentFlags().Bool(constants.FlagNameTelemetry, true, "")
+		cmd.PersistentFlags().String("config", "", "")
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs(append([]string{dir, "--init-config"}, args...))
+
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := initConfigCmd()
+	require.NoError(t, err)
+
+	configPath := filepath.Join(dir, ".sauced.yaml")
+	assert.Contains(t, out, "Wrote "+configPath+" with 3 author emails")
+
+	contents, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "attribution:\n  # 3 commits\n  Brandon Roberts:\n    - brandon@opensauced.pizza\n    - robertsbt@gmail.com\n  # 1 commit\n  John:\n")
+
+	spec, _, err := config.LoadConfigFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"Brandon Roberts": {"brandon@opensauced.pizza", "robertsbt@gmail.com"},
+		"John":            {"john@opensauced.pizza"},
+	}, spec.Attributions)
+
+	t.Run("existing config", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(configPath, []byte("attribution: {}\n"), 0600))
+
+		_, err := initConfigCmd()
+		require.ErrorContains(t, err, "already exists, pass --force to overwrite it")
+		assert.Equal(t, constants.ExitCodeConfig, utils.ExitCode(err))
+
+		contents, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, "attribution: {}\n", string(contents))
+
+		_, err = initConfigCmd("--force")
+		require.NoError(t, err)
+
+		spec, _, err := config.LoadConfigFile(configPath)
+		require.NoError(t, err)
+		assert.Len(t, spec.Attributions, 2)
+	})
+
+	t.Run("custom config path", func(t *testing.T) {
+		customPath := filepath.Join(t.TempDir(), "configs", "sauced.yaml")
+
+		_, err := initConfigCmd("--config", customPath)
+		require.NoError(t, err)
+		assert.FileExists(t, customPath)
+	})
+}
@@ -0,0 +1,115 @@
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	// defaultInlineOwnersDirective is the directive developers use to pin the owners
+	// of a file from within the file itself. Example: "// pizza-owners: @alice @bob"
+	defaultInlineOwnersDirective = "pizza-owners:"
+
+	// inlineOwnersMaxLines is the number of lines at the top of a file scanned for the directive
+	inlineOwnersMaxLines = 20
+)
+
+// parseInlineOwners scans the top of a file for the inline owners directive and returns
+// the pinned owners without their "@" prefix. The directive may be in any comment syntax:
+// the owners are the "@" prefixed words following it, up to the first non-owner word
+// (i.e., a closing "*/" or "-->").
+func parseInlineOwners(r io.Reader, directive string) ([]string, bool) {
+	scanner := bufio.NewScanner(r)
+
+	for lines := 0; lines < inlineOwnersMaxLines && scanner.Scan(); lines++ {
+		line := scanner.Text()
+
+		idx := strings.Index(line, directive)
+		if idx < 0 {
+			continue
+		}
+
+		var owners []string
+		for _, word := range strings.Fields(line[idx+len(directive):]) {
+			word = strings.TrimRight(word, ",")
+			if !strings.HasPrefix(word, "@") || len(word) == 1 {
+				break
+			}
+
+			owners = append(owners, strings.TrimPrefix(word, "@"))
+		}
+
+		return owners, len(owners) > 0
+	}
+
+	return nil, false
+}
+
+// analyzedTree gets the tree of the analyzed commit: the --at commit, the default
+// branch with --use-default-branch, or HEAD
+func analyzedTree(repo *git.Repository, opts *Options) (*object.Tree, error) {
+	commit := opts.atCommit
+	if commit == nil {
+		hash, err := (&ProcessOptions{repo: repo, ref: opts.defaultBranchRef}).resolveRef()
+		if err != nil {
+			return nil, err
+		}
+
+		commit, err = repo.CommitObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("could not get commit %s: %w", hash, err)
+		}
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("could not get tree for commit %s: %w", commit.Hash, err)
+	}
+
+	return tree, nil
+}
+
+// loadInlineOwners reads the inline owners directive of each file from the
+// tree of the analyzed commit, and records any pinned owners on the options
+func loadInlineOwners(repo *git.Repository, fileStats FileStats, opts *Options) error {
+	tree, err := analyzedTree(repo, opts)
+	if err != nil {
+		return err
+	}
+
+	directive := opts.config.InlineOwnersDirective
+	if directive == "" {
+		directive = defaultInlineOwnersDirective
+	}
+
+	if opts.inlineOwners == nil {
+		opts.inlineOwners = make(map[string][]string)
+	}
+
+	for filename := range fileStats {
+		file, err := tree.File(filename)
+		if err != nil {
+			// files no longer in the tree can't have inline owners
+			continue
+		}
+
+		reader, err := file.Reader()
+		if err != nil {
+			return fmt.Errorf("could not read %s: %w", filename, err)
+		}
+
+		owners, ok := parseInlineOwners(reader, directive)
+		reader.Close()
+
+		if ok {
+			opts.inlineOwners[filename] = owners
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package codeowners
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+func TestParseInlineOwners(t *testing.T) {
+	var tests = []struct {
+		name     string
+		contents string
+		expected []string
+	}{
+		{"go line comment", "// pizza-owners: @alice @bob\npackage main\n", []string{"alice", "bob"}},
+		{"shell comment after shebang", "#!/bin/bash\n# pizza-owners: @open-sauced/engineering\n", []string{"open-sauced/engineering"}},
+		{"block comment", "/* pizza-owners: @alice */\n", []string{"alice"}},
+		{"html comment", "<!-- pizza-owners: @alice, @bob -->\n", []string{"alice", "bob"}},
+		{"sql comment", "-- pizza-owners: @alice\nSELECT 1;\n", []string{"alice"}},
+		{"no owners", "// pizza-owners:\n", nil},
+		{"no directive", "package main\n", nil},
+		{"directive too far down", strings.Repeat("\n", inlineOwnersMaxLines) + "// pizza-owners: @alice\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owners, ok := parseInlineOwners(strings.NewReader(tt.contents), defaultInlineOwnersDirective)
+			assert.Equal(t, tt.expected != nil, ok)
+			assert.Equal(t, tt.expected, owners)
+		})
+	}
+
+	t.Run("custom directive", func(t *testing.T) {
+		owners, ok := parseInlineOwners(strings.NewReader("// OWNERS: @alice\n"), "OWNERS:")
+		assert.True(t, ok)
+		assert.Equal(t, []string{"alice"}, owners)
+	})
+}
+
+func TestLoadInlineOwners(t *testing.T) {
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{
+			"pinned.go":   "// pizza-owners: @jpmcb\npackage main\n",
+			"computed.go": "package main\n",
+		}},
+	)
+
+	opts := &Options{
+		maxOwners: 3,
+		config: &config.Spec{
+			Attributions: map[string][]string{"brandonroberts": {"brandon@opensauced.pizza"}},
+		},
+	}
+
+	po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, logger: newTestLogger(t)}
+	fs, err := po.process()
+	require.NoError(t, err)
+	require.NoError(t, loadInlineOwners(repo, fs, opts))
+
+	owners := getOwners("pinned.go", fs["pinned.go"], opts)
+	require.Len(t, owners, 1)
+	assert.Equal(t, "jpmcb", owners[0].GitHubAlias)
+
+	owners = getOwners("computed.go", fs["computed.go"], opts)
+	require.Len(t, owners, 1)
+	assert.Equal(t, "brandonroberts", owners[0].GitHubAlias)
+}
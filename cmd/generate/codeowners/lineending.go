@@ -0,0 +1,107 @@
+package codeowners
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// The supported line endings of the output files
+const (
+	lineEndingLF   = "lf"
+	lineEndingCRLF = "crlf"
+	lineEndingAuto = "auto"
+)
+
+var lineEndings = []string{lineEndingLF, lineEndingCRLF, lineEndingAuto}
+
+// crlfWriter converts the "\n" line endings written by the output functions to "\r\n".
+// Each write is expected to contain whole lines.
+type crlfWriter struct {
+	w io.Writer
+}
+
+func (cw *crlfWriter) Write(p []byte) (int, error) {
+	normalized := bytes.ReplaceAll(p, []byte("\r\n"), []byte("\n"))
+	normalized = bytes.ReplaceAll(normalized, []byte("\n"), []byte("\r\n"))
+
+	_, err := cw.w.Write(normalized)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// newLineEndingWriter wraps the writer to write the given line ending
+func newLineEndingWriter(w io.Writer, lineEnding string) io.Writer {
+	if lineEnding == lineEndingCRLF {
+		return &crlfWriter{w: w}
+	}
+
+	return w
+}
+
+// resolveLineEnding resolves the "auto" line ending to the line ending of the existing
+// output file, so regenerating a file checked out with CRLF line endings doesn't change
+// every line. New files default to LF.
+func resolveLineEnding(outputPath string, lineEnding string) string {
+	if lineEnding != lineEndingAuto {
+		return lineEnding
+	}
+
+	existing, err := os.ReadFile(outputPath)
+	if err == nil && bytes.Contains(existing, []byte("\r\n")) {
+		return lineEndingCRLF
+	}
+
+	return lineEndingLF
+}
+
+// createOutputWriter creates a temporary file beside the output file and a writer for it
+// with the configured line ending and byte order mark. Once it's complete, the temporary
+// file replaces the output file with replaceOutputFile, so a failed write, such as an
+// invalid line in strict mode, leaves the existing output file instead of a partial one.
+// The temporary file must be closed and removed by the caller.
+func createOutputWriter(outputPath string, opts *Options) (*os.File, io.Writer, error) {
+	lineEnding := resolveLineEnding(outputPath, opts.lineEnding)
+
+	err := os.MkdirAll(filepath.Dir(outputPath), os.ModePerm)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating directory at %s filepath: %w", outputPath, err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(outputPath), "."+filepath.Base(outputPath)+".*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating %s file: %w", outputPath, err)
+	}
+
+	w, err := newOutputWriter(file, lineEnding, opts.bom)
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, nil, fmt.Errorf("error writing to %s file: %w", outputPath, err)
+	}
+
+	return file, w, nil
+}
+
+// replaceOutputFile closes the complete temporary file and moves it into place as the output file
+func replaceOutputFile(file *os.File, outputPath string) error {
+	// temporary files are only readable by their owner
+	err := file.Chmod(0644)
+	if err == nil {
+		err = file.Close()
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), outputPath)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error writing to %s file: %w", outputPath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateWithLineEnding(t *testing.T, outputPath string, lineEnding string) string {
+	t.Helper()
+
+	fileStats, opts := newGranularityTestData()
+	opts.granularity = granularityFile
+	opts.lineEnding = lineEnding
+
+	require.NoError(t, generateOutputFile(fileStats, outputPath, opts, &cobra.Command{}))
+
+	contents, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	return string(contents)
+}
+
+func TestLineEnding(t *testing.T) {
+	t.Run("lf", func(t *testing.T) {
+		contents := generateWithLineEnding(t, filepath.Join(t.TempDir(), "CODEOWNERS"), lineEndingLF)
+
+		assert.NotContains(t, contents, "\r")
+		assert.Contains(t, contents, "main.go @brandonroberts\n")
+	})
+
+	t.Run("crlf", func(t *testing.T) {
+		contents := generateWithLineEnding(t, filepath.Join(t.TempDir(), "CODEOWNERS"), lineEndingCRLF)
+
+		assert.Equal(t, strings.Count(contents, "\n"), strings.Count(contents, "\r\n"))
+		assert.NotContains(t, contents, "\r\r")
+		assert.Contains(t, contents, "main.go @brandonroberts\r\n")
+	})
+
+	t.Run("auto keeps an existing crlf file", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "CODEOWNERS")
+		require.NoError(t, os.WriteFile(outputPath, []byte("# old\r\nmain.go @jpmcb\r\n"), 0600))
+
+		contents := generateWithLineEnding(t, outputPath, lineEndingAuto)
+		assert.Equal(t, strings.Count(contents, "\n"), strings.Count(contents, "\r\n"))
+	})
+
+	t.Run("auto defaults to lf", func(t *testing.T) {
+		contents := generateWithLineEnding(t, filepath.Join(t.TempDir(), "CODEOWNERS"), lineEndingAuto)
+		assert.NotContains(t, contents, "\r")
+	})
+}
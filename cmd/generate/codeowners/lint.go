@@ -0,0 +1,305 @@
+package codeowners
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+)
+
+// The supported formats of the lint findings
+const (
+	lintFormatText = "text"
+	lintFormatJSON = constants.OutputJSON
+)
+
+var lintFormats = []string{lintFormatText, lintFormatJSON}
+
+// The kinds of issues found when linting a CODEOWNERS file
+const (
+	lintDuplicatePattern   = "duplicate-pattern"
+	lintUnreachableRule    = "unreachable-rule"
+	lintUnescapedCharacter = "unescaped-character"
+	lintUnsupportedPattern = "unsupported-pattern"
+	lintInvalidOwner       = "invalid-owner"
+	lintUnknownOwner       = "unknown-owner"
+)
+
+// lintFinding is an issue found on a line of a CODEOWNERS file
+type lintFinding struct {
+	Line    int    `json:"line"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// lintRule is a rule parsed from a line of a CODEOWNERS file
+type lintRule struct {
+	line    int
+	pattern string
+	owners  []string
+}
+
+// lintCodeowners checks the contents of a GitHub CODEOWNERS file for common issues:
+// lines GitHub would reject or ignore, duplicate patterns, rules shadowed by a later
+// broader rule, and owners not listed in the config. The config owners are only
+// checked when the config lists any. The findings are sorted by line.
+func lintCodeowners(contents []byte, spec *config.Spec) []lintFinding {
+	var findings []lintFinding
+	var rules []lintRule
+
+	lines := strings.Split(string(bytes.ReplaceAll(contents, []byte("\r\n"), []byte("\n"))), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if err := validateGitHubCodeownersLine(line); err != nil {
+			findings = append(findings, lintFinding{Line: i + 1, Kind: lintValidationKind(err), Message: err.Error()})
+		}
+
+		fields := splitUnescaped(trimmed)
+		rule := lintRule{line: i + 1, pattern: fields[0]}
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+
+			rule.owners = append(rule.owners, owner)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	findings = append(findings, lintShadowedRules(rules)...)
+	findings = append(findings, lintUnknownOwners(rules, spec)...)
+
+	sortLintFindings(findings)
+	return findings
+}
+
+// lintValidationKind gets the kind of issue of a line GitHub would reject or ignore
+func lintValidationKind(err error) string {
+	switch {
+	case errors.Is(err, errUnescapedHash):
+		return lintUnescapedCharacter
+	case errors.Is(err, errInvalidOwner):
+		return lintInvalidOwner
+	default:
+		return lintUnsupportedPattern
+	}
+}
+
+// lintShadowedRules finds the rules which never apply since GitHub uses the last matching
+// rule: rules whose pattern is repeated later, and rules a later broader rule covers
+func lintShadowedRules(rules []lintRule) []lintFinding {
+	var findings []lintFinding
+
+	for i, rule := range rules {
+		for _, later := range rules[i+1:] {
+			if later.pattern == rule.pattern {
+				findings = append(findings, lintFinding{
+					Line:    rule.line,
+					Kind:    lintDuplicatePattern,
+					Message: fmt.Sprintf("pattern %s is repeated on line %d, which overrides its owners", rule.pattern, later.line),
+				})
+				break
+			}
+
+			if patternCovers(later.pattern, rule.pattern) {
+				findings = append(findings, lintFinding{
+					Line:    rule.line,
+					Kind:    lintUnreachableRule,
+					Message: fmt.Sprintf("pattern %s is shadowed by the broader pattern %s on line %d", rule.pattern, later.pattern, later.line),
+				})
+				break
+			}
+		}
+	}
+
+	return findings
+}
+
+// lintUnknownOwners finds the owners which aren't listed in the config: users and teams
+// which aren't attributed or referenced, and emails which aren't attributed
+func lintUnknownOwners(rules []lintRule, spec *config.Spec) []lintFinding {
+	known := configOwners(spec)
+	for _, emails := range spec.Attributions {
+		for _, email := range emails {
+			known[email] = nil
+		}
+	}
+
+	if len(known) == 0 {
+		return nil
+	}
+
+	var findings []lintFinding
+	for _, rule := range rules {
+		for _, owner := range rule.owners {
+			if !ownerPattern.MatchString(owner) {
+				// already reported as an invalid owner
+				continue
+			}
+
+			if _, ok := known[strings.TrimPrefix(owner, "@")]; !ok {
+				findings = append(findings, lintFinding{
+					Line:    rule.line,
+					Kind:    lintUnknownOwner,
+					Message: fmt.Sprintf("owner %s of pattern %s is not listed in the config", owner, rule.pattern),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// patternCovers conservatively reports whether the broad CODEOWNERS pattern matches every
+// file the narrow pattern matches. Glob patterns are only covered by a broad pattern
+// matching the directory before their first glob character.
+func patternCovers(broad, narrow string) bool {
+	switch broad {
+	case "*", "**", "/**":
+		return true
+	}
+
+	narrow = unescapePattern(narrow)
+	if i := strings.IndexAny(narrow, "*?["); i >= 0 {
+		dir := narrow[:strings.LastIndex(narrow[:i], "/")+1]
+		if dir == "" || dir == "/" {
+			return false
+		}
+
+		return codeownersPatternMatches(broad, strings.Trim(dir, "/"), true)
+	}
+
+	return codeownersPatternMatches(broad, strings.Trim(narrow, "/"), strings.HasSuffix(narrow, "/"))
+}
+
+// codeownersPatternMatches reports whether the CODEOWNERS pattern matches the path, and
+// everything beneath it when it's a directory. Like gitignore, a pattern with a slash
+// before its end is anchored to the root, otherwise it matches at any depth.
+func codeownersPatternMatches(pattern, filePath string, isDir bool) bool {
+	pattern = unescapePattern(pattern)
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	// a directory pattern matches everything beneath the directory, while a
+	// pattern ending in "/*" only matches the directory's direct contents
+	var globs []string
+	switch {
+	case strings.HasSuffix(pattern, "/"):
+		globs = []string{pattern + "**"}
+	case strings.HasSuffix(pattern, "/*"):
+		globs = []string{pattern}
+	default:
+		globs = []string{pattern, pattern + "/**"}
+	}
+
+	// the contents of a directory are represented by a placeholder name which
+	// only matches patterns matching everything in the directory
+	if isDir {
+		filePath += "/\x00"
+	}
+
+	candidates := []string{filePath}
+	if !anchored {
+		for i, c := range filePath {
+			if c == '/' {
+				candidates = append(candidates, filePath[i+1:])
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		for _, glob := range globs {
+			if matched, err := matchGlob(glob, candidate); err == nil && matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// unescapePattern removes the escapes from a CODEOWNERS pattern
+func unescapePattern(pattern string) string {
+	var sb strings.Builder
+
+	escaped := false
+	for _, c := range pattern {
+		if c == codeownersEscapeChar && !escaped {
+			escaped = true
+			continue
+		}
+
+		escaped = false
+		sb.WriteRune(c)
+	}
+
+	return sb.String()
+}
+
+// sortLintFindings sorts the findings by line, keeping the order of each line's findings
+func sortLintFindings(findings []lintFinding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+}
+
+// writeLintFindings writes the findings as "file:line: kind: message" lines,
+// or as a JSON array
+func writeLintFindings(w io.Writer, filename string, findings []lintFinding, format string) error {
+	if format == lintFormatJSON {
+		if findings == nil {
+			findings = []lintFinding{}
+		}
+
+		data, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling lint findings: %w", err)
+		}
+
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	for _, finding := range findings {
+		if _, err := fmt.Fprintf(w, "%s:%d: %s: %s\n", filename, finding.Line, finding.Kind, finding.Message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// errLintFindings is returned by lintFile when the linted file has any findings
+var errLintFindings = errors.New("found issues")
+
+// lintFile lints the CODEOWNERS file, writing its findings to w. errLintFindings is
+// returned when there are any findings.
+func lintFile(w io.Writer, filename string, spec *config.Spec, format string) error {
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("could not read %s: %w", filename, err)
+	}
+
+	findings := lintCodeowners(trimBOM(contents), spec)
+	if err := writeLintFindings(w, filename, findings, format); err != nil {
+		return err
+	}
+
+	if len(findings) > 0 {
+		return fmt.Errorf("%w: %d in %s", errLintFindings, len(findings), filename)
+	}
+
+	return nil
+}
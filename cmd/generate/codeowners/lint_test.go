@@ -0,0 +1,118 @@
+package codeowners
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+func TestLintCodeowners(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		contents := "# generated\n" +
+			"* @open-sauced/engineering\n" +
+			"/docs/ @jpmcb\n" +
+			"/docs/guide.md @brandonroberts # the guide\n"
+
+		assert.Empty(t, lintCodeowners([]byte(contents), &config.Spec{}))
+	})
+
+	t.Run("shadowed rules", func(t *testing.T) {
+		contents := "/docs/a.md @jpmcb\n" +
+			"*.go @brandonroberts\n" +
+			"/docs/ @jpmcb\n" +
+			"*.go @jpmcb\n" +
+			"/cmd/root.go @jpmcb\n" +
+			"* @open-sauced/engineering\n"
+
+		assert.Equal(t, []lintFinding{
+			{Line: 1, Kind: lintUnreachableRule, Message: "pattern /docs/a.md is shadowed by the broader pattern /docs/ on line 3"},
+			{Line: 2, Kind: lintDuplicatePattern, Message: "pattern *.go is repeated on line 4, which overrides its owners"},
+			{Line: 3, Kind: lintUnreachableRule, Message: "pattern /docs/ is shadowed by the broader pattern * on line 6"},
+			{Line: 4, Kind: lintUnreachableRule, Message: "pattern *.go is shadowed by the broader pattern * on line 6"},
+			{Line: 5, Kind: lintUnreachableRule, Message: "pattern /cmd/root.go is shadowed by the broader pattern * on line 6"},
+		}, lintCodeowners([]byte(contents), &config.Spec{}))
+	})
+
+	t.Run("invalid lines", func(t *testing.T) {
+		contents := "/docs/#notes.md @jpmcb\n" +
+			"!/docs/ @jpmcb\n" +
+			"/cmd/ jpmcb\n"
+
+		findings := lintCodeowners([]byte(contents), &config.Spec{})
+		require.Len(t, findings, 3)
+		assert.Equal(t, lintUnescapedCharacter, findings[0].Kind)
+		assert.Equal(t, lintUnsupportedPattern, findings[1].Kind)
+		assert.Equal(t, lintInvalidOwner, findings[2].Kind)
+	})
+
+	t.Run("unknown owners", func(t *testing.T) {
+		spec := &config.Spec{
+			Attributions:        map[string][]string{"jpmcb": {"john@opensauced.pizza"}},
+			AttributionFallback: []string{"open-sauced/engineering"},
+		}
+		contents := "* @open-sauced/engineering\n" +
+			"/cmd/ @jpmcb john@opensauced.pizza\n" +
+			"/docs/ @departed other@opensauced.pizza\n"
+
+		assert.Equal(t, []lintFinding{
+			{Line: 3, Kind: lintUnknownOwner, Message: "owner @departed of pattern /docs/ is not listed in the config"},
+			{Line: 3, Kind: lintUnknownOwner, Message: "owner other@opensauced.pizza of pattern /docs/ is not listed in the config"},
+		}, lintCodeowners([]byte(contents), spec))
+	})
+}
+
+func TestPatternCovers(t *testing.T) {
+	tests := []struct {
+		broad  string
+		narrow string
+		want   bool
+	}{
+		{"*", "/docs/", true},
+		{"/docs/", "/docs/api/index.md", true},
+		{"docs/", "/pkg/docs/index.md", true},
+		{"/docs/", "/pkg/docs/index.md", false},
+		{"/docs/", "/docs/**/*.md", true},
+		{"*.md", "/docs/index.md", true},
+		{"*.md", "/docs/", false},
+		{"/docs/a.md", "/docs/", false},
+		{"/docs/*", "/docs/api/", false},
+		{"/docs/**", "/docs/api/", true},
+		{"/docs/", "*.md", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, patternCovers(tt.broad, tt.narrow), "%s covers %s", tt.broad, tt.narrow)
+	}
+}
+
+func TestLintFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "CODEOWNERS")
+	require.NoError(t, os.WriteFile(filename, []byte("*.go @jpmcb\n*.go @brandonroberts\n"), 0o600))
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.ErrorIs(t, lintFile(&buf, filename, &config.Spec{}, lintFormatText), errLintFindings)
+		assert.Equal(t, filename+":1: duplicate-pattern: pattern *.go is repeated on line 2, which overrides its owners\n", buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.Error(t, lintFile(&buf, filename, &config.Spec{}, lintFormatJSON))
+		assert.JSONEq(t, `[{"line": 1, "kind": "duplicate-pattern", "message": "pattern *.go is repeated on line 2, which overrides its owners"}]`, buf.String())
+	})
+
+	t.Run("no findings", func(t *testing.T) {
+		clean := filepath.Join(t.TempDir(), "CODEOWNERS")
+		require.NoError(t, os.WriteFile(clean, []byte("* @jpmcb\n"), 0o600))
+
+		var buf bytes.Buffer
+		require.NoError(t, lintFile(&buf, clean, &config.Spec{}, lintFormatJSON))
+		assert.Equal(t, "[]\n", buf.String())
+	})
+}
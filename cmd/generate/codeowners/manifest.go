@@ -0,0 +1,233 @@
+package codeowners
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// manifestRootMarker marks the root of a directory analyzed from a contributions
+// manifest, which has no ".git" directory to mark it
+const manifestRootMarker = ".sauced.yaml"
+
+// The columns of a CSV contributions manifest
+const (
+	manifestColumnFile    = "file"
+	manifestColumnName    = "name"
+	manifestColumnEmail   = "email"
+	manifestColumnLines   = "lines"
+	manifestColumnCommits = "commits"
+)
+
+var manifestColumns = []string{manifestColumnFile, manifestColumnName, manifestColumnEmail, manifestColumnLines, manifestColumnCommits}
+
+// manifestRecord is a single entry of a contributions manifest: the number of lines
+// of a file changed by an author, and optionally the number of commits it took.
+// Example JSON: { "file": "src/main.go", "name": "Jane", "email": "jane@example.com", "lines": 120, "commits": 4 }
+type manifestRecord struct {
+	File    string `json:"file"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Lines   int    `json:"lines"`
+	Commits int    `json:"commits"`
+}
+
+// manifestHistory implements HistoryProvider by reading the contributions of each
+// author from a manifest instead of walking the git log. This supports code vendored
+// without its git metadata. The manifest is either a CSV file with a header row of
+// "file,name,email,lines,commits" or a JSON array of records with the same fields.
+// The "name" and "commits" are optional.
+//
+// The files are filtered the same way as the files of the git log. There's no
+// history to look back over, so the number of days analyzed doesn't apply.
+type manifestHistory struct {
+	manifestPath string
+	po           ProcessOptions
+}
+
+// FileStats reads the stats of each file from the manifest. The manifest's files are
+// relative to the analyzed directory, so the path and ref are unused.
+func (mh manifestHistory) FileStats(_, _ string) (FileStats, error) {
+	records, err := readManifest(mh.manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := make(FileStats)
+	for _, record := range records {
+		if !mh.po.matcher.matches(record.File) || !mh.po.analyzes(record.File) {
+			continue
+		}
+
+		identity := &object.Signature{Name: record.Name, Email: record.Email}
+		if !mh.po.isAllowedAuthor(identity) {
+			fs.addFile(record.File)
+			continue
+		}
+
+		fs.addContribution(record.File, identity, record.Lines, record.Commits)
+	}
+
+	return fs, nil
+}
+
+// readManifest reads and validates the records of a CSV or JSON contributions manifest
+func readManifest(manifestPath string) ([]manifestRecord, error) {
+	var parse func(io.Reader) ([]manifestRecord, error)
+	switch strings.ToLower(filepath.Ext(manifestPath)) {
+	case ".csv":
+		parse = parseCSVManifest
+	case ".json":
+		parse = parseJSONManifest
+	default:
+		return nil, fmt.Errorf("unsupported manifest %s, must be a .csv or .json file", manifestPath)
+	}
+
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open manifest: %w", err)
+	}
+	defer file.Close()
+
+	records, err := parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", manifestPath, err)
+	}
+
+	return records, nil
+}
+
+// parseJSONManifest parses and validates a JSON array of manifest records.
+// Unknown fields are rejected to catch typos.
+func parseJSONManifest(r io.Reader) ([]manifestRecord, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var records []manifestRecord
+	if err := decoder.Decode(&records); err != nil {
+		return nil, err
+	}
+
+	for i := range records {
+		if err := records[i].validate(); err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+	}
+
+	return records, nil
+}
+
+// parseCSVManifest parses and validates a CSV manifest. The header row names the columns,
+// in any order. The "file", "email", and "lines" columns are required.
+func parseCSVManifest(r io.Reader) ([]manifestRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("missing header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !slices.Contains(manifestColumns, column) {
+			return nil, fmt.Errorf("unknown column %q, must be one of: %s", column, strings.Join(manifestColumns, ", "))
+		}
+		if _, ok := columns[column]; ok {
+			return nil, fmt.Errorf("duplicate column %q", column)
+		}
+
+		columns[column] = i
+	}
+
+	for _, required := range []string{manifestColumnFile, manifestColumnEmail, manifestColumnLines} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing required column %q", required)
+		}
+	}
+
+	var records []manifestRecord
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(column string) string {
+			i, ok := columns[column]
+			if !ok {
+				return ""
+			}
+
+			return strings.TrimSpace(row[i])
+		}
+
+		record := manifestRecord{
+			File:  field(manifestColumnFile),
+			Name:  field(manifestColumnName),
+			Email: field(manifestColumnEmail),
+		}
+
+		record.Lines, err = strconv.Atoi(field(manifestColumnLines))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid lines %q", line, field(manifestColumnLines))
+		}
+
+		if commits := field(manifestColumnCommits); commits != "" {
+			record.Commits, err = strconv.Atoi(commits)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid commits %q", line, commits)
+			}
+		}
+
+		if err := record.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// validate checks the record is complete and cleans its file into a slash separated
+// path relative to the analyzed directory. Example: "./src//main.go" is "src/main.go"
+func (mr *manifestRecord) validate() error {
+	if mr.File == "" {
+		return errors.New("missing file")
+	}
+
+	file := path.Clean(filepath.ToSlash(mr.File))
+	if path.IsAbs(file) || file == "." || file == ".." || strings.HasPrefix(file, "../") {
+		return fmt.Errorf("file %q must be relative to the analyzed directory", mr.File)
+	}
+	mr.File = file
+
+	if mr.Email == "" {
+		return fmt.Errorf("missing email for file %q", mr.File)
+	}
+
+	if mr.Lines < 0 || mr.Commits < 0 {
+		return fmt.Errorf("negative lines or commits for file %q", mr.File)
+	}
+
+	return nil
+}
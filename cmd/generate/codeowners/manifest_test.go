@@ -0,0 +1,148 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
+)
+
+func TestParseCSVManifest(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		records, err := parseCSVManifest(strings.NewReader("email,file,lines,commits,name\n" +
+			"jane@example.com,./src//main.go,120,4,Jane\n" +
+			"john@example.com,README.md,10,,John\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []manifestRecord{
+			{File: "src/main.go", Name: "Jane", Email: "jane@example.com", Lines: 120, Commits: 4},
+			{File: "README.md", Name: "John", Email: "john@example.com", Lines: 10},
+		}, records)
+	})
+
+	tests := []struct {
+		name     string
+		manifest string
+		err      string
+	}{
+		{"empty", "", "missing header row"},
+		{"unknown column", "file,email,lines,author\n", `unknown column "author"`},
+		{"duplicate column", "file,email,lines,lines\n", `duplicate column "lines"`},
+		{"missing column", "file,email\n", `missing required column "lines"`},
+		{"invalid lines", "file,email,lines\nmain.go,jane@example.com,many\n", `line 2: invalid lines "many"`},
+		{"invalid commits", "file,email,lines,commits\nmain.go,jane@example.com,1,x\n", `line 2: invalid commits "x"`},
+		{"missing email", "file,email,lines\nmain.go,,1\n", "line 2: missing email"},
+		{"outside the directory", "file,email,lines\n../main.go,jane@example.com,1\n", "must be relative"},
+		{"negative lines", "file,email,lines\nmain.go,jane@example.com,-1\n", "negative lines"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCSVManifest(strings.NewReader(tt.manifest))
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseJSONManifest(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		records, err := parseJSONManifest(strings.NewReader(`[{"file": "main.go", "email": "jane@example.com", "lines": 3}]`))
+		require.NoError(t, err)
+		assert.Equal(t, []manifestRecord{{File: "main.go", Email: "jane@example.com", Lines: 3}}, records)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := parseJSONManifest(strings.NewReader(`[{"file": "main.go", "email": "jane@example.com", "line": 3}]`))
+		require.ErrorContains(t, err, `unknown field "line"`)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := parseJSONManifest(strings.NewReader(`[{"email": "jane@example.com", "lines": 3}]`))
+		require.ErrorContains(t, err, "record 1: missing file")
+	})
+}
+
+func TestManifestHistory(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "contributions.csv")
+	require.NoError(t, os.WriteFile(manifestPath, []byte("file,name,email,lines,commits\n"+
+		"main.go,Jane,jane@example.com,100,2\n"+
+		"main.go,Jane,jane@example.com,20,1\n"+
+		"main.go,Bot,bot@example.com,500,9\n"+
+		"docs/guide.md,John,john@example.com,10,1\n"), 0600))
+
+	t.Run("stats", func(t *testing.T) {
+		mh := manifestHistory{manifestPath: manifestPath, po: ProcessOptions{allowedAuthors: []string{"*@example.com"}}}
+
+		fileStats, err := mh.FileStats("", "")
+		require.NoError(t, err)
+		require.Len(t, fileStats, 2)
+
+		jane := fileStats["main.go"]["Jane <jane@example.com>"]
+		require.NotNil(t, jane)
+		assert.Equal(t, 120, jane.Lines)
+		assert.Equal(t, 3, jane.Commits)
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		mh := manifestHistory{manifestPath: manifestPath, po: ProcessOptions{
+			extensionFilter: &extensionFilter{exclude: map[string]struct{}{".md": {}}},
+			allowedAuthors:  []string{"jane@*"},
+		}}
+
+		fileStats, err := mh.FileStats("", "")
+		require.NoError(t, err)
+		require.Len(t, fileStats, 1)
+		assert.Len(t, fileStats["main.go"], 1)
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		mh := manifestHistory{manifestPath: filepath.Join(t.TempDir(), "contributions.txt")}
+
+		_, err := mh.FileStats("", "")
+		require.ErrorContains(t, err, "must be a .csv or .json file")
+	})
+}
+
+func TestRunFromManifest(t *testing.T) {
+	// the directory isn't a git repository
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "contributions.json")
+	require.NoError(t, os.WriteFile(manifestPath, []byte(`[
+		{"file": "lib/main.go", "name": "John", "email": "john@opensauced.pizza", "lines": 10, "commits": 1}
+	]`), 0600))
+
+	opts := &Options{
+		path:         dir,
+		outputPath:   dir,
+		format:       formatGitHub,
+		maxOwners:    3,
+		maxDepth:     -1,
+		manifestPath: manifestPath,
+		telemetry:    utils.NewPosthogCliClient(false),
+		config: &config.Spec{
+			Attributions: map[string][]string{"jpmcb": {"john@opensauced.pizza"}},
+		},
+	}
+
+	require.NoError(t, run(opts, &cobra.Command{}))
+
+	contents, err := os.ReadFile(filepath.Join(dir, "CODEOWNERS"))
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "lib/main.go @jpmcb\n")
+}
+
+func TestResolvePathArgsRootMarker(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, manifestRootMarker), []byte{}, 0600))
+
+	path, patterns, err := resolvePathArgs([]string{filepath.Join(root, "src", "*.go")}, manifestRootMarker)
+	require.NoError(t, err)
+	assert.Equal(t, root, path)
+	assert.Equal(t, []string{"src/*.go"}, patterns)
+}
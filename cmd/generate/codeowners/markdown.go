@@ -0,0 +1,106 @@
+package codeowners
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// markdownSummaryHeader is the heading and introduction of the Markdown summary
+const markdownSummaryHeader = `# Code owners
+
+The owners of each path in this repository, generated with ` + "`pizza generate codeowners`" + `.
+`
+
+// markdownSummaryRow is a rule of the Markdown summary with its owners
+type markdownSummaryRow struct {
+	rule   string
+	owners []string
+}
+
+// buildMarkdownSummary attributes owners to every rule, the same as the other formats, for
+// a Markdown summary of the attributions. Group references are expanded to their members.
+// The rules are sorted the same as the output file, each directory before its contents.
+func buildMarkdownSummary(fileStats FileStats, opts *Options) ([]markdownSummaryRow, error) {
+	if opts.granularity == granularityDirectory {
+		fileStats = fileStats.aggregateDirectories(opts)
+	}
+
+	rules := make([]string, 0, len(fileStats))
+	for rule := range fileStats {
+		rules = append(rules, rule)
+	}
+	sortRules(rules)
+
+	rows := make([]markdownSummaryRow, 0, len(rules))
+	for _, rule := range rules {
+		owners, err := expandOwnerGroups(getOwners(rule, fileStats[rule], opts), opts.config)
+		if err != nil {
+			return nil, fmt.Errorf("error expanding the owners of %s: %w", rule, err)
+		}
+
+		row := markdownSummaryRow{rule: rule}
+		for _, owner := range sortOwners(owners, opts) {
+			row.owners = append(row.owners, withoutMention("@"+owner.GitHubAlias, opts))
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// writeMarkdownSummary writes the rules and their owners as a Markdown table. Example:
+//
+//	| Path | Owners |
+//	| --- | --- |
+//	| `cmd/` | @jpmcb, @brandonroberts |
+func writeMarkdownSummary(w io.Writer, rows []markdownSummaryRow) error {
+	if _, err := fmt.Fprintf(w, "%s\n| Path | Owners |\n| --- | --- |\n", markdownSummaryHeader); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		owners := "_No owners_"
+		if len(row.owners) > 0 {
+			owners = markdownTableCell(strings.Join(row.owners, ", "))
+		}
+
+		if _, err := fmt.Fprintf(w, "| `%s` | %s |\n", markdownTableCell(row.rule), owners); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// markdownTableCell escapes the pipes of a table cell's text, which would end the cell.
+// GitHub unescapes them within code spans too.
+func markdownTableCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
+
+// generateMarkdownSummary writes the Markdown summary of the attributions to its own file,
+// alongside the output file, for --markdown-summary
+func generateMarkdownSummary(fileStats FileStats, outputPath string, opts *Options) error {
+	stopAttribute := opts.profiler.phase(phaseAttribute)
+	rows, err := buildMarkdownSummary(fileStats, opts)
+	stopAttribute()
+	if err != nil {
+		return err
+	}
+
+	defer opts.profiler.phase(phaseWrite)()
+
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := writeMarkdownSummary(file, rows); err != nil {
+		return fmt.Errorf("error writing to %s file: %w", outputPath, err)
+	}
+
+	return nil
+}
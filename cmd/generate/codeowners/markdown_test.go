@@ -0,0 +1,81 @@
+package codeowners
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMarkdownSummary(t *testing.T) {
+	t.Run("files", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.granularity = granularityFile
+
+		rows, err := buildMarkdownSummary(fileStats, opts)
+		require.NoError(t, err)
+		assert.Equal(t, []markdownSummaryRow{
+			{rule: "docs/README.md", owners: []string{"@brandonroberts", "@jpmcb"}},
+			{rule: "docs/api/index.md", owners: []string{"@jpmcb"}},
+			{rule: "docs/guide.md", owners: []string{"@jpmcb"}},
+			{rule: "main.go", owners: []string{"@brandonroberts"}},
+		}, rows)
+	})
+
+	t.Run("directories", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+
+		rows, err := buildMarkdownSummary(fileStats, opts)
+		require.NoError(t, err)
+		assert.Equal(t, []markdownSummaryRow{
+			{rule: rootDirectoryRule, owners: []string{"@brandonroberts"}},
+			{rule: "docs/", owners: []string{"@brandonroberts", "@jpmcb"}},
+			{rule: "docs/api/", owners: []string{"@jpmcb"}},
+		}, rows)
+	})
+
+	t.Run("alphabetical owners without mentions", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		fileStats["docs/README.md"]["john"].Lines = 50
+		opts.ownerSort = ownerSortAlpha
+		opts.noMention = true
+
+		rows, err := buildMarkdownSummary(fileStats, opts)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"brandonroberts", "jpmcb"}, rows[1].owners)
+	})
+}
+
+func TestWriteMarkdownSummary(t *testing.T) {
+	rows := []markdownSummaryRow{
+		{rule: "docs/", owners: []string{"@brandonroberts", "@jpmcb"}},
+		{rule: "scripts/a|b.sh", owners: []string{"@open-sauced/engineering"}},
+		{rule: "vendor/"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeMarkdownSummary(&buf, rows))
+	assert.Equal(t, "# Code owners\n"+
+		"\n"+
+		"The owners of each path in this repository, generated with `pizza generate codeowners`.\n"+
+		"\n"+
+		"| Path | Owners |\n"+
+		"| --- | --- |\n"+
+		"| `docs/` | @brandonroberts, @jpmcb |\n"+
+		"| `scripts/a\\|b.sh` | @open-sauced/engineering |\n"+
+		"| `vendor/` | _No owners_ |\n", buf.String())
+}
+
+func TestGenerateMarkdownSummary(t *testing.T) {
+	fileStats, opts := newGranularityTestData()
+	outputPath := filepath.Join(t.TempDir(), "OWNERS.md")
+
+	require.NoError(t, generateMarkdownSummary(fileStats, outputPath, opts))
+
+	contents, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "| `docs/` | @brandonroberts, @jpmcb |\n")
+}
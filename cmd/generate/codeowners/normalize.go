@@ -0,0 +1,46 @@
+package codeowners
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// normalizePath collapses a path into the clean, slash separated form CODEOWNERS rules
+// match: "./" and ".." segments and redundant separators are removed, and on Windows,
+// backslashes become forward slashes. A directory's trailing slash is kept.
+// Example: "./src//pkg/../main.go" is "src/main.go"
+func normalizePath(name string) string {
+	if name == rootDirectoryRule {
+		return name
+	}
+
+	slashed := filepath.ToSlash(name)
+	normalized := strings.TrimPrefix(path.Clean(slashed), "/")
+	if normalized == "." {
+		return rootDirectoryRule
+	}
+
+	if strings.HasSuffix(slashed, "/") {
+		normalized += "/"
+	}
+
+	return normalized
+}
+
+// normalizePaths normalizes each filename with normalizePath. The stats of
+// filenames which normalize to the same path are merged.
+func (fs FileStats) normalizePaths() FileStats {
+	normalized := make(FileStats, len(fs))
+
+	for filename, authorStats := range fs {
+		name := normalizePath(filename)
+		if _, ok := normalized[name]; !ok {
+			normalized[name] = make(AuthorStats)
+		}
+
+		normalized[name].merge(authorStats)
+	}
+
+	return normalized
+}
@@ -0,0 +1,55 @@
+package codeowners
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"src/main.go", "src/main.go"},
+		{"./src//main.go", "src/main.go"},
+		{"src/pkg/../main.go", "src/main.go"},
+		{"/src/main.go", "src/main.go"},
+		{"./docs//", "docs/"},
+		{"./", rootDirectoryRule},
+		{rootDirectoryRule, rootDirectoryRule},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, normalizePath(tt.input), tt.input)
+	}
+
+	t.Run("backslashes", func(t *testing.T) {
+		// backslashes only separate paths on Windows: elsewhere they're part of the filename
+		expected := `src\pkg\main.go`
+		if runtime.GOOS == "windows" {
+			expected = "src/pkg/main.go"
+		}
+
+		assert.Equal(t, expected, normalizePath(`src\pkg\main.go`))
+	})
+}
+
+func TestNormalizePaths(t *testing.T) {
+	fileStats := FileStats{
+		"src/main.go":    {"brandon": {Email: "brandon@opensauced.pizza", Lines: 10, Commits: 1}},
+		"./src//main.go": {"brandon": {Email: "brandon@opensauced.pizza", Lines: 5, Commits: 1}},
+		"./README.md":    {},
+	}
+
+	normalized := fileStats.normalizePaths()
+	require.Len(t, normalized, 2)
+	assert.Contains(t, normalized, "README.md")
+
+	brandon := normalized["src/main.go"]["brandon"]
+	require.NotNil(t, brandon)
+	assert.Equal(t, 15, brandon.Lines)
+	assert.Equal(t, 2, brandon.Commits)
+}
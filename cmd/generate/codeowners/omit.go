@@ -0,0 +1,65 @@
+package codeowners
+
+import (
+	"path"
+)
+
+// omitFallbackOnlyRules removes the rules whose owners are only the fallback owners when a
+// broader rule written before them already gives the file the same owners: the rule of the
+// nearest ancestor directory, or the "*" catch-all rule. The catch-all rule is added with the
+// fallback owners when it's missing, so omitted files keep their owners on GitHub, where the
+// last matching rule wins. The rules must be sorted, and are returned in the same order.
+func omitFallbackOnlyRules(rules []string, owners map[string]AuthorStatSlice, opts *Options) ([]string, error) {
+	if _, ok := owners[rootDirectoryRule]; !ok {
+		catchAll, err := expandOwnerGroups(getOwners(rootDirectoryRule, AuthorStats{}, opts), opts.config)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(catchAll) > 0 {
+			owners[rootDirectoryRule] = catchAll
+			rules = append([]string{rootDirectoryRule}, rules...)
+		}
+	}
+
+	kept := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		if rule != rootDirectoryRule && len(owners[rule]) > 0 && isFallbackOnly(owners[rule]) {
+			if covering, ok := coveringRule(rule, owners); ok && sameOwners(owners[rule], owners[covering]) {
+				continue
+			}
+		}
+
+		kept = append(kept, rule)
+	}
+
+	return kept, nil
+}
+
+// coveringRule gets the rule of the nearest ancestor directory of a rule, falling back
+// to the "*" catch-all rule, which applies to the rule's files when it's left out
+func coveringRule(rule string, owners map[string]AuthorStatSlice) (string, bool) {
+	for dir := ancestorDir(rule); dir != "."; dir = path.Dir(dir) {
+		if _, ok := owners[dir+"/"]; ok {
+			return dir + "/", true
+		}
+	}
+
+	_, ok := owners[rootDirectoryRule]
+	return rootDirectoryRule, ok
+}
+
+// sameOwners returns true if both rules have the same owners in the same order
+func sameOwners(a, b AuthorStatSlice) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].GitHubAlias != b[i].GitHubAlias {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,75 @@
+package codeowners
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOmitFallbackOnly(t *testing.T) {
+	newTestData := func() (FileStats, *Options) {
+		fileStats, opts := newGranularityTestData()
+		opts.omitFallbackOnly = true
+		opts.config.AttributionFallback = []string{"open-sauced/engineering"}
+
+		unattributed := AuthorStats{"unknown": {Email: "unknown@opensauced.pizza", Lines: 1, Commits: 1}}
+		fileStats["scripts/build.sh"] = unattributed
+		fileStats["docs/api/generated.md"] = unattributed
+
+		return fileStats, opts
+	}
+
+	t.Run("files", func(t *testing.T) {
+		fileStats, opts := newTestData()
+		opts.granularity = granularityFile
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+		assert.Equal(t, "* @open-sauced/engineering\n"+
+			"/docs/README.md @brandonroberts @jpmcb\n"+
+			"/docs/api/index.md @jpmcb\n"+
+			"/docs/guide.md @jpmcb\n"+
+			"/main.go @brandonroberts\n", buf.String())
+	})
+
+	t.Run("directories", func(t *testing.T) {
+		fileStats, opts := newTestData()
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+
+		// the scripts directory is covered by the catch-all, but the root directory
+		// has its own owners, so it still needs its rule
+		assert.Equal(t, "* @brandonroberts\n"+
+			"/docs/ @brandonroberts @jpmcb\n"+
+			"/docs/api/ @jpmcb\n"+
+			"/scripts/ @open-sauced/engineering\n", buf.String())
+	})
+
+	t.Run("covered by an ancestor directory", func(t *testing.T) {
+		_, opts := newTestData()
+		owners := map[string]AuthorStatSlice{
+			rootDirectoryRule: {{GitHubAlias: "brandonroberts"}},
+			"docs/":           {{GitHubAlias: "open-sauced/engineering", Source: ownerSourceFallback}},
+			"docs/api/":       {{GitHubAlias: "open-sauced/engineering", Source: ownerSourceFallback}},
+			"scripts/":        {{GitHubAlias: "open-sauced/engineering", Source: ownerSourceFallback}},
+		}
+
+		rules, err := omitFallbackOnlyRules([]string{rootDirectoryRule, "docs/", "docs/api/", "scripts/"}, owners, opts)
+		require.NoError(t, err)
+		assert.Equal(t, []string{rootDirectoryRule, "docs/", "scripts/"}, rules)
+	})
+
+	t.Run("without a fallback", func(t *testing.T) {
+		fileStats, opts := newTestData()
+		opts.granularity = granularityFile
+		opts.config.AttributionFallback = nil
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+		assert.NotContains(t, buf.String(), "* ")
+		assert.Contains(t, buf.String(), "scripts/build.sh\n")
+	})
+}
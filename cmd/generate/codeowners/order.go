@@ -0,0 +1,91 @@
+package codeowners
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+// rulePattern gets the pattern a rule is written as in the configured format
+func rulePattern(rule string, opts *Options) string {
+	// the rules of --patterns-from are already patterns
+	if opts.sourcePatterns != nil {
+		return rule
+	}
+
+	switch opts.format {
+	case formatOwners:
+		return rule
+	case formatGitea:
+		if opts.config.GiteaPatternStyle == config.GiteaPatternStyleRegex {
+			return giteaRegexRule(rule)
+		}
+	}
+
+	return cleanRule(rule)
+}
+
+// readExistingPatterns reads the pattern of each rule in an existing output file, in order.
+// Comments, blank lines, and the indented owners of an "OWNERS" style file are skipped.
+// A missing file has no patterns.
+func readExistingPatterns(outputPath string, opts *Options) ([]string, error) {
+	contents, err := os.ReadFile(outputPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read existing output file %s: %w", outputPath, err)
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(string(bytes.ReplaceAll(trimBOM(contents), []byte("\r\n"), []byte("\n"))), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+
+		// the filename of an "OWNERS" style file is the whole line, which may include spaces
+		if opts.format == formatOwners {
+			pattern, _, _ := strings.Cut(line, " # source: ")
+			patterns = append(patterns, pattern)
+			continue
+		}
+
+		patterns = append(patterns, splitUnescaped(line)[0])
+	}
+
+	return patterns, nil
+}
+
+// preserveRuleOrder orders the rules to follow the existing patterns so that
+// regenerating a file only changes the lines whose owners changed. Rules which
+// still apply keep their existing order, obsolete rules are dropped, and new
+// rules are appended in their sorted order.
+func preserveRuleOrder(rules []string, existingPatterns []string, opts *Options) []string {
+	byPattern := make(map[string]string, len(rules))
+	for _, rule := range rules {
+		byPattern[rulePattern(rule, opts)] = rule
+	}
+
+	ordered := make([]string, 0, len(rules))
+	placed := make(map[string]bool, len(rules))
+	for _, pattern := range existingPatterns {
+		rule, ok := byPattern[pattern]
+		if !ok || placed[rule] {
+			continue
+		}
+
+		ordered = append(ordered, rule)
+		placed[rule] = true
+	}
+
+	for _, rule := range rules {
+		if !placed[rule] {
+			ordered = append(ordered, rule)
+		}
+	}
+
+	return ordered
+}
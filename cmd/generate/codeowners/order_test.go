@@ -0,0 +1,103 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generatePreservingOrder(t *testing.T, fileStats FileStats, opts *Options, outputPath string) []string {
+	t.Helper()
+
+	require.NoError(t, generateOutputFile(fileStats, outputPath, opts, &cobra.Command{}))
+
+	contents, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var rules []string
+	for _, line := range strings.Split(string(contents), "\n") {
+		if line != "" && !strings.HasPrefix(line, "#") {
+			rules = append(rules, line)
+		}
+	}
+
+	return rules
+}
+
+func TestPreserveOrder(t *testing.T) {
+	t.Run("only the changed rule differs", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "CODEOWNERS")
+		require.NoError(t, os.WriteFile(outputPath, []byte("# hand ordered\n/main.go @jpmcb\n/docs/guide.md @jpmcb\n/docs/api/index.md @jpmcb\n/docs/README.md @brandonroberts @jpmcb\n"), 0600))
+
+		fileStats, opts := newGranularityTestData()
+		opts.granularity = granularityFile
+		opts.preserveOrder = true
+
+		rules := generatePreservingOrder(t, fileStats, opts, outputPath)
+		assert.Equal(t, []string{
+			"/main.go @brandonroberts",
+			"/docs/guide.md @jpmcb",
+			"/docs/api/index.md @jpmcb",
+			"/docs/README.md @brandonroberts @jpmcb",
+		}, rules)
+	})
+
+	t.Run("obsolete rules are removed and new rules appended", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "CODEOWNERS")
+		require.NoError(t, os.WriteFile(outputPath, []byte("/docs/guide.md @jpmcb\n/removed.go @jpmcb\n/main.go @brandonroberts\n"), 0600))
+
+		fileStats, opts := newGranularityTestData()
+		opts.granularity = granularityFile
+		opts.preserveOrder = true
+
+		rules := generatePreservingOrder(t, fileStats, opts, outputPath)
+		assert.Equal(t, []string{
+			"/docs/guide.md @jpmcb",
+			"/main.go @brandonroberts",
+			"/docs/README.md @brandonroberts @jpmcb",
+			"/docs/api/index.md @jpmcb",
+		}, rules)
+	})
+
+	t.Run("missing file is sorted", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.granularity = granularityFile
+		opts.preserveOrder = true
+
+		rules := generatePreservingOrder(t, fileStats, opts, filepath.Join(t.TempDir(), "CODEOWNERS"))
+		assert.Equal(t, []string{
+			"/docs/README.md @brandonroberts @jpmcb",
+			"/docs/api/index.md @jpmcb",
+			"/docs/guide.md @jpmcb",
+			"/main.go @brandonroberts",
+		}, rules)
+	})
+}
+
+func TestReadExistingPatterns(t *testing.T) {
+	t.Run("codeowners", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "CODEOWNERS")
+		require.NoError(t, os.WriteFile(outputPath, []byte("# header\r\n\r\nsrc/my\\ file.go @jpmcb # source: override\r\nmain.go\r\n"), 0600))
+
+		_, opts := newGranularityTestData()
+		patterns, err := readExistingPatterns(outputPath, opts)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"src/my\\ file.go", "main.go"}, patterns)
+	})
+
+	t.Run("owners", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "OWNERS")
+		require.NoError(t, os.WriteFile(outputPath, []byte("src/my file.go # source: override\n  - John\n    - john@opensauced.pizza\nmain.go\n"), 0600))
+
+		_, opts := newGranularityTestData()
+		opts.format = formatOwners
+		patterns, err := readExistingPatterns(outputPath, opts)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"src/my file.go", "main.go"}, patterns)
+	})
+}
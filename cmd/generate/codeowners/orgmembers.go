@@ -0,0 +1,232 @@
+package codeowners
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jpmcb/gopherlogs/pkg/colors"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+)
+
+// noreplyLoginPattern matches the login in a GitHub noreply email:
+// "login@users.noreply.github.com" or "123+login@users.noreply.github.com"
+var noreplyLoginPattern = regexp.MustCompile(`(?i)^(?:\d+\+)?([a-z\d](?:[a-z\d-]*[a-z\d])?)@users\.noreply\.github\.com$`)
+
+// orgMembersPageSize is the number of org members requested per page, GitHub's maximum
+const orgMembersPageSize = 100
+
+// githubUser is a user returned by the GitHub API
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+// githubUserSearch is the response of the GitHub user search API
+type githubUserSearch struct {
+	Items []githubUser `json:"items"`
+}
+
+// orgMemberResolver resolves commit emails to the logins of a GitHub org's members.
+// The members are fetched once, and each email is looked up once.
+type orgMemberResolver struct {
+	client *githubClient
+	org    string
+
+	// members maps the lowercased login of each member to their login
+	members map[string]string
+
+	// logins caches the login of each email looked up, "" when it has none
+	logins map[string]string
+
+	// rateLimited is set once the user search is rate limited, after which the
+	// remaining emails are skipped instead of searched for. skipped counts them.
+	rateLimited bool
+	skipped     int
+}
+
+func newOrgMemberResolver(client *githubClient, org string) *orgMemberResolver {
+	return &orgMemberResolver{
+		client: client,
+		org:    org,
+		logins: make(map[string]string),
+	}
+}
+
+// loadMembers fetches every member of the org. Without a token, only
+// the public members can be listed.
+func (r *orgMemberResolver) loadMembers() error {
+	membersPath := "/orgs/" + url.PathEscape(r.org) + "/members"
+	if r.client.token == "" {
+		membersPath = "/orgs/" + url.PathEscape(r.org) + "/public_members"
+	}
+
+	r.members = make(map[string]string)
+	for page := 1; ; page++ {
+		var users []githubUser
+		found, err := r.client.get(membersPath+"?per_page="+strconv.Itoa(orgMembersPageSize)+"&page="+strconv.Itoa(page), &users)
+		if err != nil {
+			return fmt.Errorf("could not list the members of %s: %w", r.org, err)
+		}
+		if !found {
+			return fmt.Errorf("could not list the members of %s: the org was not found", r.org)
+		}
+
+		for _, user := range users {
+			r.members[strings.ToLower(user.Login)] = user.Login
+		}
+
+		if len(users) < orgMembersPageSize {
+			return nil
+		}
+	}
+}
+
+// resolve gets the login of the org member with the email. The login is taken from
+// GitHub noreply emails, otherwise the email is searched for, which needs a token.
+func (r *orgMemberResolver) resolve(email string) (string, bool, error) {
+	if r.members == nil {
+		if err := r.loadMembers(); err != nil {
+			return "", false, err
+		}
+	}
+
+	login, ok := r.logins[email]
+	if !ok {
+		var err error
+		login, err = r.lookupLogin(email)
+		if err != nil {
+			return "", false, err
+		}
+
+		r.logins[email] = login
+	}
+
+	member, ok := r.members[strings.ToLower(login)]
+	return member, ok && login != "", nil
+}
+
+// lookupLogin gets the login of the user with the email, or "" when it's not known
+func (r *orgMemberResolver) lookupLogin(email string) (string, error) {
+	if match := noreplyLoginPattern.FindStringSubmatch(email); match != nil {
+		return match[1], nil
+	}
+
+	// the search API only finds users by their public email without a token
+	if r.client.token == "" {
+		return "", nil
+	}
+
+	// the search API has a low rate limit, so the rest of the emails are left unattributed once it's reached
+	if r.rateLimited {
+		r.skipped++
+		return "", nil
+	}
+
+	var search githubUserSearch
+	_, err := r.client.get("/search/users?q="+url.QueryEscape(email+" in:email"), &search)
+	if errors.Is(err, errRateLimited) {
+		r.rateLimited = true
+		r.skipped++
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not look up %s: %w", email, err)
+	}
+
+	// an email matching several users is ambiguous
+	if len(search.Items) != 1 {
+		return "", nil
+	}
+
+	return search.Items[0].Login, nil
+}
+
+// newOrgMemberResolverFromEnv creates the resolver of the org's members with the GitHub
+// token in the environment. Without a token, only public members and noreply emails are resolved.
+func newOrgMemberResolverFromEnv(org string, opts *Options) *orgMemberResolver {
+	token := githubToken()
+	if token == "" {
+		opts.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("No GitHub token in %s, only the public members of %s are attributed by their noreply emails\n", strings.Join(githubTokenEnvs, " or "), org)
+	}
+
+	return newOrgMemberResolver(newGitHubClient(&http.Client{Timeout: githubAPITimeout}, githubAPIEndpoint, token), org)
+}
+
+// applyOrgMembers attributes the unattributed contributors of the files to their
+// login when they're members of the org set with --attribute-org-members
+func applyOrgMembers(fs FileStats, opts *Options) error {
+	if opts.orgMembers == nil {
+		return nil
+	}
+
+	count, err := attributeOrgMembers(fs, opts.config, opts.orgMembers)
+	if err != nil {
+		return err
+	}
+
+	opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Attributed %d emails to members of %s\n", count, opts.orgMembers.org)
+	if opts.orgMembers.rateLimited {
+		opts.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("GitHub's user search rate limit was reached, so %d emails weren't looked up and aren't attributed to members of %s\n", opts.orgMembers.skipped, opts.orgMembers.org)
+	}
+
+	return nil
+}
+
+// attributeOrgMembers attributes the emails of the contributors which aren't attributed
+// in the config to their login when they're a member of the org, adding them to the
+// config's attributions. The number of emails attributed is returned.
+func attributeOrgMembers(fs FileStats, spec *config.Spec, r *orgMemberResolver) (int, error) {
+	attributed := make(map[string]bool)
+	for _, emails := range spec.Attributions {
+		for _, email := range emails {
+			attributed[email] = true
+		}
+	}
+
+	unattributed := make(map[string]bool)
+	for _, authorStats := range fs {
+		for _, stat := range authorStats {
+			if stat.Email == "" || attributed[stat.Email] {
+				continue
+			}
+
+			if _, ok := regexAttribution(stat.Email, spec); ok {
+				continue
+			}
+
+			unattributed[stat.Email] = true
+		}
+	}
+
+	emails := make([]string, 0, len(unattributed))
+	for email := range unattributed {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+
+	count := 0
+	for _, email := range emails {
+		login, ok, err := r.resolve(email)
+		if err != nil {
+			return count, err
+		}
+		if !ok {
+			continue
+		}
+
+		if spec.Attributions == nil {
+			spec.Attributions = make(map[string][]string)
+		}
+		spec.Attributions[login] = append(spec.Attributions[login], email)
+		count++
+	}
+
+	return count, nil
+}
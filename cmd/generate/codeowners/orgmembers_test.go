@@ -0,0 +1,144 @@
+package codeowners
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+func TestAttributeOrgMembers(t *testing.T) {
+	newServer := func(t *testing.T, requests map[string]int) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests[r.URL.Path]++
+
+			var body any
+			switch r.URL.Path {
+			case "/orgs/open-sauced/members":
+				body = []githubUser{{Login: "jpmcb"}, {Login: "NickyTonline"}, {Login: "zeucapua"}}
+			case "/orgs/open-sauced/public_members":
+				body = []githubUser{{Login: "jpmcb"}}
+			case "/search/users":
+				switch r.URL.Query().Get("q") {
+				case "nick@example.com in:email":
+					body = githubUserSearch{Items: []githubUser{{Login: "nickytonline"}}}
+				case "outsider@example.com in:email":
+					body = githubUserSearch{Items: []githubUser{{Login: "outsider"}}}
+				default:
+					body = githubUserSearch{}
+				}
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(body)
+		}))
+		t.Cleanup(server.Close)
+
+		return server
+	}
+
+	fileStats := FileStats{
+		"main.go": {
+			"brandon":  {Email: "brandon@opensauced.pizza", Lines: 10},
+			"john":     {Email: "123+jpmcb@users.noreply.github.com", Lines: 5},
+			"nick":     {Email: "nick@example.com", Lines: 3},
+			"outsider": {Email: "outsider@example.com", Lines: 1},
+		},
+		"docs/README.md": {
+			"john":    {Email: "123+jpmcb@users.noreply.github.com", Lines: 5},
+			"unknown": {Email: "unknown@example.com", Lines: 1},
+		},
+	}
+
+	t.Run("with a token", func(t *testing.T) {
+		requests := make(map[string]int)
+		server := newServer(t, requests)
+		spec := &config.Spec{Attributions: map[string][]string{"brandonroberts": {"brandon@opensauced.pizza"}}}
+		r := newOrgMemberResolver(newGitHubClient(server.Client(), server.URL, "token"), "open-sauced")
+
+		count, err := attributeOrgMembers(fileStats, spec, r)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, map[string][]string{
+			"brandonroberts": {"brandon@opensauced.pizza"},
+			"jpmcb":          {"123+jpmcb@users.noreply.github.com"},
+			"NickyTonline":   {"nick@example.com"},
+		}, spec.Attributions)
+
+		// the members are fetched once, and noreply emails aren't searched for
+		assert.Equal(t, 1, requests["/orgs/open-sauced/members"])
+		assert.Equal(t, 3, requests["/search/users"])
+
+		// emails are looked up once
+		_, err = attributeOrgMembers(FileStats{"a.go": {"unknown": {Email: "unknown@example.com"}}}, spec, r)
+		require.NoError(t, err)
+		assert.Equal(t, 3, requests["/search/users"])
+	})
+
+	t.Run("without a token", func(t *testing.T) {
+		requests := make(map[string]int)
+		server := newServer(t, requests)
+		spec := &config.Spec{}
+		r := newOrgMemberResolver(newGitHubClient(server.Client(), server.URL, ""), "open-sauced")
+
+		count, err := attributeOrgMembers(fileStats, spec, r)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, map[string][]string{"jpmcb": {"123+jpmcb@users.noreply.github.com"}}, spec.Attributions)
+		assert.Equal(t, 1, requests["/orgs/open-sauced/public_members"])
+		assert.Zero(t, requests["/search/users"])
+	})
+
+	t.Run("rate limited search", func(t *testing.T) {
+		requests := make(map[string]int)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests[r.URL.Path]++
+
+			if r.URL.Path == "/search/users" {
+				w.Header().Set("Retry-After", "3600")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode([]githubUser{{Login: "jpmcb"}})
+		}))
+		t.Cleanup(server.Close)
+
+		client := newGitHubClient(server.Client(), server.URL, "token")
+		client.sleep = func(time.Duration) { t.Fatal("waited for a rate limit longer than the max wait") }
+
+		spec := &config.Spec{}
+		r := newOrgMemberResolver(client, "open-sauced")
+
+		// the remaining emails are skipped instead of failing the run
+		count, err := attributeOrgMembers(fileStats, spec, r)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, map[string][]string{"jpmcb": {"123+jpmcb@users.noreply.github.com"}}, spec.Attributions)
+		assert.Equal(t, 1, requests["/search/users"])
+		assert.True(t, r.rateLimited)
+		assert.Equal(t, 4, r.skipped)
+	})
+
+	t.Run("unknown org", func(t *testing.T) {
+		server := newServer(t, make(map[string]int))
+		r := newOrgMemberResolver(newGitHubClient(server.Client(), server.URL, "token"), "missing")
+
+		_, err := attributeOrgMembers(fileStats, &config.Spec{}, r)
+		require.ErrorContains(t, err, "could not list the members of missing")
+	})
+}
+
+func TestNoreplyLoginPattern(t *testing.T) {
+	assert.Equal(t, []string{"123+jpmcb@users.noreply.github.com", "jpmcb"}, noreplyLoginPattern.FindStringSubmatch("123+jpmcb@users.noreply.github.com"))
+	assert.Equal(t, []string{"jpmcb@users.noreply.github.com", "jpmcb"}, noreplyLoginPattern.FindStringSubmatch("jpmcb@users.noreply.github.com"))
+	assert.Nil(t, noreplyLoginPattern.FindStringSubmatch("jpmcb@opensauced.pizza"))
+}
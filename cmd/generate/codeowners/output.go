@@ -1,38 +1,143 @@
 package codeowners
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 
+	"github.com/jpmcb/gopherlogs/pkg/colors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"golang.org/x/text/unicode/norm"
 
 	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
 )
 
 func generateOutputFile(fileStats FileStats, outputPath string, opts *Options, cmd *cobra.Command) error {
+	// the existing file is read before it's truncated
+	if opts.preserveOrder {
+		var err error
+		opts.existingPatterns, err = readExistingPatterns(outputPath, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the output is generated in memory to compare it with the existing file, or to
+	// print it or its diff instead with a dry run or --check-against-remote
+	var buf bytes.Buffer
+	w, err := newOutputWriter(&buf, resolveLineEnding(outputPath, opts.lineEnding), opts.bom)
+	if err != nil {
+		return err
+	}
+
+	err = writeHeader(w, outputPath, opts, cmd)
+	if err != nil {
+		return err
+	}
+
+	err = writeFileStats(w, fileStats, outputPath, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.dryRun {
+		return writeDryRun(cmd.OutOrStdout(), buf.Bytes(), outputPath, useColor(cmd.OutOrStdout(), opts.noColor))
+	}
+
+	if opts.checkAgainstRemote != "" {
+		return checkAgainstRemote(cmd.OutOrStdout(), buf.Bytes(), outputPath, opts.remoteCheckClient, opts, useColor(cmd.OutOrStdout(), opts.noColor))
+	}
+
+	return writeOutputIfChanged(outputPath, buf.Bytes(), opts)
+}
+
+// writeOutputIfChanged writes the contents to the output file, unless the existing file
+// already has them. Skipping the write preserves its modification time, so regenerating
+// the same owners doesn't trigger file watchers or CI. With --always-write, it's always written.
+func writeOutputIfChanged(outputPath string, contents []byte, opts *Options) error {
+	if !opts.alwaysWrite {
+		existing, err := os.ReadFile(outputPath)
+		if err == nil && bytes.Equal(existing, contents) {
+			opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("%s is unchanged, skipping writing it\n", outputPath)
+			return nil
+		}
+	}
+
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(contents)
+	if err != nil {
+		return fmt.Errorf("error writing to %s file: %w", outputPath, err)
+	}
+
+	return nil
+}
+
+// errEmptyOutput is returned with --fail-on-empty when every file was filtered out
+var errEmptyOutput = errors.New("every file was filtered out, the generated file would be empty")
 
+// checkEmptyOutput warns when no files are left to attribute, which is likely a mistake in
+// the filters since the generated file would only have its header. With --fail-on-empty,
+// errEmptyOutput is returned instead.
+func checkEmptyOutput(files int, opts *Options) error {
+	if files > 0 {
+		return nil
+	}
+
+	if opts.failOnEmpty {
+		return errEmptyOutput
+	}
+
+	opts.logger.V(logging.LogInfo).Style(0, colors.FgRed).Warnf("Warning: every file was filtered out, the generated file will be empty. Check the path arguments and the --ignore, --exclude-path, --min-churn, and --range filters\n")
+	return nil
+}
+
+// createOutputFile creates the output file and any of its parent directories
+func createOutputFile(outputPath string) (*os.File, error) {
 	// Create specified output directories if necessary
 	err := os.MkdirAll(filepath.Dir(outputPath), os.ModePerm)
 	if err != nil {
 		if !os.IsExist(err) {
-			return fmt.Errorf("error creating directory at %s filepath: %w", outputPath, err)
+			return nil, fmt.Errorf("error creating directory at %s filepath: %w", outputPath, err)
 		}
 	}
 
 	// Open the file for writing
 	file, err := os.Create(outputPath)
 	if err != nil {
-		return fmt.Errorf("error creating %s file: %w", outputPath, err)
+		return nil, fmt.Errorf("error creating %s file: %w", outputPath, err)
 	}
-	defer file.Close()
+
+	return file, nil
+}
+
+// previewFlags only change how the file is previewed or written, so they're left out of its header
+var previewFlags = []string{"dry-run", "no-color", "always-write", "check-against-remote"}
+
+// writeHeader writes the generated file header, including the command used to generate it
+func writeHeader(file io.Writer, outputPath string, opts *Options, cmd *cobra.Command) error {
 	var flags []string
 
 	cmd.Flags().Visit(func(f *pflag.Flag) {
+		// previewing the file doesn't change it, so its diff stays focused on the owners
+		if slices.Contains(previewFlags, f.Name) {
+			return
+		}
+
 		flags = append(flags, fmt.Sprintf("--%s %s", f.Name, f.Value.String()))
 	})
 	generatedCommand := fmt.Sprintf("# $ pizza generate codeowners %s/", filepath.Base(opts.path))
@@ -42,77 +147,270 @@ func generateOutputFile(fileStats FileStats, outputPath string, opts *Options, c
 	}
 
 	// Write the header
-	_, err = file.WriteString(fmt.Sprintf("# This file is generated automatically by OpenSauced pizza-cli. DO NOT EDIT. Stay saucy!\n#\n# Generated with command:\n%s\n\n", generatedCommand))
-
+	_, err := fmt.Fprintf(file, "# This file is generated automatically by OpenSauced pizza-cli. DO NOT EDIT. Stay saucy!\n#\n# Generated with command:\n%s\n\n", generatedCommand)
 	if err != nil {
 		return fmt.Errorf("error writing to %s file: %w", outputPath, err)
 	}
 
+	return nil
+}
+
+// writeFileStats writes the owners of each file, sorted by filename, in the configured format.
+// With directory granularity, the owners of each directory are written instead.
+func writeFileStats(file io.Writer, fileStats FileStats, outputPath string, opts *Options) error {
+	stopAttribute := opts.profiler.phase(phaseAttribute)
+
+	if opts.granularity == granularityDirectory {
+		fileStats = fileStats.aggregateDirectories(opts)
+	}
+
+	if opts.sourcePatterns != nil {
+		fileStats = fileStats.aggregateSourcePatterns(opts)
+	}
+
 	// Sort the filenames to ensure consistent output
 	var filenames []string
 	for filename := range fileStats {
 		filenames = append(filenames, filename)
 	}
-	sort.Strings(filenames)
+	sortRules(filenames)
 
-	// Process each file
+	// Get the owners of each file
+	owners := make(map[string]AuthorStatSlice, len(filenames))
 	for _, filename := range filenames {
 		authorStats := fileStats[filename]
-		if opts.ownersStyleFile {
-			err = writeOwnersChunk(authorStats, opts.config, file, filename, outputPath)
-			if err != nil {
-				return fmt.Errorf("error writing to %s file: %w", outputPath, err)
-			}
-		} else {
-			_, err := writeGitHubCodeownersChunk(authorStats, opts.config, file, filename, outputPath)
+
+		for _, warning := range checkOverrideConsistency(filename, authorStats, opts.config) {
+			opts.logger.V(logging.LogDebug).Style(0, colors.FgYellow).Warnf("%s\n", warning)
+		}
+
+		// group references are expanded once the owners are attributed
+		var err error
+		owners[filename], err = expandOwnerGroups(getOwners(filename, authorStats, opts), opts.config)
+		if err != nil {
+			return fmt.Errorf("error expanding the owners of %s: %w", filename, err)
+		}
+
+		opts.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Attributed %s to %s (source: %s)\n", filename, ownerAliases(owners[filename]), ruleSource(owners[filename]))
+	}
+
+	if opts.omitFallbackOnly {
+		var err error
+		filenames, err = omitFallbackOnlyRules(filenames, owners, opts)
+		if err != nil {
+			return fmt.Errorf("error omitting the rules only the fallback owns: %w", err)
+		}
+	}
+
+	if opts.patternMode {
+		filenames, owners = collapsePatterns(owners, opts.trackedFiles)
+		sortRules(filenames)
+	}
+
+	if opts.preserveOrder {
+		filenames = preserveRuleOrder(filenames, opts.existingPatterns, opts)
+	}
+
+	// the patterns are written in their original order, since it decides which rule applies
+	if opts.sourcePatterns != nil {
+		filenames = opts.sourcePatterns
+	}
+
+	if opts.format == formatGitLab {
+		filenames = groupGitLabSections(filenames, opts.config.GitLabSections)
+	}
+
+	// a streamed file is written a directory at a time, so its rules are never all known
+	if !opts.stream {
+		checkSelfOwnership(filenames, owners, outputPath, opts)
+	}
+
+	stopAttribute()
+	defer opts.profiler.phase(phaseWrite)()
+
+	if opts.format == formatGitHub {
+		file = &codeownersValidator{
+			w:      file,
+			strict: opts.strict,
+			report: func(line string, err error) {
+				opts.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("GitHub would ignore the line %q: %s\n", line, err)
+			},
+		}
+	}
+
+	// Process each file
+	section := -1
+	for _, filename := range filenames {
+		var err error
+		if opts.format == formatGitLab {
+			section, err = writeGitLabSectionHeader(file, filename, section, opts.config.GitLabSections, outputPath)
 			if err != nil {
-				return fmt.Errorf("error writing to %s file: %w", outputPath, err)
+				return err
 			}
 		}
+
+		switch opts.format {
+		case formatOwners:
+			err = writeOwnersChunk(owners[filename], opts, file, filename, outputPath)
+		case formatGitea:
+			err = writeGiteaCodeownersChunk(owners[filename], opts, file, filename, outputPath)
+		case formatBitbucket:
+			err = writeBitbucketCodeownersChunk(owners[filename], opts, file, filename, outputPath)
+		default:
+			// GitLab rules use the same syntax as GitHub
+			_, err = writeGitHubCodeownersChunk(owners[filename], opts, file, filename, outputPath)
+		}
+
+		if err != nil {
+			return fmt.Errorf("error writing to %s file: %w", outputPath, err)
+		}
 	}
 
-	return nil
+	return writeOutputFileRule(file, outputPath, opts)
+}
+
+// sortOwners orders the owners of a rule for writing. They're in rank order unless --owner-sort
+// is alpha, which sorts them alphabetically by alias, ignoring case. The owners are copied.
+func sortOwners(owners AuthorStatSlice, opts *Options) AuthorStatSlice {
+	if opts.ownerSort != ownerSortAlpha {
+		return owners
+	}
+
+	sorted := slices.Clone(owners)
+	slices.SortStableFunc(sorted, func(a, b *CodeownerStat) int {
+		return compareAliases(a.GitHubAlias, b.GitHubAlias)
+	})
+
+	return sorted
+}
+
+// compareAliases compares aliases alphabetically, ignoring case
+func compareAliases(a, b string) int {
+	if c := strings.Compare(strings.ToLower(a), strings.ToLower(b)); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a, b)
+}
+
+// withoutMention drops the "@" of an owner with --no-mention, so a file used as a
+// reference lists bare usernames instead of notifying the owners
+func withoutMention(owner string, opts *Options) string {
+	if !opts.noMention {
+		return owner
+	}
+
+	return strings.TrimPrefix(owner, "@")
 }
 
-func writeGitHubCodeownersChunk(authorStats AuthorStats, config *config.Spec, file *os.File, srcFilename string, outputPath string) ([]string, error) {
-	topContributors := getTopContributorAttributions(authorStats, 3, config)
+// writeGitHubCodeownersChunk writes the rule of a file with its owners in rank order: the highest
+// ranked owner first, after any priority owners. Tools which auto assign the first listed owner
+// can rely on this order, which is deterministic for owners who rank equally. With --owner-sort
+// alpha, the owners kept within --max-owners-per-line are sorted by alias instead.
+func writeGitHubCodeownersChunk(topContributors AuthorStatSlice, opts *Options, file io.Writer, srcFilename string, outputPath string) ([]string, error) {
+	if opts.maxOwnersPerLine > 0 && len(topContributors) > opts.maxOwnersPerLine {
+		opts.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("Dropping the %d lowest ranked owners of %s to keep its rule within %d owners, see --max-owners-per-line\n", len(topContributors)-opts.maxOwnersPerLine, srcFilename, opts.maxOwnersPerLine)
+		topContributors = topContributors[:opts.maxOwnersPerLine]
+	}
+	topContributors = sortOwners(topContributors, opts)
 
 	resultSlice := []string{}
 	for _, contributor := range topContributors {
-		resultSlice = append(resultSlice, contributor.GitHubAlias)
+		resultSlice = append(resultSlice, githubIdentity(contributor, opts))
 	}
 
+	// files with no code owners to attribute are written without owners
+	line := rulePattern(srcFilename, opts)
 	if len(topContributors) > 0 {
-		_, err := fmt.Fprintf(file, "%s @%s\n", cleanFilename(srcFilename), strings.Join(resultSlice, " @"))
-		if err != nil {
-			return nil, fmt.Errorf("error writing to %s file: %w", outputPath, err)
+		line += " " + strings.Join(resultSlice, " ")
+	}
+
+	_, err := fmt.Fprintf(file, "%s\n", annotateSource(line, topContributors, opts))
+	if err != nil {
+		return nil, fmt.Errorf("error writing to %s file: %w", outputPath, err)
+	}
+
+	return resultSlice, nil
+}
+
+// defaultMaxOwnersPerLine is the default maximum number of owners written on a single rule
+// in the github and gitlab formats. Lines with many more owners risk being rejected.
+const defaultMaxOwnersPerLine = 50
+
+// githubIdentity gets the identity of an owner in the GitHub and GitLab formats: their @alias,
+// or their email with --github-identity email. GitHub only accepts emails added to a user's
+// account, so emails it won't accept fall back to an acceptable email attributed to the owner
+// in the config, then their @alias, i.e., for teams and fallback owners without an email.
+func githubIdentity(contributor *CodeownerStat, opts *Options) string {
+	if opts.githubIdentity == githubIdentityEmail {
+		if isAcceptedGitHubEmail(contributor.Email) {
+			return contributor.Email
 		}
-	} else {
-		// no code owners to attribute to file
-		_, err := fmt.Fprintf(file, "%s\n", cleanFilename(srcFilename))
-		if err != nil {
-			return nil, fmt.Errorf("error writing to %s file: %w", outputPath, err)
+
+		if opts.config != nil {
+			for _, email := range opts.config.Attributions[contributor.GitHubAlias] {
+				if isAcceptedGitHubEmail(email) {
+					return email
+				}
+			}
 		}
 	}
 
-	return resultSlice, nil
+	return "@" + contributor.GitHubAlias
 }
 
-func writeOwnersChunk(authorStats AuthorStats, config *config.Spec, file *os.File, srcFilename string, outputPath string) error {
-	topContributors := getTopContributorAttributions(authorStats, 3, config)
+// githubNoreplyEmailDomain is the domain of GitHub's private commit emails, which
+// aren't added to a user's account so GitHub won't accept them as owners
+const githubNoreplyEmailDomain = "@users.noreply.github.com"
 
-	_, err := fmt.Fprintf(file, "%s\n", srcFilename)
+// isAcceptedGitHubEmail returns true if GitHub would accept the email as an owner
+func isAcceptedGitHubEmail(email string) bool {
+	if strings.HasSuffix(strings.ToLower(email), githubNoreplyEmailDomain) || strings.HasPrefix(email, "@") {
+		return false
+	}
+
+	return ownerPattern.MatchString(email)
+}
+
+func writeOwnersChunk(topContributors AuthorStatSlice, opts *Options, file io.Writer, srcFilename string, outputPath string) error {
+	topContributors = sortOwners(topContributors, opts)
+
+	_, err := fmt.Fprintf(file, "%s\n", annotateSource(srcFilename, topContributors, opts))
 	if err != nil {
 		return fmt.Errorf("error writing to %s file: %w", outputPath, err)
 	}
 
-	for i := 0; i < len(topContributors) && i < 3; i++ {
-		_, err = fmt.Fprintf(file, "  - %s\n", topContributors[i].Name)
+	// the owners are already capped to the maximum owners when attributed
+	for _, contributor := range topContributors {
+		// the stats are shared by every rule the owner is attributed to, so they're copied
+		named := *contributor
+		named.Name = withoutMention(ownerName(contributor, opts.ownersName, opts.config), opts)
+		contributor := &named
+
+		if identity, ok := ownersIdentity(contributor, opts.ownersIdentity); ok {
+			_, err = fmt.Fprintf(file, "  - %s\n", withoutMention(identity, opts))
+			if err != nil {
+				return fmt.Errorf("error writing to %s file: %w", outputPath, err)
+			}
+
+			continue
+		}
+
+		if opts.ownersLayout == ownersLayoutCombined {
+			_, err = fmt.Fprintf(file, "  - %s <%s>\n", contributor.Name, contributor.Email)
+			if err != nil {
+				return fmt.Errorf("error writing to %s file: %w", outputPath, err)
+			}
+
+			continue
+		}
+
+		_, err = fmt.Fprintf(file, "  - %s\n", contributor.Name)
 		if err != nil {
 			return fmt.Errorf("error writing to %s file: %w", outputPath, err)
 		}
 
-		_, err = fmt.Fprintf(file, "    - %s\n", topContributors[i].Email)
+		_, err = fmt.Fprintf(file, "    - %s\n", contributor.Email)
 		if err != nil {
 			return fmt.Errorf("error writing to %s file: %w", outputPath, err)
 		}
@@ -121,41 +419,563 @@ func writeOwnersChunk(authorStats AuthorStats, config *config.Spec, file *os.Fil
 	return nil
 }
 
-func getTopContributorAttributions(authorStats AuthorStats, n int, config *config.Spec) AuthorStatSlice {
-	sortedAuthorStats := authorStats.ToSortedSlice()
+// ownersNameFallbacks are the sources of an owner's name tried, in order, when the
+// preferred source has no name for them. Owners without either fall back to their @alias.
+var ownersNameFallbacks = []string{ownersNameDisplay, ownersNameGit}
+
+// ownerName gets the name of an owner in an "OWNERS" style file from the preferred source:
+// their git name, GitHub @login, or display name in the config. Owners without a name from
+// the source fall back to their display name then git name, or "" without either.
+func ownerName(contributor *CodeownerStat, preference string, spec *config.Spec) string {
+	names := map[string]string{
+		ownersNameGit: strings.TrimSpace(contributor.Name),
+	}
+	if contributor.GitHubAlias != "" {
+		names[ownersNameLogin] = "@" + contributor.GitHubAlias
+		if spec != nil {
+			names[ownersNameDisplay] = strings.TrimSpace(spec.DisplayNames[contributor.GitHubAlias])
+		}
+	}
+
+	for _, source := range append([]string{preference}, ownersNameFallbacks...) {
+		if name := names[source]; name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// ownersIdentity gets the single line identity of an owner in an "OWNERS" style file:
+// their email or their @alias. Owners without an email fall back to their alias, and
+// owners without an alias fall back to their name and email in the configured layout,
+// or only their email without a name.
+func ownersIdentity(contributor *CodeownerStat, identity string) (string, bool) {
+	switch identity {
+	case ownersIdentityEmail:
+		if contributor.Email != "" {
+			return contributor.Email, true
+		}
+	case ownersIdentityAlias:
+		// the alias is used below when it's known
+	default:
+		// owners without an email, e.g., the fallback owners, are written by their alias
+		if contributor.Name != "" && contributor.Email != "" {
+			return "", false
+		}
+	}
+
+	if contributor.GitHubAlias != "" {
+		return "@" + contributor.GitHubAlias, true
+	}
+
+	// owners without a name or alias are written by their email
+	if contributor.Name == "" && contributor.Email != "" {
+		return contributor.Email, true
+	}
+
+	return "", false
+}
+
+func writeBitbucketCodeownersChunk(topContributors AuthorStatSlice, opts *Options, file io.Writer, srcFilename string, outputPath string) error {
+	topContributors = sortOwners(topContributors, opts)
 
-	// Get top n contributors (or all if less than n)
+	identities := make([]string, 0, len(topContributors))
+	for _, contributor := range topContributors {
+		identities = append(identities, getBitbucketIdentity(contributor, opts.config))
+	}
+
+	line := cleanRule(srcFilename)
+	if len(identities) > 0 {
+		line += " " + strings.Join(identities, " ")
+	}
+
+	_, err := fmt.Fprintf(file, "%s\n", annotateSource(line, topContributors, opts))
+	if err != nil {
+		return fmt.Errorf("error writing to %s file: %w", outputPath, err)
+	}
+
+	return nil
+}
+
+// getBitbucketIdentity gets the identifier BitBucket code owners expect for a contributor.
+// BitBucket identifies users by account UUID or email instead of @handles:
+// a configured identity for the GitHub alias is preferred, then the commit email.
+// Owners with neither (i.e., fallback groups) are emitted as @handles.
+func getBitbucketIdentity(contributor *CodeownerStat, config *config.Spec) string {
+	if identity, ok := config.BitbucketIdentities[contributor.GitHubAlias]; ok {
+		return identity
+	}
+
+	if contributor.Email != "" {
+		return contributor.Email
+	}
+
+	return "@" + contributor.GitHubAlias
+}
+
+func writeGiteaCodeownersChunk(topContributors AuthorStatSlice, opts *Options, file io.Writer, srcFilename string, outputPath string) error {
+	topContributors = sortOwners(topContributors, opts)
+
+	line := cleanRule(srcFilename)
+	if opts.config.GiteaPatternStyle == config.GiteaPatternStyleRegex {
+		line = giteaRegexRule(srcFilename)
+	}
+
+	for _, contributor := range topContributors {
+		line += " @" + contributor.GitHubAlias
+	}
+
+	_, err := fmt.Fprintf(file, "%s\n", annotateSource(line, topContributors, opts))
+	if err != nil {
+		return fmt.Errorf("error writing to %s file: %w", outputPath, err)
+	}
+
+	return nil
+}
+
+// getOwners gets the owners of a file. Owners pinned inline in the file itself
+// take precedence over the owners attributed from its git history. With --inherit-owners,
+// files without qualifying contributors inherit the owners of their nearest ancestor directory.
+func getOwners(filename string, authorStats AuthorStats, opts *Options) AuthorStatSlice {
+	if inlineOwners, ok := opts.inlineOwners[filename]; ok {
+		owners := make(AuthorStatSlice, 0, len(inlineOwners))
+		for _, inlineOwner := range inlineOwners {
+			owners = append(owners, &CodeownerStat{
+				GitHubAlias: inlineOwner,
+				Source:      ownerSourceInline,
+			})
+		}
+
+		return owners
+	}
+
+	owners := getTopContributorAttributions(filename, authorStats, opts.ranker, opts.maxOwners, opts.config)
+
+	// Files nobody qualifies as an owner of inherit the owners of an ancestor directory
+	// instead of the fallback attribution
+	if opts.ancestorStats != nil && isFallbackOnly(owners) {
+		if inherited, ok := opts.ancestorStats.inheritOwners(filename, opts); ok {
+			owners = inherited
+		}
+	}
+
+	if opts.useTeams {
+		owners = mapOwnersToTeams(owners, opts.config)
+	}
+
+	return owners
+}
+
+// getTopContributorAttributions gets the top n contributors of a file, as ranked by the ranker.
+// Explicit overrides, the minimum owners and commits, fallback owners, and priority owners
+// from the config are all applied.
+func getTopContributorAttributions(filename string, authorStats AuthorStats, ranker Ranker, n int, config *config.Spec) AuthorStatSlice {
+	// explicit overrides replace the computed owners entirely
+	if overrides, ok := getOverrideOwners(filename, config); ok {
+		var topContributors AuthorStatSlice
+		for _, override := range overrides {
+			topContributors = append(topContributors, &CodeownerStat{
+				GitHubAlias: override,
+				Source:      ownerSourceOverride,
+			})
+		}
+
+		return prioritizeOwners(filename, topContributors, config)
+	}
+
+	sortedAuthorStats := mergeLogins(authorStats, ranker, config).ToRankedSlice(ranker)
+	minOwners := min(config.MinOwners, n)
+
+	// Files whose top author barely edges out the others use the fallback instead
 	var topContributors AuthorStatSlice
+	if attributionConfidence(sortedAuthorStats) >= config.MinConfidence {
+		topContributors = attributeContributors(sortedAuthorStats, n, config.MinCommits, config)
 
-	for i := 0; i < len(sortedAuthorStats) && i < n; i++ {
-		// get attributions for email / github handles
-		for username, emails := range config.Attributions {
-			for _, email := range emails {
-				if email == sortedAuthorStats[i].Email {
-					sortedAuthorStats[i].GitHubAlias = username
-					topContributors = append(topContributors, sortedAuthorStats[i])
-				}
-			}
+		// Widen the search by relaxing the minimum commits threshold
+		// until the minimum number of owners is met
+		for threshold := config.MinCommits - 1; len(topContributors) < minOwners && threshold >= 0; threshold-- {
+			topContributors = attributeContributors(sortedAuthorStats, n, threshold, config)
 		}
 	}
 
-	if len(topContributors) == 0 {
-		for _, fallbackAttribution := range config.AttributionFallback {
+	if len(topContributors) < max(minOwners, 1) {
+		for _, fallbackAttribution := range fallbackOwners(config) {
+			if slices.ContainsFunc(topContributors, func(stat *CodeownerStat) bool {
+				return stat.GitHubAlias == fallbackAttribution
+			}) {
+				continue
+			}
+
 			topContributors = append(topContributors, &CodeownerStat{
 				GitHubAlias: fallbackAttribution,
+				Source:      ownerSourceFallback,
 			})
 		}
 	}
 
+	return prioritizeOwners(filename, topContributors, config)
+}
+
+// fallbackOwners gets the owners of the first fallback tier with a valid owner. Invalid
+// owners, i.e., a blank or malformed handle, are skipped, and a tier without any valid
+// owners escalates to the next tier.
+func fallbackOwners(config *config.Spec) []string {
+	for _, tier := range config.FallbackTiers() {
+		var owners []string
+		for _, owner := range tier {
+			if isValidFallbackOwner(owner, config) {
+				owners = append(owners, owner)
+			}
+		}
+
+		if len(owners) > 0 {
+			return owners
+		}
+	}
+
+	return nil
+}
+
+// isValidFallbackOwner returns true if the fallback owner is a defined group,
+// a GitHub user or team, or an email
+func isValidFallbackOwner(owner string, spec *config.Spec) bool {
+	if group, ok := config.GroupReference(owner); ok {
+		_, defined := spec.Groups[group]
+		return defined
+	}
+
+	return ownerPattern.MatchString("@"+strings.TrimPrefix(owner, "@")) || ownerPattern.MatchString(owner)
+}
+
+// attributionConfidence is the share of a file's lines changed by its top ranked author,
+// from 0 to 1. A file nobody changed any lines of has no confidence.
+func attributionConfidence(sortedAuthorStats AuthorStatSlice) float64 {
+	total := 0
+	for _, stat := range sortedAuthorStats {
+		total += stat.Lines
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(sortedAuthorStats[0].Lines) / float64(total)
+}
+
+// mergeLogins merges the stats of the contributors attributed to the same canonical GitHub
+// handle, i.e., the emails of a user who was renamed, so they're ranked by their combined
+// contributions. The merged stats have the name and email of the highest ranked contributor.
+func mergeLogins(authorStats AuthorStats, ranker Ranker, config *config.Spec) AuthorStats {
+	merged := make(AuthorStats, len(authorStats))
+
+	// the key of the merged stats of each login, and whether they're a copy of the file's stats
+	logins := make(map[string]string)
+	copied := make(map[string]bool)
+
+	for _, stat := range authorStats.ToRankedSlice(ranker) {
+		key := fmt.Sprintf("%s <%s>", stat.Name, stat.Email)
+
+		login, ok := exactAttribution(stat.Email, config)
+		if !ok {
+			login, ok = regexAttribution(stat.Email, config)
+		}
+		if !ok {
+			merged[key] = stat
+			continue
+		}
+
+		login = config.CanonicalLogin(login)
+		first, ok := logins[login]
+		if !ok {
+			logins[login] = key
+			merged[key] = stat
+			continue
+		}
+
+		// the file's stats are copied before merging, since they're shared with the other outputs
+		if !copied[login] {
+			clone := &CodeownerStat{Name: merged[first].Name, Email: merged[first].Email}
+			clone.add(merged[first])
+			merged[first] = clone
+			copied[login] = true
+		}
+		merged[first].add(stat)
+	}
+
+	return merged
+}
+
+// attributeContributors gets the top n contributors (or all if less than n) with
+// at least minCommits commits and attributes them to their configured GitHub handles.
+// Contributors without a configured attribution are not included. Contributors attributed
+// to aliases of the same canonical handle are only included once, as the highest ranked.
+func attributeContributors(sortedAuthorStats AuthorStatSlice, n int, minCommits int, config *config.Spec) AuthorStatSlice {
+	var topContributors AuthorStatSlice
+
+	considered := 0
+	for i := 0; i < len(sortedAuthorStats) && considered < n; i++ {
+		if sortedAuthorStats[i].Commits < minCommits {
+			continue
+		}
+
+		// get attributions for email / github handles. Emails without an
+		// exact attribution may match a regex attribution.
+		username, ok := exactAttribution(sortedAuthorStats[i].Email, config)
+		if !ok {
+			username, ok = regexAttribution(sortedAuthorStats[i].Email, config)
+		}
+
+		if ok {
+			username = config.CanonicalLogin(username)
+			if slices.ContainsFunc(topContributors, func(stat *CodeownerStat) bool {
+				return stat.GitHubAlias == username
+			}) {
+				continue
+			}
+		}
+		considered++
+
+		if ok {
+			sortedAuthorStats[i].GitHubAlias = username
+			sortedAuthorStats[i].Source = ownerSourceTopContributors
+			topContributors = append(topContributors, sortedAuthorStats[i])
+		}
+	}
+
 	return topContributors
 }
 
+// exactAttribution gets the username the email is attributed to in the config. An email
+// attributed to several usernames is attributed to the first in sorted order, so the
+// owners don't depend on the config's map iteration order.
+func exactAttribution(email string, config *config.Spec) (string, bool) {
+	username := ""
+	for name, emails := range config.Attributions {
+		if (username == "" || name < username) && slices.Contains(emails, email) {
+			username = name
+		}
+	}
+
+	return username, username != ""
+}
+
+// regexCache caches compiled regex attribution patterns since the same
+// config patterns are matched against the authors of every file
+var regexCache sync.Map
+
+// regexAttribution gets the first username, in sorted order, with a regex attribution
+// matching the email. Invalid patterns are rejected when the config is loaded, so they're skipped.
+func regexAttribution(email string, config *config.Spec) (string, bool) {
+	if len(config.RegexAttributions) == 0 {
+		return "", false
+	}
+
+	usernames := make([]string, 0, len(config.RegexAttributions))
+	for username := range config.RegexAttributions {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	for _, username := range usernames {
+		for _, pattern := range config.RegexAttributions[username] {
+			if matchRegex(pattern, email) {
+				return username, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// matchRegex reports whether the regular expression pattern matches s,
+// compiling the pattern once
+func matchRegex(pattern, s string) bool {
+	if cached, ok := regexCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp).MatchString(s)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false
+	}
+
+	regexCache.Store(pattern, re)
+	return re.MatchString(s)
+}
+
+// getOverrideOwners returns the explicitly configured owners for the given filename.
+// Override globs are evaluated in sorted order and, just like CODEOWNERS, the last
+// matching glob wins.
+func getOverrideOwners(filename string, config *config.Spec) ([]string, bool) {
+	if len(config.Overrides) == 0 {
+		return nil, false
+	}
+
+	globs := make([]string, 0, len(config.Overrides))
+	for glob := range config.Overrides {
+		globs = append(globs, glob)
+	}
+	sort.Strings(globs)
+
+	var owners []string
+	found := false
+	for _, glob := range globs {
+		matched, err := matchGlob(glob, filename)
+		if err != nil || !matched {
+			continue
+		}
+
+		owners = config.Overrides[glob]
+		found = true
+	}
+
+	return owners, found
+}
+
+// checkOverrideConsistency compares the explicit override owners of a file against
+// the git history for that file. A warning is returned for each override owner
+// with known attributed emails that has no contributions to the file at all:
+// this usually means the override is stale.
+func checkOverrideConsistency(filename string, authorStats AuthorStats, config *config.Spec) []string {
+	overrides, ok := getOverrideOwners(filename, config)
+	if !ok || len(authorStats) == 0 {
+		return nil
+	}
+
+	var warnings []string
+	for _, override := range overrides {
+		emails, known := config.Attributions[override]
+		if !known {
+			// teams and unattributed owners can't be checked against git history
+			continue
+		}
+
+		contributed := false
+		for _, stat := range authorStats {
+			if slices.Contains(emails, stat.Email) && stat.Lines > 0 {
+				contributed = true
+				break
+			}
+		}
+
+		if !contributed {
+			warnings = append(warnings, fmt.Sprintf("override owner @%s has no commits to %s: the override may be stale", override, filename))
+		}
+	}
+
+	return warnings
+}
+
+// prioritizeOwners moves any configured priority owners for the given filename
+// to the front of the owners list. A priority group reference, i.e., "@@leads",
+// prioritizes each of the group's members. Priority owners are only reordered, never added:
+// the remaining git derived owners keep their ranked order behind them.
+func prioritizeOwners(filename string, owners AuthorStatSlice, spec *config.Spec) AuthorStatSlice {
+	if len(spec.PriorityOwners) == 0 {
+		return owners
+	}
+
+	// Sort the globs to ensure consistent ordering when multiple globs match
+	globs := make([]string, 0, len(spec.PriorityOwners))
+	for glob := range spec.PriorityOwners {
+		globs = append(globs, glob)
+	}
+	sort.Strings(globs)
+
+	var priorities []string
+	for _, glob := range globs {
+		matched, err := matchGlob(glob, filename)
+		if err != nil || !matched {
+			continue
+		}
+
+		for _, priority := range spec.PriorityOwners[glob] {
+			priorities = append(priorities, priority)
+
+			// a group's members are prioritized in the group's order. Undefined
+			// groups are rejected when the config is validated.
+			if _, ok := config.GroupReference(priority); ok {
+				members, err := spec.ExpandGroups([]string{priority})
+				if err == nil {
+					priorities = append(priorities, members...)
+				}
+			}
+		}
+	}
+
+	if len(priorities) == 0 {
+		return owners
+	}
+
+	prioritized := make(AuthorStatSlice, 0, len(owners))
+	placed := make(map[*CodeownerStat]bool)
+
+	for _, priority := range priorities {
+		for _, owner := range owners {
+			if owner.GitHubAlias == priority && !placed[owner] {
+				prioritized = append(prioritized, owner)
+				placed[owner] = true
+			}
+		}
+	}
+
+	for _, owner := range owners {
+		if !placed[owner] {
+			prioritized = append(prioritized, owner)
+		}
+	}
+
+	return prioritized
+}
+
+// cleanRule cleans the filename of a rule. File and directory rules are anchored to the
+// repository root since a pattern without a leading "/" matches at any depth, i.e., a
+// "main.go" rule would also match, and take precedence for, "cmd/main.go".
+func cleanRule(rule string) string {
+	if rule == rootDirectoryRule {
+		return rule
+	}
+
+	if isExtensionPattern(rule) {
+		return cleanExtensionPattern(rule)
+	}
+
+	return "/" + cleanFilename(rule)
+}
+
+// giteaRegexRule gets the Gitea regex pattern matching a rule
+func giteaRegexRule(rule string) string {
+	if rule == rootDirectoryRule {
+		return "re:^.*$"
+	}
+
+	if isExtensionPattern(rule) {
+		return giteaExtensionPattern(rule)
+	}
+
+	if isDirectoryRule(rule) {
+		return "re:^" + regexp.QuoteMeta(rule) + ".*$"
+	}
+
+	return "re:^" + regexp.QuoteMeta(renameSource(rule)) + "$"
+}
+
 func cleanFilename(filename string) string {
-	// Split the filename in case its rename, see https://github.com/open-sauced/pizza-cli/issues/101
-	parsedFilename := strings.Split(filename, " ")[0]
-	// Replace anything that is not a word, period, single quote, dash, space, forward slash, or backslash with an escaped version
-	re := regexp.MustCompile(`([^\w\.\'\-\s\/\\])`)
-	escapedFilename := re.ReplaceAllString(parsedFilename, "\\$0")
+	// paths are NFC normalized so the same name typed on macOS, which decomposes
+	// accented letters, and elsewhere gets the same rule
+	parsedFilename := norm.NFC.String(renameSource(filename))
+	// Escape anything that is not a letter, number, underscore, period, single quote, dash, forward slash, or backslash.
+	// i.e., a literal "#" is escaped so GitHub doesn't treat it as the start of a comment
+	escapedFilename := codeownersSpecialChars.ReplaceAllString(parsedFilename, "\\$0")
 
 	return escapedFilename
 }
+
+// renameSource gets the first path of a renamed file's "old => new" stat name.
+// Other filenames, including those with spaces, are returned as is.
+// See https://github.com/open-sauced/pizza-cli/issues/101
+func renameSource(filename string) string {
+	source, _, _ := strings.Cut(filename, " => ")
+	return source
+}
@@ -1,11 +1,25 @@
 package codeowners
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/jpmcb/gopherlogs"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
 )
 
 func TestCleanFilename(testRunner *testing.T) {
@@ -22,6 +36,15 @@ func TestCleanFilename(testRunner *testing.T) {
 		{`path\to\[home].go`, `path\to\[home].go`, `path\to\\[home\].go`},
 		{`path\to\+page.go`, `path\to\+page.go`, `path\to\\+page.go`},
 		{`path\to\go-home.go`, `path\to\go-home.go`, `path\to\go-home.go`},
+		{"path/to/#home.go", "path/to/#home.go", `path/to/\#home.go`},
+		{"#home.go", "#home.go", `\#home.go`},
+		{"unicode", "docs/naïve café.md", `docs/naïve\ café.md`},
+		{"non latin", "文档/ファイル.go", "文档/ファイル.go"},
+		{"decomposed unicode", "docs/nai\u0308ve.md", "docs/na\u00efve.md"},
+		{"trailing space", "path/to/home.go ", `path/to/home.go\ `},
+		{"unicode space", "path/to/home\u00a0page.go", "path/to/home\\\u00a0page.go"},
+		{"zero width", "path/to/home\u200bpage.go", "path/to/home\\\u200bpage.go"},
+		{"rename", "path/to/old.go => path/to/new.go", "path/to/old.go"},
 	}
 
 	for _, testItem := range tests {
@@ -47,12 +70,251 @@ func TestGetTopContributorAttributions(testRunner *testing.T) {
 		"john":    {GitHubAlias: "john", Email: "john@opensauced.pizza", Lines: 15},
 	}
 
-	results := getTopContributorAttributions(authorStats, 3, &configSpec)
+	results := getTopContributorAttributions("path/to/file.go", authorStats, LinesRanker, 3, &configSpec)
 
 	assert.Len(testRunner, results, 1, "Expected 1 result")
 	assert.Equal(testRunner, "brandonroberts", results[0].GitHubAlias, "Expected brandonroberts")
 }
 
+func TestRegexAttributions(t *testing.T) {
+	configSpec := &config.Spec{
+		Attributions: map[string][]string{
+			"brandonroberts": {"brandon@opensauced.pizza"},
+		},
+		RegexAttributions: map[string][]string{
+			"open-sauced/bots": {`^.*\[bot\]@users\.noreply\.github\.com$`},
+			"jpmcb":            {`^john(\+.*)?@opensauced\.pizza$`},
+			"zeke":             {`@opensauced\.pizza$`},
+		},
+	}
+
+	authorStats := AuthorStats{
+		"brandon": {Email: "brandon@opensauced.pizza", Lines: 40},
+		"john":    {Email: "john+work@opensauced.pizza", Lines: 30},
+		"bot":     {Email: "renovate[bot]@users.noreply.github.com", Lines: 20},
+		"someone": {Email: "someone@example.com", Lines: 10},
+	}
+
+	results := getTopContributorAttributions("main.go", authorStats, LinesRanker, 4, configSpec)
+
+	var aliases []string
+	for _, result := range results {
+		aliases = append(aliases, result.GitHubAlias)
+		assert.Equal(t, ownerSourceTopContributors, result.Source)
+	}
+
+	// exact attributions take precedence over regex attributions, and the first
+	// username in sorted order wins when several patterns match
+	assert.Equal(t, []string{"brandonroberts", "jpmcb", "open-sauced/bots"}, aliases)
+}
+
+func TestExactAttributionToSeveralUsernames(t *testing.T) {
+	configSpec := &config.Spec{
+		Attributions: map[string][]string{
+			"nickytonline": {"shared@opensauced.pizza"},
+			"jpmcb":        {"shared@opensauced.pizza", "john@opensauced.pizza"},
+			"zeucapua":     {"shared@opensauced.pizza"},
+		},
+	}
+
+	authorStats := AuthorStats{
+		"shared": {Email: "shared@opensauced.pizza", Lines: 40},
+		"john":   {Email: "john@opensauced.pizza", Lines: 30},
+	}
+
+	// the email is attributed to the first username in sorted order, once, on every run.
+	// Both emails are jpmcb's, who is only attributed once as the highest ranked.
+	for range 10 {
+		results := getTopContributorAttributions("main.go", authorStats, LinesRanker, 3, configSpec)
+		require.Len(t, results, 1)
+		assert.Equal(t, "jpmcb", results[0].GitHubAlias)
+		assert.Equal(t, "shared@opensauced.pizza", results[0].Email)
+	}
+}
+
+func TestLoginAliasAttributions(t *testing.T) {
+	configSpec := &config.Spec{
+		Attributions: map[string][]string{
+			"old-login": {"old@opensauced.pizza"},
+			"new-login": {"new@opensauced.pizza"},
+			"jpmcb":     {"john@opensauced.pizza"},
+		},
+		Aliases: map[string]string{"old-login": "new-login"},
+	}
+
+	authorStats := AuthorStats{
+		"old":  {Email: "old@opensauced.pizza", Lines: 40},
+		"new":  {Email: "new@opensauced.pizza", Lines: 30},
+		"john": {Email: "john@opensauced.pizza", Lines: 10},
+	}
+
+	// the contributions under the old login are attributed to the new login, once,
+	// so they don't take the place of another owner
+	results := getTopContributorAttributions("main.go", authorStats, LinesRanker, 2, configSpec)
+	require.Len(t, results, 2)
+	assert.Equal(t, "new-login", results[0].GitHubAlias)
+	assert.Equal(t, "old@opensauced.pizza", results[0].Email)
+	assert.Equal(t, "jpmcb", results[1].GitHubAlias)
+
+	t.Run("merged contributions outrank", func(t *testing.T) {
+		authorStats := AuthorStats{
+			"old":  {Email: "old@opensauced.pizza", Lines: 25, Commits: 2},
+			"new":  {Email: "new@opensauced.pizza", Lines: 20, Commits: 1},
+			"john": {Email: "john@opensauced.pizza", Lines: 30, Commits: 3},
+		}
+
+		// neither login outranks jpmcb on its own, but together they do
+		results := getTopContributorAttributions("main.go", authorStats, LinesRanker, 1, configSpec)
+		require.Len(t, results, 1)
+		assert.Equal(t, "new-login", results[0].GitHubAlias)
+		assert.Equal(t, 45, results[0].Lines)
+		assert.Equal(t, 3, results[0].Commits)
+
+		// the file's stats are left as is
+		assert.Equal(t, 25, authorStats["old"].Lines)
+		assert.Equal(t, 20, authorStats["new"].Lines)
+	})
+}
+
+func TestMaxOwnersExceedsContributors(t *testing.T) {
+	configSpec := config.Spec{
+		Attributions: map[string][]string{
+			"brandonroberts": {"brandon@opensauced.pizza"},
+			"jpmcb":          {"john@opensauced.pizza"},
+		},
+		AttributionFallback: []string{"open-sauced/engineering"},
+	}
+
+	authorStats := AuthorStats{
+		"brandon": {Email: "brandon@opensauced.pizza", Lines: 20, Commits: 2},
+		"john":    {Email: "john@opensauced.pizza", Lines: 15, Commits: 1},
+	}
+
+	results := getTopContributorAttributions("path/to/file.go", authorStats, LinesRanker, 10, &configSpec)
+	require.Len(t, results, 2)
+	assert.NotContains(t, results, (*CodeownerStat)(nil))
+	assert.Equal(t, "brandonroberts", results[0].GitHubAlias)
+	assert.Equal(t, "jpmcb", results[1].GitHubAlias)
+
+	var buf bytes.Buffer
+	_, err := writeGitHubCodeownersChunk(results, &Options{}, &buf, "path/to/file.go", "CODEOWNERS")
+	require.NoError(t, err)
+	assert.Equal(t, "/path/to/file.go @brandonroberts @jpmcb\n", buf.String())
+}
+
+func TestMinConfidence(t *testing.T) {
+	configSpec := &config.Spec{
+		Attributions: map[string][]string{
+			"brandonroberts": {"brandon@opensauced.pizza"},
+			"jpmcb":          {"john@opensauced.pizza"},
+		},
+		AttributionFallback: []string{"open-sauced/engineering"},
+	}
+
+	authorStats := AuthorStats{
+		"brandon": {Email: "brandon@opensauced.pizza", Lines: 11, Commits: 1},
+		"john":    {Email: "john@opensauced.pizza", Lines: 9, Commits: 1},
+	}
+
+	assert.InDelta(t, 0.55, attributionConfidence(authorStats.ToSortedSlice()), 0.001)
+	assert.Zero(t, attributionConfidence(AuthorStatSlice{}))
+
+	t.Run("disabled by default", func(t *testing.T) {
+		results := getTopContributorAttributions("main.go", authorStats, LinesRanker, 3, configSpec)
+		require.Len(t, results, 2)
+		assert.Equal(t, "brandonroberts", results[0].GitHubAlias)
+	})
+
+	t.Run("met", func(t *testing.T) {
+		configSpec.MinConfidence = 0.5
+		results := getTopContributorAttributions("main.go", authorStats, LinesRanker, 3, configSpec)
+		require.Len(t, results, 2)
+		assert.Equal(t, ownerSourceTopContributors, results[0].Source)
+	})
+
+	t.Run("below the threshold", func(t *testing.T) {
+		configSpec.MinConfidence = 0.6
+		results := getTopContributorAttributions("main.go", authorStats, LinesRanker, 3, configSpec)
+		require.Len(t, results, 1)
+		assert.Equal(t, "open-sauced/engineering", results[0].GitHubAlias)
+		assert.Equal(t, ownerSourceFallback, results[0].Source)
+	})
+}
+
+func TestFallbackTiers(t *testing.T) {
+	authorStats := AuthorStats{
+		"unknown": {Email: "unknown@opensauced.pizza", Lines: 10, Commits: 1},
+	}
+
+	fallback := func(t *testing.T, configSpec *config.Spec) []string {
+		t.Helper()
+
+		var aliases []string
+		for _, stat := range getTopContributorAttributions("main.go", authorStats, LinesRanker, 3, configSpec) {
+			assert.Equal(t, ownerSourceFallback, stat.Source)
+			aliases = append(aliases, stat.GitHubAlias)
+		}
+
+		return aliases
+	}
+
+	tests := []struct {
+		name       string
+		configSpec *config.Spec
+		expected   []string
+	}{
+		{
+			name: "first tier",
+			configSpec: &config.Spec{
+				AttributionFallbackTiers: [][]string{{"open-sauced/frontend", "jpmcb"}, {"open-sauced/engineering"}},
+				AttributionFallback:      []string{"open-sauced/maintainers"},
+			},
+			expected: []string{"open-sauced/frontend", "jpmcb"},
+		},
+		{
+			name: "escalates past a tier without valid owners",
+			configSpec: &config.Spec{
+				AttributionFallbackTiers: [][]string{{"", "not a team"}, {"open-sauced/engineering"}},
+			},
+			expected: []string{"open-sauced/engineering"},
+		},
+		{
+			name: "skips invalid owners within a tier",
+			configSpec: &config.Spec{
+				AttributionFallbackTiers: [][]string{{"bad handle", "jpmcb"}},
+			},
+			expected: []string{"jpmcb"},
+		},
+		{
+			name: "escalates to the fallback attribution",
+			configSpec: &config.Spec{
+				AttributionFallbackTiers: [][]string{{}},
+				AttributionFallback:      []string{"open-sauced/maintainers"},
+			},
+			expected: []string{"open-sauced/maintainers"},
+		},
+		{
+			name: "group reference",
+			configSpec: &config.Spec{
+				Groups:                   map[string][]string{"frontend": {"alice"}},
+				AttributionFallbackTiers: [][]string{{"@@frontend"}},
+			},
+			expected: []string{"@@frontend"},
+		},
+		{
+			name:       "no valid tier",
+			configSpec: &config.Spec{AttributionFallbackTiers: [][]string{{"not valid"}}},
+			expected:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, fallback(t, tt.configSpec))
+		})
+	}
+}
+
 func TestGetFallbackAttributions(testRunner *testing.T) {
 	configSpec := config.Spec{
 		Attributions: map[string][]string{
@@ -62,8 +324,723 @@ func TestGetFallbackAttributions(testRunner *testing.T) {
 		AttributionFallback: []string{"open-sauced/engineering"},
 	}
 
-	results := getTopContributorAttributions(AuthorStats{}, 3, &configSpec)
+	results := getTopContributorAttributions("path/to/file.go", AuthorStats{}, LinesRanker, 3, &configSpec)
 
 	assert.Len(testRunner, results, 1, "Expected 1 result")
 	assert.Equal(testRunner, "open-sauced/engineering", results[0].GitHubAlias, "Expected open-sauced/engineering")
 }
+
+func TestPriorityOwnerOrdering(testRunner *testing.T) {
+	configSpec := config.Spec{
+		Attributions: map[string][]string{
+			"brandonroberts":          {"brandon@opensauced.pizza"},
+			"jpmcb":                   {"john@opensauced.pizza"},
+			"open-sauced/engineering": {"engineering@opensauced.pizza"},
+		},
+		PriorityOwners: map[string][]string{
+			"src/**": {"open-sauced/engineering"},
+		},
+	}
+
+	var authorStats = AuthorStats{
+		"brandon":     {Email: "brandon@opensauced.pizza", Lines: 20},
+		"john":        {Email: "john@opensauced.pizza", Lines: 15},
+		"engineering": {Email: "engineering@opensauced.pizza", Lines: 5},
+	}
+
+	testRunner.Run("priority owner placed first", func(tester *testing.T) {
+		results := getTopContributorAttributions("src/main.go", authorStats, LinesRanker, 3, &configSpec)
+
+		assert.Len(tester, results, 3)
+		assert.Equal(tester, "open-sauced/engineering", results[0].GitHubAlias)
+		assert.Equal(tester, "brandonroberts", results[1].GitHubAlias)
+		assert.Equal(tester, "jpmcb", results[2].GitHubAlias)
+	})
+
+	testRunner.Run("non-matching glob keeps ranked order", func(tester *testing.T) {
+		results := getTopContributorAttributions("docs/README.md", authorStats, LinesRanker, 3, &configSpec)
+
+		assert.Len(tester, results, 3)
+		assert.Equal(tester, "brandonroberts", results[0].GitHubAlias)
+		assert.Equal(tester, "jpmcb", results[1].GitHubAlias)
+		assert.Equal(tester, "open-sauced/engineering", results[2].GitHubAlias)
+	})
+
+	testRunner.Run("priority group members placed first", func(tester *testing.T) {
+		groupSpec := configSpec
+		groupSpec.Groups = map[string][]string{"leads": {"@jpmcb", "open-sauced/engineering"}}
+		groupSpec.PriorityOwners = map[string][]string{"src/**": {"@@leads"}}
+
+		results := getTopContributorAttributions("src/main.go", authorStats, LinesRanker, 3, &groupSpec)
+
+		assert.Len(tester, results, 3)
+		assert.Equal(tester, "jpmcb", results[0].GitHubAlias)
+		assert.Equal(tester, "open-sauced/engineering", results[1].GitHubAlias)
+		assert.Equal(tester, "brandonroberts", results[2].GitHubAlias)
+	})
+
+	testRunner.Run("priority owner not added when absent", func(tester *testing.T) {
+		results := getTopContributorAttributions("src/main.go", authorStats, LinesRanker, 2, &configSpec)
+
+		assert.Len(tester, results, 2)
+		assert.Equal(tester, "brandonroberts", results[0].GitHubAlias)
+		assert.Equal(tester, "jpmcb", results[1].GitHubAlias)
+	})
+}
+
+func TestOverrideAttributions(testRunner *testing.T) {
+	configSpec := config.Spec{
+		Attributions: map[string][]string{
+			"brandonroberts": {"brandon@opensauced.pizza"},
+			"jpmcb":          {"john@opensauced.pizza"},
+		},
+		Overrides: map[string][]string{
+			"docs/**": {"open-sauced/docs"},
+			"src/**":  {"jpmcb"},
+		},
+	}
+
+	var authorStats = AuthorStats{
+		"brandon": {Email: "brandon@opensauced.pizza", Lines: 20},
+	}
+
+	results := getTopContributorAttributions("docs/README.md", authorStats, LinesRanker, 3, &configSpec)
+	assert.Len(testRunner, results, 1)
+	assert.Equal(testRunner, "open-sauced/docs", results[0].GitHubAlias)
+
+	results = getTopContributorAttributions("main.go", authorStats, LinesRanker, 3, &configSpec)
+	assert.Len(testRunner, results, 1)
+	assert.Equal(testRunner, "brandonroberts", results[0].GitHubAlias)
+}
+
+func TestCheckOverrideConsistency(testRunner *testing.T) {
+	configSpec := config.Spec{
+		Attributions: map[string][]string{
+			"brandonroberts": {"brandon@opensauced.pizza"},
+			"jpmcb":          {"john@opensauced.pizza"},
+		},
+		Overrides: map[string][]string{
+			"src/**":  {"jpmcb", "open-sauced/engineering"},
+			"docs/**": {"brandonroberts"},
+		},
+	}
+
+	var authorStats = AuthorStats{
+		"brandon": {Email: "brandon@opensauced.pizza", Lines: 20},
+	}
+
+	warnings := checkOverrideConsistency("src/main.go", authorStats, &configSpec)
+	assert.Len(testRunner, warnings, 1)
+	assert.Contains(testRunner, warnings[0], "@jpmcb")
+
+	assert.Empty(testRunner, checkOverrideConsistency("docs/README.md", authorStats, &configSpec))
+	assert.Empty(testRunner, checkOverrideConsistency("main.go", authorStats, &configSpec))
+	assert.Empty(testRunner, checkOverrideConsistency("src/main.go", AuthorStats{}, &configSpec))
+}
+
+func TestMinOwnersWidening(testRunner *testing.T) {
+	var authorStats = AuthorStats{
+		"brandon": {Email: "brandon@opensauced.pizza", Lines: 20, Commits: 5},
+		"john":    {Email: "john@opensauced.pizza", Lines: 15, Commits: 2},
+		"nick":    {Email: "nick@opensauced.pizza", Lines: 10, Commits: 1},
+	}
+
+	attributions := map[string][]string{
+		"brandonroberts": {"brandon@opensauced.pizza"},
+		"jpmcb":          {"john@opensauced.pizza"},
+		"nickytonline":   {"nick@opensauced.pizza"},
+	}
+
+	testRunner.Run("min commits without min owners", func(tester *testing.T) {
+		configSpec := config.Spec{Attributions: attributions, MinCommits: 3}
+		results := getTopContributorAttributions("main.go", authorStats, LinesRanker, 3, &configSpec)
+
+		assert.Len(tester, results, 1)
+		assert.Equal(tester, "brandonroberts", results[0].GitHubAlias)
+	})
+
+	testRunner.Run("widens min commits to meet min owners", func(tester *testing.T) {
+		configSpec := config.Spec{Attributions: attributions, MinCommits: 3, MinOwners: 2}
+		results := getTopContributorAttributions("main.go", authorStats, LinesRanker, 3, &configSpec)
+
+		assert.Len(tester, results, 2)
+		assert.Equal(tester, "brandonroberts", results[0].GitHubAlias)
+		assert.Equal(tester, "jpmcb", results[1].GitHubAlias)
+	})
+
+	testRunner.Run("falls back when widening fails", func(tester *testing.T) {
+		configSpec := config.Spec{
+			Attributions:        map[string][]string{"brandonroberts": {"brandon@opensauced.pizza"}},
+			AttributionFallback: []string{"open-sauced/engineering"},
+			MinCommits:          3,
+			MinOwners:           2,
+		}
+		results := getTopContributorAttributions("main.go", authorStats, LinesRanker, 3, &configSpec)
+
+		assert.Len(tester, results, 2)
+		assert.Equal(tester, "brandonroberts", results[0].GitHubAlias)
+		assert.Equal(tester, "open-sauced/engineering", results[1].GitHubAlias)
+	})
+}
+
+func TestWriteOwnersChunkLayout(testRunner *testing.T) {
+	configSpec := config.Spec{
+		Attributions: map[string][]string{
+			"brandonroberts": {"brandon@opensauced.pizza"},
+		},
+	}
+
+	newAuthorStats := func() AuthorStats {
+		return AuthorStats{
+			"brandon": {Name: "Brandon Roberts", Email: "brandon@opensauced.pizza", Lines: 20},
+		}
+	}
+
+	testRunner.Run("nested layout", func(tester *testing.T) {
+		opts := &Options{config: &configSpec, maxOwners: 3, ownersLayout: ownersLayoutNested}
+
+		var buf bytes.Buffer
+		err := writeOwnersChunk(getOwners("main.go", newAuthorStats(), opts), opts, &buf, "main.go", "OWNERS")
+		require.NoError(tester, err)
+		assert.Equal(tester, "main.go\n  - Brandon Roberts\n    - brandon@opensauced.pizza\n", buf.String())
+	})
+
+	testRunner.Run("combined layout", func(tester *testing.T) {
+		opts := &Options{config: &configSpec, maxOwners: 3, ownersLayout: ownersLayoutCombined}
+
+		var buf bytes.Buffer
+		err := writeOwnersChunk(getOwners("main.go", newAuthorStats(), opts), opts, &buf, "main.go", "OWNERS")
+		require.NoError(tester, err)
+		assert.Equal(tester, "main.go\n  - Brandon Roberts <brandon@opensauced.pizza>\n", buf.String())
+	})
+}
+
+func TestGitHubOwnersRankOrder(t *testing.T) {
+	configSpec := &config.Spec{
+		Attributions: map[string][]string{
+			"alice": {"alice@opensauced.pizza"},
+			"bob":   {"bob@opensauced.pizza"},
+			"carol": {"carol@opensauced.pizza"},
+			"dave":  {"dave@opensauced.pizza"},
+		},
+	}
+
+	// carol and bob tie, so they're ordered by email
+	authorStats := AuthorStats{
+		"alice": {Email: "alice@opensauced.pizza", Lines: 5},
+		"bob":   {Email: "bob@opensauced.pizza", Lines: 20},
+		"carol": {Email: "carol@opensauced.pizza", Lines: 20},
+		"dave":  {Email: "dave@opensauced.pizza", Lines: 40},
+	}
+
+	opts := &Options{config: configSpec, maxOwners: 4}
+
+	for i := 0; i < 20; i++ {
+		var buf bytes.Buffer
+		_, err := writeGitHubCodeownersChunk(getOwners("main.go", authorStats, opts), opts, &buf, "main.go", "CODEOWNERS")
+		require.NoError(t, err)
+		require.Equal(t, "/main.go @dave @bob @carol @alice\n", buf.String())
+	}
+}
+
+func TestWriteGitHubCodeownersChunkMaxOwnersPerLine(t *testing.T) {
+	owners := AuthorStatSlice{
+		{GitHubAlias: "brandonroberts"},
+		{GitHubAlias: "jpmcb"},
+		{GitHubAlias: "nickytonline"},
+	}
+
+	var tests = []struct {
+		name             string
+		maxOwnersPerLine int
+		expected         string
+	}{
+		{"capped", 2, "/main.go @brandonroberts @jpmcb\n"},
+		{"within the cap", 3, "/main.go @brandonroberts @jpmcb @nickytonline\n"},
+		{"uncapped", 0, "/main.go @brandonroberts @jpmcb @nickytonline\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &Options{maxOwnersPerLine: tt.maxOwnersPerLine, logger: newTestLogger(t)}
+
+			var buf bytes.Buffer
+			_, err := writeGitHubCodeownersChunk(owners, opts, &buf, "main.go", "CODEOWNERS")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	// the attributed owners are left intact for the other outputs
+	assert.Len(t, owners, 3)
+}
+
+func TestWriteGitHubCodeownersChunkIdentity(t *testing.T) {
+	configSpec := &config.Spec{
+		Attributions: map[string][]string{
+			"nickytonline": {"123+nickytonline@users.noreply.github.com", "nick@opensauced.pizza"},
+		},
+	}
+
+	owners := AuthorStatSlice{
+		{Email: "brandon@opensauced.pizza", GitHubAlias: "brandonroberts"},
+		{Email: "123+nickytonline@users.noreply.github.com", GitHubAlias: "nickytonline"},
+		{Email: "456+jpmcb@users.noreply.github.com", GitHubAlias: "jpmcb"},
+		{GitHubAlias: "open-sauced/engineering", Source: ownerSourceFallback},
+	}
+
+	var tests = []struct {
+		identity string
+		expected string
+	}{
+		{githubIdentityAlias, "/main.go @brandonroberts @nickytonline @jpmcb @open-sauced/engineering\n"},
+		{githubIdentityEmail, "/main.go brandon@opensauced.pizza nick@opensauced.pizza @jpmcb @open-sauced/engineering\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.identity, func(t *testing.T) {
+			opts := &Options{config: configSpec, githubIdentity: tt.identity}
+
+			var buf bytes.Buffer
+			_, err := writeGitHubCodeownersChunk(owners, opts, &buf, "main.go", "CODEOWNERS")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+			require.NoError(t, validateGitHubCodeownersLine(buf.String()))
+		})
+	}
+
+	assert.False(t, isAcceptedGitHubEmail(""))
+	assert.False(t, isAcceptedGitHubEmail("not an email"))
+	assert.False(t, isAcceptedGitHubEmail("123+jpmcb@Users.Noreply.GitHub.com"))
+	assert.True(t, isAcceptedGitHubEmail("john@opensauced.pizza"))
+}
+
+func TestWriteOwnersChunkIdentity(t *testing.T) {
+	owners := AuthorStatSlice{
+		{Name: "Brandon Roberts", Email: "brandon@opensauced.pizza", GitHubAlias: "brandonroberts"},
+		{Name: "Anonymous", Email: "anon@example.com"},
+		{GitHubAlias: "open-sauced/engineering", Source: ownerSourceFallback},
+	}
+
+	var tests = []struct {
+		identity string
+		expected string
+	}{
+		{ownersIdentityName, "main.go\n  - Brandon Roberts\n    - brandon@opensauced.pizza\n  - Anonymous\n    - anon@example.com\n  - @open-sauced/engineering\n"},
+		{ownersIdentityEmail, "main.go\n  - brandon@opensauced.pizza\n  - anon@example.com\n  - @open-sauced/engineering\n"},
+		{ownersIdentityAlias, "main.go\n  - @brandonroberts\n  - Anonymous\n    - anon@example.com\n  - @open-sauced/engineering\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.identity, func(t *testing.T) {
+			opts := &Options{ownersLayout: ownersLayoutNested, ownersIdentity: tt.identity}
+
+			var buf bytes.Buffer
+			require.NoError(t, writeOwnersChunk(owners, opts, &buf, "main.go", "OWNERS"))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestWriteOwnersChunkName(t *testing.T) {
+	owners := AuthorStatSlice{
+		{Name: "Jane", Email: "jane@opensauced.pizza", GitHubAlias: "jdoe"},
+		{Name: "", Email: "john@opensauced.pizza", GitHubAlias: "jpmcb"},
+		{Name: "Brandon Roberts", Email: "brandon@opensauced.pizza", GitHubAlias: "brandonroberts"},
+		{Name: " ", Email: "anon@example.com"},
+		{GitHubAlias: "open-sauced/engineering", Source: ownerSourceFallback},
+	}
+	spec := &config.Spec{
+		DisplayNames: map[string]string{
+			"jdoe":  "Jane Doe",
+			"jpmcb": "John McBride",
+		},
+	}
+
+	var tests = []struct {
+		name     string
+		expected string
+	}{
+		{ownersNameGit, "main.go\n" +
+			"  - Jane\n    - jane@opensauced.pizza\n" +
+			"  - John McBride\n    - john@opensauced.pizza\n" +
+			"  - Brandon Roberts\n    - brandon@opensauced.pizza\n" +
+			"  - anon@example.com\n" +
+			"  - @open-sauced/engineering\n"},
+		{ownersNameLogin, "main.go\n" +
+			"  - @jdoe\n    - jane@opensauced.pizza\n" +
+			"  - @jpmcb\n    - john@opensauced.pizza\n" +
+			"  - @brandonroberts\n    - brandon@opensauced.pizza\n" +
+			"  - anon@example.com\n" +
+			"  - @open-sauced/engineering\n"},
+		{ownersNameDisplay, "main.go\n" +
+			"  - Jane Doe\n    - jane@opensauced.pizza\n" +
+			"  - John McBride\n    - john@opensauced.pizza\n" +
+			"  - Brandon Roberts\n    - brandon@opensauced.pizza\n" +
+			"  - anon@example.com\n" +
+			"  - @open-sauced/engineering\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &Options{ownersLayout: ownersLayoutNested, ownersIdentity: ownersIdentityName, ownersName: tt.name, config: spec}
+
+			var buf bytes.Buffer
+			require.NoError(t, writeOwnersChunk(owners, opts, &buf, "main.go", "OWNERS"))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	// the shared stats keep their git name
+	assert.Equal(t, "Jane", owners[0].Name)
+}
+
+func TestOwnerSetsMatchAcrossFormats(t *testing.T) {
+	configSpec := &config.Spec{
+		Attributions: map[string][]string{
+			"alice": {"alice@opensauced.pizza"},
+			"bob":   {"bob@opensauced.pizza"},
+			"carol": {"carol@opensauced.pizza"},
+			"dave":  {"dave@opensauced.pizza"},
+			"erin":  {"erin@opensauced.pizza"},
+		},
+	}
+
+	fileStats := FileStats{
+		"main.go": {
+			"alice": {Name: "Alice", Email: "alice@opensauced.pizza", Lines: 50},
+			"bob":   {Name: "Bob", Email: "bob@opensauced.pizza", Lines: 40},
+			"carol": {Name: "Carol", Email: "carol@opensauced.pizza", Lines: 30},
+			"dave":  {Name: "Dave", Email: "dave@opensauced.pizza", Lines: 20},
+			"erin":  {Name: "Erin", Email: "erin@opensauced.pizza", Lines: 10},
+		},
+	}
+
+	for _, maxOwners := range []int{1, 3, 4, 10} {
+		t.Run(fmt.Sprintf("max owners %d", maxOwners), func(t *testing.T) {
+			var github bytes.Buffer
+			githubOpts := &Options{config: configSpec, maxOwners: maxOwners, format: formatGitHub, logger: newTestLogger(t)}
+			require.NoError(t, writeFileStats(&github, fileStats, "CODEOWNERS", githubOpts))
+
+			var owners bytes.Buffer
+			ownersOpts := &Options{config: configSpec, maxOwners: maxOwners, format: formatOwners, ownersLayout: ownersLayoutCombined, logger: newTestLogger(t)}
+			require.NoError(t, writeFileStats(&owners, fileStats, "OWNERS", ownersOpts))
+
+			// map each "Name <email>" line of the OWNERS file back to its alias
+			var ownersAliases []string
+			for _, line := range strings.Split(strings.TrimSpace(owners.String()), "\n")[1:] {
+				name, _, _ := strings.Cut(strings.TrimPrefix(line, "  - "), " <")
+				ownersAliases = append(ownersAliases, strings.ToLower(name))
+			}
+
+			githubAliases := strings.Fields(strings.ReplaceAll(strings.TrimSpace(github.String()), "@", ""))[1:]
+
+			assert.Len(t, githubAliases, min(maxOwners, 5))
+			assert.Equal(t, githubAliases, ownersAliases)
+		})
+	}
+}
+
+func TestWriteBitbucketCodeownersChunk(testRunner *testing.T) {
+	configSpec := config.Spec{
+		Attributions: map[string][]string{
+			"brandonroberts": {"brandon@opensauced.pizza"},
+			"jpmcb":          {"john@opensauced.pizza"},
+		},
+		AttributionFallback: []string{"open-sauced/engineering"},
+		BitbucketIdentities: map[string]string{
+			"jpmcb": "{a1b2c3d4-0000-0000-0000-000000000000}",
+		},
+	}
+	opts := &Options{config: &configSpec, maxOwners: 3}
+
+	testRunner.Run("identities and emails", func(tester *testing.T) {
+		authorStats := AuthorStats{
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 20},
+			"john":    {Email: "john@opensauced.pizza", Lines: 15},
+		}
+
+		var buf bytes.Buffer
+		err := writeBitbucketCodeownersChunk(getOwners("src/main.go", authorStats, opts), opts, &buf, "src/main.go", "CODEOWNERS")
+		require.NoError(tester, err)
+		assert.Equal(tester, "/src/main.go brandon@opensauced.pizza {a1b2c3d4-0000-0000-0000-000000000000}\n", buf.String())
+	})
+
+	testRunner.Run("fallback", func(tester *testing.T) {
+		var buf bytes.Buffer
+		err := writeBitbucketCodeownersChunk(getOwners("src/main.go", AuthorStats{}, opts), opts, &buf, "src/main.go", "CODEOWNERS")
+		require.NoError(tester, err)
+		assert.Equal(tester, "/src/main.go @open-sauced/engineering\n", buf.String())
+	})
+}
+
+func TestWriteGiteaCodeownersChunk(testRunner *testing.T) {
+	newAuthorStats := func() AuthorStats {
+		return AuthorStats{
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 20},
+		}
+	}
+
+	attributions := map[string][]string{
+		"brandonroberts": {"brandon@opensauced.pizza"},
+	}
+
+	testRunner.Run("glob patterns", func(tester *testing.T) {
+		opts := &Options{config: &config.Spec{Attributions: attributions}, maxOwners: 3}
+
+		var buf bytes.Buffer
+		err := writeGiteaCodeownersChunk(getOwners("src/(main).go", newAuthorStats(), opts), opts, &buf, "src/(main).go", "CODEOWNERS")
+		require.NoError(tester, err)
+		assert.Equal(tester, "/src/\\(main\\).go @brandonroberts\n", buf.String())
+	})
+
+	testRunner.Run("regex patterns", func(tester *testing.T) {
+		opts := &Options{config: &config.Spec{Attributions: attributions, GiteaPatternStyle: config.GiteaPatternStyleRegex}, maxOwners: 3}
+
+		var buf bytes.Buffer
+		err := writeGiteaCodeownersChunk(getOwners("src/(main).go", newAuthorStats(), opts), opts, &buf, "src/(main).go", "CODEOWNERS")
+		require.NoError(tester, err)
+		assert.Equal(tester, "re:^src/\\(main\\)\\.go$ @brandonroberts\n", buf.String())
+	})
+}
+
+func TestWriteGitHubFilenameWithHash(testRunner *testing.T) {
+	fileStats, opts := newGranularityTestData()
+	opts.granularity = granularityFile
+	opts.strict = true
+	fileStats["#notes.md"] = fileStats["main.go"]
+	fileStats["docs/#1.md"] = fileStats["docs/guide.md"]
+
+	var buf bytes.Buffer
+	require.NoError(testRunner, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+
+	assert.Contains(testRunner, buf.String(), "\\#notes.md @brandonroberts\n")
+	assert.Contains(testRunner, buf.String(), "docs/\\#1.md @jpmcb\n")
+
+	// the owners still apply instead of the lines being read as comments
+	assert.Equal(testRunner, "@brandonroberts", resolveGitHubOwners(buf.String(), "#notes.md"))
+	assert.Equal(testRunner, "@jpmcb", resolveGitHubOwners(buf.String(), "docs/#1.md"))
+}
+
+func TestCheckEmptyOutput(t *testing.T) {
+	dir, _ := newTestRepo(t, testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "package main\n", "docs/guide.md": "# Guide\n"}})
+
+	configPath := filepath.Join(t.TempDir(), ".sauced.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("attribution:\n  brandonroberts: [brandon@opensauced.pizza]\n"), 0600))
+
+	generate := func(t *testing.T, outputPath string, args ...string) (string, error) {
+		t.Helper()
+
+		cmd := NewCodeownersCommand()
+		cmd.PersistentFlags().Bool(constants.FlagNameTelemetry, true, "")
+		cmd.PersistentFlags().String("config", "", "")
+		cmd.SetArgs(append([]string{dir, "--config", configPath, "--output-path", outputPath, "--ignore", "main.go,docs/"}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+
+		if err := cmd.Execute(); err != nil {
+			return "", err
+		}
+
+		contents, err := os.ReadFile(filepath.Join(outputPath, "CODEOWNERS"))
+		require.NoError(t, err)
+		return string(contents), nil
+	}
+
+	t.Run("warns when every file is ignored", func(t *testing.T) {
+		contents, err := generate(t, t.TempDir())
+		require.NoError(t, err)
+		assert.NotContains(t, contents, "/main.go @")
+
+		var buf bytes.Buffer
+		logger, err := gopherlogs.NewLogger(gopherlogs.WithOutputWriter(&buf), gopherlogs.WithLogVerbosity(logging.LogInfo))
+		require.NoError(t, err)
+
+		require.NoError(t, checkEmptyOutput(0, &Options{logger: logger}))
+		assert.Contains(t, buf.String(), "every file was filtered out")
+	})
+
+	t.Run("fails with --fail-on-empty", func(t *testing.T) {
+		_, err := generate(t, t.TempDir(), "--fail-on-empty")
+		require.ErrorIs(t, err, errEmptyOutput)
+		assert.Equal(t, constants.ExitCodeEmpty, utils.ExitCode(err))
+	})
+
+	t.Run("fails with --fail-on-empty when streaming", func(t *testing.T) {
+		outputPath := t.TempDir()
+		_, err := generate(t, outputPath, "--fail-on-empty", "--stream")
+		require.ErrorIs(t, err, errEmptyOutput)
+		assert.Equal(t, constants.ExitCodeEmpty, utils.ExitCode(err))
+
+		// the header alone isn't left behind
+		entries, err := os.ReadDir(outputPath)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("files left", func(t *testing.T) {
+		require.NoError(t, checkEmptyOutput(1, &Options{failOnEmpty: true}))
+	})
+}
+
+func TestWriteFileStatsLogsOwners(t *testing.T) {
+	fileStats, opts := newGranularityTestData()
+	opts.granularity = granularityFile
+	opts.config.AttributionFallback = []string{"open-sauced/engineering"}
+
+	var logs bytes.Buffer
+	opts.logger = logging.NewJSONLogger(&logs, logging.LogDebug)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+
+	assert.Contains(t, logs.String(), `"level":"debug","message":"Attributed main.go to @brandonroberts (source: top-contributors)"`)
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		assert.True(t, json.Valid([]byte(line)), line)
+	}
+}
+
+func TestOwnerSort(t *testing.T) {
+	fileStats := FileStats{
+		"main.go": {
+			"zeu":     {Email: "zeu@opensauced.pizza", Lines: 30, Commits: 3},
+			"john":    {Email: "john@opensauced.pizza", Lines: 20, Commits: 2},
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 10, Commits: 1},
+		},
+	}
+	configSpec := &config.Spec{
+		Attributions: map[string][]string{
+			"zeucapua":       {"zeu@opensauced.pizza"},
+			"jpmcb":          {"john@opensauced.pizza"},
+			"BrandonRoberts": {"brandon@opensauced.pizza"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		ownerSort string
+		format    string
+		want      string
+	}{
+		{"rank", ownerSortRank, formatGitHub, "/main.go @zeucapua @jpmcb @BrandonRoberts\n"},
+		{"alpha", ownerSortAlpha, formatGitHub, "/main.go @BrandonRoberts @jpmcb @zeucapua\n"},
+		{"alpha gitea", ownerSortAlpha, formatGitea, "/main.go @BrandonRoberts @jpmcb @zeucapua\n"},
+		{"alpha owners", ownerSortAlpha, formatOwners, "main.go\n  - @BrandonRoberts\n  - @jpmcb\n  - @zeucapua\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &Options{config: configSpec, maxOwners: 3, format: tt.format, ownerSort: tt.ownerSort, ownersLayout: ownersLayoutNested, ownersIdentity: ownersIdentityAlias, logger: newTestLogger(t)}
+
+			var buf bytes.Buffer
+			require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+
+	t.Run("alpha keeps the highest ranked owners", func(t *testing.T) {
+		opts := &Options{config: configSpec, maxOwners: 3, maxOwnersPerLine: 2, format: formatGitHub, ownerSort: ownerSortAlpha, logger: newTestLogger(t)}
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+		assert.Equal(t, "/main.go @jpmcb @zeucapua\n", buf.String())
+	})
+}
+
+func TestNoMention(t *testing.T) {
+	fileStats := FileStats{
+		"main.go": {
+			"john":    {Name: "John", Email: "john@opensauced.pizza", Lines: 20, Commits: 2},
+			"brandon": {Name: "Brandon", Email: "brandon@opensauced.pizza", Lines: 10, Commits: 1},
+		},
+	}
+	configSpec := &config.Spec{
+		Attributions: map[string][]string{
+			"jpmcb":          {"john@opensauced.pizza"},
+			"brandonroberts": {"brandon@opensauced.pizza"},
+		},
+		AttributionFallback: []string{"open-sauced/engineering"},
+		MinOwners:           3,
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"owners by alias", Options{format: formatOwners, ownersIdentity: ownersIdentityAlias}, "main.go\n  - jpmcb\n  - brandonroberts\n  - open-sauced/engineering\n"},
+		{"owners by login", Options{format: formatOwners, ownersIdentity: ownersIdentityName, ownersName: ownersNameLogin, ownersLayout: ownersLayoutCombined}, "main.go\n  - jpmcb <john@opensauced.pizza>\n  - brandonroberts <brandon@opensauced.pizza>\n  - open-sauced/engineering\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.config = configSpec
+			opts.maxOwners = 3
+			opts.noMention = true
+			opts.logger = newTestLogger(t)
+
+			var buf bytes.Buffer
+			require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", &opts))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+
+	t.Run("invalid for CODEOWNERS formats", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), ".sauced.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte("attribution:\n  jpmcb: [john@opensauced.pizza]\n"), 0600))
+
+		for _, format := range []string{formatGitHub, formatGitLab, formatGitea, formatBitbucket} {
+			cmd := NewCodeownersCommand()
+			cmd.PersistentFlags().Bool(constants.FlagNameTelemetry, true, "")
+			cmd.PersistentFlags().String("config", "", "")
+			cmd.SetArgs([]string{t.TempDir(), "--config", configPath, "--format", format, "--no-mention"})
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Equal(t, constants.ExitCodeConfig, utils.ExitCode(err), format)
+			assert.ErrorContains(t, err, "--no-mention can only be used")
+		}
+	})
+}
+
+func TestWriteIfChanged(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "CODEOWNERS")
+	fileStats, opts := newGranularityTestData()
+	opts.granularity = granularityFile
+
+	require.NoError(t, generateOutputFile(fileStats, outputPath, opts, &cobra.Command{}))
+
+	// backdate the file so a rewrite is detectable
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(outputPath, past, past))
+
+	modTime := func() time.Time {
+		info, err := os.Stat(outputPath)
+		require.NoError(t, err)
+		return info.ModTime()
+	}
+
+	t.Run("unchanged file is not rewritten", func(t *testing.T) {
+		require.NoError(t, generateOutputFile(fileStats, outputPath, opts, &cobra.Command{}))
+		assert.True(t, past.Equal(modTime()))
+	})
+
+	t.Run("always write", func(t *testing.T) {
+		opts.alwaysWrite = true
+		t.Cleanup(func() { opts.alwaysWrite = false })
+
+		require.NoError(t, generateOutputFile(fileStats, outputPath, opts, &cobra.Command{}))
+		assert.True(t, modTime().After(past))
+		require.NoError(t, os.Chtimes(outputPath, past, past))
+	})
+
+	t.Run("changed file is rewritten", func(t *testing.T) {
+		opts.maxOwners = 1
+
+		require.NoError(t, generateOutputFile(fileStats, outputPath, opts, &cobra.Command{}))
+		assert.True(t, modTime().After(past))
+	})
+}
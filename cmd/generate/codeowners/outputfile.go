@@ -0,0 +1,66 @@
+package codeowners
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// The placeholders which may be used in an --output-file template
+const (
+	placeholderFormat = "{format}"
+	placeholderDir    = "{dir}"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+// validateOutputFile checks that the --output-file template only uses the placeholders
+// supplied by the selected modes: "{format}" is always supplied, while "{dir}" is only
+// supplied when each directory gets its own file with --owners-hierarchy, which then requires it.
+func validateOutputFile(template string, opts *Options) error {
+	if template == "" {
+		return nil
+	}
+
+	for _, placeholder := range placeholderPattern.FindAllString(template, -1) {
+		switch placeholder {
+		case placeholderFormat:
+		case placeholderDir:
+			if !opts.ownersHierarchy {
+				return fmt.Errorf("the %s placeholder can only be used with --owners-hierarchy", placeholderDir)
+			}
+		default:
+			return fmt.Errorf("unknown placeholder %s, must be one of: %s, %s", placeholder, placeholderFormat, placeholderDir)
+		}
+	}
+
+	if opts.ownersHierarchy && !strings.Contains(template, placeholderDir) {
+		return fmt.Errorf("--owners-hierarchy requires the %s placeholder so each directory gets its own file", placeholderDir)
+	}
+
+	return nil
+}
+
+// outputFilePath gets the path of a generated file for the given directory of the repository.
+// The --output-file template is expanded, relative to the repository, when set. Otherwise, the file is named after its
+// file type ("CODEOWNERS" or "OWNERS") within the directory beneath the output path.
+func (opts *Options) outputFilePath(fileType string, dir string) string {
+	if opts.outputFile == "" {
+		return filepath.Join(opts.outputPath, filepath.FromSlash(dir), fileType)
+	}
+
+	replacer := strings.NewReplacer(
+		placeholderFormat, opts.format,
+		placeholderDir, filepath.FromSlash(dir),
+	)
+
+	// like the config's output path, a relative path is relative to the repository.
+	// Remote repositories have no base path on disk, so it's kept relative to the current directory.
+	path := filepath.Clean(replacer.Replace(opts.outputFile))
+	if !filepath.IsAbs(path) && opts.remoteURL == "" {
+		path = filepath.Join(opts.path, path)
+	}
+
+	return path
+}
@@ -0,0 +1,84 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+func TestValidateOutputFile(t *testing.T) {
+	tests := []struct {
+		name            string
+		template        string
+		ownersHierarchy bool
+		valid           bool
+	}{
+		{"no template", "", false, true},
+		{"no placeholders", ".github/CODEOWNERS", false, true},
+		{"format", "out/{format}/CODEOWNERS", false, true},
+		{"dir without hierarchy", "{dir}/CODEOWNERS", false, false},
+		{"dir with hierarchy", "owners/{dir}/OWNERS", true, true},
+		{"hierarchy without dir", "owners/OWNERS", true, false},
+		{"unknown placeholder", "{branch}/CODEOWNERS", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOutputFile(tt.template, &Options{ownersHierarchy: tt.ownersHierarchy})
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestOutputFilePath(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		opts := &Options{outputPath: "repo"}
+		assert.Equal(t, filepath.Join("repo", "CODEOWNERS"), opts.outputFilePath("CODEOWNERS", ""))
+		assert.Equal(t, filepath.Join("repo", "docs", "OWNERS"), opts.outputFilePath("OWNERS", "docs"))
+	})
+
+	t.Run("template", func(t *testing.T) {
+		opts := &Options{outputPath: "repo", format: formatGitea, outputFile: "out/{format}/{dir}/CODEOWNERS"}
+		assert.Equal(t, filepath.Join("out", "gitea", "docs", "CODEOWNERS"), opts.outputFilePath("CODEOWNERS", "docs"))
+		assert.Equal(t, filepath.Join("out", "gitea", "CODEOWNERS"), opts.outputFilePath("CODEOWNERS", "."))
+	})
+
+	t.Run("relative to the repository", func(t *testing.T) {
+		opts := &Options{path: "repo", outputPath: "repo", outputFile: ".github/CODEOWNERS"}
+		assert.Equal(t, filepath.Join("repo", ".github", "CODEOWNERS"), opts.outputFilePath("CODEOWNERS", ""))
+
+		opts.outputFile = filepath.Join(t.TempDir(), "CODEOWNERS")
+		assert.Equal(t, opts.outputFile, opts.outputFilePath("CODEOWNERS", ""))
+
+		opts.remoteURL = "https://github.com/open-sauced/pizza-cli"
+		opts.outputFile = "CODEOWNERS"
+		assert.Equal(t, "CODEOWNERS", opts.outputFilePath("CODEOWNERS", ""))
+	})
+
+	t.Run("hierarchy", func(t *testing.T) {
+		outputPath := t.TempDir()
+		opts := &Options{format: formatOwners, outputFile: filepath.Join(outputPath, "owners", "{dir}", "OWNERS"), config: &config.Spec{}}
+
+		files := []ownersFile{
+			{Dir: ".", Approvers: []string{"brandonroberts"}},
+			{Dir: "docs", Approvers: []string{"jpmcb"}},
+		}
+		require.NoError(t, writeOwnersHierarchy(files, opts, &cobra.Command{}))
+
+		assert.FileExists(t, filepath.Join(outputPath, "owners", "OWNERS"))
+		assert.FileExists(t, filepath.Join(outputPath, "owners", "docs", "OWNERS"))
+
+		_, err := os.Stat(filepath.Join(outputPath, "docs"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
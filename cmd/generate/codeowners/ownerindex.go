@@ -0,0 +1,135 @@
+package codeowners
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+)
+
+// The supported formats of the owner index
+const (
+	ownerIndexFormatText = "text"
+	ownerIndexFormatJSON = constants.OutputJSON
+)
+
+var ownerIndexFormats = []string{ownerIndexFormatText, ownerIndexFormatJSON}
+
+// ownerIndexFileType gets the name of the owner index file in the given format
+func ownerIndexFileType(format string) string {
+	if format == ownerIndexFormatJSON {
+		return "OWNER_INDEX.json"
+	}
+
+	return "OWNER_INDEX"
+}
+
+// ownerIndex is the inverse of the attributions: each owner mapped to the sorted
+// rules they own, to answer "what do I own?".
+// Example: { "@jpmcb": [ "cmd/root.go", "main.go" ]}
+type ownerIndex map[string][]string
+
+// buildOwnerIndex attributes owners to every rule, the same as the other formats,
+// and indexes the rules by owner. Group references are expanded to their members.
+func buildOwnerIndex(fileStats FileStats, opts *Options) (ownerIndex, error) {
+	if opts.granularity == granularityDirectory {
+		fileStats = fileStats.aggregateDirectories(opts)
+	}
+
+	index := make(ownerIndex)
+	for filename, authorStats := range fileStats {
+		owners, err := expandOwnerGroups(getOwners(filename, authorStats, opts), opts.config)
+		if err != nil {
+			return nil, fmt.Errorf("error expanding the owners of %s: %w", filename, err)
+		}
+
+		for _, owner := range owners {
+			alias := withoutMention("@"+owner.GitHubAlias, opts)
+			index[alias] = append(index[alias], filename)
+		}
+	}
+
+	for _, rules := range index {
+		sortRules(rules)
+	}
+
+	return index, nil
+}
+
+// sortedOwners gets the owners of the index in sorted order
+func (oi ownerIndex) sortedOwners() []string {
+	owners := make([]string, 0, len(oi))
+	for owner := range oi {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+
+	return owners
+}
+
+// writeOwnerIndex writes the owner index as text, each owner followed by their
+// indented rules, or as a JSON object of each owner to their rules
+func writeOwnerIndex(w io.Writer, index ownerIndex, format string) error {
+	if format == ownerIndexFormatJSON {
+		// maps are marshaled with sorted keys
+		data, err := json.MarshalIndent(index, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling owner index: %w", err)
+		}
+
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	for _, owner := range index.sortedOwners() {
+		if _, err := fmt.Fprintf(w, "%s\n", owner); err != nil {
+			return err
+		}
+
+		for _, rule := range index[owner] {
+			if _, err := fmt.Fprintf(w, "  %s\n", rule); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// generateOwnerIndexFile writes the owner index to the output file, or its diff with --dry-run
+func generateOwnerIndexFile(fileStats FileStats, outputPath string, opts *Options, cmd *cobra.Command) error {
+	stopAttribute := opts.profiler.phase(phaseAttribute)
+	index, err := buildOwnerIndex(fileStats, opts)
+	stopAttribute()
+	if err != nil {
+		return err
+	}
+
+	defer opts.profiler.phase(phaseWrite)()
+
+	if opts.dryRun {
+		var buf bytes.Buffer
+		if err := writeOwnerIndex(&buf, index, opts.ownerIndexFormat); err != nil {
+			return err
+		}
+
+		return writeDryRun(cmd.OutOrStdout(), buf.Bytes(), outputPath, useColor(cmd.OutOrStdout(), opts.noColor))
+	}
+
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := writeOwnerIndex(file, index, opts.ownerIndexFormat); err != nil {
+		return fmt.Errorf("error writing to %s file: %w", outputPath, err)
+	}
+
+	return nil
+}
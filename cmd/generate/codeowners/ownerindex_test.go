@@ -0,0 +1,80 @@
+package codeowners
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildOwnerIndex(t *testing.T) {
+	t.Run("files", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.granularity = granularityFile
+
+		index, err := buildOwnerIndex(fileStats, opts)
+		require.NoError(t, err)
+		assert.Equal(t, ownerIndex{
+			"@brandonroberts": {"docs/README.md", "main.go"},
+			"@jpmcb":          {"docs/README.md", "docs/api/index.md", "docs/guide.md"},
+		}, index)
+	})
+
+	t.Run("without mentions", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.granularity = granularityFile
+		opts.noMention = true
+
+		index, err := buildOwnerIndex(fileStats, opts)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"brandonroberts", "jpmcb"}, index.sortedOwners())
+	})
+
+	t.Run("directories", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+
+		index, err := buildOwnerIndex(fileStats, opts)
+		require.NoError(t, err)
+		assert.Equal(t, ownerIndex{
+			"@brandonroberts": {rootDirectoryRule, "docs/"},
+			"@jpmcb":          {"docs/", "docs/api/"},
+		}, index)
+	})
+}
+
+func TestWriteOwnerIndex(t *testing.T) {
+	index := ownerIndex{
+		"@jpmcb":          {"docs/guide.md"},
+		"@brandonroberts": {"docs/README.md", "main.go"},
+	}
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeOwnerIndex(&buf, index, ownerIndexFormatText))
+		assert.Equal(t, "@brandonroberts\n  docs/README.md\n  main.go\n@jpmcb\n  docs/guide.md\n", buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeOwnerIndex(&buf, index, ownerIndexFormatJSON))
+		assert.JSONEq(t, `{"@brandonroberts": ["docs/README.md", "main.go"], "@jpmcb": ["docs/guide.md"]}`, buf.String())
+	})
+}
+
+func TestGenerateOwnerIndexFile(t *testing.T) {
+	fileStats, opts := newGranularityTestData()
+	opts.granularity = granularityFile
+	opts.ownerIndexFormat = ownerIndexFormatJSON
+
+	outputPath := filepath.Join(t.TempDir(), ownerIndexFileType(opts.ownerIndexFormat))
+	require.NoError(t, generateOwnerIndexFile(fileStats, outputPath, opts, &cobra.Command{}))
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"@jpmcb": [`)
+	assert.Equal(t, "OWNER_INDEX.json", filepath.Base(outputPath))
+}
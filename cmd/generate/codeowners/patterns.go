@@ -0,0 +1,172 @@
+package codeowners
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// collapsePatterns groups file rules into directory ("/docs/") and extension ("/docs/*.md")
+// patterns when every file the pattern would match has the same owners.
+// The largest consistent directory is preferred, then extensions within a directory.
+//
+// To never grant ownership to a file with different owners, every tracked file a pattern
+// matches must have a rule with identical owners: tracked files without a rule (i.e., ignored
+// or unchanged files) prevent grouping. When trackedFiles is nil, only the files with
+// rules are considered tracked.
+func collapsePatterns(owners map[string]AuthorStatSlice, trackedFiles map[string]struct{}) ([]string, map[string]AuthorStatSlice) {
+	tracked := trackedFiles
+	if tracked == nil {
+		tracked = make(map[string]struct{}, len(owners))
+		for filename := range owners {
+			tracked[filename] = struct{}{}
+		}
+	}
+
+	dirGroups := make(map[string]*patternGroup)
+	extGroups := make(map[string]*patternGroup)
+	for filename := range tracked {
+		signature, ok := ownersSignature(filename, owners)
+
+		for dir := path.Dir(filename); ; dir = path.Dir(dir) {
+			addToGroup(dirGroups, dir, signature, ok)
+			if dir == "." {
+				break
+			}
+		}
+
+		if pattern, isPattern := extensionPattern(filename); isPattern {
+			addToGroup(extGroups, pattern, signature, ok)
+		}
+	}
+
+	collapsed := make(map[string]AuthorStatSlice)
+	for filename, fileOwners := range owners {
+		rule := filename
+
+		if _, ok := tracked[filename]; !ok {
+			// i.e., files deleted from the tree keep their own rule
+			collapsed[rule] = fileOwners
+			continue
+		}
+
+		if dir, found := topConsistentDir(filename, dirGroups); found {
+			rule = directoryPatternRule(dir)
+		} else if pattern, isPattern := extensionPattern(filename); isPattern && extGroups[pattern].consistent() {
+			rule = pattern
+		}
+
+		collapsed[rule] = fileOwners
+	}
+
+	rules := make([]string, 0, len(collapsed))
+	for rule := range collapsed {
+		rules = append(rules, rule)
+	}
+
+	return rules, collapsed
+}
+
+// patternGroup tracks whether every file a pattern matches has the same owners
+type patternGroup struct {
+	signature string
+	files     int
+	mixed     bool
+}
+
+// consistent returns true if the group's pattern is worth emitting:
+// it matches more than one file and they all have the same owners
+func (pg *patternGroup) consistent() bool {
+	return pg != nil && !pg.mixed && pg.files > 1
+}
+
+func addToGroup(groups map[string]*patternGroup, key, signature string, owned bool) {
+	group, ok := groups[key]
+	if !ok {
+		group = &patternGroup{signature: signature}
+		groups[key] = group
+	}
+
+	group.files++
+	if !owned || group.signature != signature {
+		group.mixed = true
+	}
+}
+
+// ownersSignature identifies the owners of a file, in order. False is returned
+// if the file has no rule.
+func ownersSignature(filename string, owners map[string]AuthorStatSlice) (string, bool) {
+	fileOwners, ok := owners[filename]
+	if !ok {
+		return "", false
+	}
+
+	var sb strings.Builder
+	for _, owner := range fileOwners {
+		sb.WriteString(owner.GitHubAlias + "\x00" + owner.Name + "\x00" + owner.Email + "\x00" + owner.Source + "\n")
+	}
+
+	return sb.String(), true
+}
+
+// topConsistentDir finds the highest directory above the file which can be collapsed
+func topConsistentDir(filename string, dirGroups map[string]*patternGroup) (string, bool) {
+	top, found := "", false
+	for dir := path.Dir(filename); ; dir = path.Dir(dir) {
+		group := dirGroups[dir]
+		if group.mixed {
+			// a directory is never consistent when a subdirectory is mixed
+			break
+		}
+
+		if group.consistent() {
+			top, found = dir, true
+		}
+
+		if dir == "." {
+			break
+		}
+	}
+
+	return top, found
+}
+
+func directoryPatternRule(dir string) string {
+	if dir == "." {
+		return rootDirectoryRule
+	}
+
+	return dir + "/"
+}
+
+// extensionPattern gets the pattern matching the files with the same extension
+// in the file's directory. The pattern is anchored to the repository root with a
+// leading slash, which also distinguishes it from a literal filename.
+// Example: "docs/guide.md" is matched by "/docs/*.md"
+func extensionPattern(filename string) (string, bool) {
+	dir, base := path.Split(filename)
+
+	ext := path.Ext(base)
+	if ext == "" || ext == base || strings.ContainsAny(filename, "*?[ ") {
+		return "", false
+	}
+
+	return "/" + dir + "*" + ext, true
+}
+
+// isExtensionPattern returns true if the rule is a pattern generated by extensionPattern
+func isExtensionPattern(rule string) bool {
+	return strings.HasPrefix(rule, "/")
+}
+
+// cleanExtensionPattern cleans the literal parts of an extension pattern
+func cleanExtensionPattern(rule string) string {
+	i := strings.LastIndex(rule, "/*.")
+	return cleanFilename(rule[:i+1]) + "*." + cleanFilename(rule[i+3:])
+}
+
+// giteaExtensionPattern gets the Gitea regex pattern matching an extension pattern
+func giteaExtensionPattern(rule string) string {
+	i := strings.LastIndex(rule, "/*.")
+	return "re:^" + regexp.QuoteMeta(rule[1:i+1]) + "[^/]*" + regexp.QuoteMeta(rule[i+2:]) + "$"
+}
@@ -0,0 +1,130 @@
+package codeowners
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPatternOwners(owners map[string]string) map[string]AuthorStatSlice {
+	patternOwners := make(map[string]AuthorStatSlice, len(owners))
+	for filename, owner := range owners {
+		patternOwners[filename] = AuthorStatSlice{{GitHubAlias: owner}}
+	}
+
+	return patternOwners
+}
+
+func TestCollapsePatterns(t *testing.T) {
+	t.Run("directory", func(t *testing.T) {
+		owners := newPatternOwners(map[string]string{
+			"main.go":          "brandonroberts",
+			"docs/guide.md":    "jpmcb",
+			"docs/api/v1.md":   "jpmcb",
+			"docs/api/v2.yaml": "jpmcb",
+		})
+
+		rules, collapsed := collapsePatterns(owners, nil)
+		sortRules(rules)
+
+		assert.Equal(t, []string{"docs/", "main.go"}, rules)
+		assert.Equal(t, "jpmcb", collapsed["docs/"][0].GitHubAlias)
+	})
+
+	t.Run("whole repository", func(t *testing.T) {
+		owners := newPatternOwners(map[string]string{
+			"main.go":       "jpmcb",
+			"docs/guide.md": "jpmcb",
+		})
+
+		rules, _ := collapsePatterns(owners, nil)
+		assert.Equal(t, []string{rootDirectoryRule}, rules)
+	})
+
+	t.Run("extension", func(t *testing.T) {
+		owners := newPatternOwners(map[string]string{
+			"src/a.go":      "brandonroberts",
+			"src/b.go":      "brandonroberts",
+			"src/style.css": "jpmcb",
+			"src/sub/c.go":  "jpmcb",
+		})
+
+		rules, _ := collapsePatterns(owners, nil)
+		sortRules(rules)
+
+		// "/src/*.go" doesn't match files in subdirectories
+		assert.Equal(t, []string{"/src/*.go", "src/style.css", "src/sub/c.go"}, rules)
+	})
+
+	t.Run("mixed owners are not grouped", func(t *testing.T) {
+		owners := newPatternOwners(map[string]string{
+			"src/a.go": "brandonroberts",
+			"src/b.go": "jpmcb",
+		})
+
+		rules, _ := collapsePatterns(owners, nil)
+		sortRules(rules)
+
+		assert.Equal(t, []string{"src/a.go", "src/b.go"}, rules)
+	})
+
+	t.Run("tracked files without rules are not grouped", func(t *testing.T) {
+		owners := newPatternOwners(map[string]string{
+			"src/a.go": "brandonroberts",
+			"src/b.go": "brandonroberts",
+		})
+
+		tracked := map[string]struct{}{"src/a.go": {}, "src/b.go": {}, "src/ignored.go": {}}
+
+		rules, _ := collapsePatterns(owners, tracked)
+		sortRules(rules)
+
+		assert.Equal(t, []string{"src/a.go", "src/b.go"}, rules)
+	})
+
+	t.Run("untracked files keep their own rule", func(t *testing.T) {
+		owners := newPatternOwners(map[string]string{
+			"src/a.go":       "brandonroberts",
+			"src/b.go":       "brandonroberts",
+			"src/deleted.go": "jpmcb",
+		})
+
+		tracked := map[string]struct{}{"src/a.go": {}, "src/b.go": {}}
+
+		rules, collapsed := collapsePatterns(owners, tracked)
+		sortRules(rules)
+
+		assert.Equal(t, []string{"*", "src/deleted.go"}, rules)
+		assert.Equal(t, "brandonroberts", collapsed["*"][0].GitHubAlias)
+	})
+
+	t.Run("a single file is not grouped", func(t *testing.T) {
+		owners := newPatternOwners(map[string]string{
+			"main.go":  "brandonroberts",
+			"src/a.go": "jpmcb",
+		})
+
+		rules, _ := collapsePatterns(owners, nil)
+		sortRules(rules)
+
+		assert.Equal(t, []string{"main.go", "src/a.go"}, rules)
+	})
+}
+
+func TestWritePatternRules(t *testing.T) {
+	fileStats, opts := newGranularityTestData()
+	opts.granularity = granularityFile
+	opts.patternMode = true
+	fileStats["docs/api/guide.md"] = fileStats["docs/api/index.md"]
+
+	var buf bytes.Buffer
+	require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+	assert.Equal(t, "/docs/README.md @brandonroberts @jpmcb\n/docs/api/ @jpmcb\n/docs/guide.md @jpmcb\n/main.go @brandonroberts\n", buf.String())
+
+	t.Run("gitea regex", func(t *testing.T) {
+		assert.Equal(t, `re:^docs/[^/]*\.md$`, giteaExtensionPattern("/docs/*.md"))
+		assert.Equal(t, `re:^[^/]*\.go$`, giteaExtensionPattern("/*.go"))
+	})
+}
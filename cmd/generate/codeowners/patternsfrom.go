@@ -0,0 +1,74 @@
+package codeowners
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/jpmcb/gopherlogs/pkg/colors"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+)
+
+// readSourcePatterns reads the patterns of an existing CODEOWNERS file for --patterns-from,
+// in order and without duplicates. Unlike the existing output file, the file must exist.
+func readSourcePatterns(path string) ([]string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("could not read patterns file: %w", err)
+	}
+
+	patterns, err := readExistingPatterns(path, &Options{format: formatGitHub})
+	if err != nil {
+		return nil, err
+	}
+
+	// the last occurrence of a duplicate pattern is the one that takes effect, since the last
+	// matching rule wins, so it's the one kept
+	seen := make(map[string]bool, len(patterns))
+	unique := make([]string, 0, len(patterns))
+	for i := len(patterns) - 1; i >= 0; i-- {
+		if !seen[patterns[i]] {
+			seen[patterns[i]] = true
+			unique = append(unique, patterns[i])
+		}
+	}
+	slices.Reverse(unique)
+
+	return unique, nil
+}
+
+// aggregateSourcePatterns merges the author stats of the files each --patterns-from pattern
+// matches into a rule for the pattern. Every file a pattern matches counts toward it, even
+// when a later pattern also matches the file. Patterns which match no file are kept, so the
+// fallback applies to them, and are reported since they're likely obsolete.
+func (fs FileStats) aggregateSourcePatterns(opts *Options) FileStats {
+	// files without history in the range still count as matches
+	files := make(map[string]struct{}, len(fs)+len(opts.trackedFiles))
+	for filename := range fs {
+		files[filename] = struct{}{}
+	}
+	for filename := range opts.trackedFiles {
+		files[filename] = struct{}{}
+	}
+
+	aggregated := make(FileStats, len(opts.sourcePatterns))
+	for _, pattern := range opts.sourcePatterns {
+		aggregated[pattern] = make(AuthorStats)
+
+		matches := 0
+		for filename := range files {
+			if !codeownersPatternMatches(pattern, filename, false) {
+				continue
+			}
+
+			matches++
+			aggregated[pattern].merge(fs[filename])
+		}
+
+		if matches == 0 {
+			opts.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("Pattern %s no longer matches any files\n", pattern)
+		}
+	}
+
+	return aggregated
+}
@@ -0,0 +1,105 @@
+package codeowners
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jpmcb/gopherlogs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
+)
+
+func TestReadSourcePatterns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CODEOWNERS")
+	require.NoError(t, os.WriteFile(path, []byte("# owners\n* @jpmcb\n\n/docs/ @brandonroberts # docs\n*.md @jpmcb\n* @zeucapua\n"), 0o600))
+
+	patterns, err := readSourcePatterns(path)
+	require.NoError(t, err)
+	// the duplicate is kept where it takes effect, after the patterns it overrides
+	assert.Equal(t, []string{"/docs/", "*.md", "*"}, patterns)
+
+	_, err = readSourcePatterns(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
+
+func TestAggregateSourcePatterns(t *testing.T) {
+	fileStats, opts := newGranularityTestData()
+
+	var logs bytes.Buffer
+	logger, err := gopherlogs.NewLogger(gopherlogs.WithOutputWriter(&logs), gopherlogs.WithLogVerbosity(logging.LogInfo))
+	require.NoError(t, err)
+	opts.logger = logger
+	opts.sourcePatterns = []string{"*", "/docs/api/", "*.md", "/gone/"}
+
+	aggregated := fileStats.aggregateSourcePatterns(opts)
+	assert.Len(t, aggregated, 4)
+	assert.Equal(t, 40, aggregated["*"]["brandon"].Lines)
+	assert.Equal(t, 25, aggregated["*"]["john"].Lines)
+	assert.Equal(t, AuthorStats{"john": {Email: "john@opensauced.pizza", Lines: 10, Commits: 1}}, aggregated["/docs/api/"])
+	assert.Equal(t, 30, aggregated["*.md"]["brandon"].Lines)
+	assert.Equal(t, 25, aggregated["*.md"]["john"].Lines)
+	assert.Empty(t, aggregated["/gone/"])
+
+	assert.Contains(t, logs.String(), "Pattern /gone/ no longer matches any files")
+	assert.NotContains(t, logs.String(), "Pattern *.md")
+}
+
+func TestGeneratePatternsFrom(t *testing.T) {
+	dir, _ := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "package main\n", "docs/guide.md": "# guide\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"docs/guide.md": "# guide\n\nmore\n", "docs/api.md": "# api\n"}},
+	)
+
+	configPath := filepath.Join(t.TempDir(), ".sauced.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("attribution:\n  brandonroberts: [brandon@opensauced.pizza]\n  jpmcb: [john@opensauced.pizza]\nattribution-fallback: [open-sauced/engineering]\n"), 0600))
+
+	patternsPath := filepath.Join(t.TempDir(), "CODEOWNERS")
+	require.NoError(t, os.WriteFile(patternsPath, []byte("* @someone\n/docs/*.md @someone\n/legacy/ @someone\n"), 0o600))
+
+	generate := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+
+		outputPath := t.TempDir()
+		cmd := NewCodeownersCommand()
+		cmd.PersistentFlags().Bool(constants.FlagNameTelemetry, true, "")
+		cmd.PersistentFlags().String("config", "", "")
+		cmd.SetArgs(append([]string{dir, "--config", configPath, "--output-path", outputPath}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+
+		if err := cmd.Execute(); err != nil {
+			return "", err
+		}
+
+		contents, err := os.ReadFile(filepath.Join(outputPath, "CODEOWNERS"))
+		require.NoError(t, err)
+		return string(contents), nil
+	}
+
+	t.Run("keeps the patterns", func(t *testing.T) {
+		contents, err := generate(t, "--patterns-from", patternsPath)
+		require.NoError(t, err)
+
+		_, rules, _ := strings.Cut(contents, "\n\n")
+		assert.Equal(t, "* @jpmcb @brandonroberts\n/docs/*.md @jpmcb @brandonroberts\n/legacy/ @open-sauced/engineering\n", rules)
+	})
+
+	t.Run("invalid with pattern mode", func(t *testing.T) {
+		_, err := generate(t, "--patterns-from", patternsPath, "--pattern-mode")
+		require.Error(t, err)
+		assert.Equal(t, constants.ExitCodeConfig, utils.ExitCode(err))
+	})
+
+	t.Run("missing patterns file", func(t *testing.T) {
+		_, err := generate(t, "--patterns-from", filepath.Join(t.TempDir(), "CODEOWNERS"))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,84 @@
+package codeowners
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/pprof"
+	"text/tabwriter"
+	"time"
+)
+
+// The phases of generation timed by --profile
+const (
+	phaseOpenRepo    = "open repository"
+	phaseWalkHistory = "walk history"
+	phaseAttribute   = "attribute owners"
+	phaseWrite       = "write output"
+)
+
+// profiler records how long each phase of generation takes. A nil profiler
+// records nothing, so profiling has no overhead when it's disabled.
+type profiler struct {
+	phases    []string
+	durations map[string]time.Duration
+}
+
+func newProfiler() *profiler {
+	return &profiler{durations: make(map[string]time.Duration)}
+}
+
+// phase starts timing a phase and returns a function which stops timing it.
+// The durations of a phase timed more than once, i.e., once per streamed directory, are summed.
+func (p *profiler) phase(name string) func() {
+	if p == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		if _, ok := p.durations[name]; !ok {
+			p.phases = append(p.phases, name)
+		}
+
+		p.durations[name] += time.Since(start)
+	}
+}
+
+// write writes a table of the timed phases, in the order they were first timed, and their total
+func (p *profiler) write(w io.Writer) error {
+	if p == nil {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PHASE\tDURATION")
+
+	var total time.Duration
+	for _, phase := range p.phases {
+		total += p.durations[phase]
+		fmt.Fprintf(tw, "%s\t%s\n", phase, p.durations[phase].Round(time.Microsecond))
+	}
+	fmt.Fprintf(tw, "total\t%s\n", total.Round(time.Microsecond))
+
+	return tw.Flush()
+}
+
+// startCPUProfile writes a pprof CPU profile to the given path until the returned function is called
+func startCPUProfile(path string) (func(), error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create CPU profile %s: %w", path, err)
+	}
+
+	err = pprof.StartCPUProfile(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("could not start CPU profile: %w", err)
+	}
+
+	return func() {
+		pprof.StopCPUProfile()
+		file.Close()
+	}, nil
+}
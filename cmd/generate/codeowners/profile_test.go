@@ -0,0 +1,63 @@
+package codeowners
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfiler(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		var p *profiler
+		p.phase(phaseWalkHistory)()
+
+		var buf bytes.Buffer
+		require.NoError(t, p.write(&buf))
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("phases are summed in order", func(t *testing.T) {
+		p := newProfiler()
+		p.phase(phaseOpenRepo)()
+		p.phase(phaseWalkHistory)()
+		p.phase(phaseWalkHistory)()
+
+		assert.Equal(t, []string{phaseOpenRepo, phaseWalkHistory}, p.phases)
+
+		var buf bytes.Buffer
+		require.NoError(t, p.write(&buf))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 4)
+		assert.True(t, strings.HasPrefix(lines[0], "PHASE"))
+		assert.True(t, strings.HasPrefix(lines[1], phaseOpenRepo))
+		assert.True(t, strings.HasPrefix(lines[2], phaseWalkHistory))
+		assert.True(t, strings.HasPrefix(lines[3], "total"))
+	})
+
+	t.Run("write file stats", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		opts.profiler = newProfiler()
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+		assert.Equal(t, []string{phaseAttribute, phaseWrite}, opts.profiler.phases)
+	})
+}
+
+func TestStartCPUProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cpu.pprof")
+
+	stop, err := startCPUProfile(path)
+	require.NoError(t, err)
+	stop()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Positive(t, info.Size())
+}
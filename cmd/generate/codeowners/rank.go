@@ -0,0 +1,142 @@
+package codeowners
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// Ranker is a strategy for ranking the authors of a file. The top ranked
+// authors with configured attributions become the file's owners.
+// Custom rankers may be registered with RegisterRanker.
+type Ranker interface {
+	// Rank sorts the authors in place, from the most to the least likely owner.
+	// The sort should be stable: authors are given in a deterministic order.
+	Rank(authors AuthorStatSlice)
+}
+
+// RankerFunc adapts a "less" function, which reports whether a should be
+// ranked before b, into a Ranker
+type RankerFunc func(a, b *CodeownerStat) bool
+
+func (f RankerFunc) Rank(authors AuthorStatSlice) {
+	sort.SliceStable(authors, func(i, j int) bool {
+		return f(authors[i], authors[j])
+	})
+}
+
+// The names of the built in rankers
+const (
+	RankByLines      = "lines"
+	RankByCommits    = "commits"
+	RankByRecency    = "recency"
+	RankByBlame      = "blame"
+	RankByCommitDays = "commit-days"
+)
+
+// LinesRanker ranks authors by descending number of lines changed. This is the default ranker.
+var LinesRanker Ranker = RankerFunc(func(a, b *CodeownerStat) bool {
+	return a.Lines > b.Lines
+})
+
+// CommitsRanker ranks authors by descending number of commits, then lines changed
+var CommitsRanker Ranker = RankerFunc(func(a, b *CodeownerStat) bool {
+	if a.Commits != b.Commits {
+		return a.Commits > b.Commits
+	}
+
+	return a.Lines > b.Lines
+})
+
+// RecencyRanker ranks authors by their most recent commit, then lines changed
+var RecencyRanker Ranker = RankerFunc(func(a, b *CodeownerStat) bool {
+	if !a.LastCommit.Equal(b.LastCommit) {
+		return a.LastCommit.After(b.LastCommit)
+	}
+
+	return a.Lines > b.Lines
+})
+
+// BlameRanker ranks authors by their descending number of surviving lines, according to
+// git blame, then lines changed. Authors whose code was since rewritten rank lower.
+var BlameRanker Ranker = RankerFunc(func(a, b *CodeownerStat) bool {
+	if a.BlameLines != b.BlameLines {
+		return a.BlameLines > b.BlameLines
+	}
+
+	return a.Lines > b.Lines
+})
+
+// CommitDaysRanker ranks authors by the descending number of distinct days they committed
+// on, then commits and lines changed. Sustained contributions rank above a single burst.
+var CommitDaysRanker Ranker = RankerFunc(func(a, b *CodeownerStat) bool {
+	if a.CommitDays != b.CommitDays {
+		return a.CommitDays > b.CommitDays
+	}
+
+	if a.Commits != b.Commits {
+		return a.Commits > b.Commits
+	}
+
+	return a.Lines > b.Lines
+})
+
+// seededRanker breaks the ties of a ranker randomly, but reproducibly for the same seed,
+// instead of alphabetically by email. The authors are shuffled with the seed before the
+// ranker's stable sort, so only the order of the authors who rank equally changes.
+type seededRanker struct {
+	ranker Ranker
+	seed   int64
+}
+
+func (r seededRanker) Rank(authors AuthorStatSlice) {
+	random := rand.New(rand.NewSource(r.seed))
+	random.Shuffle(len(authors), func(i, j int) {
+		authors[i], authors[j] = authors[j], authors[i]
+	})
+
+	r.ranker.Rank(authors)
+}
+
+var (
+	rankersMu sync.RWMutex
+	rankers   = map[string]Ranker{
+		RankByLines:      LinesRanker,
+		RankByCommits:    CommitsRanker,
+		RankByRecency:    RecencyRanker,
+		RankByBlame:      BlameRanker,
+		RankByCommitDays: CommitDaysRanker,
+	}
+)
+
+// RegisterRanker registers a custom ranker which can then be selected with --rank-by.
+// Registering a ranker with an existing name replaces it.
+func RegisterRanker(name string, ranker Ranker) {
+	rankersMu.Lock()
+	defer rankersMu.Unlock()
+
+	rankers[name] = ranker
+}
+
+// GetRanker gets the registered ranker with the given name
+func GetRanker(name string) (Ranker, bool) {
+	rankersMu.RLock()
+	defer rankersMu.RUnlock()
+
+	ranker, ok := rankers[name]
+	return ranker, ok
+}
+
+// RankerNames gets the sorted names of the registered rankers
+func RankerNames() []string {
+	rankersMu.RLock()
+	defer rankersMu.RUnlock()
+
+	names := make([]string, 0, len(rankers))
+	for name := range rankers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,127 @@
+package codeowners
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+func newRankTestStats() AuthorStats {
+	now := time.Now()
+
+	return AuthorStats{
+		"brandon": {Email: "brandon@opensauced.pizza", Lines: 100, Commits: 1, LastCommit: now.AddDate(0, 0, -10)},
+		"john":    {Email: "john@opensauced.pizza", Lines: 50, Commits: 5, LastCommit: now.AddDate(0, 0, -5)},
+		"nick":    {Email: "nick@opensauced.pizza", Lines: 10, Commits: 2, LastCommit: now},
+	}
+}
+
+func rankedEmails(slice AuthorStatSlice) []string {
+	emails := make([]string, 0, len(slice))
+	for _, stat := range slice {
+		emails = append(emails, stat.Email)
+	}
+
+	return emails
+}
+
+func TestBuiltInRankers(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{RankByLines, []string{"brandon@opensauced.pizza", "john@opensauced.pizza", "nick@opensauced.pizza"}},
+		{RankByCommits, []string{"john@opensauced.pizza", "nick@opensauced.pizza", "brandon@opensauced.pizza"}},
+		{RankByRecency, []string{"nick@opensauced.pizza", "john@opensauced.pizza", "brandon@opensauced.pizza"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranker, ok := GetRanker(tt.name)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, rankedEmails(newRankTestStats().ToRankedSlice(ranker)))
+		})
+	}
+}
+
+func TestCommitDaysRanker(t *testing.T) {
+	stats := newRankTestStats()
+	stats["brandon"].CommitDays = 1
+	stats["john"].CommitDays = 1
+	stats["nick"].CommitDays = 2
+
+	ranker, ok := GetRanker(RankByCommitDays)
+	assert.True(t, ok)
+
+	// authors who committed on as many days rank by commits
+	assert.Equal(t, []string{"nick@opensauced.pizza", "john@opensauced.pizza", "brandon@opensauced.pizza"}, rankedEmails(stats.ToRankedSlice(ranker)))
+}
+
+func TestRegisterRanker(t *testing.T) {
+	// rank by ascending email as a stand in for a custom strategy
+	RegisterRanker("test-email", RankerFunc(func(a, b *CodeownerStat) bool {
+		return a.Email < b.Email
+	}))
+
+	// the registry is global, so the ranker mustn't leak into other tests
+	t.Cleanup(func() {
+		rankersMu.Lock()
+		defer rankersMu.Unlock()
+
+		delete(rankers, "test-email")
+	})
+
+	assert.Contains(t, RankerNames(), "test-email")
+
+	ranker, ok := GetRanker("test-email")
+	assert.True(t, ok)
+
+	configSpec := &config.Spec{
+		Attributions: map[string][]string{
+			"brandonroberts": {"brandon@opensauced.pizza"},
+			"jpmcb":          {"john@opensauced.pizza"},
+			"nickytonline":   {"nick@opensauced.pizza"},
+		},
+	}
+
+	owners := getTopContributorAttributions("main.go", newRankTestStats(), ranker, 2, configSpec)
+	assert.Equal(t, []string{"brandon@opensauced.pizza", "john@opensauced.pizza"}, rankedEmails(owners))
+
+	_, ok = GetRanker("unknown")
+	assert.False(t, ok)
+}
+
+func TestSeededRanker(t *testing.T) {
+	stats := AuthorStats{
+		"brandon": {Email: "brandon@opensauced.pizza", Lines: 100},
+		"john":    {Email: "john@opensauced.pizza", Lines: 10},
+		"nick":    {Email: "nick@opensauced.pizza", Lines: 10},
+		"zeu":     {Email: "zeu@opensauced.pizza", Lines: 10},
+		"bdougie": {Email: "bdougie@opensauced.pizza", Lines: 10},
+	}
+
+	alphabetical := rankedEmails(stats.ToRankedSlice(LinesRanker))
+	assert.Equal(t, []string{"brandon@opensauced.pizza", "bdougie@opensauced.pizza", "john@opensauced.pizza", "nick@opensauced.pizza", "zeu@opensauced.pizza"}, alphabetical)
+
+	shuffled := false
+	for seed := int64(1); seed <= 10; seed++ {
+		ranked := rankedEmails(stats.ToRankedSlice(seededRanker{ranker: LinesRanker, seed: seed}))
+
+		// only the tied authors are reordered
+		assert.Equal(t, "brandon@opensauced.pizza", ranked[0])
+		assert.ElementsMatch(t, alphabetical, ranked)
+
+		// the same seed breaks the ties the same way
+		assert.Equal(t, ranked, rankedEmails(stats.ToRankedSlice(seededRanker{ranker: LinesRanker, seed: seed})))
+
+		if !slices.Equal(alphabetical, ranked) {
+			shuffled = true
+		}
+	}
+
+	assert.True(t, shuffled)
+}
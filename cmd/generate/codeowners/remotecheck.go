@@ -0,0 +1,127 @@
+package codeowners
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jpmcb/gopherlogs/pkg/colors"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+)
+
+// githubRepoPattern matches a GitHub repository: "owner/repo"
+var githubRepoPattern = regexp.MustCompile(`^[A-Za-z\d](?:[A-Za-z\d-]*[A-Za-z\d])?/[A-Za-z\d._-]+$`)
+
+// errRemoteStale is returned with --check-against-remote when the generated file differs
+// from the file in the GitHub repository
+var errRemoteStale = errors.New("the generated file is out of date with the file in the GitHub repository")
+
+// githubContents is the response of the GitHub repository contents API for a file
+type githubContents struct {
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// fetchRemoteFile gets the contents of the file at the path in the GitHub repository's
+// default branch. It returns false when the file, or the repository, isn't found.
+func fetchRemoteFile(client *githubClient, repo string, filePath string) ([]byte, bool, error) {
+	owner, name, _ := strings.Cut(repo, "/")
+
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	var contents githubContents
+	found, err := client.get("/repos/"+url.PathEscape(owner)+"/"+url.PathEscape(name)+"/contents/"+strings.Join(segments, "/"), &contents)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not fetch %s from %s: %w", filePath, repo, err)
+	}
+	if !found {
+		return nil, false, nil
+	}
+
+	// files over 1 MB are listed without their content
+	if contents.Encoding != "base64" {
+		return nil, false, fmt.Errorf("could not fetch %s from %s: unsupported encoding %q", filePath, repo, contents.Encoding)
+	}
+
+	// the content is wrapped across lines
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(contents.Content, "\n", ""))
+	if err != nil {
+		return nil, false, fmt.Errorf("could not decode %s from %s: %w", filePath, repo, err)
+	}
+
+	return data, true, nil
+}
+
+// remoteFilePath gets the path of the output file relative to the repository, which is
+// where the file is fetched from in the GitHub repository
+func remoteFilePath(outputPath string, opts *Options) (string, error) {
+	rel, err := filepath.Rel(opts.path, outputPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("the output file %s must be in the repository to check it against %s", outputPath, opts.checkAgainstRemote)
+	}
+
+	return filepath.ToSlash(rel), nil
+}
+
+// checkAgainstRemote compares the generated file against the file at the same path in the
+// GitHub repository set with --check-against-remote, instead of writing it. When they differ,
+// a unified diff of the changes is written to w and errRemoteStale is returned.
+func checkAgainstRemote(w io.Writer, generated []byte, outputPath string, client *githubClient, opts *Options, color bool) error {
+	filePath, err := remoteFilePath(outputPath, opts)
+	if err != nil {
+		return err
+	}
+
+	remote, found, err := fetchRemoteFile(client, opts.checkAgainstRemote, filePath)
+	if err != nil {
+		if errors.Is(err, errRateLimited) && client.token == "" {
+			return fmt.Errorf("%w. Set a GitHub token in %s for a higher rate limit", err, strings.Join(githubTokenEnvs, " or "))
+		}
+
+		return err
+	}
+
+	if !found {
+		if client.token == "" {
+			return fmt.Errorf("%s was not found in %s. Private repositories need a GitHub token in %s", filePath, opts.checkAgainstRemote, strings.Join(githubTokenEnvs, " or "))
+		}
+
+		return fmt.Errorf("%w: %s doesn't exist in %s", errRemoteStale, filePath, opts.checkAgainstRemote)
+	}
+
+	if bytes.Equal(remote, generated) {
+		opts.logger.V(logging.LogInfo).Style(0, colors.FgGreen).Infof("%s is up to date in %s\n", filePath, opts.checkAgainstRemote)
+		return nil
+	}
+
+	remoteName := opts.checkAgainstRemote + ":" + filePath
+	err = writeDiff(w, remote, generated, remoteName, remoteName+" (generated)", color)
+	if err != nil {
+		return fmt.Errorf("could not diff %s: %w", remoteName, err)
+	}
+
+	return errRemoteStale
+}
+
+// newRemoteCheckClient creates the GitHub client the file is fetched with for
+// --check-against-remote, with the token in the environment. Without a token, only
+// public repositories can be checked, within GitHub's lower unauthenticated rate limit.
+func newRemoteCheckClient(opts *Options) *githubClient {
+	token := githubToken()
+	if token == "" {
+		opts.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("No GitHub token in %s, only public repositories can be checked and the API is rate limited to 60 requests an hour\n", strings.Join(githubTokenEnvs, " or "))
+	}
+
+	return newGitHubClient(&http.Client{Timeout: githubAPITimeout}, githubAPIEndpoint, token)
+}
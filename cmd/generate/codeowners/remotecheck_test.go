@@ -0,0 +1,195 @@
+package codeowners
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
+)
+
+func newRemoteContentsServer(t *testing.T, contents string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/open-sauced/pizza-cli/contents/.github/CODEOWNERS" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		// the API wraps the base64 content across lines
+		encoded := base64.StdEncoding.EncodeToString([]byte(contents))
+		if len(encoded) > 8 {
+			encoded = encoded[:8] + "\n" + encoded[8:]
+		}
+		_ = json.NewEncoder(w).Encode(githubContents{Content: encoded, Encoding: "base64"})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestCheckAgainstRemote(t *testing.T) {
+	t.Parallel()
+
+	remote := "* @jpmcb\n/docs/ @brandonroberts\n"
+	server := newRemoteContentsServer(t, remote)
+
+	newOpts := func(t *testing.T) *Options {
+		t.Helper()
+		return &Options{
+			path:               t.TempDir(),
+			checkAgainstRemote: "open-sauced/pizza-cli",
+			logger:             newTestLogger(t),
+		}
+	}
+
+	t.Run("Up to date", func(t *testing.T) {
+		t.Parallel()
+		opts := newOpts(t)
+
+		var out bytes.Buffer
+		err := checkAgainstRemote(&out, []byte(remote), filepath.Join(opts.path, ".github", "CODEOWNERS"), newGitHubClient(server.Client(), server.URL, "token"), opts, false)
+		require.NoError(t, err)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("Out of date", func(t *testing.T) {
+		t.Parallel()
+		opts := newOpts(t)
+
+		var out bytes.Buffer
+		err := checkAgainstRemote(&out, []byte("* @jpmcb\n/docs/ @nickytonline\n"), filepath.Join(opts.path, ".github", "CODEOWNERS"), newGitHubClient(server.Client(), server.URL, "token"), opts, false)
+		require.ErrorIs(t, err, errRemoteStale)
+
+		assert.Contains(t, out.String(), "--- open-sauced/pizza-cli:.github/CODEOWNERS\n")
+		assert.Contains(t, out.String(), "-/docs/ @brandonroberts\n")
+		assert.Contains(t, out.String(), "+/docs/ @nickytonline\n")
+	})
+
+	t.Run("Missing file with a token", func(t *testing.T) {
+		t.Parallel()
+		opts := newOpts(t)
+
+		err := checkAgainstRemote(&bytes.Buffer{}, []byte(remote), filepath.Join(opts.path, "CODEOWNERS"), newGitHubClient(server.Client(), server.URL, "token"), opts, false)
+		require.ErrorIs(t, err, errRemoteStale)
+		assert.ErrorContains(t, err, "CODEOWNERS doesn't exist in open-sauced/pizza-cli")
+	})
+
+	t.Run("Missing file without a token", func(t *testing.T) {
+		t.Parallel()
+		opts := newOpts(t)
+
+		err := checkAgainstRemote(&bytes.Buffer{}, []byte(remote), filepath.Join(opts.path, "CODEOWNERS"), newGitHubClient(server.Client(), server.URL, ""), opts, false)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, errRemoteStale)
+		assert.ErrorContains(t, err, "Private repositories need a GitHub token")
+	})
+
+	t.Run("Output file outside the repository", func(t *testing.T) {
+		t.Parallel()
+		opts := newOpts(t)
+
+		err := checkAgainstRemote(&bytes.Buffer{}, []byte(remote), filepath.Join(filepath.Dir(opts.path), "CODEOWNERS"), newGitHubClient(server.Client(), server.URL, "token"), opts, false)
+		require.ErrorContains(t, err, "must be in the repository")
+	})
+}
+
+func TestCheckAgainstRemoteCommand(t *testing.T) {
+	dir, _ := newTestRepo(t, testCommit{"John", "john@opensauced.pizza", map[string]string{"main.go": "package main\n"}})
+
+	configPath := filepath.Join(t.TempDir(), ".sauced.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("attribution:\n  jpmcb: [john@opensauced.pizza]\n"), 0600))
+
+	// the fake API serves the file last written to the repository
+	outputPath := filepath.Join(dir, ".github")
+	var remote []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/open-sauced/pizza-cli/contents/.github/CODEOWNERS" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(githubContents{Content: base64.StdEncoding.EncodeToString(remote), Encoding: "base64"})
+	}))
+	t.Cleanup(server.Close)
+
+	endpoint := githubAPIEndpoint
+	githubAPIEndpoint = server.URL
+	t.Cleanup(func() { githubAPIEndpoint = endpoint })
+	t.Setenv("GITHUB_TOKEN", "token")
+
+	generate := func(t *testing.T, args ...string) error {
+		t.Helper()
+
+		cmd := NewCodeownersCommand()
+		cmd.PersistentFlags().Bool(constants.FlagNameTelemetry, true, "")
+		cmd.PersistentFlags().String("config", "", "")
+		cmd.SetArgs(append([]string{dir, "--config", configPath, "--output-path", outputPath}, args...))
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+
+		return cmd.Execute()
+	}
+
+	require.NoError(t, generate(t))
+
+	var err error
+	remote, err = os.ReadFile(filepath.Join(outputPath, "CODEOWNERS"))
+	require.NoError(t, err)
+
+	t.Run("Up to date", func(t *testing.T) {
+		// the flag is left out of the header, so the file generated without it matches
+		require.NoError(t, generate(t, "--check-against-remote", "open-sauced/pizza-cli"))
+	})
+
+	t.Run("Out of date", func(t *testing.T) {
+		remote = append(remote, "docs/ @brandonroberts\n"...)
+
+		err := generate(t, "--check-against-remote", "open-sauced/pizza-cli")
+		require.ErrorIs(t, err, errRemoteStale)
+		assert.Equal(t, constants.ExitCodeStale, utils.ExitCode(err))
+	})
+}
+
+func TestCheckAgainstRemoteFlagValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "Invalid repository", args: []string{"--check-against-remote", "pizza-cli"}},
+		{name: "With dry run", args: []string{"--check-against-remote", "open-sauced/pizza-cli", "--dry-run"}},
+		{name: "With stream", args: []string{"--check-against-remote", "open-sauced/pizza-cli", "--stream"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			configPath := filepath.Join(t.TempDir(), ".sauced.yaml")
+			require.NoError(t, os.WriteFile(configPath, []byte("attribution:\n  jpmcb: [john@opensauced.pizza]\n"), 0600))
+
+			cmd := NewCodeownersCommand()
+			cmd.PersistentFlags().Bool(constants.FlagNameTelemetry, true, "")
+			cmd.PersistentFlags().String("config", "", "")
+			cmd.SetArgs(append([]string{t.TempDir(), "--config", configPath}, tt.args...))
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Equal(t, constants.ExitCodeConfig, utils.ExitCode(err))
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package codeowners
+
+import "strings"
+
+// The ways of crediting the renames of files, with --credit-rename-to
+const (
+	// creditRenameToRenamer doesn't follow renames: a renamed file's history starts at the
+	// rename, which credits the renamer as if they added the file. This is the default.
+	creditRenameToRenamer = "renamer"
+
+	// creditRenameToNone follows renames, crediting the authors before a rename to the
+	// current path, without crediting the renamer for the rename commit
+	creditRenameToNone = "none"
+
+	// creditRenameToBoth follows renames, crediting the authors before a rename to the
+	// current path, and credits the renamer for the rename commit like any other change
+	creditRenameToBoth = "both"
+)
+
+var renameCredits = []string{creditRenameToNone, creditRenameToRenamer, creditRenameToBoth}
+
+// renameTracker follows the renames of files through the history, so the changes made to
+// a file before it was renamed are credited to its current path. The history is walked from
+// the most recent commit, so a rename is seen before the changes made under the old path.
+// Renames are tracked across every branch in commit order, so the paths of files renamed
+// on diverging branches are approximate.
+type renameTracker struct {
+	// the current path of each path files were renamed from
+	renames map[string]string
+}
+
+func newRenameTracker() *renameTracker {
+	return &renameTracker{renames: make(map[string]string)}
+}
+
+// current gets the current path of a file stat's name, i.e., "old => new" for a rename,
+// with the path prefix prepended. Without a tracker, renames aren't followed.
+func (rt *renameTracker) current(prefix, name string) string {
+	from, to, renamed := strings.Cut(name, " => ")
+	if !renamed {
+		to = from
+	}
+
+	if rt == nil {
+		return prefix + to
+	}
+
+	if current, ok := rt.renames[prefix+to]; ok {
+		return current
+	}
+
+	return prefix + to
+}
+
+// follow gets the current path of a file stat's name, like current, and records the renames
+// of "old => new" names so older changes to the old path are credited to the current path.
+// It reports whether the name is a rename.
+func (rt *renameTracker) follow(prefix, name string) (string, bool) {
+	current := rt.current(prefix, name)
+
+	from, _, renamed := strings.Cut(name, " => ")
+	if rt == nil || !renamed {
+		return current, false
+	}
+
+	if prefix+from == current {
+		// renamed back to a previous path
+		delete(rt.renames, current)
+	} else {
+		rt.renames[prefix+from] = current
+	}
+
+	return current, true
+}
@@ -0,0 +1,81 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameTracker(t *testing.T) {
+	t.Parallel()
+
+	renames := newRenameTracker()
+
+	// the history is walked from the most recent commit: c.go was b.go, which was a.go
+	current, renamed := renames.follow("", "b.go => c.go")
+	assert.True(t, renamed)
+	assert.Equal(t, "c.go", current)
+
+	current, renamed = renames.follow("", "a.go => b.go")
+	assert.True(t, renamed)
+	assert.Equal(t, "c.go", current)
+
+	current, renamed = renames.follow("", "a.go")
+	assert.False(t, renamed)
+	assert.Equal(t, "c.go", current)
+
+	assert.Equal(t, "lib/other.go", renames.current("lib/", "other.go"))
+
+	t.Run("Without a tracker, renames aren't followed", func(t *testing.T) {
+		var untracked *renameTracker
+
+		current, renamed := untracked.follow("lib/", "a.go => b.go")
+		assert.False(t, renamed)
+		assert.Equal(t, "lib/b.go", current)
+		assert.Equal(t, "lib/a.go", untracked.current("lib/", "a.go"))
+	})
+}
+
+func TestCreditRenameTo(t *testing.T) {
+	t.Parallel()
+
+	contents := "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n"
+	dir, repo := newTestRepo(t,
+		testCommit{name: "John", email: "john@opensauced.pizza", files: map[string]string{"a.go": contents}},
+		testCommit{name: "Brandon", email: "brandon@opensauced.pizza", files: map[string]string{"a.go": "", "b.go": contents}},
+		testCommit{name: "Nick", email: "nick@opensauced.pizza", files: map[string]string{"b.go": contents + "\nfunc other() {}\n"}},
+	)
+
+	process := func(t *testing.T, creditRenameTo string) FileStats {
+		t.Helper()
+
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, creditRenameTo: creditRenameTo, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+		return fs
+	}
+
+	t.Run("The renamer is credited for adding the file by default", func(t *testing.T) {
+		fs := process(t, creditRenameToRenamer)
+		require.Len(t, fs["b.go"], 2)
+		assert.Contains(t, fs["b.go"], "Brandon <brandon@opensauced.pizza>")
+		assert.Contains(t, fs["b.go"], "Nick <nick@opensauced.pizza>")
+		assert.Contains(t, fs, "a.go")
+	})
+
+	t.Run("Renames are followed without crediting the renamer", func(t *testing.T) {
+		fs := process(t, creditRenameToNone)
+		assert.NotContains(t, fs, "a.go")
+		require.Len(t, fs["b.go"], 2)
+		assert.Equal(t, 1, fs["b.go"]["John <john@opensauced.pizza>"].Commits)
+		assert.Equal(t, 1, fs["b.go"]["Nick <nick@opensauced.pizza>"].Commits)
+	})
+
+	t.Run("Renames are followed crediting the renamer too", func(t *testing.T) {
+		fs := process(t, creditRenameToBoth)
+		assert.NotContains(t, fs, "a.go")
+		require.Len(t, fs["b.go"], 3)
+		assert.Equal(t, 1, fs["b.go"]["Brandon <brandon@opensauced.pizza>"].Commits)
+	})
+}
@@ -0,0 +1,65 @@
+package codeowners
+
+import (
+	"regexp"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// revertPattern matches the line "git revert" adds to the message of a revert commit
+var revertPattern = regexp.MustCompile(`(?m)^This reverts commit ([0-9a-f]{40})\b`)
+
+// revertedCommits gets the commits a commit's message says it reverts
+func revertedCommits(message string) []plumbing.Hash {
+	var hashes []plumbing.Hash
+	for _, match := range revertPattern.FindAllStringSubmatch(message, -1) {
+		hashes = append(hashes, plumbing.NewHash(match[1]))
+	}
+
+	return hashes
+}
+
+// revertNetter nets revert pairs out of the stats with --net-reverts: neither a commit
+// which was later reverted, nor the revert of it, credits its author, since the changes
+// don't survive. Reverts are detected by the "This reverts commit <sha>" line in their message.
+type revertNetter struct {
+	// the commits reverted by the more recent commits walked so far
+	reverted map[plumbing.Hash]struct{}
+
+	// the number of commits netted out
+	netted int
+}
+
+func newRevertNetter() *revertNetter {
+	return &revertNetter{reverted: make(map[plumbing.Hash]struct{})}
+}
+
+// nets reports whether the commit is netted out: either it reverts a commit, or a more
+// recent commit reverted it. The history is walked from the most recent commit, so a
+// revert is always seen before the commit it reverts. A revert which was itself reverted
+// reinstates the original commit, so the commits it reverts are still credited.
+// Reverts of commits older than the walked range are netted out on their own.
+func (rn *revertNetter) nets(commit *object.Commit) bool {
+	if rn == nil {
+		return false
+	}
+
+	if _, ok := rn.reverted[commit.Hash]; ok {
+		delete(rn.reverted, commit.Hash)
+		rn.netted++
+		return true
+	}
+
+	hashes := revertedCommits(commit.Message)
+	for _, hash := range hashes {
+		rn.reverted[hash] = struct{}{}
+	}
+
+	if len(hashes) == 0 {
+		return false
+	}
+
+	rn.netted++
+	return true
+}
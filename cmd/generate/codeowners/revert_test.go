@@ -0,0 +1,92 @@
+package codeowners
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevertedCommits(t *testing.T) {
+	t.Parallel()
+
+	first := "0123456789abcdef0123456789abcdef01234567"
+	second := "89abcdef0123456789abcdef0123456789abcdef"
+
+	assert.Equal(t, []plumbing.Hash{plumbing.NewHash(first)}, revertedCommits(fmt.Sprintf("Revert \"Add a feature\"\n\nThis reverts commit %s.\n", first)))
+	assert.Equal(t, []plumbing.Hash{plumbing.NewHash(first), plumbing.NewHash(second)}, revertedCommits(fmt.Sprintf("Revert two commits\n\nThis reverts commit %s.\nThis reverts commit %s.\n", first, second)))
+
+	// the line must be at the start of a line with a full hash
+	assert.Empty(t, revertedCommits("Fix the bug\n\nThis reverts commit 0123456.\n"))
+	assert.Empty(t, revertedCommits(fmt.Sprintf("Mention that this reverts commit %s\n", first)))
+}
+
+func TestNetReverts(t *testing.T) {
+	t.Parallel()
+
+	dir, repo := newTestRepo(t,
+		testCommit{name: "John", email: "john@opensauced.pizza", files: map[string]string{"main.go": "package main\n"}},
+		testCommit{name: "Brandon", email: "brandon@opensauced.pizza", files: map[string]string{"main.go": "package main\n\nfunc main() {}\n"}},
+	)
+
+	// revert commits the contents of main.go with a message reverting the commit
+	revert := func(t *testing.T, repo *git.Repository, name, email, contents string, reverted plumbing.Hash) {
+		t.Helper()
+
+		worktree, err := repo.Worktree()
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(contents), 0600))
+		_, err = worktree.Add("main.go")
+		require.NoError(t, err)
+
+		signature := &object.Signature{Name: name, Email: email, When: time.Now()}
+		_, err = worktree.Commit(fmt.Sprintf("Revert\n\nThis reverts commit %s.\n", reverted), &git.CommitOptions{Author: signature, Committer: signature})
+		require.NoError(t, err)
+	}
+
+	head := func(t *testing.T) plumbing.Hash {
+		t.Helper()
+
+		ref, err := repo.Head()
+		require.NoError(t, err)
+		return ref.Hash()
+	}
+
+	revert(t, repo, "Nick", "nick@opensauced.pizza", "package main\n", head(t))
+
+	process := func(t *testing.T, netReverts bool) AuthorStats {
+		t.Helper()
+
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, netReverts: netReverts, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+		return fs["main.go"]
+	}
+
+	t.Run("Reverts are credited by default", func(t *testing.T) {
+		authorStats := process(t, false)
+		assert.Len(t, authorStats, 3)
+	})
+
+	t.Run("Revert pairs are netted out", func(t *testing.T) {
+		authorStats := process(t, true)
+		require.Len(t, authorStats, 1)
+		assert.Equal(t, 1, authorStats["John <john@opensauced.pizza>"].Commits)
+	})
+
+	t.Run("Reverted reverts reinstate the original commit", func(t *testing.T) {
+		revert(t, repo, "Zeu", "zeu@opensauced.pizza", "package main\n\nfunc main() {}\n", head(t))
+
+		authorStats := process(t, true)
+		require.Len(t, authorStats, 2)
+		assert.Equal(t, 1, authorStats["John <john@opensauced.pizza>"].Commits)
+		assert.Equal(t, 1, authorStats["Brandon <brandon@opensauced.pizza>"].Commits)
+	})
+}
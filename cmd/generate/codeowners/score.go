@@ -0,0 +1,137 @@
+package codeowners
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jpmcb/gopherlogs"
+	"github.com/jpmcb/gopherlogs/pkg/colors"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+)
+
+// scoreCommandTimeout is how long the --score-command may take to score a single commit
+const scoreCommandTimeout = 10 * time.Second
+
+// scoredCommit is the commit metadata written as JSON to the stdin of the --score-command
+type scoredCommit struct {
+	Hash      string             `json:"hash"`
+	Author    scoredIdentity     `json:"author"`
+	Committer scoredIdentity     `json:"committer"`
+	Message   string             `json:"message"`
+	Parents   int                `json:"parents"`
+	Files     []scoredCommitFile `json:"files"`
+}
+
+// scoredIdentity is the author or committer of a scored commit
+type scoredIdentity struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	When  time.Time `json:"when"`
+}
+
+// scoredCommitFile is a file changed by a scored commit
+type scoredCommitFile struct {
+	Name      string `json:"name"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// commitScorer weighs each commit by running the --score-command, which reads the
+// commit's metadata as JSON on stdin and writes its weight, a non-negative number,
+// to stdout. A weight of 0 ignores the commit and 1 gives it full credit.
+type commitScorer struct {
+	command string
+	timeout time.Duration
+	logger  gopherlogs.Logger
+}
+
+func newCommitScorer(command string, logger gopherlogs.Logger) *commitScorer {
+	return &commitScorer{
+		command: command,
+		timeout: scoreCommandTimeout,
+		logger:  logger,
+	}
+}
+
+// score gets the weight of the commit. When the command fails or its output isn't a
+// valid weight, a warning is logged and the commit gets full credit instead.
+func (cs *commitScorer) score(commit *object.Commit, stats object.FileStats) float64 {
+	weight, err := cs.run(commit, stats)
+	if err != nil {
+		cs.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("Could not score commit %s, giving it full credit: %s\n", commit.Hash, err)
+		return 1
+	}
+
+	return weight
+}
+
+// run runs the command with the commit's metadata on stdin and parses its weight
+func (cs *commitScorer) run(commit *object.Commit, stats object.FileStats) (float64, error) {
+	scored := scoredCommit{
+		Hash:      commit.Hash.String(),
+		Author:    scoredIdentity{Name: commit.Author.Name, Email: commit.Author.Email, When: commit.Author.When},
+		Committer: scoredIdentity{Name: commit.Committer.Name, Email: commit.Committer.Email, When: commit.Committer.When},
+		Message:   commit.Message,
+		Parents:   commit.NumParents(),
+		Files:     make([]scoredCommitFile, 0, len(stats)),
+	}
+	for _, stat := range stats {
+		scored.Files = append(scored.Files, scoredCommitFile{Name: stat.Name, Additions: stat.Addition, Deletions: stat.Deletion})
+	}
+
+	input, err := json.Marshal(scored)
+	if err != nil {
+		return 0, fmt.Errorf("could not marshal commit: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cs.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := shellCommand(ctx, cs.command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// children of the shell may keep its output open after it's killed
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return 0, fmt.Errorf("timed out after %s", cs.timeout)
+		}
+
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return 0, fmt.Errorf("%w: %s", err, msg)
+		}
+
+		return 0, err
+	}
+
+	weight, err := strconv.ParseFloat(strings.TrimSpace(stdout.String()), 64)
+	if err != nil || weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+		return 0, fmt.Errorf("invalid weight %q, must be a non-negative number", strings.TrimSpace(stdout.String()))
+	}
+
+	return weight, nil
+}
+
+// shellCommand creates the command to run the command line with the system's shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+
+	//nolint:gosec
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,72 @@
+package codeowners
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitScorer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test commands use sh")
+	}
+
+	commit := &object.Commit{
+		Author:  object.Signature{Name: "John", Email: "john@opensauced.pizza"},
+		Message: "format the code",
+	}
+	stats := object.FileStats{{Name: "main.go", Addition: 10, Deletion: 10}}
+
+	t.Run("reads the commit on stdin", func(t *testing.T) {
+		scorer := newCommitScorer(`grep -q '"message":"format' && echo 0 || echo 1`, newTestLogger(t))
+		assert.Equal(t, 0.0, scorer.score(commit, stats))
+
+		scorer = newCommitScorer(`grep -q '"additions":10' && echo 0.5`, newTestLogger(t))
+		assert.Equal(t, 0.5, scorer.score(commit, stats))
+	})
+
+	t.Run("failures give full credit", func(t *testing.T) {
+		for _, command := range []string{"echo oops >&2; exit 3", "echo heavy", "echo -1", "echo NaN"} {
+			scorer := newCommitScorer(command, newTestLogger(t))
+			_, err := scorer.run(commit, stats)
+			require.Error(t, err, command)
+			assert.Equal(t, 1.0, scorer.score(commit, stats), command)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		scorer := newCommitScorer("exec sleep 5", newTestLogger(t))
+		scorer.timeout = 10 * time.Millisecond
+
+		_, err := scorer.run(commit, stats)
+		require.ErrorContains(t, err, "timed out")
+	})
+
+	t.Run("weighs the processed commits", func(t *testing.T) {
+		dir, repo := newTestRepo(t,
+			testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "a\n"}},
+			testCommit{"John", "john@opensauced.pizza", map[string]string{"main.go": "b\n", "util.go": "c\n"}},
+		)
+
+		po := ProcessOptions{
+			repo:         repo,
+			previousDays: 30,
+			dirPath:      dir,
+			scorer:       newCommitScorer(`grep -q john@ && echo 0 || echo 2`, newTestLogger(t)),
+			logger:       newTestLogger(t),
+		}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, fs["main.go"]["Brandon <brandon@opensauced.pizza>"].Commits)
+		assert.NotContains(t, fs["main.go"], "John <john@opensauced.pizza>")
+
+		// the files of ignored commits are still tracked for the fallback
+		assert.Contains(t, fs, "util.go")
+		assert.Empty(t, fs["util.go"])
+	})
+}
@@ -0,0 +1,98 @@
+package codeowners
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/jpmcb/gopherlogs/pkg/colors"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+)
+
+// outputFileRepoPath gets the path of the output file relative to the repository root,
+// in the slash separated form of the rules. Output files outside the repository have none.
+func outputFileRepoPath(outputPath string, opts *Options) (string, bool) {
+	repoPath, err := filepath.Abs(opts.path)
+	if err != nil {
+		return "", false
+	}
+
+	absOutputPath, err := filepath.Abs(outputPath)
+	if err != nil {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(repoPath, absOutputPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
+}
+
+// selfOwningRule gets the last of the rules whose pattern matches the output file itself,
+// which is the rule GitHub applies to it: its owners must approve every change to the
+// ownership of the repository. The output file's own history makes whoever last regenerated
+// it an owner of a rule like ".github/", which is rarely what's intended.
+func selfOwningRule(rules []string, outputRepoPath string, opts *Options) (string, bool) {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if codeownersPatternMatches(rulePattern(rules[i], opts), outputRepoPath, false) {
+			return rules[i], true
+		}
+	}
+
+	return "", false
+}
+
+// checkSelfOwnership warns when a generated rule grants ownership of the output file itself,
+// unless --output-file-owner writes an explicit rule for it
+func checkSelfOwnership(rules []string, owners map[string]AuthorStatSlice, outputPath string, opts *Options) {
+	if len(opts.outputFileOwners) > 0 || (opts.format != formatGitHub && opts.format != formatGitLab) {
+		return
+	}
+
+	outputRepoPath, ok := outputFileRepoPath(outputPath, opts)
+	if !ok {
+		return
+	}
+
+	rule, ok := selfOwningRule(rules, outputRepoPath, opts)
+	if !ok || len(owners[rule]) == 0 {
+		return
+	}
+
+	opts.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("The rule %s makes %s the owners of %s itself, so they must approve every change to code ownership. Set --output-file-owner to own it explicitly\n", rulePattern(rule, opts), ownerAliases(owners[rule]), outputRepoPath)
+}
+
+// writeOutputFileRule writes the explicit rule of the output file itself with the
+// --output-file-owner owners. It's written last so it overrides any generated rule.
+func writeOutputFileRule(file io.Writer, outputPath string, opts *Options) error {
+	if len(opts.outputFileOwners) == 0 {
+		return nil
+	}
+
+	outputRepoPath, ok := outputFileRepoPath(outputPath, opts)
+	if !ok {
+		opts.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("Skipping the rule of %s since it's outside the repository\n", outputPath)
+		return nil
+	}
+
+	// owners are written as @mentions, unless they're emails
+	owners := make([]string, 0, len(opts.outputFileOwners))
+	for _, owner := range opts.outputFileOwners {
+		if !strings.Contains(owner, "@") {
+			owner = "@" + owner
+		}
+
+		owners = append(owners, owner)
+	}
+
+	_, err := fmt.Fprintf(file, "\n# the owners of this file\n/%s %s\n", cleanFilename(outputRepoPath), strings.Join(owners, " "))
+	if err != nil {
+		return fmt.Errorf("error writing to %s file: %w", outputPath, err)
+	}
+
+	return nil
+}
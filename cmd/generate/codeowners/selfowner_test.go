@@ -0,0 +1,79 @@
+package codeowners
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/jpmcb/gopherlogs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+)
+
+func TestOutputFileRepoPath(t *testing.T) {
+	dir := t.TempDir()
+	opts := &Options{path: dir}
+
+	repoPath, ok := outputFileRepoPath(filepath.Join(dir, ".github", "CODEOWNERS"), opts)
+	require.True(t, ok)
+	assert.Equal(t, ".github/CODEOWNERS", repoPath)
+
+	_, ok = outputFileRepoPath(filepath.Join(filepath.Dir(dir), "CODEOWNERS"), opts)
+	assert.False(t, ok)
+}
+
+func TestSelfOwningRule(t *testing.T) {
+	opts := &Options{format: formatGitHub}
+
+	rule, ok := selfOwningRule([]string{rootDirectoryRule, ".github/", "docs/"}, ".github/CODEOWNERS", opts)
+	require.True(t, ok)
+	assert.Equal(t, ".github/", rule)
+
+	rule, ok = selfOwningRule([]string{rootDirectoryRule, "docs/"}, ".github/CODEOWNERS", opts)
+	require.True(t, ok)
+	assert.Equal(t, rootDirectoryRule, rule)
+
+	_, ok = selfOwningRule([]string{"docs/", "main.go"}, ".github/CODEOWNERS", opts)
+	assert.False(t, ok)
+}
+
+func TestSelfOwnership(t *testing.T) {
+	newTestData := func(t *testing.T) (FileStats, *Options, string, *bytes.Buffer) {
+		t.Helper()
+
+		fileStats, opts := newGranularityTestData()
+		fileStats[".github/CODEOWNERS"] = AuthorStats{
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 50, Commits: 4},
+		}
+
+		var logs bytes.Buffer
+		logger, err := gopherlogs.NewLogger(gopherlogs.WithOutputWriter(&logs), gopherlogs.WithLogVerbosity(logging.LogInfo))
+		require.NoError(t, err)
+		opts.logger = logger
+		opts.format = formatGitHub
+		opts.path = t.TempDir()
+
+		return fileStats, opts, filepath.Join(opts.path, ".github", "CODEOWNERS"), &logs
+	}
+
+	t.Run("a generated rule owning the output file is warned about", func(t *testing.T) {
+		fileStats, opts, outputPath, logs := newTestData(t)
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, outputPath, opts))
+		assert.Contains(t, logs.String(), "The rule /.github/ makes @brandonroberts the owners of .github/CODEOWNERS itself")
+		assert.NotContains(t, buf.String(), "# the owners of this file")
+	})
+
+	t.Run("an explicit rule owns the output file", func(t *testing.T) {
+		fileStats, opts, outputPath, logs := newTestData(t)
+		opts.outputFileOwners = []string{"open-sauced/maintainers", "@jpmcb", "admin@opensauced.pizza"}
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, outputPath, opts))
+		assert.NotContains(t, logs.String(), "itself")
+		assert.True(t, bytes.HasSuffix(buf.Bytes(), []byte("\n# the owners of this file\n/.github/CODEOWNERS @open-sauced/maintainers @jpmcb admin@opensauced.pizza\n")), buf.String())
+	})
+}
@@ -0,0 +1,58 @@
+package codeowners
+
+import (
+	"slices"
+	"strings"
+)
+
+// The sources of a file's owners
+const (
+	ownerSourceTopContributors = "top-contributors"
+	ownerSourceOverride        = "override"
+	ownerSourceFallback        = "fallback"
+	ownerSourceInline          = "inline"
+	ownerSourceAncestor        = "ancestor"
+)
+
+// ruleSource describes how the owners of a rule were derived. Owners from multiple
+// sources, such as top contributors topped up with fallback owners, are listed in order.
+// Example: "top-contributors, fallback"
+func ruleSource(owners AuthorStatSlice) string {
+	var sources []string
+	for _, owner := range owners {
+		if owner.Source != "" && !slices.Contains(sources, owner.Source) {
+			sources = append(sources, owner.Source)
+		}
+	}
+
+	return strings.Join(sources, ", ")
+}
+
+// annotateSource appends a trailing comment with the source of the owners to the
+// line when --annotate-source is set. Lines without owners aren't annotated.
+func annotateSource(line string, owners AuthorStatSlice, opts *Options) string {
+	if !opts.annotateSource {
+		return line
+	}
+
+	source := ruleSource(owners)
+	if source == "" {
+		return line
+	}
+
+	return line + " # source: " + source
+}
+
+// ownerAliases lists the aliases of the owners for logging. Example: "@jpmcb, @brandonroberts"
+func ownerAliases(owners AuthorStatSlice) string {
+	if len(owners) == 0 {
+		return "no owners"
+	}
+
+	aliases := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		aliases = append(aliases, "@"+owner.GitHubAlias)
+	}
+
+	return strings.Join(aliases, ", ")
+}
@@ -0,0 +1,37 @@
+package codeowners
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotateSource(t *testing.T) {
+	fileStats, opts := newGranularityTestData()
+	opts.granularity = granularityFile
+	opts.annotateSource = true
+	opts.config.Overrides = map[string][]string{"docs/api/**": {"open-sauced/docs"}}
+	opts.config.AttributionFallback = []string{"open-sauced/engineering"}
+	opts.config.MinOwners = 2
+	opts.inlineOwners = map[string][]string{"docs/guide.md": {"alice"}}
+	fileStats["unowned.go"] = AuthorStats{}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+
+	assert.Equal(t, "/docs/README.md @brandonroberts @jpmcb # source: top-contributors\n"+
+		"/docs/api/index.md @open-sauced/docs # source: override\n"+
+		"/docs/guide.md @alice # source: inline\n"+
+		"/main.go @brandonroberts @open-sauced/engineering # source: top-contributors, fallback\n"+
+		"/unowned.go @open-sauced/engineering # source: fallback\n", buf.String())
+
+	t.Run("disabled", func(t *testing.T) {
+		opts.annotateSource = false
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+		assert.NotContains(t, buf.String(), "# source:")
+	})
+}
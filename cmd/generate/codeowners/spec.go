@@ -2,7 +2,9 @@ package codeowners
 
 import (
 	"fmt"
+	"math"
 	"sort"
+	"time"
 
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
@@ -11,8 +13,33 @@ import (
 // Example: { "path/to/file": { Author stats }}
 type FileStats map[string]AuthorStats
 
-func (fs FileStats) addStat(filestat *object.FileStat, commit *object.Commit) {
-	author := fmt.Sprintf("%s <%s>", commit.Author.Name, commit.Author.Email)
+// authorKey gets the key of an identity's stats in AuthorStats. Example: "Name <email>"
+func authorKey(identity *object.Signature) string {
+	return fmt.Sprintf("%s <%s>", identity.Name, identity.Email)
+}
+
+// boostAuthors multiplies the credit of an author of each file, i.e., the author who
+// created it, by the boost. Authors without stats for the file are skipped.
+func (fs FileStats) boostAuthors(authors map[string]string, boost float64) {
+	for filename, author := range authors {
+		stat, ok := fs[filename][author]
+		if !ok {
+			continue
+		}
+
+		stat.weightedLines *= boost
+		stat.weightedCommits *= boost
+		stat.Lines = int(math.Round(stat.weightedLines))
+		stat.Commits = int(math.Round(stat.weightedCommits))
+	}
+}
+
+// addStat attributes the lines changed in a file to the given identity of a commit:
+// either its author or its committer. The lines and commit are credited with the given
+// weight, i.e., 0.25 for a merge commit which only gets partial credit. Fractional
+// credit accumulates so the rounded Lines and Commits stay accurate across commits.
+func (fs FileStats) addStat(filestat *object.FileStat, identity *object.Signature, weight float64) {
+	author := authorKey(identity)
 	filename := filestat.Name
 
 	if _, ok := fs[filename]; !ok {
@@ -21,12 +48,115 @@ func (fs FileStats) addStat(filestat *object.FileStat, commit *object.Commit) {
 
 	if _, ok := fs[filename][author]; !ok {
 		fs[filename][author] = &CodeownerStat{
-			Name:  commit.Author.Name,
-			Email: commit.Author.Email,
+			Name:  identity.Name,
+			Email: identity.Email,
 		}
 	}
 
-	fs[filename][author].Lines += filestat.Addition + filestat.Deletion
+	stat := fs[filename][author]
+	stat.weightedLines += weight * float64(filestat.Addition+filestat.Deletion)
+	stat.weightedCommits += weight
+	stat.Lines = int(math.Round(stat.weightedLines))
+	stat.Commits = int(math.Round(stat.weightedCommits))
+	stat.addCommitDay(identity.When)
+
+	if identity.When.After(fs[filename][author].LastCommit) {
+		fs[filename][author].LastCommit = identity.When
+	}
+}
+
+// addContribution attributes a number of lines and commits to a file at once,
+// i.e., from a contributions manifest instead of the git log
+func (fs FileStats) addContribution(filename string, identity *object.Signature, lines, commits int) {
+	author := fmt.Sprintf("%s <%s>", identity.Name, identity.Email)
+
+	if _, ok := fs[filename]; !ok {
+		fs[filename] = make(AuthorStats)
+	}
+
+	if _, ok := fs[filename][author]; !ok {
+		fs[filename][author] = &CodeownerStat{
+			Name:  identity.Name,
+			Email: identity.Email,
+		}
+	}
+
+	stat := fs[filename][author]
+	stat.weightedLines += float64(lines)
+	stat.weightedCommits += float64(commits)
+	stat.Lines += lines
+	stat.Commits += commits
+}
+
+// addFile tracks the given filename without attributing any author stats to it
+func (fs FileStats) addFile(filename string) {
+	if _, ok := fs[filename]; !ok {
+		fs[filename] = make(AuthorStats)
+	}
+}
+
+// removeMatching removes the files matched by the given path matcher.
+// An empty matcher removes nothing.
+func (fs FileStats) removeMatching(pm *pathMatcher) {
+	if pm.isEmpty() {
+		return
+	}
+
+	for filename := range fs {
+		if pm.matches(filename) {
+			delete(fs, filename)
+		}
+	}
+}
+
+// merge adds the stats of each author in other to the author's stats
+func (as AuthorStats) merge(other AuthorStats) {
+	for author, stat := range other {
+		merged, ok := as[author]
+		if !ok {
+			merged = &CodeownerStat{Name: stat.Name, Email: stat.Email}
+			as[author] = merged
+		}
+
+		merged.add(stat)
+	}
+}
+
+// add adds the lines, commits, and commit days of other to the stat
+func (cs *CodeownerStat) add(other *CodeownerStat) {
+	cs.Lines += other.Lines
+	cs.Commits += other.Commits
+	cs.BlameLines += other.BlameLines
+	if other.LastCommit.After(cs.LastCommit) {
+		cs.LastCommit = other.LastCommit
+	}
+
+	// the same day counts once across files
+	for day := range other.commitDays {
+		cs.addDay(day)
+	}
+	cs.CommitDays = max(cs.CommitDays, other.CommitDays)
+}
+
+// removeBelowChurn removes the files changed by fewer than minChurn commits,
+// leaving only the frequently changed files. A minChurn of 0 removes nothing.
+func (fs FileStats) removeBelowChurn(minChurn int) {
+	for filename, authorStats := range fs {
+		if authorStats.churn() < minChurn {
+			delete(fs, filename)
+		}
+	}
+}
+
+// churn is the number of commits which changed the file, counted across its authors.
+// Commits by authors who aren't allowed to be attributed aren't counted.
+func (as AuthorStats) churn() int {
+	churn := 0
+	for _, stat := range as {
+		churn += stat.Commits
+	}
+
+	return churn
 }
 
 // AuthorStats is a mapping of author name email combinations to codeowner stats.
@@ -40,14 +170,62 @@ type CodeownerStat struct {
 	Name        string
 	Email       string
 	Lines       int
+	Commits     int
+	LastCommit  time.Time
 	GitHubAlias string
+
+	// BlameLines is the number of lines of the file, as of the processed ref, last
+	// changed by the author according to git blame. Only loaded with --rank-by blame.
+	BlameLines int
+
+	// CommitDays is the number of distinct days the author committed to the file,
+	// in the time zone of each commit. Not loaded from a contributions manifest.
+	CommitDays int
+
+	// Source is how the owner was derived. Example: "top-contributors"
+	Source string
+
+	// the weighted lines and commits, which Lines and Commits are rounded from
+	weightedLines   float64
+	weightedCommits float64
+
+	// the distinct days of the author's commits, which CommitDays counts
+	commitDays map[int64]struct{}
+}
+
+// addCommitDay records the day of a commit in its own time zone
+func (cs *CodeownerStat) addCommitDay(when time.Time) {
+	year, month, day := when.Date()
+	cs.addDay(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60))
+}
+
+// addDay records a day, counted from the Unix epoch
+func (cs *CodeownerStat) addDay(day int64) {
+	if cs.commitDays == nil {
+		cs.commitDays = make(map[int64]struct{})
+	}
+
+	cs.commitDays[day] = struct{}{}
+	cs.CommitDays = len(cs.commitDays)
 }
 
 // AuthorStatSlice is a slice of codeowner stats. This is a utility type that makes
 // turning a mapping of author stats to slices easy.
 type AuthorStatSlice []*CodeownerStat
 
+// ToSortedSlice sorts the author stats by descending number of lines
 func (as AuthorStats) ToSortedSlice() AuthorStatSlice {
+	return as.ToRankedSlice(LinesRanker)
+}
+
+// ToRankedSlice sorts the author stats with the given ranker. A nil ranker ranks by lines.
+// Authors the ranker considers equal are ordered by email, then name, so the ranking
+// is deterministic regardless of map iteration order.
+func (as AuthorStats) ToRankedSlice(ranker Ranker) AuthorStatSlice {
+	if ranker == nil {
+		ranker = LinesRanker
+	}
+
 	slice := make(AuthorStatSlice, 0, len(as))
 
 	for _, stat := range as {
@@ -55,9 +233,14 @@ func (as AuthorStats) ToSortedSlice() AuthorStatSlice {
 	}
 
 	sort.Slice(slice, func(i, j int) bool {
-		// sort the author stats by descending number of lines
-		return slice[i].Lines > slice[j].Lines
+		if slice[i].Email != slice[j].Email {
+			return slice[i].Email < slice[j].Email
+		}
+
+		return slice[i].Name < slice[j].Name
 	})
 
+	ranker.Rank(slice)
+
 	return slice
 }
@@ -0,0 +1,222 @@
+package codeowners
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	bubblesTable "github.com/charmbracelet/bubbles/table"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
+)
+
+// statsReport is the aggregate ownership report printed by "--stats-only"
+type statsReport struct {
+	Owners      ownerStatsSlice     `json:"owners"`
+	Directories directoryStatsSlice `json:"directories"`
+}
+
+// ownerStat is the aggregate ownership of a single owner across every file
+type ownerStat struct {
+	Owner string  `json:"owner"`
+	Files int     `json:"files"`
+	Share float64 `json:"share"`
+}
+
+type ownerStatsSlice []ownerStat
+
+// directoryStat is the bus factor of a single top level directory: the minimum
+// number of people whose departure would leave one of its files unowned.
+// Directories with a bus factor of 1 are flagged as risky.
+type directoryStat struct {
+	Directory        string `json:"directory"`
+	Files            int    `json:"files"`
+	BusFactor        int    `json:"busFactor"`
+	SingleOwnerFiles int    `json:"singleOwnerFiles"`
+	Risky            bool   `json:"risky"`
+}
+
+type directoryStatsSlice []directoryStat
+
+// computeStatsReport attributes owners to every file and aggregates them
+// into per owner totals and per directory bus factors. Group references are
+// expanded so a group counts as each of its members.
+func computeStatsReport(fileStats FileStats, opts *Options) (statsReport, error) {
+	attributions := make(map[string]AuthorStatSlice, len(fileStats))
+	for filename, authorStats := range fileStats {
+		owners, err := expandOwnerGroups(getOwners(filename, authorStats, opts), opts.config)
+		if err != nil {
+			return statsReport{}, fmt.Errorf("error expanding the owners of %s: %w", filename, err)
+		}
+
+		attributions[filename] = owners
+	}
+
+	return statsReport{
+		Owners:      computeOwnerStats(attributions),
+		Directories: computeDirectoryStats(attributions),
+	}, nil
+}
+
+// computeOwnerStats aggregates the attributed owners of every file into per owner
+// totals: the number of files they own and the fraction of all files that is.
+// The result is sorted by descending number of files.
+func computeOwnerStats(attributions map[string]AuthorStatSlice) ownerStatsSlice {
+	fileCounts := make(map[string]int)
+	for _, owners := range attributions {
+		for _, owner := range owners {
+			fileCounts[owner.GitHubAlias]++
+		}
+	}
+
+	stats := make(ownerStatsSlice, 0, len(fileCounts))
+	for owner, count := range fileCounts {
+		stats = append(stats, ownerStat{
+			Owner: owner,
+			Files: count,
+			Share: float64(count) / float64(len(attributions)),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Files != stats[j].Files {
+			return stats[i].Files > stats[j].Files
+		}
+
+		return stats[i].Owner < stats[j].Owner
+	})
+
+	return stats
+}
+
+// computeDirectoryStats computes the bus factor of each top level directory.
+// Files in the root of the repository are grouped under ".".
+// The result is sorted by risk: lowest bus factor first, then by the most single owner files.
+func computeDirectoryStats(attributions map[string]AuthorStatSlice) directoryStatsSlice {
+	directories := make(map[string]*directoryStat)
+	for filename, owners := range attributions {
+		directory := "."
+		if i := strings.Index(filename, "/"); i > 0 {
+			directory = filename[:i]
+		}
+
+		stat, ok := directories[directory]
+		if !ok {
+			stat = &directoryStat{
+				Directory: directory,
+				BusFactor: len(owners),
+			}
+			directories[directory] = stat
+		}
+
+		stat.Files++
+		stat.BusFactor = min(stat.BusFactor, len(owners))
+		if len(owners) <= 1 {
+			stat.SingleOwnerFiles++
+		}
+	}
+
+	stats := make(directoryStatsSlice, 0, len(directories))
+	for _, stat := range directories {
+		stat.Risky = stat.BusFactor <= 1
+		stats = append(stats, *stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].BusFactor != stats[j].BusFactor {
+			return stats[i].BusFactor < stats[j].BusFactor
+		}
+
+		if stats[i].SingleOwnerFiles != stats[j].SingleOwnerFiles {
+			return stats[i].SingleOwnerFiles > stats[j].SingleOwnerFiles
+		}
+
+		return stats[i].Directory < stats[j].Directory
+	})
+
+	return stats
+}
+
+func (sr statsReport) BuildOutput(format string) (string, error) {
+	switch format {
+	case constants.OutputTable:
+		return sr.Owners.OutputTable() + "\n\n" + sr.Directories.OutputTable(), nil
+	case constants.OutputJSON:
+		return utils.OutputJSON(sr)
+	default:
+		return "", fmt.Errorf("unknown stats format %s", format)
+	}
+}
+
+func (oss ownerStatsSlice) OutputTable() string {
+	rows := make([]bubblesTable.Row, 0, len(oss))
+	for _, stat := range oss {
+		rows = append(rows, bubblesTable.Row{
+			"@" + stat.Owner,
+			strconv.Itoa(stat.Files),
+			fmt.Sprintf("%.1f%%", stat.Share*100),
+		})
+	}
+
+	columns := []bubblesTable.Column{
+		{
+			Title: "Owner",
+			Width: max(utils.GetMaxTableRowWidth(rows), len("Owner")),
+		},
+		{
+			Title: "Files",
+			Width: 8,
+		},
+		{
+			Title: "Share",
+			Width: 8,
+		},
+	}
+
+	return utils.OutputTable(rows, columns)
+}
+
+func (dss directoryStatsSlice) OutputTable() string {
+	rows := make([]bubblesTable.Row, 0, len(dss))
+	for _, stat := range dss {
+		risk := ""
+		if stat.Risky {
+			risk = "RISKY"
+		}
+
+		rows = append(rows, bubblesTable.Row{
+			stat.Directory,
+			strconv.Itoa(stat.Files),
+			strconv.Itoa(stat.BusFactor),
+			strconv.Itoa(stat.SingleOwnerFiles),
+			risk,
+		})
+	}
+
+	columns := []bubblesTable.Column{
+		{
+			Title: "Directory",
+			Width: max(utils.GetMaxTableRowWidth(rows), len("Directory")),
+		},
+		{
+			Title: "Files",
+			Width: 8,
+		},
+		{
+			Title: "Bus factor",
+			Width: 11,
+		},
+		{
+			Title: "Single owner files",
+			Width: 19,
+		},
+		{
+			Title: "Risk",
+			Width: 6,
+		},
+	}
+
+	return utils.OutputTable(rows, columns)
+}
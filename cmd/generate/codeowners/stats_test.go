@@ -0,0 +1,93 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+)
+
+func newStatsTestData() (FileStats, *Options) {
+	opts := &Options{
+		maxOwners: 3,
+		config: &config.Spec{
+			Attributions: map[string][]string{
+				"brandonroberts": {"brandon@opensauced.pizza"},
+				"jpmcb":          {"john@opensauced.pizza"},
+			},
+			AttributionFallback: []string{"open-sauced/engineering"},
+		},
+	}
+
+	fileStats := FileStats{
+		"cmd/a.go": {
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 10},
+			"john":    {Email: "john@opensauced.pizza", Lines: 5},
+		},
+		"cmd/b.go": {
+			"brandon": {Email: "brandon@opensauced.pizza", Lines: 10},
+			"john":    {Email: "john@opensauced.pizza", Lines: 5},
+		},
+		"pkg/c.go": {
+			"john": {Email: "john@opensauced.pizza", Lines: 10},
+		},
+		"main.go": {},
+	}
+
+	return fileStats, opts
+}
+
+func TestComputeOwnerStats(t *testing.T) {
+	fileStats, opts := newStatsTestData()
+	report, err := computeStatsReport(fileStats, opts)
+	require.NoError(t, err)
+
+	require.Len(t, report.Owners, 3)
+	assert.Equal(t, ownerStat{Owner: "jpmcb", Files: 3, Share: 0.75}, report.Owners[0])
+	assert.Equal(t, ownerStat{Owner: "brandonroberts", Files: 2, Share: 0.5}, report.Owners[1])
+	assert.Equal(t, ownerStat{Owner: "open-sauced/engineering", Files: 1, Share: 0.25}, report.Owners[2])
+
+	output, err := report.BuildOutput(constants.OutputJSON)
+	require.NoError(t, err)
+	assert.Contains(t, output, `"owner": "brandonroberts"`)
+
+	output, err = report.BuildOutput(constants.OutputTable)
+	require.NoError(t, err)
+	assert.Contains(t, output, "@jpmcb")
+}
+
+func TestComputeDirectoryStats(t *testing.T) {
+	fileStats, opts := newStatsTestData()
+	report, err := computeStatsReport(fileStats, opts)
+	require.NoError(t, err)
+
+	require.Len(t, report.Directories, 3)
+	assert.Equal(t, directoryStat{Directory: ".", Files: 1, BusFactor: 1, SingleOwnerFiles: 1, Risky: true}, report.Directories[0])
+	assert.Equal(t, directoryStat{Directory: "pkg", Files: 1, BusFactor: 1, SingleOwnerFiles: 1, Risky: true}, report.Directories[1])
+	assert.Equal(t, directoryStat{Directory: "cmd", Files: 2, BusFactor: 2, SingleOwnerFiles: 0, Risky: false}, report.Directories[2])
+}
+
+func TestComputeStatsReportExpandsGroups(t *testing.T) {
+	fileStats, opts := newStatsTestData()
+	opts.config.Groups = map[string][]string{"frontend": {"@alice", "@bob"}}
+	opts.config.AttributionFallback = []string{"@@frontend"}
+
+	report, err := computeStatsReport(fileStats, opts)
+	require.NoError(t, err)
+
+	// the fallback group counts as each of its members, not as one owner
+	require.Len(t, report.Owners, 4)
+	assert.Contains(t, report.Owners, ownerStat{Owner: "alice", Files: 1, Share: 0.25})
+	assert.Contains(t, report.Owners, ownerStat{Owner: "bob", Files: 1, Share: 0.25})
+	assert.Contains(t, report.Directories, directoryStat{Directory: ".", Files: 1, BusFactor: 2, SingleOwnerFiles: 0, Risky: false})
+
+	t.Run("undefined group", func(t *testing.T) {
+		opts.config.Overrides = map[string][]string{"pkg/**": {"@@missing"}}
+
+		_, err := computeStatsReport(fileStats, opts)
+		require.ErrorContains(t, err, `undefined group "@@missing"`)
+	})
+}
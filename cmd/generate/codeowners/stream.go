@@ -0,0 +1,282 @@
+package codeowners
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/spf13/cobra"
+)
+
+// streamScope is a subset of the repository which is processed and written on
+// its own when streaming. Only one scope's file stats are held in memory at a time.
+type streamScope struct {
+	name    string
+	matches func(filename string) bool
+}
+
+// listStreamScopes lists the scopes to stream in a stable order: the files in the root
+// of the repository first, then each top level directory sorted by name. The directories
+// are those of the trees of every processed commit, and of their parents, so directories
+// deleted within the processed history, which still have stats, are streamed too.
+func listStreamScopes(po *ProcessOptions) ([]streamScope, error) {
+	from, err := po.resolveRef()
+	if err != nil {
+		return nil, err
+	}
+
+	previousTime := po.since()
+	commitIter, err := po.log(from, &previousTime)
+	if err != nil {
+		return nil, fmt.Errorf("could not get repo log iterator: %w", err)
+	}
+	defer commitIter.Close()
+
+	dirs := make(map[string]struct{})
+	addDirs := func(commit *object.Commit) error {
+		tree, err := commit.Tree()
+		if err != nil {
+			return fmt.Errorf("could not get tree for commit %s: %w", commit.Hash, err)
+		}
+
+		for _, entry := range tree.Entries {
+			// submodules are directories of files when they're followed
+			if entry.Mode == filemode.Dir || entry.Mode == filemode.Submodule {
+				dirs[entry.Name] = struct{}{}
+			}
+		}
+
+		return nil
+	}
+
+	walked := 0
+	err = commitIter.ForEach(func(commit *object.Commit) error {
+		if po.maxCommits > 0 && walked == po.maxCommits {
+			return storer.ErrStop
+		}
+		walked++
+
+		err := addDirs(commit)
+		if err != nil {
+			return err
+		}
+
+		// the files of a directory deleted by the commit are only in its parents' trees
+		for _, hash := range commit.ParentHashes {
+			parent, err := po.repo.CommitObject(hash)
+			if errors.Is(err, plumbing.ErrObjectNotFound) {
+				// the history of a shallow clone ends at a missing parent
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("could not get parent commit to commit %s: %w", commit.Hash, err)
+			}
+
+			err = addDirs(parent)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list the top level directories: %w", err)
+	}
+
+	scopes := []streamScope{
+		{
+			name: ".",
+			matches: func(filename string) bool {
+				return !strings.Contains(filename, "/")
+			},
+		},
+	}
+
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Strings(sorted)
+
+	for _, dir := range sorted {
+		prefix := dir + "/"
+		scopes = append(scopes, streamScope{
+			name: dir,
+			matches: func(filename string) bool {
+				return strings.HasPrefix(filename, prefix)
+			},
+		})
+	}
+
+	return scopes, nil
+}
+
+// splitRootStats splits the stats of the files in the root of the repository into those
+// whose rules sort before the rules of a top level directory, and the rest, so the root
+// rules are interleaved with the directories in the same order as the buffered output
+func splitRootStats(rootStats FileStats, dir string, opts *Options) (before FileStats, rest FileStats) {
+	before, rest = make(FileStats), make(FileStats)
+	for filename, authorStats := range rootStats {
+		// the root files are aggregated into the root directory rule, which sorts first
+		if opts.granularity == granularityDirectory || ruleSortKey(filename) < ruleSortKey(dir+"/") {
+			before[filename] = authorStats
+		} else {
+			rest[filename] = authorStats
+		}
+	}
+
+	return before, rest
+}
+
+// streamOutputFile processes and writes the output file one scope at a time,
+// flushing each scope's owners as soon as they are computed. This bounds peak
+// memory to the largest top level directory instead of the whole repository,
+// at the cost of walking the git history once per scope.
+// The rules are written in the same order as the buffered output. With --inherit-owners,
+// files only inherit from the directories of their own scope, since the stats of the
+// other scopes aren't held in memory: the root directory's contributors, for one, are
+// only those of the files in the root.
+func streamOutputFile(po ProcessOptions, ignoreMatcher *pathMatcher, outputPath string, opts *Options, cmd *cobra.Command) error {
+	scopes, err := listStreamScopes(&po)
+	if err != nil {
+		return err
+	}
+
+	file, w, err := createOutputWriter(outputPath, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// the temporary file is already gone once it replaced the output file
+		file.Close()
+		os.Remove(file.Name())
+	}()
+
+	err = writeHeader(w, outputPath, opts, cmd)
+	if err != nil {
+		return err
+	}
+
+	var counts []contributorCount
+	files := 0
+	write := func(fileStats FileStats, ancestors ancestorStats) error {
+		if len(fileStats) == 0 {
+			return nil
+		}
+
+		opts.ancestorStats = ancestors
+		if opts.contributorCountFile != "" {
+			counts = append(counts, countContributors(fileStats)...)
+		}
+
+		files += len(fileStats)
+		return writeFileStats(w, fileStats, outputPath, opts)
+	}
+
+	var rootStats FileStats
+	var rootAncestors ancestorStats
+	for _, scope := range scopes {
+		fileStats, err := processStreamScope(po, scope, ignoreMatcher, opts)
+		if err != nil {
+			return err
+		}
+
+		var ancestors ancestorStats
+		if opts.inheritOwners {
+			ancestors = newAncestorStats(fileStats)
+		}
+
+		// the root files are written as the directories they sort before are reached
+		if scope.name == "." {
+			rootStats, rootAncestors = fileStats, ancestors
+			continue
+		}
+
+		var before FileStats
+		before, rootStats = splitRootStats(rootStats, scope.name, opts)
+		err = write(before, rootAncestors)
+		if err != nil {
+			return err
+		}
+
+		err = write(fileStats, ancestors)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = write(rootStats, rootAncestors)
+	if err != nil {
+		return err
+	}
+
+	err = checkEmptyOutput(files, opts)
+	if err != nil {
+		return err
+	}
+
+	err = replaceOutputFile(file, outputPath)
+	if err != nil {
+		return err
+	}
+
+	if opts.contributorCountFile != "" {
+		sortContributorCounts(counts)
+		return writeContributorCounts(opts.contributorCountFile, counts)
+	}
+
+	return nil
+}
+
+// processStreamScope walks the history for the files of a scope, and attributes them
+func processStreamScope(po ProcessOptions, scope streamScope, ignoreMatcher *pathMatcher, opts *Options) (FileStats, error) {
+	scoped := po
+	scoped.scope = scope.matches
+
+	stopWalk := opts.profiler.phase(phaseWalkHistory)
+	fileStats, err := scoped.process()
+	stopWalk()
+	if err != nil {
+		return nil, fmt.Errorf("error processing %s: %w", scope.name, err)
+	}
+
+	if opts.normalizePaths {
+		fileStats = fileStats.normalizePaths()
+	}
+
+	fileStats.removeMatching(ignoreMatcher)
+	fileStats.removeBelowChurn(opts.minChurn)
+
+	err = applyOrgMembers(fileStats, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error attributing org members of %s: %w", scope.name, err)
+	}
+
+	err = applyActiveMembers(fileStats, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error removing inactive members of %s: %w", scope.name, err)
+	}
+
+	if opts.rankBy == RankByBlame {
+		err = blameFileStats(&po, fileStats, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error blaming %s: %w", scope.name, err)
+		}
+	}
+
+	if opts.readInlineOwners {
+		err = loadInlineOwners(po.repo, fileStats, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return fileStats, nil
+}
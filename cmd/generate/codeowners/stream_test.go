@@ -0,0 +1,214 @@
+package codeowners
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+func newStreamTestRepo(t testing.TB, dirs int, filesPerDir int) (string, ProcessOptions) {
+	t.Helper()
+
+	files := map[string]string{"README.md": "readme\n"}
+	for d := 0; d < dirs; d++ {
+		for f := 0; f < filesPerDir; f++ {
+			files[fmt.Sprintf("dir%d/file%d.go", d, f)] = fmt.Sprintf("package dir%d\n", d)
+		}
+	}
+
+	dir, repo := newTestRepo(t, testCommit{"Brandon", "brandon@opensauced.pizza", files})
+
+	return dir, ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, logger: newTestLogger(t)}
+}
+
+func newStreamTestOptions(t testing.TB, dir string) *Options {
+	t.Helper()
+
+	return &Options{
+		path:      dir,
+		maxOwners: 3,
+		logger:    newTestLogger(t),
+		config: &config.Spec{
+			Attributions: map[string][]string{"brandonroberts": {"brandon@opensauced.pizza"}},
+		},
+	}
+}
+
+func TestStreamOutputFile(t *testing.T) {
+	dir, po := newStreamTestRepo(t, 2, 2)
+	opts := newStreamTestOptions(t, dir)
+
+	outputPath := filepath.Join(t.TempDir(), "CODEOWNERS")
+	require.NoError(t, streamOutputFile(po, nil, outputPath, opts, &cobra.Command{}))
+
+	streamed, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	// the streamed entries are identical to the buffered entries
+	fileStats, err := po.process()
+	require.NoError(t, err)
+
+	var buffered bytes.Buffer
+	require.NoError(t, writeFileStats(&buffered, fileStats, outputPath, opts))
+
+	assert.Contains(t, string(streamed), "/README.md @brandonroberts\n/dir0/file0.go @brandonroberts\n/dir0/file1.go @brandonroberts\n/dir1/file0.go @brandonroberts\n/dir1/file1.go @brandonroberts\n")
+	assert.Contains(t, string(streamed), buffered.String())
+}
+
+func TestStreamOutputFileStrict(t *testing.T) {
+	dir, po := newStreamTestRepo(t, 2, 2)
+	opts := newStreamTestOptions(t, dir)
+	opts.format = formatGitHub
+	opts.strict = true
+	opts.config.Attributions = map[string][]string{"brandon!roberts": {"brandon@opensauced.pizza"}}
+
+	outputDir := t.TempDir()
+	outputPath := filepath.Join(outputDir, "CODEOWNERS")
+	require.NoError(t, os.WriteFile(outputPath, []byte("* @jpmcb\n"), 0600))
+
+	err := streamOutputFile(po, nil, outputPath, opts, &cobra.Command{})
+	require.ErrorIs(t, err, errInvalidCodeowners)
+
+	// the existing file is left as is instead of being partially written
+	contents, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, "* @jpmcb\n", string(contents))
+
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestStreamOutputFileOrder(t *testing.T) {
+	// streamOutput writes the file streamed from the repository, and the buffered output of the same stats
+	streamOutput := func(t *testing.T, opts *Options, commits ...testCommit) (string, string) {
+		t.Helper()
+
+		dir, repo := newTestRepo(t, commits...)
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, logger: newTestLogger(t)}
+		opts.path = dir
+
+		outputPath := filepath.Join(t.TempDir(), "CODEOWNERS")
+		require.NoError(t, streamOutputFile(po, nil, outputPath, opts, &cobra.Command{}))
+
+		streamed, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+
+		fileStats, err := po.process()
+		require.NoError(t, err)
+
+		if opts.inheritOwners {
+			opts.ancestorStats = newAncestorStats(fileStats)
+		}
+
+		var buffered bytes.Buffer
+		require.NoError(t, writeFileStats(&buffered, fileStats, outputPath, opts))
+
+		return string(streamed), buffered.String()
+	}
+
+	t.Run("root files are interleaved with the directories", func(t *testing.T) {
+		opts := newStreamTestOptions(t, "")
+		streamed, buffered := streamOutput(t, opts, testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{
+			"a.go":      "package a\n",
+			"b/main.go": "package b\n",
+			"c.go":      "package c\n",
+			"d/main.go": "package d\n",
+			"e.go":      "package e\n",
+		}})
+
+		assert.Contains(t, streamed, "/a.go @brandonroberts\n/b/main.go @brandonroberts\n/c.go @brandonroberts\n/d/main.go @brandonroberts\n/e.go @brandonroberts\n")
+		assert.Contains(t, streamed, buffered)
+	})
+
+	t.Run("directories deleted from the ref are streamed", func(t *testing.T) {
+		opts := newStreamTestOptions(t, "")
+		streamed, buffered := streamOutput(t, opts,
+			testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{
+				"README.md":  "readme\n",
+				"old/old.go": "package old\n",
+			}},
+			testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"old/old.go": ""}},
+		)
+
+		assert.Contains(t, buffered, "old/old.go")
+		assert.Contains(t, streamed, buffered)
+	})
+
+	t.Run("files only inherit from the directories of their own scope", func(t *testing.T) {
+		opts := newStreamTestOptions(t, "")
+		opts.inheritOwners = true
+		opts.config.MinCommits = 2
+
+		// dir/ has no contributor with enough commits on its own, only the root does
+		streamed, buffered := streamOutput(t, opts,
+			testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"README.md": "readme\n", "dir/main.go": "package dir\n"}},
+			testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"README.md": "readme, again\n"}},
+		)
+
+		assert.Contains(t, buffered, "/dir/main.go @brandonroberts\n")
+		assert.NotContains(t, streamed, "/dir/main.go @brandonroberts\n")
+	})
+}
+
+func TestStreamOutputFileActiveMembers(t *testing.T) {
+	var requests int
+	server := newActiveMembersServer(t, &requests)
+
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"README.md": "readme\n", "dir/main.go": "package dir\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"dir/main.go": "package dir\n\nfunc main() {}\n"}},
+	)
+	po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, logger: newTestLogger(t)}
+
+	opts := newStreamTestOptions(t, dir)
+	opts.config.Attributions["jpmcb"] = []string{"john@opensauced.pizza"}
+	opts.config.AttributionFallback = []string{"open-sauced/engineering"}
+	opts.activeMembers = newOrgMemberResolver(newGitHubClient(server.Client(), server.URL, "token"), "open-sauced")
+
+	outputPath := filepath.Join(t.TempDir(), "CODEOWNERS")
+	require.NoError(t, streamOutputFile(po, nil, outputPath, opts, &cobra.Command{}))
+
+	streamed, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	// brandonroberts isn't a member of the org, so each scope leaves them out
+	assert.Contains(t, string(streamed), "/README.md @open-sauced/engineering\n/dir/main.go @jpmcb\n")
+	assert.Equal(t, 1, requests)
+}
+
+func BenchmarkGenerateBuffered(b *testing.B) {
+	dir, po := newStreamTestRepo(b, 20, 20)
+	opts := newStreamTestOptions(b, dir)
+	outputPath := filepath.Join(b.TempDir(), "CODEOWNERS")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		fileStats, err := po.process()
+		require.NoError(b, err)
+		require.NoError(b, generateOutputFile(fileStats, outputPath, opts, &cobra.Command{}))
+	}
+}
+
+func BenchmarkGenerateStreamed(b *testing.B) {
+	dir, po := newStreamTestRepo(b, 20, 20)
+	opts := newStreamTestOptions(b, dir)
+	outputPath := filepath.Join(b.TempDir(), "CODEOWNERS")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		require.NoError(b, streamOutputFile(po, nil, outputPath, opts, &cobra.Command{}))
+	}
+}
@@ -0,0 +1,37 @@
+package codeowners
+
+import (
+	"strings"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+// mapOwnersToTeams replaces the owners in the config's team-map with their team,
+// keeping the ranked order. Owners mapped to the same team, or to a team which
+// already owns the file, are only written once, in the place of the highest ranked.
+func mapOwnersToTeams(owners AuthorStatSlice, spec *config.Spec) AuthorStatSlice {
+	if len(spec.TeamMap) == 0 {
+		return owners
+	}
+
+	mapped := make(AuthorStatSlice, 0, len(owners))
+	seen := make(map[string]bool, len(owners))
+
+	for _, owner := range owners {
+		if team, ok := spec.TeamMap[owner.GitHubAlias]; ok {
+			// the stats are shared by every rule the owner is attributed to, so they're copied
+			teamOwner := *owner
+			teamOwner.GitHubAlias = strings.TrimPrefix(team, "@")
+			owner = &teamOwner
+		}
+
+		if seen[owner.GitHubAlias] {
+			continue
+		}
+
+		mapped = append(mapped, owner)
+		seen[owner.GitHubAlias] = true
+	}
+
+	return mapped
+}
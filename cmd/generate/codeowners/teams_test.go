@@ -0,0 +1,57 @@
+package codeowners
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapOwnersToTeams(t *testing.T) {
+	newTestData := func() (FileStats, *Options) {
+		fileStats, opts := newGranularityTestData()
+		opts.granularity = granularityFile
+		opts.useTeams = true
+		opts.config.TeamMap = map[string]string{
+			"brandonroberts": "@open-sauced/frontend",
+			"jpmcb":          "open-sauced/frontend",
+		}
+
+		return fileStats, opts
+	}
+
+	t.Run("dedupes owners of the same team", func(t *testing.T) {
+		fileStats, opts := newTestData()
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFileStats(&buf, fileStats, "CODEOWNERS", opts))
+		assert.Equal(t, "/docs/README.md @open-sauced/frontend\n"+
+			"/docs/api/index.md @open-sauced/frontend\n"+
+			"/docs/guide.md @open-sauced/frontend\n"+
+			"/main.go @open-sauced/frontend\n", buf.String())
+	})
+
+	t.Run("keeps unmapped owners in ranked order", func(t *testing.T) {
+		fileStats, opts := newTestData()
+		delete(opts.config.TeamMap, "brandonroberts")
+
+		owners := getOwners("docs/README.md", fileStats["docs/README.md"], opts)
+		require.Len(t, owners, 2)
+		assert.Equal(t, "brandonroberts", owners[0].GitHubAlias)
+		assert.Equal(t, "open-sauced/frontend", owners[1].GitHubAlias)
+		assert.Equal(t, ownerSourceTopContributors, owners[1].Source)
+
+		// the shared stats aren't changed
+		assert.Equal(t, "jpmcb", getTopContributorAttributions("docs/README.md", fileStats["docs/README.md"], opts.ranker, opts.maxOwners, opts.config)[1].GitHubAlias)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		fileStats, opts := newTestData()
+		opts.useTeams = false
+
+		owners := getOwners("main.go", fileStats["main.go"], opts)
+		require.Len(t, owners, 1)
+		assert.Equal(t, "brandonroberts", owners[0].GitHubAlias)
+	})
+}
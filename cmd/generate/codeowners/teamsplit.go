@@ -0,0 +1,119 @@
+package codeowners
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// teamRule is a rule a team owns with every owner of the rule
+type teamRule struct {
+	rule   string
+	owners AuthorStatSlice
+}
+
+// teamFilePath gets the path of a team's CODEOWNERS file beneath the split directory,
+// named after the team within a directory named after its organization.
+// Example: "open-sauced/engineering" is "<dir>/open-sauced/engineering.CODEOWNERS"
+func teamFilePath(dir, team string) string {
+	return filepath.Join(dir, filepath.FromSlash(team)+".CODEOWNERS")
+}
+
+// teamOwner gets the "org/team" of an owner which is a team
+func teamOwner(owner *CodeownerStat) (string, bool) {
+	alias := strings.TrimPrefix(owner.GitHubAlias, "@")
+	if org, team, ok := strings.Cut(alias, "/"); !ok || org == "" || team == "" {
+		return "", false
+	}
+
+	return alias, true
+}
+
+// buildTeamSplit attributes owners to every rule, the same as the other formats, and
+// partitions the rules by the teams which own them. A rule owned by several teams is in
+// each of their partitions, and rules without a team owner aren't in any.
+// The rules of each team are sorted the same as the output file.
+func buildTeamSplit(fileStats FileStats, opts *Options) (map[string][]teamRule, error) {
+	if opts.granularity == granularityDirectory {
+		fileStats = fileStats.aggregateDirectories(opts)
+	}
+
+	rules := make([]string, 0, len(fileStats))
+	for rule := range fileStats {
+		rules = append(rules, rule)
+	}
+	sortRules(rules)
+
+	split := make(map[string][]teamRule)
+	for _, rule := range rules {
+		owners, err := expandOwnerGroups(getOwners(rule, fileStats[rule], opts), opts.config)
+		if err != nil {
+			return nil, fmt.Errorf("error expanding the owners of %s: %w", rule, err)
+		}
+
+		seen := make(map[string]bool)
+		for _, owner := range owners {
+			team, ok := teamOwner(owner)
+			if !ok || seen[team] {
+				continue
+			}
+
+			split[team] = append(split[team], teamRule{rule: rule, owners: owners})
+			seen[team] = true
+		}
+	}
+
+	return split, nil
+}
+
+// generateTeamSplit writes a CODEOWNERS file of the rules each team owns beneath the
+// directory, alongside the output file, for --split-by-team
+func generateTeamSplit(fileStats FileStats, dir string, opts *Options, cmd *cobra.Command) error {
+	stopAttribute := opts.profiler.phase(phaseAttribute)
+	split, err := buildTeamSplit(fileStats, opts)
+	stopAttribute()
+	if err != nil {
+		return err
+	}
+
+	defer opts.profiler.phase(phaseWrite)()
+
+	teams := make([]string, 0, len(split))
+	for team := range split {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+
+	for _, team := range teams {
+		filePath := teamFilePath(dir, team)
+
+		var buf bytes.Buffer
+		w, err := newOutputWriter(&buf, resolveLineEnding(filePath, opts.lineEnding), opts.bom)
+		if err != nil {
+			return err
+		}
+
+		err = writeHeader(w, filePath, opts, cmd)
+		if err != nil {
+			return err
+		}
+
+		for _, teamRule := range split[team] {
+			_, err = writeGitHubCodeownersChunk(teamRule.owners, opts, w, teamRule.rule, filePath)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = writeOutputIfChanged(filePath, buf.Bytes(), opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
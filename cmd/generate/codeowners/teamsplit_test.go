@@ -0,0 +1,90 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useTestTeams maps the owners of the granularity test data to their teams
+func useTestTeams(opts *Options) {
+	opts.granularity = granularityFile
+	opts.useTeams = true
+	opts.config.TeamMap = map[string]string{
+		"brandonroberts": "@open-sauced/web",
+		"jpmcb":          "open-sauced/docs",
+	}
+}
+
+func TestTeamFilePath(t *testing.T) {
+	assert.Equal(t, filepath.Join("teams", "open-sauced", "engineering.CODEOWNERS"), teamFilePath("teams", "open-sauced/engineering"))
+}
+
+func TestBuildTeamSplit(t *testing.T) {
+	t.Run("rules are partitioned by team", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		useTestTeams(opts)
+
+		split, err := buildTeamSplit(fileStats, opts)
+		require.NoError(t, err)
+		require.Len(t, split, 2)
+
+		rules := func(team string) []string {
+			var rules []string
+			for _, teamRule := range split[team] {
+				rules = append(rules, teamRule.rule)
+			}
+
+			return rules
+		}
+
+		// docs/README.md is owned by both teams
+		assert.Equal(t, []string{"docs/README.md", "main.go"}, rules("open-sauced/web"))
+		assert.Equal(t, []string{"docs/README.md", "docs/api/index.md", "docs/guide.md"}, rules("open-sauced/docs"))
+		assert.Len(t, split["open-sauced/web"][0].owners, 2)
+	})
+
+	t.Run("rules without a team owner are left out", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		useTestTeams(opts)
+		delete(opts.config.TeamMap, "jpmcb")
+
+		split, err := buildTeamSplit(fileStats, opts)
+		require.NoError(t, err)
+		require.Len(t, split, 1)
+		assert.Len(t, split["open-sauced/web"], 2)
+	})
+
+	t.Run("directories", func(t *testing.T) {
+		fileStats, opts := newGranularityTestData()
+		useTestTeams(opts)
+		opts.granularity = granularityDirectory
+
+		split, err := buildTeamSplit(fileStats, opts)
+		require.NoError(t, err)
+		assert.Len(t, split["open-sauced/web"], 2)
+		assert.Len(t, split["open-sauced/docs"], 2)
+	})
+}
+
+func TestGenerateTeamSplit(t *testing.T) {
+	fileStats, opts := newGranularityTestData()
+	useTestTeams(opts)
+	dir := t.TempDir()
+
+	require.NoError(t, generateTeamSplit(fileStats, dir, opts, &cobra.Command{}))
+
+	web, err := os.ReadFile(teamFilePath(dir, "open-sauced/web"))
+	require.NoError(t, err)
+	assert.Contains(t, string(web), "main.go @open-sauced/web\n")
+	assert.Contains(t, string(web), "docs/README.md @open-sauced/web @open-sauced/docs\n")
+	assert.NotContains(t, string(web), "guide.md")
+
+	docs, err := os.ReadFile(teamFilePath(dir, "open-sauced/docs"))
+	require.NoError(t, err)
+	assert.Contains(t, string(docs), "docs/guide.md @open-sauced/docs\n")
+}
@@ -2,12 +2,16 @@ package codeowners
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/storer"
 	"github.com/jpmcb/gopherlogs"
@@ -21,6 +25,81 @@ type ProcessOptions struct {
 	repo         *git.Repository
 	previousDays int
 	dirPath      string
+	matcher      *pathMatcher
+
+	// asOf is the time the previous days are counted back from. Defaults to now when zero.
+	asOf time.Time
+
+	// ref is the revision to walk the history back from. Defaults to HEAD when empty.
+	ref string
+
+	// maxCommits, when positive, stops the walk after this many of the most recent
+	// commits in the range. Older changes aren't attributed, so the stats are approximate.
+	maxCommits int
+
+	// attributeBy is the identity of each commit which is attributed: its author (default) or its committer
+	attributeBy string
+
+	// firstParent only walks the first parent of each commit, following the
+	// mainline history like "git log --first-parent"
+	firstParent bool
+
+	// mergeWeight, when weighMerges is set, is the share of credit given for the
+	// changes of a merge commit. Merge commits get full credit otherwise.
+	mergeWeight float64
+	weighMerges bool
+
+	// creatorBoost, when boostCreator is set, multiplies the credit of the author who
+	// created each file, i.e., the commit which added it in the range
+	creatorBoost float64
+	boostCreator bool
+
+	// pathLocalOnly only credits a file's changes in commits which don't change
+	// any analyzed file outside the file's directory rule (see pathLocal)
+	pathLocalOnly bool
+
+	// netReverts nets commits which were later reverted, and the reverts of them, out
+	// of the stats, so neither author is credited for changes which didn't survive
+	netReverts bool
+
+	// creditRenameTo is how renames are credited, one of renameCredits. Unless it's
+	// creditRenameToRenamer, renames are followed so a file's history includes its old paths.
+	creditRenameTo string
+
+	// scorer, when set, weighs each commit with the --score-command, on top of the merge weight
+	scorer *commitScorer
+
+	// followSubmodules processes each initialized submodule's own history and
+	// attributes its files, instead of the superproject's gitlink to it
+	followSubmodules bool
+
+	// pathPrefix is prepended to every filename. Used to name the files of a
+	// submodule relative to the superproject. Example: "lib/"
+	pathPrefix string
+
+	// scope, when set, limits processing to the files it matches.
+	// Used to process the repository one directory at a time when streaming.
+	scope func(filename string) bool
+
+	// excludeMatcher prunes matching paths from analysis before their diffs are computed
+	excludeMatcher *pathMatcher
+
+	// extensionFilter prunes files whose extension isn't included, or is excluded,
+	// from analysis before their diffs are computed
+	extensionFilter *extensionFilter
+
+	// maxDepth, when limitDepth is set, prunes files nested deeper than this many
+	// directories from analysis. Files at the top level have a depth of 0.
+	maxDepth   int
+	limitDepth bool
+
+	// allowedAuthors, when set, restricts attribution to commits whose attributed
+	// identity has an email or name matching one of these globs
+	allowedAuthors []string
+
+	// treeFiles, when set, restricts the processed files to those present in the
+	// HEAD tree object. Used for bare repositories which have no working tree.
+	treeFiles map[string]struct{}
 
 	logger gopherlogs.Logger
 }
@@ -28,20 +107,14 @@ type ProcessOptions struct {
 func (po *ProcessOptions) process() (FileStats, error) {
 	fs := make(FileStats)
 
-	// Get the HEAD reference
-	head, err := po.repo.Head()
+	from, err := po.resolveRef()
 	if err != nil {
-		return nil, fmt.Errorf("could not get repo head: %w", err)
+		return nil, err
 	}
 
-	now := time.Now()
-	previousTime := now.AddDate(0, 0, -po.previousDays)
-
 	// Get the commit history for all files
-	commitIter, err := po.repo.Log(&git.LogOptions{
-		From:  head.Hash(),
-		Since: &previousTime,
-	})
+	previousTime := po.since()
+	commitIter, err := po.log(from, &previousTime)
 	if err != nil {
 		return nil, fmt.Errorf("could not get repo log iterator: %w", err)
 	}
@@ -57,14 +130,65 @@ func (po *ProcessOptions) process() (FileStats, error) {
 		)
 	}(ctx)
 
+	// the author who created each file, from the most recent commit which added it
+	creators := make(map[string]string)
+
+	var netter *revertNetter
+	if po.netReverts {
+		netter = newRevertNetter()
+	}
+
+	var renames *renameTracker
+	if po.followsRenames() {
+		renames = newRenameTracker()
+	}
+
+	walked := 0
 	err = commitIter.ForEach(func(commit *object.Commit) error {
+		if po.maxCommits > 0 && walked == po.maxCommits {
+			po.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Stopping after the most recent %d commits\n", po.maxCommits)
+			return storer.ErrStop
+		}
+		walked++
+
 		// Get the patch for this commit between the head and the parent commit
 		patch, err := po.getPatchForCommit(commit)
 		if err != nil {
 			return fmt.Errorf("could not get patch for commit %s: %w", commit.Hash, err)
 		}
 
-		for _, fileStat := range patch.Stats() {
+		identity := po.identity(commit)
+		allowed := po.isAllowedAuthor(identity)
+
+		weight := 1.0
+		if po.weighMerges && commit.NumParents() > 1 {
+			weight = po.mergeWeight
+		}
+		if netter.nets(commit) {
+			weight = 0
+		}
+
+		stats := patch.Stats()
+		if po.scorer != nil && allowed && weight != 0 && len(stats) > 0 {
+			weight *= po.scorer.score(commit, stats)
+		}
+
+		var changed []string
+		if po.pathLocalOnly {
+			changed = make([]string, 0, len(stats))
+			for _, fileStat := range stats {
+				// files pruned by the maximum depth aren't analyzed, so changing them doesn't make a commit non-local
+				name := renames.current(po.pathPrefix, fileStat.Name)
+				if !po.exceedsMaxDepth(name) {
+					changed = append(changed, name)
+				}
+			}
+		}
+
+		for _, fileStat := range stats {
+			var renamed bool
+			fileStat.Name, renamed = renames.follow(po.pathPrefix, fileStat.Name)
+
 			if !po.isSubPath(po.dirPath, fileStat.Name) {
 				// Explicitly ignore paths that do not exist in the repo.
 				// This is relevant for old changes and filename changes.
@@ -75,7 +199,36 @@ func (po *ProcessOptions) process() (FileStats, error) {
 				return nil
 			}
 
-			fs.addStat(&fileStat, commit)
+			if !po.matcher.matches(fileStat.Name) {
+				continue
+			}
+
+			if po.treeFiles != nil {
+				if _, ok := po.treeFiles[fileStat.Name]; !ok {
+					continue
+				}
+			}
+
+			if !allowed || weight == 0 || (po.pathLocalOnly && !pathLocal(fileStat.Name, changed)) ||
+				(renamed && po.creditRenameTo == creditRenameToNone) {
+				// the file is still tracked so that the fallback
+				// applies when every author has been filtered out
+				fs.addFile(fileStat.Name)
+				continue
+			}
+
+			fs.addStat(&fileStat, identity, weight)
+		}
+
+		if po.boostCreator {
+			// the history is walked from the most recent commit, so a file's
+			// earlier creations, before it was deleted, are ignored
+			for _, name := range addedFiles(patch) {
+				name = renames.current(po.pathPrefix, name)
+				if _, ok := creators[name]; !ok {
+					creators[name] = authorKey(identity)
+				}
+			}
 		}
 
 		return nil
@@ -87,10 +240,182 @@ func (po *ProcessOptions) process() (FileStats, error) {
 	}
 
 	cancel()
+
+	if netter != nil {
+		po.logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Netted out %d reverted and revert commits\n", netter.netted)
+	}
+
+	if po.boostCreator {
+		fs.boostAuthors(creators, po.creatorBoost)
+	}
+
 	po.logger.V(logging.LogInfo).Style(0, colors.FgGreen).ReplaceLinef("Finished processing commits for: %s", po.dirPath)
+
+	if po.followSubmodules {
+		err = po.mergeSubmodules(fs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return fs, nil
 }
 
+// mergeSubmodules replaces the gitlink of each submodule with the stats of the files in
+// the submodule, derived from the submodule's own history. Nested submodules are followed too.
+// Submodules which haven't been initialized, i.e., with "git submodule update --init",
+// have no history to process: they're skipped with a warning and keep their gitlink.
+func (po *ProcessOptions) mergeSubmodules(fs FileStats) error {
+	worktree, err := po.repo.Worktree()
+	if errors.Is(err, git.ErrIsBareRepository) {
+		po.logger.V(logging.LogDebug).Style(0, colors.FgYellow).Warnf("Repo has no working tree, skipping submodules\n")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not get repo worktree: %w", err)
+	}
+
+	submodules, err := worktree.Submodules()
+	if err != nil {
+		return fmt.Errorf("could not read submodules: %w", err)
+	}
+
+	for _, submodule := range submodules {
+		submodulePath := submodule.Config().Path
+
+		// the submodule's checkout is opened directly since go-git initializes
+		// an empty repository for a submodule which hasn't been cloned
+		repo, err := git.PlainOpen(filepath.Join(worktree.Filesystem.Root(), filepath.FromSlash(submodulePath)))
+		if err != nil {
+			po.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("Skipping submodule %s which could not be opened, is it initialized?: %s\n", po.pathPrefix+submodulePath, err)
+			continue
+		}
+
+		sub := *po
+		sub.repo = repo
+		sub.ref = ""
+		sub.treeFiles = nil
+		sub.pathPrefix = po.pathPrefix + submodulePath + "/"
+
+		subStats, err := sub.process()
+		if err != nil {
+			return fmt.Errorf("error processing submodule %s: %w", po.pathPrefix+submodulePath, err)
+		}
+
+		delete(fs, po.pathPrefix+submodulePath)
+		maps.Copy(fs, subStats)
+	}
+
+	return nil
+}
+
+// since gets the time the processed history starts at: the given number of days before now,
+// or before the commit time of --at when it's set
+func (po *ProcessOptions) since() time.Time {
+	now := time.Now()
+	if !po.asOf.IsZero() {
+		now = po.asOf
+	}
+
+	return now.AddDate(0, 0, -po.previousDays)
+}
+
+// log gets an iterator of the commits reachable from the given commit since the given time
+func (po *ProcessOptions) log(from plumbing.Hash, since *time.Time) (object.CommitIter, error) {
+	if !po.firstParent {
+		return po.repo.Log(&git.LogOptions{
+			From:  from,
+			Since: since,
+		})
+	}
+
+	commit, err := po.repo.CommitObject(from)
+	if err != nil {
+		return nil, fmt.Errorf("could not get commit %s: %w", from, err)
+	}
+
+	return object.NewCommitLimitIterFromIter(&firstParentIter{next: commit}, object.LogLimitOptions{Since: since}), nil
+}
+
+// firstParentIter iterates a commit and its chain of first parents
+type firstParentIter struct {
+	next *object.Commit
+}
+
+func (fi *firstParentIter) Next() (*object.Commit, error) {
+	if fi.next == nil {
+		return nil, io.EOF
+	}
+
+	commit := fi.next
+	fi.next = nil
+
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		switch {
+		case errors.Is(err, plumbing.ErrObjectNotFound):
+			// the history of a shallow clone ends at a missing parent
+		case err != nil:
+			return nil, fmt.Errorf("could not get parent commit to commit %s: %w", commit.Hash, err)
+		default:
+			fi.next = parent
+		}
+	}
+
+	return commit, nil
+}
+
+func (fi *firstParentIter) ForEach(cb func(*object.Commit) error) error {
+	for {
+		commit, err := fi.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = cb(commit)
+		if err == storer.ErrStop {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (fi *firstParentIter) Close() {}
+
+// identity gets the identity of the commit which is attributed
+func (po *ProcessOptions) identity(commit *object.Commit) *object.Signature {
+	if po.attributeBy == attributeByCommitter {
+		return &commit.Committer
+	}
+
+	return &commit.Author
+}
+
+// isAllowedAuthor returns true if the commit's attributed identity is allowed to be attributed.
+// Every author is allowed when no allowlist is set.
+func (po *ProcessOptions) isAllowedAuthor(identity *object.Signature) bool {
+	if len(po.allowedAuthors) == 0 {
+		return true
+	}
+
+	for _, allowed := range po.allowedAuthors {
+		if matched, err := matchGlob(allowed, identity.Email); err == nil && matched {
+			return true
+		}
+
+		if matched, err := matchGlob(allowed, identity.Name); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (po *ProcessOptions) isSubPath(basePath, relativePath string) bool {
 	// Clean the paths to remove any '..' or '.' components
 	basePath = filepath.Clean(basePath)
@@ -111,7 +436,7 @@ func (po *ProcessOptions) getPatchForCommit(commit *object.Commit) (*object.Patc
 			return nil, fmt.Errorf("could not get commit tree for commit %s: %w", commit.Hash, err)
 		}
 
-		return parentTree.Patch(commitTree)
+		return po.diffTrees(parentTree, commitTree)
 	}
 
 	commitTree, err := commit.Tree()
@@ -129,5 +454,116 @@ func (po *ProcessOptions) getPatchForCommit(commit *object.Commit) (*object.Patc
 		return nil, fmt.Errorf("could not get parent commit tree for parent commit %s: %w", parentCommit.Hash, err)
 	}
 
-	return parentTree.Patch(commitTree)
+	return po.diffTrees(parentTree, commitTree)
+}
+
+// diffTrees gets the patch between two trees. Changes to excluded paths are
+// dropped before the patch is computed so their history is never read.
+func (po *ProcessOptions) diffTrees(from, to *object.Tree) (*object.Patch, error) {
+	var changes object.Changes
+	var err error
+	if po.followsRenames() {
+		changes, err = object.DiffTreeWithOptions(context.Background(), from, to, object.DefaultDiffTreeOptions)
+	} else {
+		changes, err = object.DiffTree(from, to)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not diff trees: %w", err)
+	}
+
+	if !po.excludeMatcher.isEmpty() || !po.extensionFilter.isEmpty() || po.scope != nil || po.limitDepth {
+		filtered := make(object.Changes, 0, len(changes))
+		for _, change := range changes {
+			name := change.To.Name
+			if name == "" {
+				name = change.From.Name
+			}
+			name = po.pathPrefix + name
+
+			if !po.analyzes(name) {
+				continue
+			}
+
+			filtered = append(filtered, change)
+		}
+
+		changes = filtered
+	}
+
+	return changes.Patch()
+}
+
+// followsRenames returns true if renames are detected and followed, see creditRenameTo
+func (po *ProcessOptions) followsRenames() bool {
+	return po.creditRenameTo == creditRenameToNone || po.creditRenameTo == creditRenameToBoth
+}
+
+// addedFiles lists the files the patch adds
+func addedFiles(patch *object.Patch) []string {
+	var added []string
+	for _, filePatch := range patch.FilePatches() {
+		if from, to := filePatch.Files(); from == nil && to != nil {
+			added = append(added, to.Path())
+		}
+	}
+
+	return added
+}
+
+// analyzes returns true if the file isn't pruned from analysis by the excluded paths,
+// extension filter, maximum depth, or scope
+func (po *ProcessOptions) analyzes(name string) bool {
+	if !po.excludeMatcher.isEmpty() && po.excludeMatcher.matches(name) {
+		return false
+	}
+
+	if !po.extensionFilter.matches(name) {
+		return false
+	}
+
+	if po.exceedsMaxDepth(name) {
+		return false
+	}
+
+	return po.scope == nil || po.scope(name)
+}
+
+// exceedsMaxDepth returns true if the file is nested deeper than the maximum depth
+func (po *ProcessOptions) exceedsMaxDepth(name string) bool {
+	return po.limitDepth && strings.Count(name, "/") > po.maxDepth
+}
+
+// listTreeFiles lists the files in the tree object of the given ref, or of HEAD when
+// the ref is empty. This does not depend on a working tree on disk.
+func listTreeFiles(repo *git.Repository, ref string) (map[string]struct{}, error) {
+	hash, err := (&ProcessOptions{repo: repo, ref: ref}).resolveRef()
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("could not get commit %s: %w", hash, err)
+	}
+
+	return listCommitFiles(commit)
+}
+
+// listCommitFiles lists the files in the commit's tree object
+func listCommitFiles(commit *object.Commit) (map[string]struct{}, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("could not get tree for commit %s: %w", commit.Hash, err)
+	}
+
+	files := make(map[string]struct{})
+	err = tree.Files().ForEach(func(file *object.File) error {
+		files[file.Name] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not iterate tree files: %w", err)
+	}
+
+	return files, nil
 }
@@ -0,0 +1,673 @@
+package codeowners
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jpmcb/gopherlogs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+// testCommit is a commit to apply to a test repository. Files with empty
+// contents are removed from the working tree.
+type testCommit struct {
+	name  string
+	email string
+	files map[string]string
+}
+
+// newTestRepo creates a git repository on disk with the given commits applied in order
+func newTestRepo(t testing.TB, commits ...testCommit) (string, *git.Repository) {
+	t.Helper()
+
+	dir := t.TempDir()
+	return dir, newTestRepoAt(t, dir, commits...)
+}
+
+// newTestRepoAt creates a git repository in the given directory with the given commits applied in order
+func newTestRepoAt(t testing.TB, dir string, commits ...testCommit) *git.Repository {
+	t.Helper()
+
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	when := time.Now().AddDate(0, 0, -len(commits))
+	for i, commit := range commits {
+		for name, contents := range commit.files {
+			path := filepath.Join(dir, filepath.FromSlash(name))
+
+			if contents == "" {
+				_, err = worktree.Remove(name)
+				require.NoError(t, err)
+				continue
+			}
+
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+			_, err = worktree.Add(name)
+			require.NoError(t, err)
+		}
+
+		signature := &object.Signature{
+			Name:  commit.name,
+			Email: commit.email,
+			When:  when.AddDate(0, 0, i),
+		}
+
+		_, err = worktree.Commit("test commit", &git.CommitOptions{
+			Author:    signature,
+			Committer: signature,
+		})
+		require.NoError(t, err)
+	}
+
+	return repo
+}
+
+// commitTestFile writes a file to the repository in the given directory and commits it as
+// the given author, whose email is derived from their name. Parents, when given, replace
+// HEAD as the commit's parents, i.e., to commit a merge.
+func commitTestFile(t testing.TB, dir string, worktree *git.Worktree, name, contents, author string, parents ...plumbing.Hash) plumbing.Hash {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0600))
+	_, err := worktree.Add(name)
+	require.NoError(t, err)
+
+	signature := &object.Signature{Name: author, Email: author + "@opensauced.pizza", When: time.Now()}
+	hash, err := worktree.Commit("test commit", &git.CommitOptions{Author: signature, Committer: signature, Parents: parents})
+	require.NoError(t, err)
+
+	return hash
+}
+
+func newTestLogger(t testing.TB) gopherlogs.Logger {
+	t.Helper()
+
+	logger, err := gopherlogs.NewLogger(gopherlogs.WithOutputWriter(io.Discard))
+	require.NoError(t, err)
+
+	return logger
+}
+
+func TestProcessBareRepository(t *testing.T) {
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"a.go": "a\n", "b.go": "b\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"b.go": ""}},
+	)
+
+	t.Run("working tree", func(t *testing.T) {
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+		assert.Contains(t, fs, "a.go")
+		assert.Contains(t, fs, "b.go")
+	})
+
+	t.Run("bare clone reads files from the tree object", func(t *testing.T) {
+		bareDir := t.TempDir()
+		bareRepo, err := git.PlainClone(bareDir, true, &git.CloneOptions{URL: dir})
+		require.NoError(t, err)
+
+		_, err = bareRepo.Worktree()
+		require.ErrorIs(t, err, git.ErrIsBareRepository)
+
+		treeFiles, err := listTreeFiles(bareRepo, "")
+		require.NoError(t, err)
+
+		po := ProcessOptions{repo: bareRepo, previousDays: 30, dirPath: bareDir, treeFiles: treeFiles, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+		assert.Len(t, fs, 1)
+		assert.Equal(t, 1, fs["a.go"]["Brandon <brandon@opensauced.pizza>"].Lines)
+	})
+}
+
+func TestProcessExcludePath(t *testing.T) {
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "a\n", "vendor/lib/lib.go": "b\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"vendor/lib/lib.go": "c\n", "vendor.go": "d\n"}},
+	)
+
+	excludeMatcher, err := newPrefixMatcher([]string{"vendor/"})
+	require.NoError(t, err)
+
+	po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, excludeMatcher: excludeMatcher, logger: newTestLogger(t)}
+	fs, err := po.process()
+	require.NoError(t, err)
+
+	assert.Contains(t, fs, "main.go")
+	assert.Contains(t, fs, "vendor.go")
+	assert.NotContains(t, fs, "vendor/lib/lib.go")
+}
+
+func TestProcessExtensionFilter(t *testing.T) {
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "a\n", "web/app.ts": "b\n", "docs/guide.md": "c\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"main_test.go": "d\n", "README": "e\n"}},
+	)
+
+	t.Run("include", func(t *testing.T) {
+		extensionFilter, err := newExtensionFilter([]string{".go", ".ts"}, nil)
+		require.NoError(t, err)
+
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, extensionFilter: extensionFilter, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.Len(t, fs, 3)
+		assert.Contains(t, fs, "main.go")
+		assert.Contains(t, fs, "main_test.go")
+		assert.Contains(t, fs, "web/app.ts")
+	})
+
+	t.Run("exclude", func(t *testing.T) {
+		extensionFilter, err := newExtensionFilter(nil, []string{".md"})
+		require.NoError(t, err)
+
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, extensionFilter: extensionFilter, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.Len(t, fs, 4)
+		assert.NotContains(t, fs, "docs/guide.md")
+	})
+
+	t.Run("combined with a path matcher and exclude path", func(t *testing.T) {
+		extensionFilter, err := newExtensionFilter([]string{".go", ".ts"}, nil)
+		require.NoError(t, err)
+
+		matcher, err := newPathMatcher([]string{"*.go", "web/app.ts"})
+		require.NoError(t, err)
+
+		excludeMatcher, err := newPrefixMatcher([]string{"main_test.go"})
+		require.NoError(t, err)
+
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, matcher: matcher, excludeMatcher: excludeMatcher, extensionFilter: extensionFilter, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.Len(t, fs, 2)
+		assert.Contains(t, fs, "main.go")
+		assert.Contains(t, fs, "web/app.ts")
+	})
+}
+
+func TestProcessFollowSubmodules(t *testing.T) {
+	gitmodules := "[submodule \"lib\"]\n\tpath = lib\n\turl = https://example.com/lib.git\n" +
+		"[submodule \"missing\"]\n\tpath = vendor/missing\n\turl = https://example.com/missing.git\n"
+
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "a\n", ".gitmodules": gitmodules}},
+	)
+	newTestRepoAt(t, filepath.Join(dir, "lib"),
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"lib.go": "b\n", "pkg/util.go": "c\n"}},
+	)
+
+	t.Run("followed", func(t *testing.T) {
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, followSubmodules: true, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.Contains(t, fs, "main.go")
+		assert.Equal(t, 1, fs["lib/lib.go"]["John <john@opensauced.pizza>"].Lines)
+		assert.Contains(t, fs, "lib/pkg/util.go")
+		assert.NotContains(t, fs, "lib.go")
+	})
+
+	t.Run("filters apply to superproject paths", func(t *testing.T) {
+		excludeMatcher, err := newPrefixMatcher([]string{"lib/pkg"})
+		require.NoError(t, err)
+
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, followSubmodules: true, excludeMatcher: excludeMatcher, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.Contains(t, fs, "lib/lib.go")
+		assert.NotContains(t, fs, "lib/pkg/util.go")
+	})
+
+	t.Run("not followed", func(t *testing.T) {
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.NotContains(t, fs, "lib/lib.go")
+	})
+}
+
+func TestProcessMaxDepth(t *testing.T) {
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "a\n", "cmd/cmd.go": "b\n", "cmd/sub/sub.go": "c\n"}},
+	)
+
+	t.Run("top level", func(t *testing.T) {
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, maxDepth: 0, limitDepth: true, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.Contains(t, fs, "main.go")
+		assert.NotContains(t, fs, "cmd/cmd.go")
+		assert.NotContains(t, fs, "cmd/sub/sub.go")
+	})
+
+	t.Run("one level", func(t *testing.T) {
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, maxDepth: 1, limitDepth: true, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.Contains(t, fs, "main.go")
+		assert.Contains(t, fs, "cmd/cmd.go")
+		assert.NotContains(t, fs, "cmd/sub/sub.go")
+	})
+}
+
+func TestProcessFirstParent(t *testing.T) {
+	dir, repo := newTestRepo(t, testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "a\n"}})
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	base, err := repo.Head()
+	require.NoError(t, err)
+
+	// a feature branch commit, merged into a mainline commit made in the meantime
+	feature := commitTestFile(t, dir, worktree, "feature.go", "feature.go\n", "john")
+	require.NoError(t, worktree.Reset(&git.ResetOptions{Commit: base.Hash(), Mode: git.HardReset}))
+	mainline := commitTestFile(t, dir, worktree, "other.go", "other.go\n", "brandon")
+	commitTestFile(t, dir, worktree, "feature.go", "feature.go\n", "nick", mainline, feature)
+
+	t.Run("all parents", func(t *testing.T) {
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.Contains(t, fs["feature.go"], "john <john@opensauced.pizza>")
+	})
+
+	t.Run("first parent", func(t *testing.T) {
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, firstParent: true, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.NotContains(t, fs["feature.go"], "john <john@opensauced.pizza>")
+		assert.Contains(t, fs["feature.go"], "nick <nick@opensauced.pizza>")
+		assert.Contains(t, fs["other.go"], "brandon <brandon@opensauced.pizza>")
+		assert.Contains(t, fs, "main.go")
+	})
+}
+
+func TestProcessMergeWeight(t *testing.T) {
+	dir, repo := newTestRepo(t, testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "a\n"}})
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	base, err := repo.Head()
+	require.NoError(t, err)
+
+	// the merge commit brings the feature branch's 4 lines into the mainline
+	feature := commitTestFile(t, dir, worktree, "feature.go", "a\nb\nc\nd\n", "john")
+	require.NoError(t, worktree.Reset(&git.ResetOptions{Commit: base.Hash(), Mode: git.HardReset}))
+	mainline := commitTestFile(t, dir, worktree, "other.go", "other\n", "brandon")
+	commitTestFile(t, dir, worktree, "feature.go", "a\nb\nc\nd\n", "nick", mainline, feature)
+
+	process := func(t *testing.T, po ProcessOptions) FileStats {
+		t.Helper()
+
+		po.repo = repo
+		po.previousDays = 30
+		po.dirPath = dir
+		po.logger = newTestLogger(t)
+
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		return fs
+	}
+
+	t.Run("full credit by default", func(t *testing.T) {
+		fs := process(t, ProcessOptions{})
+
+		assert.Equal(t, 4, fs["feature.go"]["nick <nick@opensauced.pizza>"].Lines)
+		assert.Equal(t, 1, fs["feature.go"]["nick <nick@opensauced.pizza>"].Commits)
+	})
+
+	t.Run("fractional credit", func(t *testing.T) {
+		fs := process(t, ProcessOptions{mergeWeight: 0.25, weighMerges: true})
+
+		nick := fs["feature.go"]["nick <nick@opensauced.pizza>"]
+		assert.Equal(t, 1, nick.Lines)
+		assert.InDelta(t, 0.25, nick.weightedCommits, 0.001)
+
+		// regular commits keep full credit
+		assert.Equal(t, 4, fs["feature.go"]["john <john@opensauced.pizza>"].Lines)
+		assert.Equal(t, 1, fs["feature.go"]["john <john@opensauced.pizza>"].Commits)
+		assert.Equal(t, 1, fs["other.go"]["brandon <brandon@opensauced.pizza>"].Lines)
+	})
+
+	t.Run("no credit", func(t *testing.T) {
+		fs := process(t, ProcessOptions{mergeWeight: 0, weighMerges: true})
+
+		assert.NotContains(t, fs["feature.go"], "nick <nick@opensauced.pizza>")
+		assert.Contains(t, fs["feature.go"], "john <john@opensauced.pizza>")
+	})
+}
+
+func TestAddStatWeight(t *testing.T) {
+	fs := make(FileStats)
+	identity := &object.Signature{Name: "John", Email: "john@opensauced.pizza"}
+
+	for i := 0; i < 4; i++ {
+		fs.addStat(&object.FileStat{Name: "main.go", Addition: 1, Deletion: 1}, identity, 0.25)
+	}
+
+	// fractional credit accumulates instead of being rounded away on each commit
+	assert.Equal(t, 2, fs["main.go"]["John <john@opensauced.pizza>"].Lines)
+	assert.Equal(t, 1, fs["main.go"]["John <john@opensauced.pizza>"].Commits)
+}
+
+func TestAddStatCommitDays(t *testing.T) {
+	fs := make(FileStats)
+	pst := time.FixedZone("PST", -8*60*60)
+	day := time.Date(2024, 3, 1, 9, 0, 0, 0, pst)
+
+	// a burst of commits on a single day, including one late in the evening
+	// which is already the next day in UTC
+	for _, when := range []time.Time{day, day.Add(2 * time.Hour), day.Add(14 * time.Hour)} {
+		fs.addStat(&object.FileStat{Name: "main.go", Addition: 10}, &object.Signature{Name: "Brandon", Email: "brandon@opensauced.pizza", When: when}, 1)
+	}
+
+	// a commit on each of two days
+	for _, when := range []time.Time{day, day.AddDate(0, 1, 0)} {
+		fs.addStat(&object.FileStat{Name: "main.go", Addition: 1}, &object.Signature{Name: "John", Email: "john@opensauced.pizza", When: when}, 1)
+		fs.addStat(&object.FileStat{Name: "docs/README.md", Addition: 1}, &object.Signature{Name: "John", Email: "john@opensauced.pizza", When: when}, 1)
+	}
+
+	brandon := "Brandon <brandon@opensauced.pizza>"
+	john := "John <john@opensauced.pizza>"
+	assert.Equal(t, 1, fs["main.go"][brandon].CommitDays)
+	assert.Equal(t, 3, fs["main.go"][brandon].Commits)
+	assert.Equal(t, 2, fs["main.go"][john].CommitDays)
+
+	ranked := fs["main.go"].ToRankedSlice(CommitDaysRanker)
+	assert.Equal(t, "john@opensauced.pizza", ranked[0].Email)
+
+	// the same day counts once when the files are merged into a directory
+	merged := make(AuthorStats)
+	merged.merge(fs["main.go"])
+	merged.merge(fs["docs/README.md"])
+	assert.Equal(t, 2, merged[john].CommitDays)
+	assert.Equal(t, 4, merged[john].Commits)
+}
+
+func TestProcessAttributeBy(t *testing.T) {
+	dir, repo := newTestRepo(t)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("a\n"), 0600))
+	_, err = worktree.Add("main.go")
+	require.NoError(t, err)
+
+	_, err = worktree.Commit("rebased commit", &git.CommitOptions{
+		Author:    &object.Signature{Name: "John", Email: "john@opensauced.pizza", When: time.Now()},
+		Committer: &object.Signature{Name: "Brandon", Email: "brandon@opensauced.pizza", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	t.Run("author", func(t *testing.T) {
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.Contains(t, fs["main.go"], "John <john@opensauced.pizza>")
+		assert.NotContains(t, fs["main.go"], "Brandon <brandon@opensauced.pizza>")
+	})
+
+	t.Run("committer", func(t *testing.T) {
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, attributeBy: attributeByCommitter, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.Contains(t, fs["main.go"], "Brandon <brandon@opensauced.pizza>")
+		assert.NotContains(t, fs["main.go"], "John <john@opensauced.pizza>")
+	})
+
+	t.Run("allowed committers", func(t *testing.T) {
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, attributeBy: attributeByCommitter, allowedAuthors: []string{"brandon@*"}, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.Contains(t, fs["main.go"], "Brandon <brandon@opensauced.pizza>")
+	})
+}
+
+func TestProcessAllowedAuthors(t *testing.T) {
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"a.go": "a\n", "b.go": "b\n"}},
+		testCommit{"Outside", "outside@example.com", map[string]string{"a.go": "c\n", "c.go": "c\n"}},
+	)
+
+	po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, allowedAuthors: []string{"*@opensauced.pizza"}, logger: newTestLogger(t)}
+	fs, err := po.process()
+	require.NoError(t, err)
+
+	assert.Len(t, fs["a.go"], 1)
+	assert.Contains(t, fs["a.go"], "Brandon <brandon@opensauced.pizza>")
+	assert.Len(t, fs["b.go"], 1)
+
+	// files with no allowed authors are still tracked so the fallback applies
+	require.Contains(t, fs, "c.go")
+	assert.Empty(t, fs["c.go"])
+
+	configSpec := &config.Spec{AttributionFallback: []string{"open-sauced/engineering"}}
+	owners := getTopContributorAttributions("c.go", fs["c.go"], LinesRanker, 3, configSpec)
+	require.Len(t, owners, 1)
+	assert.Equal(t, "open-sauced/engineering", owners[0].GitHubAlias)
+}
+
+func TestProcessMaxCommits(t *testing.T) {
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "package main\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"util.go": "package main\n"}},
+		testCommit{"Nick", "nick@opensauced.pizza", map[string]string{"main.go": "package main\n\nfunc main() {}\n"}},
+	)
+
+	tests := []struct {
+		name       string
+		maxCommits int
+		expected   map[string][]string
+	}{
+		{"unlimited", 0, map[string][]string{
+			"main.go": {"Brandon <brandon@opensauced.pizza>", "Nick <nick@opensauced.pizza>"},
+			"util.go": {"John <john@opensauced.pizza>"},
+		}},
+		{"most recent commits", 2, map[string][]string{
+			"main.go": {"Nick <nick@opensauced.pizza>"},
+			"util.go": {"John <john@opensauced.pizza>"},
+		}},
+		{"more than the history", 10, map[string][]string{
+			"main.go": {"Brandon <brandon@opensauced.pizza>", "Nick <nick@opensauced.pizza>"},
+			"util.go": {"John <john@opensauced.pizza>"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, maxCommits: tt.maxCommits, logger: newTestLogger(t)}
+
+			fs, err := po.process()
+			require.NoError(t, err)
+			require.Len(t, fs, len(tt.expected))
+
+			for filename, authors := range tt.expected {
+				require.Len(t, fs[filename], len(authors))
+				for _, author := range authors {
+					assert.Contains(t, fs[filename], author)
+				}
+			}
+		})
+	}
+
+	t.Run("recency is kept", func(t *testing.T) {
+		all, err := (&ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, logger: newTestLogger(t)}).process()
+		require.NoError(t, err)
+
+		limited, err := (&ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, maxCommits: 2, logger: newTestLogger(t)}).process()
+		require.NoError(t, err)
+
+		// the most recent commits are the ones walked, so the authors still attributed
+		// have the same most recent commit, and rank the same by recency
+		for filename, authorStats := range limited {
+			for author, stat := range authorStats {
+				assert.Equal(t, all[filename][author].LastCommit, stat.LastCommit, author)
+			}
+		}
+		assert.Equal(t, "nick@opensauced.pizza", limited["main.go"].ToRankedSlice(RecencyRanker)[0].Email)
+		assert.Equal(t, "nick@opensauced.pizza", all["main.go"].ToRankedSlice(RecencyRanker)[0].Email)
+	})
+}
+
+func TestRemoveBelowChurn(t *testing.T) {
+	newFileStats := func() FileStats {
+		return FileStats{
+			"hot.go": {
+				"brandon": {Email: "brandon@opensauced.pizza", Lines: 10, Commits: 3},
+				"john":    {Email: "john@opensauced.pizza", Lines: 5, Commits: 2},
+			},
+			"warm.go":   {"john": {Email: "john@opensauced.pizza", Lines: 100, Commits: 2}},
+			"stable.go": {},
+		}
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		fs := newFileStats()
+		fs.removeBelowChurn(0)
+		assert.Len(t, fs, 3)
+	})
+
+	t.Run("counts commits across authors", func(t *testing.T) {
+		fs := newFileStats()
+		fs.removeBelowChurn(5)
+		assert.Len(t, fs, 1)
+		assert.Contains(t, fs, "hot.go")
+	})
+
+	t.Run("threshold is inclusive", func(t *testing.T) {
+		fs := newFileStats()
+		fs.removeBelowChurn(2)
+		assert.Len(t, fs, 2)
+		assert.NotContains(t, fs, "stable.go")
+	})
+}
+
+func TestProcessBoostCreator(t *testing.T) {
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"main.go": "a\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"main.go": "a\nb\n", "util.go": "c\n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"main.go": "a\nb\nc\n"}},
+	)
+
+	brandon := "Brandon <brandon@opensauced.pizza>"
+	john := "John <john@opensauced.pizza>"
+
+	t.Run("without a boost", func(t *testing.T) {
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, fs["main.go"][brandon].Lines)
+		assert.Equal(t, 1, fs["main.go"][brandon].Commits)
+		assert.Equal(t, 2, fs["main.go"][john].Commits)
+	})
+
+	t.Run("boosts the creator of each file", func(t *testing.T) {
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, boostCreator: true, creatorBoost: 2.5, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, fs["main.go"][brandon].Lines)
+		assert.Equal(t, 3, fs["main.go"][brandon].Commits)
+		assert.Equal(t, 2, fs["main.go"][john].Lines)
+		assert.Equal(t, 2, fs["main.go"][john].Commits)
+
+		// John created util.go
+		assert.Equal(t, 3, fs["util.go"][john].Lines)
+		assert.Equal(t, 3, fs["util.go"][john].Commits)
+
+		ranked := fs["main.go"].ToRankedSlice(nil)
+		assert.Equal(t, "brandon@opensauced.pizza", ranked[0].Email)
+	})
+
+	t.Run("files created before the range aren't boosted", func(t *testing.T) {
+		po := ProcessOptions{repo: repo, previousDays: 30, maxCommits: 2, dirPath: dir, boostCreator: true, creatorBoost: 2.5, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		assert.NotContains(t, fs["main.go"], brandon)
+		assert.Equal(t, 2, fs["main.go"][john].Commits)
+	})
+}
+
+func TestProcessPathLocalOnly(t *testing.T) {
+	dir, repo := newTestRepo(t,
+		testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"docs/a.md": "a\n", "docs/api/b.md": "b\n", "main.go": "c\n"}},
+		// a tree-wide sweep
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"docs/a.md": "a \n", "docs/api/b.md": "b \n", "main.go": "c \n"}},
+		testCommit{"John", "john@opensauced.pizza", map[string]string{"docs/a.md": "a \nd\n", "docs/api/b.md": "b \ne\n"}},
+	)
+
+	brandon := "Brandon <brandon@opensauced.pizza>"
+	john := "John <john@opensauced.pizza>"
+
+	po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, pathLocalOnly: true, logger: newTestLogger(t)}
+	fs, err := po.process()
+	require.NoError(t, err)
+
+	// only John's last commit is confined to docs/
+	assert.Equal(t, 1, fs["docs/a.md"][john].Commits)
+	assert.NotContains(t, fs["docs/a.md"], brandon)
+
+	// no commit is confined to docs/api/, though the file is still tracked
+	assert.Contains(t, fs, "docs/api/b.md")
+	assert.Empty(t, fs["docs/api/b.md"])
+
+	// every commit is local to the root directory
+	assert.Equal(t, 1, fs["main.go"][brandon].Commits)
+	assert.Equal(t, 1, fs["main.go"][john].Commits)
+
+	t.Run("files pruned by the maximum depth", func(t *testing.T) {
+		dir, repo := newTestRepo(t,
+			testCommit{"Brandon", "brandon@opensauced.pizza", map[string]string{"docs/a.md": "a\n", "src/api/b.go": "b\n"}},
+		)
+
+		po := ProcessOptions{repo: repo, previousDays: 30, dirPath: dir, pathLocalOnly: true, maxDepth: 1, limitDepth: true, logger: newTestLogger(t)}
+		fs, err := po.process()
+		require.NoError(t, err)
+
+		// src/api/b.go isn't analyzed, so the commit is local to docs/
+		assert.NotContains(t, fs, "src/api/b.go")
+		assert.Equal(t, 1, fs["docs/a.md"][brandon].Commits)
+	})
+}
+
+func TestPathLocal(t *testing.T) {
+	assert.True(t, pathLocal("main.go", []string{"main.go", "docs/a.md"}))
+	assert.True(t, pathLocal("docs/a.md", []string{"docs/a.md", "docs/api/b.md"}))
+	assert.False(t, pathLocal("docs/api/b.md", []string{"docs/a.md", "docs/api/b.md"}))
+	assert.False(t, pathLocal("docs/a.md", []string{"docs/a.md", "docsite/a.md"}))
+}
@@ -0,0 +1,144 @@
+package codeowners
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// codeownersEscapeChar escapes a character in a CODEOWNERS pattern so it's matched literally
+const codeownersEscapeChar = '\\'
+
+// codeownersSpecialChars matches the characters of a literal filename which must be
+// escaped in a CODEOWNERS pattern: anything that is not a unicode letter, mark, or number,
+// an underscore, period, single quote, dash, forward slash, or backslash.
+// Whitespace, including trailing and unicode whitespace, is escaped so it doesn't end the pattern.
+var codeownersSpecialChars = regexp.MustCompile(`([^\p{L}\p{M}\p{N}_\.\'\-\/\\])`)
+
+// ownerPattern matches a GitHub owner: a @user, an @org/team, or an email
+var ownerPattern = regexp.MustCompile(`^(@[\w.-]+(/[\w.-]+)?|[^@\s]+@[^@\s]+)$`)
+
+// The reasons GitHub would reject or silently ignore a line of a CODEOWNERS file
+var (
+	errNegationPattern = errors.New("negation patterns are not supported")
+	errCharacterRange  = errors.New("character ranges are not supported")
+	errUnescapedHash   = errors.New("unescaped # starts a comment, truncating the pattern")
+	errInvalidOwner    = errors.New("invalid owner")
+)
+
+// validateGitHubCodeownersLine checks a line of a GitHub CODEOWNERS file against GitHub's
+// rules and returns an error describing why GitHub would reject or silently ignore it.
+// Blank lines and comments are always valid.
+func validateGitHubCodeownersLine(line string) error {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return nil
+	}
+
+	fields := splitUnescaped(trimmed)
+	pattern := fields[0]
+
+	if strings.HasPrefix(pattern, "!") {
+		return fmt.Errorf("%w: %s", errNegationPattern, pattern)
+	}
+
+	if i := indexUnescaped(pattern, "[]"); i >= 0 {
+		return fmt.Errorf("%w: %s", errCharacterRange, pattern)
+	}
+
+	if i := indexUnescaped(pattern, "#"); i >= 0 {
+		return fmt.Errorf("%w: %s", errUnescapedHash, pattern)
+	}
+
+	for _, owner := range fields[1:] {
+		if strings.HasPrefix(owner, "#") {
+			// the rest of the line is a trailing comment
+			break
+		}
+
+		if !ownerPattern.MatchString(owner) {
+			return fmt.Errorf("%w %q for pattern %s", errInvalidOwner, owner, pattern)
+		}
+	}
+
+	return nil
+}
+
+// splitUnescaped splits a line on whitespace which isn't escaped
+func splitUnescaped(line string) []string {
+	var fields []string
+	var field strings.Builder
+
+	escaped := false
+	for _, c := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case c == codeownersEscapeChar:
+			escaped = true
+		case unicode.IsSpace(c):
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+			continue
+		}
+
+		field.WriteRune(c)
+	}
+
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+
+	return fields
+}
+
+// indexUnescaped gets the index of the first of the chars in s which isn't escaped, or -1
+func indexUnescaped(s string, chars string) int {
+	escaped := false
+	for i, c := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case c == codeownersEscapeChar:
+			escaped = true
+		case strings.ContainsRune(chars, c):
+			return i
+		}
+	}
+
+	return -1
+}
+
+// errInvalidCodeowners is returned when a line GitHub would reject is written in strict mode
+var errInvalidCodeowners = errors.New("invalid CODEOWNERS line")
+
+// codeownersValidator validates each line of a GitHub CODEOWNERS file before it's written.
+// Invalid lines are reported and, in strict mode, fail the write instead of being written.
+// Each write is expected to contain whole lines.
+type codeownersValidator struct {
+	w      io.Writer
+	strict bool
+	report func(line string, err error)
+}
+
+func (cv *codeownersValidator) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(bytes.TrimSuffix(p, []byte("\n")), []byte("\n")) {
+		err := validateGitHubCodeownersLine(string(line))
+		if err == nil {
+			continue
+		}
+
+		cv.report(string(line), err)
+		if cv.strict {
+			return 0, fmt.Errorf("%w: %w", errInvalidCodeowners, err)
+		}
+	}
+
+	return cv.w.Write(p)
+}
@@ -0,0 +1,83 @@
+package codeowners
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateGitHubCodeownersLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		valid bool
+	}{
+		{"blank", "", true},
+		{"comment", "# a comment", true},
+		{"file", "src/main.go @brandonroberts @open-sauced/engineering", true},
+		{"email owner", "src/main.go brandon@opensauced.pizza", true},
+		{"no owners", "src/main.go", true},
+		{"trailing comment", "src/main.go @jpmcb # source: override", true},
+		{"escaped characters", `src/\#notes\[1\].md @jpmcb`, true},
+		{"escaped space", `src/my\ file.go @jpmcb`, true},
+		{"negation", "!src/main.go @jpmcb", false},
+		{"character range", "src/[ab].go @jpmcb", false},
+		{"unescaped comment", "src/a#b.go @jpmcb", false},
+		{"invalid owner", "src/main.go jpmcb", false},
+		{"invalid team", "src/main.go @open-sauced/engineering/docs", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGitHubCodeownersLine(tt.line)
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestCleanFilenameIsValid(t *testing.T) {
+	for _, filename := range []string{"src/#notes.md", "src/[1].md", "!important.md", "src/a@b.go", "docs/naïve café.md", "trailing.go "} {
+		require.NoError(t, validateGitHubCodeownersLine(cleanFilename(filename)+" @jpmcb"), filename)
+	}
+}
+
+func TestCodeownersValidator(t *testing.T) {
+	var reported []string
+	report := func(line string, _ error) {
+		reported = append(reported, line)
+	}
+
+	t.Run("reports invalid lines", func(t *testing.T) {
+		reported = nil
+
+		var buf bytes.Buffer
+		validator := &codeownersValidator{w: &buf, report: report}
+
+		_, err := validator.Write([]byte("main.go @jpmcb\n"))
+		require.NoError(t, err)
+		_, err = validator.Write([]byte("main.go jpmcb\n"))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"main.go jpmcb"}, reported)
+		assert.Equal(t, "main.go @jpmcb\nmain.go jpmcb\n", buf.String())
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		reported = nil
+
+		var buf bytes.Buffer
+		validator := &codeownersValidator{w: &buf, strict: true, report: report}
+
+		_, err := validator.Write([]byte("main.go jpmcb\n"))
+		require.ErrorIs(t, err, errInvalidCodeowners)
+
+		assert.Equal(t, []string{"main.go jpmcb"}, reported)
+		assert.Empty(t, buf.String())
+	})
+}
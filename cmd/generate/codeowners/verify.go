@@ -0,0 +1,178 @@
+package codeowners
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jpmcb/gopherlogs/pkg/colors"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
+)
+
+// githubOwnerVerifier looks up whether config owners exist on GitHub. Each owner
+// is looked up once, no matter how often it's listed in the config.
+type githubOwnerVerifier struct {
+	client *githubClient
+
+	// cache of owner to whether it exists
+	cache map[string]bool
+}
+
+func newGitHubOwnerVerifier(httpClient *http.Client, endpoint string, token string) *githubOwnerVerifier {
+	return &githubOwnerVerifier{
+		client: newGitHubClient(httpClient, endpoint, token),
+		cache:  make(map[string]bool),
+	}
+}
+
+// exists reports whether the owner, a username or an org/team, exists on GitHub
+func (v *githubOwnerVerifier) exists(owner string) (bool, error) {
+	if exists, ok := v.cache[owner]; ok {
+		return exists, nil
+	}
+
+	apiPath := "/users/" + url.PathEscape(owner)
+	if org, team, ok := strings.Cut(owner, "/"); ok {
+		apiPath = "/orgs/" + url.PathEscape(org) + "/teams/" + url.PathEscape(team)
+	}
+
+	exists, err := v.client.get(apiPath, nil)
+	if err != nil {
+		return false, fmt.Errorf("could not look up %s: %w", owner, err)
+	}
+
+	v.cache[owner] = exists
+	return exists, nil
+}
+
+// configOwners gets the GitHub usernames and teams listed in the config, mapped to where
+// they're listed. Emails and group references can't be looked up, so they're left out,
+// but the members of each group are included.
+func configOwners(spec *config.Spec) map[string][]string {
+	owners := make(map[string][]string)
+	add := func(location string, names ...string) {
+		for _, name := range names {
+			if strings.HasPrefix(name, config.GroupReferencePrefix) {
+				continue
+			}
+
+			name = strings.TrimPrefix(name, "@")
+			if name == "" || strings.Contains(name, "@") {
+				continue
+			}
+
+			if !slices.Contains(owners[name], location) {
+				owners[name] = append(owners[name], location)
+			}
+		}
+	}
+
+	for username := range spec.Attributions {
+		add("attribution", username)
+	}
+	for username := range spec.RegexAttributions {
+		add("regex-attributions", username)
+	}
+	add("attribution-fallback", spec.AttributionFallback...)
+	for i, tier := range spec.AttributionFallbackTiers {
+		add(fmt.Sprintf("attribution-fallback-tiers.%d", i+1), tier...)
+	}
+	for name, members := range spec.Groups {
+		add("groups."+name, members...)
+	}
+	for glob, names := range spec.PriorityOwners {
+		add("priority-owners."+glob, names...)
+	}
+	for glob, names := range spec.Overrides {
+		add("overrides."+glob, names...)
+	}
+
+	for _, locations := range owners {
+		sort.Strings(locations)
+	}
+
+	return owners
+}
+
+// deadOwner is a config owner which doesn't exist on GitHub
+type deadOwner struct {
+	owner     string
+	locations []string
+}
+
+// verifyConfigOwners looks up each owner in the config, in sorted order, and returns the
+// ones which don't exist on GitHub. Teams can only be looked up with a token, so they're
+// skipped, and reported through skip, without one.
+func verifyConfigOwners(spec *config.Spec, v *githubOwnerVerifier, skip func(owner string)) ([]deadOwner, error) {
+	owners := configOwners(spec)
+
+	names := make([]string, 0, len(owners))
+	for name := range owners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var dead []deadOwner
+	for _, name := range names {
+		if v.client.token == "" && strings.Contains(name, "/") {
+			skip(name)
+			continue
+		}
+
+		exists, err := v.exists(name)
+		if err != nil {
+			return dead, err
+		}
+
+		if !exists {
+			dead = append(dead, deadOwner{owner: name, locations: owners[name]})
+		}
+	}
+
+	return dead, nil
+}
+
+// writeDeadOwners writes each dead owner and where it's listed in the config
+func writeDeadOwners(w io.Writer, dead []deadOwner) error {
+	for _, owner := range dead {
+		if _, err := fmt.Fprintf(w, "%s does not exist on GitHub (listed in %s)\n", owner.owner, strings.Join(owner.locations, ", ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runVerifyConfig verifies the config owners against GitHub, writing the dead owners to w.
+// An error is returned when any owner doesn't exist.
+func runVerifyConfig(opts *Options, w io.Writer) error {
+	token := githubToken()
+	if token == "" {
+		opts.logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("No GitHub token in %s, teams won't be verified and the rate limit is lower\n", strings.Join(githubTokenEnvs, " or "))
+	}
+
+	verifier := newGitHubOwnerVerifier(&http.Client{Timeout: githubAPITimeout}, githubAPIEndpoint, token)
+	dead, err := verifyConfigOwners(opts.config, verifier, func(owner string) {
+		opts.logger.V(logging.LogDebug).Style(0, colors.FgYellow).Warnf("Skipping team %s without a GitHub token\n", owner)
+	})
+
+	if writeErr := writeDeadOwners(w, dead); writeErr != nil {
+		return utils.NewCLIError(constants.ErrorCodeOutput, "", writeErr)
+	}
+	if err != nil {
+		return utils.NewCLIError(constants.ErrorCodeConfig, opts.configLoadedPath, err).WithField("verify-config")
+	}
+	if len(dead) > 0 {
+		return utils.NewCLIError(constants.ErrorCodeConfig, opts.configLoadedPath, fmt.Errorf("%d config owners do not exist on GitHub", len(dead))).WithField("verify-config")
+	}
+
+	return nil
+}
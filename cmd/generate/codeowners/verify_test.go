@@ -0,0 +1,133 @@
+package codeowners
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/config"
+)
+
+func TestVerifyConfigOwners(t *testing.T) {
+	spec := &config.Spec{
+		Attributions: map[string][]string{
+			"jpmcb":    {"john@opensauced.pizza"},
+			"departed": {"departed@opensauced.pizza"},
+		},
+		AttributionFallback: []string{"open-sauced/engineering"},
+		Groups: map[string][]string{
+			"frontend": {"@jpmcb", "@typo"},
+		},
+		Overrides: map[string][]string{
+			"docs/**": {"@@frontend", "docs@opensauced.pizza", "open-sauced/gone"},
+		},
+	}
+
+	newServer := func(t *testing.T, requests map[string]int) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests[r.URL.Path]++
+			switch r.URL.Path {
+			case "/users/jpmcb", "/orgs/open-sauced/teams/engineering":
+				w.WriteHeader(http.StatusOK)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(server.Close)
+
+		return server
+	}
+
+	t.Run("with a token", func(t *testing.T) {
+		requests := make(map[string]int)
+		server := newServer(t, requests)
+
+		dead, err := verifyConfigOwners(spec, newGitHubOwnerVerifier(server.Client(), server.URL, "token"), func(string) {
+			t.Fatal("nothing should be skipped with a token")
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, writeDeadOwners(&buf, dead))
+		assert.Equal(t, "departed does not exist on GitHub (listed in attribution)\n"+
+			"open-sauced/gone does not exist on GitHub (listed in overrides.docs/**)\n"+
+			"typo does not exist on GitHub (listed in groups.frontend)\n", buf.String())
+
+		// jpmcb is listed twice, but only looked up once
+		assert.Equal(t, 1, requests["/users/jpmcb"])
+		assert.Len(t, requests, 5)
+	})
+
+	t.Run("without a token", func(t *testing.T) {
+		server := newServer(t, make(map[string]int))
+
+		var skipped []string
+		dead, err := verifyConfigOwners(spec, newGitHubOwnerVerifier(server.Client(), server.URL, ""), func(owner string) {
+			skipped = append(skipped, owner)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"open-sauced/engineering", "open-sauced/gone"}, skipped)
+		assert.Len(t, dead, 2)
+	})
+}
+
+func TestGitHubOwnerVerifierRateLimit(t *testing.T) {
+	// each subtest gets its own server so no handler is swapped while a server is running
+	newServer := func(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+		t.Helper()
+
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+
+		return server
+	}
+
+	t.Run("waits for the limit to reset", func(t *testing.T) {
+		limited := true
+		server := newServer(t, func(w http.ResponseWriter, _ *http.Request) {
+			if limited {
+				limited = false
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		})
+
+		v := newGitHubOwnerVerifier(server.Client(), server.URL, "token")
+		var waited time.Duration
+		v.client.sleep = func(d time.Duration) { waited += d }
+
+		exists, err := v.exists("jpmcb")
+		require.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, 30*time.Second, waited)
+	})
+
+	t.Run("gives up on long limits", func(t *testing.T) {
+		now := time.Now()
+		resp := &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}}
+		resp.Header.Set("X-RateLimit-Remaining", "0")
+		resp.Header.Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(time.Hour).Unix(), 10))
+		assert.True(t, isRateLimited(resp))
+		assert.Greater(t, rateLimitWait(resp, now), maxRateLimitWait)
+
+		server := newServer(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+
+		v := newGitHubOwnerVerifier(server.Client(), server.URL, "token")
+		v.client.sleep = func(time.Duration) { t.Fatal("a long limit should not be waited for") }
+
+		_, err := v.exists("jpmcb")
+		require.ErrorIs(t, err, errRateLimited)
+	})
+}
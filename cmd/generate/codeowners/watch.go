@@ -0,0 +1,224 @@
+package codeowners
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-git/go-git/v5"
+	"github.com/jpmcb/gopherlogs"
+	"github.com/jpmcb/gopherlogs/pkg/colors"
+	"github.com/spf13/cobra"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+)
+
+// watchDebounce is how long --watch waits for changes to settle before regenerating,
+// so saving several files, or a commit's many writes, only regenerates once
+const watchDebounce = 500 * time.Millisecond
+
+// watchTargets are the paths whose changes regenerate the output with --watch: the
+// tracked files of the repository, its git directory, i.e., for new commits, and the config.
+// The generated files are skipped so writing them doesn't regenerate them again.
+type watchTargets struct {
+	gitDir     string
+	configPath string
+
+	// the absolute paths of the tracked files and of the generated files
+	tracked   map[string]struct{}
+	generated map[string]struct{}
+}
+
+// newWatchTargets lists the tracked files of the repository from its index
+func newWatchTargets(opts *Options) (*watchTargets, error) {
+	repoPath, err := filepath.Abs(opts.path)
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path of %s: %w", opts.path, err)
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open repository at %s: %w", repoPath, err)
+	}
+
+	index, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("could not read the index of %s: %w", repoPath, err)
+	}
+
+	targets := &watchTargets{
+		gitDir:    filepath.Join(repoPath, ".git"),
+		tracked:   make(map[string]struct{}, len(index.Entries)),
+		generated: make(map[string]struct{}),
+	}
+
+	for _, entry := range index.Entries {
+		targets.tracked[filepath.Join(repoPath, filepath.FromSlash(entry.Name))] = struct{}{}
+	}
+
+	if opts.configLoadedPath != "" {
+		targets.configPath, _ = filepath.Abs(opts.configLoadedPath)
+	}
+
+	for _, generated := range []string{opts.outputFilePath(outputFileType(opts), ""), opts.markdownSummary, opts.contributorCountFile} {
+		if generated == "" {
+			continue
+		}
+
+		if path, err := filepath.Abs(generated); err == nil {
+			targets.generated[path] = struct{}{}
+		}
+	}
+
+	return targets, nil
+}
+
+// dirs gets the directories to watch, since changes are only reported for the direct
+// contents of a watched directory: those of the tracked files, the git directory, and the config
+func (wt *watchTargets) dirs() []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	add := func(dir string) {
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	add(wt.gitDir)
+	add(filepath.Join(wt.gitDir, "refs", "heads"))
+	if wt.configPath != "" {
+		add(filepath.Dir(wt.configPath))
+	}
+
+	for path := range wt.tracked {
+		add(filepath.Dir(path))
+	}
+
+	return dirs
+}
+
+// triggers returns true if a change to the path regenerates the output
+func (wt *watchTargets) triggers(path string) bool {
+	if _, ok := wt.generated[path]; ok {
+		return false
+	}
+
+	if path == wt.configPath {
+		return true
+	}
+
+	// git writes each file it updates to a lock file before renaming it into place
+	if strings.HasPrefix(path, wt.gitDir+string(filepath.Separator)) {
+		return !strings.HasSuffix(path, ".lock")
+	}
+
+	_, ok := wt.tracked[path]
+	return ok
+}
+
+// watch regenerates the output each time a watch target changes, once the changes settle,
+// until the context is done. Failed regenerations are reported and watching continues, so
+// a mistake made while tuning the config can be fixed without restarting.
+func watch(ctx context.Context, targets *watchTargets, regenerate func() error, logger gopherlogs.Logger) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("could not create file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	addDirs := func() {
+		for _, dir := range targets.dirs() {
+			// directories of deleted files, and missing refs directories, can't be watched
+			if err := watcher.Add(dir); err != nil && !os.IsNotExist(err) {
+				logger.V(logging.LogDebug).Style(0, colors.FgYellow).Warnf("Could not watch %s: %s\n", dir, err)
+			}
+		}
+	}
+	addDirs()
+
+	logger.V(logging.LogInfo).Style(0, colors.FgCyan).Infof("Watching for changes, press Ctrl+C to stop\n")
+
+	// the debounce timer is only started once a change is seen
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if event.Has(fsnotify.Chmod) || !targets.triggers(event.Name) {
+				continue
+			}
+
+			logger.V(logging.LogDebug).Style(0, colors.FgBlue).Infof("Changed: %s\n", event.Name)
+			debounce.Reset(watchDebounce)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			logger.V(logging.LogInfo).Style(0, colors.FgYellow).Warnf("File watcher error: %s\n", err)
+
+		case <-debounce.C:
+			if err := regenerate(); err != nil {
+				logger.V(logging.LogInfo).Style(0, colors.FgRed).Warnf("Error regenerating: %s\n", err)
+				continue
+			}
+
+			// new commits may track new files in new directories
+			addDirs()
+		}
+	}
+}
+
+// runWatch generates the output, then regenerates it whenever a tracked file, the git
+// history, or the config changes, for --watch. The command is rerun to regenerate, so
+// changes to the config are loaded. It stops cleanly on an interrupt, i.e., Ctrl+C.
+func runWatch(opts *Options, cmd *cobra.Command) error {
+	if err := run(opts, cmd); err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	targets, err := newWatchTargets(opts)
+	if err != nil {
+		return err
+	}
+
+	opts.watching = true
+	return watch(ctx, targets, func() error {
+		opts.logger.V(logging.LogInfo).Style(0, colors.FgCyan).Infof("\nRegenerating\n")
+		if err := cmd.RunE(cmd, nil); err != nil {
+			return err
+		}
+
+		// the reloaded config and new commits may change the watch targets
+		reloaded, err := newWatchTargets(opts)
+		if err != nil {
+			return err
+		}
+
+		*targets = *reloaded
+		return nil
+	}, opts.logger)
+}
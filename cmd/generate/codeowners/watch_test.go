@@ -0,0 +1,109 @@
+package codeowners
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchTargets(t *testing.T) {
+	dir, _ := newTestRepo(t,
+		testCommit{name: "John", email: "john@opensauced.pizza", files: map[string]string{
+			"main.go":            "package main\n",
+			"docs/guide.md":      "# Guide\n",
+			".github/CODEOWNERS": "* @jpmcb\n",
+		}},
+	)
+
+	opts := &Options{
+		path:             dir,
+		outputPath:       filepath.Join(dir, ".github"),
+		format:           formatGitHub,
+		configLoadedPath: filepath.Join(dir, ".sauced.yaml"),
+	}
+
+	targets, err := newWatchTargets(opts)
+	require.NoError(t, err)
+
+	assert.True(t, targets.triggers(filepath.Join(dir, "main.go")))
+	assert.True(t, targets.triggers(filepath.Join(dir, "docs", "guide.md")))
+	assert.True(t, targets.triggers(filepath.Join(dir, ".sauced.yaml")))
+	assert.True(t, targets.triggers(filepath.Join(dir, ".git", "index")))
+
+	// untracked, lock, and generated files don't regenerate the output
+	assert.False(t, targets.triggers(filepath.Join(dir, "main.go.swp")))
+	assert.False(t, targets.triggers(filepath.Join(dir, ".git", "index.lock")))
+	assert.False(t, targets.triggers(filepath.Join(dir, ".github", "CODEOWNERS")))
+
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, ".git"),
+		filepath.Join(dir, ".git", "refs", "heads"),
+		dir,
+		filepath.Join(dir, "docs"),
+		filepath.Join(dir, ".github"),
+	}, targets.dirs())
+}
+
+func TestWatch(t *testing.T) {
+	dir, _ := newTestRepo(t,
+		testCommit{name: "John", email: "john@opensauced.pizza", files: map[string]string{"main.go": "package main\n"}},
+	)
+
+	targets, err := newWatchTargets(&Options{path: dir, outputPath: dir, format: formatGitHub})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var regenerations atomic.Int32
+	done := make(chan error)
+	go func() {
+		done <- watch(ctx, targets, func() error {
+			// failures are reported without stopping the watch
+			if regenerations.Add(1) == 1 {
+				return errors.New("invalid config")
+			}
+
+			return nil
+		}, newTestLogger(t))
+	}()
+
+	write := func(contents string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(contents), 0600))
+	}
+
+	// changes within the debounce are regenerated once, once the watcher has started
+	time.Sleep(watchDebounce / 2)
+	write("package main\n\nfunc main() {}\n")
+	write("package main\n\nfunc main() {\n}\n")
+	require.Eventually(t, func() bool {
+		return regenerations.Load() > 0
+	}, 10*time.Second, watchDebounce/5)
+	time.Sleep(2 * watchDebounce)
+	require.Equal(t, int32(1), regenerations.Load())
+
+	write("package main\n")
+	assert.Eventually(t, func() bool {
+		return regenerations.Load() == 2
+	}, 10*time.Second, watchDebounce/5)
+
+	// writing untracked files doesn't regenerate
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes\n"), 0600))
+	time.Sleep(2 * watchDebounce)
+	assert.Equal(t, int32(2), regenerations.Load())
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("watch didn't stop when its context was done")
+	}
+}
@@ -26,9 +26,10 @@ type Options struct {
 	// the path to the git repository on disk to generate a codeowners file for
 	path string
 
-	logger   gopherlogs.Logger
-	tty      bool
-	loglevel int
+	logger    gopherlogs.Logger
+	tty       bool
+	loglevel  int
+	logFormat string
 
 	token string
 
@@ -86,6 +87,7 @@ func NewGenerateInsightCommand() *cobra.Command {
 
 			opts.telemetry = utils.NewPosthogCliClient(!disableTelem)
 			opts.tty, _ = cmd.Flags().GetBool("tty-disable")
+			opts.logFormat, _ = cmd.Flags().GetString(constants.FlagNameLogFormat)
 
 			loglevelS, _ := cmd.Flags().GetString("log-level")
 
@@ -113,10 +115,7 @@ func NewGenerateInsightCommand() *cobra.Command {
 
 func run(opts *Options, _ *cobra.Command) error {
 	var err error
-	opts.logger, err = gopherlogs.NewLogger(
-		gopherlogs.WithLogVerbosity(opts.loglevel),
-		gopherlogs.WithTty(!opts.tty),
-	)
+	opts.logger, err = logging.NewLogger(opts.logFormat, opts.loglevel, opts.tty)
 	if err != nil {
 		return fmt.Errorf("could not build logger: %w", err)
 	}
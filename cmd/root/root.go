@@ -13,6 +13,8 @@ import (
 	"github.com/open-sauced/pizza-cli/v2/cmd/offboard"
 	"github.com/open-sauced/pizza-cli/v2/cmd/version"
 	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+	"github.com/open-sauced/pizza-cli/v2/pkg/logging"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
 )
 
 // NewRootCommand bootstraps a new root cobra command for the pizza CLI
@@ -22,6 +24,21 @@ func NewRootCommand() (*cobra.Command, error) {
 		Short: "OpenSauced CLI",
 		Long:  "A command line utility for insights, metrics, and generating CODEOWNERS documentation for your open source projects",
 		RunE:  run,
+		// errors are written by main in the configured "--error-format"
+		SilenceErrors: true,
+		PersistentPreRunE: func(c *cobra.Command, _ []string) error {
+			errFormat, _ := c.Flags().GetString(constants.FlagNameErrFormat)
+			switch errFormat {
+			case constants.ErrorFormatText:
+			case constants.ErrorFormatJSON:
+				// Usage text is noise for machines parsing structured errors
+				c.SilenceUsage = true
+			default:
+				return utils.NewCLIError(constants.ErrorCodeConfig, "", fmt.Errorf("invalid error format %q, must be one of: %s, %s", errFormat, constants.ErrorFormatText, constants.ErrorFormatJSON)).WithField(constants.FlagNameErrFormat)
+			}
+
+			return nil
+		},
 		Args: func(cmd *cobra.Command, _ []string) error {
 			betaFlag := cmd.Flags().Lookup(constants.FlagNameBeta)
 			if betaFlag.Changed {
@@ -39,7 +56,15 @@ func NewRootCommand() (*cobra.Command, error) {
 	cmd.PersistentFlags().Bool(constants.FlagNameTelemetry, false, "Disable sending telemetry data to OpenSauced")
 	cmd.PersistentFlags().StringP("config", "c", "", "The codeowners config")
 	cmd.PersistentFlags().StringP("log-level", "l", "info", "The logging level. Options: error, warn, info, debug")
+	cmd.PersistentFlags().String(constants.FlagNameLogFormat, logging.LogFormatText, fmt.Sprintf("The format of logs. Options: %s, %s. JSON logs are written to stderr, one entry per line, for log aggregation", logging.LogFormatText, logging.LogFormatJSON))
 	cmd.PersistentFlags().Bool("tty-disable", false, "Disable log stylization. Suitable for CI/CD and automation")
+	cmd.PersistentFlags().String(constants.FlagNameErrFormat, constants.ErrorFormatText, fmt.Sprintf("The format of errors written to stderr. Options: %s, %s", constants.ErrorFormatText, constants.ErrorFormatJSON))
+
+	// Shell completions for flags with a fixed set of values
+	_ = cmd.RegisterFlagCompletionFunc("log-level", cobra.FixedCompletions([]string{"error", "warn", "info", "debug"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc(constants.FlagNameLogFormat, cobra.FixedCompletions([]string{logging.LogFormatText, logging.LogFormatJSON}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.MarkPersistentFlagFilename("config", "yaml", "yml")
+	_ = cmd.RegisterFlagCompletionFunc(constants.FlagNameErrFormat, cobra.FixedCompletions([]string{constants.ErrorFormatText, constants.ErrorFormatJSON}, cobra.ShellCompDirectiveNoFileComp))
 
 	cmd.AddCommand(auth.NewLoginCommand())
 	cmd.AddCommand(generate.NewGenerateCommand())
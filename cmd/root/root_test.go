@@ -0,0 +1,46 @@
+package root
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
+)
+
+func TestErrorFormat(t *testing.T) {
+	execute := func(t *testing.T, args ...string) (*bytes.Buffer, error) {
+		t.Helper()
+
+		cmd, err := NewRootCommand()
+		require.NoError(t, err)
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+
+		return &out, cmd.Execute()
+	}
+
+	t.Run("valid formats", func(t *testing.T) {
+		for _, format := range []string{constants.ErrorFormatText, constants.ErrorFormatJSON} {
+			_, err := execute(t, "version", "--error-format", format)
+			require.NoError(t, err, format)
+		}
+	})
+
+	t.Run("unknown formats are rejected", func(t *testing.T) {
+		_, err := execute(t, "version", "--error-format", "yaml")
+		require.ErrorContains(t, err, `invalid error format "yaml"`)
+
+		var cliErr *utils.CLIError
+		require.ErrorAs(t, err, &cliErr)
+		assert.Equal(t, constants.ErrorCodeConfig, cliErr.Code)
+		assert.Equal(t, constants.FlagNameErrFormat, cliErr.Field)
+		assert.Equal(t, constants.ExitCodeConfig, utils.ExitCode(err))
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/open-sauced/pizza-cli/v2/cmd/root"
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
 	"github.com/open-sauced/pizza-cli/v2/pkg/utils"
 )
 
@@ -16,6 +17,8 @@ func main() {
 	utils.SetupRootCommand(rootCmd)
 	err = rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		errFormat, _ := rootCmd.PersistentFlags().GetString(constants.FlagNameErrFormat)
+		utils.WriteError(os.Stderr, err, errFormat)
+		os.Exit(utils.ExitCode(err))
 	}
 }
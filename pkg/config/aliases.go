@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// CanonicalLogin gets the canonical username of a username, following its aliases, i.e.,
+// a username renamed several times. Usernames without an alias are their own canonical
+// username. Any leading "@" of the aliases is ignored.
+// Example: "old-login" with the aliases { old-login: "@new-login" } is "new-login"
+func (s *Spec) CanonicalLogin(login string) string {
+	if len(s.Aliases) == 0 {
+		return login
+	}
+
+	mention := strings.HasPrefix(login, "@")
+	login = strings.TrimPrefix(login, "@")
+
+	// cycles are rejected when the config is validated, but are guarded against anyway
+	seen := []string{login}
+	for {
+		alias, ok := s.alias(login)
+		if !ok || slices.Contains(seen, alias) {
+			break
+		}
+
+		login = alias
+		seen = append(seen, login)
+	}
+
+	if mention {
+		return "@" + login
+	}
+
+	return login
+}
+
+// alias gets the username a username is an alias of
+func (s *Spec) alias(login string) (string, bool) {
+	if alias, ok := s.Aliases[login]; ok {
+		return strings.TrimPrefix(alias, "@"), true
+	}
+
+	if alias, ok := s.Aliases["@"+login]; ok {
+		return strings.TrimPrefix(alias, "@"), true
+	}
+
+	return "", false
+}
+
+// validateAliases checks that every alias names a username and that no username is an
+// alias of itself, directly or through other aliases. The path of the first cycle found
+// is reported. Example: "alice -> bob -> alice"
+func (s *Spec) validateAliases() error {
+	logins := make([]string, 0, len(s.Aliases))
+	for login := range s.Aliases {
+		logins = append(logins, login)
+	}
+	sort.Strings(logins)
+
+	for _, login := range logins {
+		if strings.TrimPrefix(s.Aliases[login], "@") == "" {
+			return fmt.Errorf("invalid aliases.%s username %q, must not be empty", login, s.Aliases[login])
+		}
+	}
+
+	for _, login := range logins {
+		path := []string{strings.TrimPrefix(login, "@")}
+		for {
+			alias, ok := s.alias(path[len(path)-1])
+			if !ok {
+				break
+			}
+
+			if slices.Contains(path, alias) {
+				return fmt.Errorf("circular aliases: %s", strings.Join(append(path, alias), " -> "))
+			}
+
+			path = append(path, alias)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalLogin(t *testing.T) {
+	spec := &Spec{
+		Aliases: map[string]string{
+			"old-login":    "@new-login",
+			"oldest-login": "old-login",
+		},
+	}
+
+	assert.Equal(t, "new-login", spec.CanonicalLogin("old-login"))
+	assert.Equal(t, "new-login", spec.CanonicalLogin("oldest-login"))
+	assert.Equal(t, "@new-login", spec.CanonicalLogin("@old-login"))
+	assert.Equal(t, "new-login", spec.CanonicalLogin("new-login"))
+	assert.Equal(t, "jpmcb", spec.CanonicalLogin("jpmcb"))
+	assert.Equal(t, "jpmcb", (&Spec{}).CanonicalLogin("jpmcb"))
+}
+
+func TestValidateAliases(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		spec := &Spec{Aliases: map[string]string{"old-login": "new-login"}}
+		require.NoError(t, spec.Validate())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		spec := &Spec{Aliases: map[string]string{"old-login": "@"}}
+		require.ErrorContains(t, spec.Validate(), `invalid aliases.old-login username "@", must not be empty`)
+	})
+
+	t.Run("circular", func(t *testing.T) {
+		spec := &Spec{Aliases: map[string]string{"alice": "bob", "bob": "@alice"}}
+		require.ErrorContains(t, spec.Validate(), "circular aliases: alice -> bob -> alice")
+	})
+
+	t.Run("self", func(t *testing.T) {
+		spec := &Spec{Aliases: map[string]string{"alice": "alice"}}
+		require.ErrorContains(t, spec.Validate(), "circular aliases: alice -> alice")
+	})
+}
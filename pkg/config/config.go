@@ -28,6 +28,17 @@ func LoadConfig(path string) (*Spec, string, error) {
 	return nil, "", fmt.Errorf("could not load config at given path: %w - could not load config at home: %w", givenPathErr, homePathErr)
 }
 
+// LoadConfigFile loads the configuration file at a given path, without
+// falling back to "~/.sauced.yaml" when it doesn't exist
+func LoadConfigFile(path string) (*Spec, string, error) {
+	return loadSpecAtPath(path)
+}
+
+// LoadHomeConfig loads "~/.sauced.yaml" from the user's home directory
+func LoadHomeConfig() (*Spec, string, error) {
+	return loadSpecAtHome()
+}
+
 func loadSpecAtPath(path string) (*Spec, string, error) {
 	config := &Spec{}
 
@@ -46,6 +57,11 @@ func loadSpecAtPath(path string) (*Spec, string, error) {
 		return nil, "", fmt.Errorf("error unmarshaling config at: %s - %w", absPath, err)
 	}
 
+	err = config.Validate()
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid config at: %s - %w", absPath, err)
+	}
+
 	return config, absPath, nil
 }
 
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -92,4 +96,239 @@ attribution:
 		assert.Equal(t, []string{"nick@nickyt.co", "nick@opensauced.pizza"}, config.Attributions["nickytonline"])
 		assert.Equal(t, []string{"coding@zeu.dev"}, config.Attributions["zeucapua"])
 	})
+	t.Run("Invalid gitea pattern style", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		configFilePath := filepath.Join(tmpDir, ".sauced.yaml")
+
+		fileContents := `attribution:
+  jpmcb:
+    - john@opensauced.pizza
+gitea-pattern-style: wildcard`
+
+		require.NoError(t, os.WriteFile(configFilePath, []byte(fileContents), 0600))
+
+		_, _, err := loadSpecAtPath(configFilePath)
+		require.ErrorContains(t, err, "gitea-pattern-style")
+	})
+	t.Run("Invalid max owners", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		configFilePath := filepath.Join(tmpDir, ".sauced.yaml")
+
+		fileContents := `attribution:
+  jpmcb:
+    - john@opensauced.pizza
+max-owners: -1`
+
+		require.NoError(t, os.WriteFile(configFilePath, []byte(fileContents), 0600))
+
+		_, _, err := loadSpecAtPath(configFilePath)
+		require.ErrorContains(t, err, "max-owners")
+	})
+	t.Run("Invalid min confidence", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		configFilePath := filepath.Join(tmpDir, ".sauced.yaml")
+
+		fileContents := `attribution:
+  jpmcb:
+    - john@opensauced.pizza
+min-confidence: 1.5`
+
+		require.NoError(t, os.WriteFile(configFilePath, []byte(fileContents), 0600))
+
+		_, _, err := loadSpecAtPath(configFilePath)
+		require.ErrorContains(t, err, "min-confidence")
+	})
+	t.Run("Circular groups", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		configFilePath := filepath.Join(tmpDir, ".sauced.yaml")
+
+		fileContents := `groups:
+  frontend:
+    - "@alice"
+    - "@@web"
+  web:
+    - "@@frontend"`
+
+		require.NoError(t, os.WriteFile(configFilePath, []byte(fileContents), 0600))
+
+		_, _, err := loadSpecAtPath(configFilePath)
+		require.ErrorContains(t, err, "circular group reference: @@frontend -> @@web -> @@frontend")
+	})
+	t.Run("Undefined group", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		configFilePath := filepath.Join(tmpDir, ".sauced.yaml")
+
+		fileContents := `groups:
+  frontend:
+    - "@alice"
+overrides:
+  "web/**":
+    - "@@frontend"
+  "docs/**":
+    - "@@docs"`
+
+		require.NoError(t, os.WriteFile(configFilePath, []byte(fileContents), 0600))
+
+		_, _, err := loadSpecAtPath(configFilePath)
+		require.ErrorContains(t, err, `overrides.docs/** references undefined group "@@docs"`)
+	})
+	t.Run("GitLab sections", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		configFilePath := filepath.Join(tmpDir, ".sauced.yaml")
+
+		fileContents := `gitlab-sections:
+  - name: Docs
+    paths:
+      - "docs/**"
+    required-approvals: 2`
+
+		require.NoError(t, os.WriteFile(configFilePath, []byte(fileContents), 0600))
+
+		config, _, err := loadSpecAtPath(configFilePath)
+		require.NoError(t, err)
+		assert.Equal(t, []GitLabSection{{Name: "Docs", Paths: []string{"docs/**"}, RequiredApprovals: 2}}, config.GitLabSections)
+	})
+	t.Run("Invalid GitLab section", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		configFilePath := filepath.Join(tmpDir, ".sauced.yaml")
+
+		fileContents := `gitlab-sections:
+  - name: "[Docs]"`
+
+		require.NoError(t, os.WriteFile(configFilePath, []byte(fileContents), 0600))
+
+		_, _, err := loadSpecAtPath(configFilePath)
+		require.ErrorContains(t, err, `invalid gitlab-sections.1 name "[Docs]"`)
+	})
+	t.Run("Invalid regex attribution", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		configFilePath := filepath.Join(tmpDir, ".sauced.yaml")
+
+		fileContents := `regex-attributions:
+  jpmcb:
+    - "^john(@"`
+
+		require.NoError(t, os.WriteFile(configFilePath, []byte(fileContents), 0600))
+
+		_, _, err := loadSpecAtPath(configFilePath)
+		require.ErrorContains(t, err, `invalid regex-attributions.jpmcb pattern "^john(@"`)
+	})
+	t.Run("Empty team map team", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		configFilePath := filepath.Join(tmpDir, ".sauced.yaml")
+
+		fileContents := `team-map:
+  jpmcb: "@"`
+
+		require.NoError(t, os.WriteFile(configFilePath, []byte(fileContents), 0600))
+
+		_, _, err := loadSpecAtPath(configFilePath)
+		require.ErrorContains(t, err, `invalid team-map.jpmcb team "@"`)
+	})
+	t.Run("Uncapped max owners per line", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+		configFilePath := filepath.Join(tmpDir, ".sauced.yaml")
+
+		require.NoError(t, os.WriteFile(configFilePath, []byte("max-owners-per-line: 0"), 0600))
+
+		config, _, err := loadSpecAtPath(configFilePath)
+		require.NoError(t, err)
+		require.NotNil(t, config.MaxOwnersPerLine)
+		assert.Equal(t, 0, *config.MaxOwnersPerLine)
+	})
+}
+
+func TestLoadRemoteSpec(t *testing.T) {
+	t.Parallel()
+
+	fileContents := `attribution:
+  jpmcb:
+    - john@opensauced.pizza`
+
+	t.Run("Fetches and caches the config", func(t *testing.T) {
+		t.Parallel()
+
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write([]byte(fileContents))
+		}))
+		defer server.Close()
+
+		cacheDir := t.TempDir()
+
+		config, err := loadRemoteSpec(server.Client(), server.URL, "Bearer token", cacheDir, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"john@opensauced.pizza"}, config.Attributions["jpmcb"])
+
+		_, err = loadRemoteSpec(server.Client(), server.URL, "Bearer token", cacheDir, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), requests.Load())
+
+		// an expired cache fetches the config again
+		_, err = loadRemoteSpec(server.Client(), server.URL, "Bearer token", cacheDir, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("Unexpected content type", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html></html>"))
+		}))
+		defer server.Close()
+
+		_, err := loadRemoteSpec(server.Client(), server.URL, "", t.TempDir(), time.Hour)
+		require.ErrorContains(t, err, "unexpected content type")
+	})
+
+	t.Run("Unexpected status", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		_, err := loadRemoteSpec(server.Client(), server.URL, "", t.TempDir(), time.Hour)
+		require.ErrorContains(t, err, "unexpected status")
+	})
+
+	t.Run("Invalid configs are not cached", func(t *testing.T) {
+		t.Parallel()
+
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			_, _ = w.Write([]byte("team-map:\n  jpmcb: \"@\""))
+		}))
+		defer server.Close()
+
+		cacheDir := t.TempDir()
+
+		_, err := loadRemoteSpec(server.Client(), server.URL, "", cacheDir, time.Hour)
+		require.ErrorContains(t, err, "invalid remote config")
+
+		_, err = loadRemoteSpec(server.Client(), server.URL, "", cacheDir, time.Hour)
+		require.ErrorContains(t, err, "invalid remote config")
+		assert.Equal(t, int32(2), requests.Load())
+
+		entries, err := os.ReadDir(cacheDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
 }
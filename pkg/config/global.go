@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// GlobalConfigPath gets the path of the user's global codeowners config, which
+// provides defaults shared by every repository: "$XDG_CONFIG_HOME/pizza/codeowners.yaml",
+// or "~/.config/pizza/codeowners.yaml" when XDG_CONFIG_HOME isn't set
+func GlobalConfigPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("couldn't get user home directory: %w", err)
+		}
+
+		configHome = filepath.Join(homeDir, ".config")
+	}
+
+	return filepath.Join(configHome, "pizza", "codeowners.yaml"), nil
+}
+
+// LoadGlobalConfig loads the global config at the given path. A nil Spec and
+// no error are returned when it doesn't exist, since the global config is optional.
+func LoadGlobalConfig(path string) (*Spec, string, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, "", nil
+	}
+
+	return loadSpecAtPath(path)
+}
+
+// MergeGlobal merges the global config under the spec, returning a new spec.
+// Values set in the spec take precedence over the global config's: map entries
+// are merged by key and any other value is only taken from the global config when
+// the spec leaves it unset. A nil global config returns the spec unchanged.
+func (s *Spec) MergeGlobal(global *Spec) (*Spec, error) {
+	if global == nil {
+		return s, nil
+	}
+
+	merged := s.mergeOver(global)
+
+	if err := merged.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config merged with the global config: %w", err)
+	}
+
+	return &merged, nil
+}
+
+// mergeOver merges the spec over the base spec. Map entries are merged by key
+// and any other value set in the spec takes precedence over the base's.
+func (s *Spec) mergeOver(base *Spec) Spec {
+	merged := *s
+
+	merged.Profiles = mergeMaps(base.Profiles, s.Profiles)
+
+	merged.Attributions = mergeMaps(base.Attributions, s.Attributions)
+	merged.RegexAttributions = mergeMaps(base.RegexAttributions, s.RegexAttributions)
+	merged.Aliases = mergeMaps(base.Aliases, s.Aliases)
+	merged.Groups = mergeMaps(base.Groups, s.Groups)
+	merged.PriorityOwners = mergeMaps(base.PriorityOwners, s.PriorityOwners)
+	merged.Overrides = mergeMaps(base.Overrides, s.Overrides)
+	merged.TeamMap = mergeMaps(base.TeamMap, s.TeamMap)
+	merged.BitbucketIdentities = mergeMaps(base.BitbucketIdentities, s.BitbucketIdentities)
+	merged.AzureIdentities = mergeMaps(base.AzureIdentities, s.AzureIdentities)
+	merged.DisplayNames = mergeMaps(base.DisplayNames, s.DisplayNames)
+
+	merged.AttributionFallback = mergeSlices(base.AttributionFallback, s.AttributionFallback)
+	merged.AttributionFallbackTiers = mergeSlices(base.AttributionFallbackTiers, s.AttributionFallbackTiers)
+	merged.AllowedAuthors = mergeSlices(base.AllowedAuthors, s.AllowedAuthors)
+	merged.NoParentOwners = mergeSlices(base.NoParentOwners, s.NoParentOwners)
+	merged.GitLabSections = mergeSlices(base.GitLabSections, s.GitLabSections)
+
+	merged.MinOwners = mergeValues(base.MinOwners, s.MinOwners)
+	merged.MinCommits = mergeValues(base.MinCommits, s.MinCommits)
+	merged.MinConfidence = mergeValues(base.MinConfidence, s.MinConfidence)
+	merged.MaxOwners = mergeValues(base.MaxOwners, s.MaxOwners)
+	merged.MaxOwnersPerLine = mergeValues(base.MaxOwnersPerLine, s.MaxOwnersPerLine)
+	merged.InlineOwnersDirective = mergeValues(base.InlineOwnersDirective, s.InlineOwnersDirective)
+	merged.Format = mergeValues(base.Format, s.Format)
+	merged.OwnersIdentity = mergeValues(base.OwnersIdentity, s.OwnersIdentity)
+	merged.OwnersName = mergeValues(base.OwnersName, s.OwnersName)
+	merged.OutputPath = mergeValues(base.OutputPath, s.OutputPath)
+	merged.GiteaPatternStyle = mergeValues(base.GiteaPatternStyle, s.GiteaPatternStyle)
+
+	return merged
+}
+
+// mergeMaps merges the override's entries over the base's
+func mergeMaps[V any](base, override map[string]V) map[string]V {
+	if len(base) == 0 {
+		return override
+	}
+
+	merged := maps.Clone(base)
+	maps.Copy(merged, override)
+
+	return merged
+}
+
+// mergeSlices gets the override, or the base when the override is empty
+func mergeSlices[S ~[]E, E any](base, override S) S {
+	if len(override) > 0 {
+		return override
+	}
+
+	return slices.Clone(base)
+}
+
+// mergeValues gets the override, or the base when the override is unset
+func mergeValues[V comparable](base, override V) V {
+	var zero V
+	if override != zero {
+		return override
+	}
+
+	return base
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobalConfigPath(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	path, err := GlobalConfigPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(configHome, "pizza", "codeowners.yaml"), path)
+}
+
+func TestLoadGlobalConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Missing global config", func(t *testing.T) {
+		t.Parallel()
+
+		global, loadedPath, err := LoadGlobalConfig(filepath.Join(t.TempDir(), "codeowners.yaml"))
+		require.NoError(t, err)
+		assert.Nil(t, global)
+		assert.Empty(t, loadedPath)
+	})
+
+	t.Run("Existing global config", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "codeowners.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("attribution-fallback: [open-sauced/engineering]\n"), 0600))
+
+		global, loadedPath, err := LoadGlobalConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"open-sauced/engineering"}, global.AttributionFallback)
+		assert.Equal(t, path, loadedPath)
+	})
+
+	t.Run("Invalid global config", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "codeowners.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("max-owners: -1\n"), 0600))
+
+		_, _, err := LoadGlobalConfig(path)
+		require.ErrorContains(t, err, "invalid max-owners")
+	})
+}
+
+func TestMergeGlobal(t *testing.T) {
+	t.Parallel()
+
+	global := &Spec{
+		Attributions: map[string][]string{
+			"jpmcb":          {"john@example.com"},
+			"brandonroberts": {"robertsbt@gmail.com"},
+		},
+		Groups:              map[string][]string{"frontend": {"@alice"}},
+		AttributionFallback: []string{"open-sauced/engineering"},
+		MinOwners:           2,
+		MaxOwners:           5,
+		Format:              "owners",
+	}
+
+	t.Run("Repository config takes precedence", func(t *testing.T) {
+		t.Parallel()
+
+		spec := &Spec{
+			Attributions: map[string][]string{
+				"jpmcb":        {"john@opensauced.pizza"},
+				"nickytonline": {"nick@opensauced.pizza"},
+			},
+			AttributionFallback: []string{"open-sauced/maintainers"},
+			MaxOwners:           3,
+		}
+
+		merged, err := spec.MergeGlobal(global)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string][]string{
+			"jpmcb":          {"john@opensauced.pizza"},
+			"brandonroberts": {"robertsbt@gmail.com"},
+			"nickytonline":   {"nick@opensauced.pizza"},
+		}, merged.Attributions)
+		assert.Equal(t, []string{"open-sauced/maintainers"}, merged.AttributionFallback)
+		assert.Equal(t, 3, merged.MaxOwners)
+
+		// Unset values come from the global config
+		assert.Equal(t, map[string][]string{"frontend": {"@alice"}}, merged.Groups)
+		assert.Equal(t, 2, merged.MinOwners)
+		assert.Equal(t, "owners", merged.Format)
+
+		// Neither config is modified
+		assert.Len(t, spec.Attributions, 2)
+		assert.Len(t, global.Attributions, 2)
+	})
+
+	t.Run("Built-in defaults when neither config sets a value", func(t *testing.T) {
+		t.Parallel()
+
+		merged, err := (&Spec{}).MergeGlobal(global)
+		require.NoError(t, err)
+		assert.Zero(t, merged.MinCommits)
+		assert.Empty(t, merged.OutputPath)
+		assert.Nil(t, merged.Overrides)
+	})
+
+	t.Run("No global config", func(t *testing.T) {
+		t.Parallel()
+
+		spec := &Spec{MaxOwners: 3}
+
+		merged, err := spec.MergeGlobal(nil)
+		require.NoError(t, err)
+		assert.Same(t, spec, merged)
+	})
+
+	t.Run("Merged config is validated", func(t *testing.T) {
+		t.Parallel()
+
+		// The repository's group cycles with the global config's group
+		spec := &Spec{Groups: map[string][]string{"backend": {"@@frontend"}}}
+		cyclic := &Spec{Groups: map[string][]string{"frontend": {"@@backend"}}}
+
+		_, err := spec.MergeGlobal(cyclic)
+		require.ErrorContains(t, err, "invalid config merged with the global config")
+	})
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// GroupReferencePrefix prefixes a reference to one of the config's named groups
+// in a list of owners. Example: "@@frontend"
+const GroupReferencePrefix = "@@"
+
+// GroupReference gets the name of the group an owner references, if it's a group reference
+func GroupReference(owner string) (string, bool) {
+	if !strings.HasPrefix(owner, GroupReferencePrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(owner, GroupReferencePrefix), true
+}
+
+// ExpandGroups expands the group references in a list of owners into the members of
+// each group, in order. Groups may reference other groups. Each owner is listed once
+// and any leading "@" of a member is removed. Example: [ "@@frontend", "jpmcb" ]
+// with the group { frontend: [ "@alice", "@bob" ] } expands to [ "alice", "bob", "jpmcb" ]
+func (s *Spec) ExpandGroups(owners []string) ([]string, error) {
+	expanded := make([]string, 0, len(owners))
+
+	err := s.expandGroups(owners, nil, &expanded)
+	if err != nil {
+		return nil, err
+	}
+
+	return expanded, nil
+}
+
+// expandGroups appends the expanded owners, tracking the groups being expanded
+// to guard against a group which references itself
+func (s *Spec) expandGroups(owners []string, expanding []string, expanded *[]string) error {
+	for _, owner := range owners {
+		group, ok := GroupReference(owner)
+		if !ok {
+			owner = strings.TrimPrefix(owner, "@")
+			if !slices.Contains(*expanded, owner) {
+				*expanded = append(*expanded, owner)
+			}
+
+			continue
+		}
+
+		members, defined := s.Groups[group]
+		if !defined {
+			return fmt.Errorf("undefined group %q", GroupReferencePrefix+group)
+		}
+
+		if slices.Contains(expanding, group) {
+			return fmt.Errorf("group %q references itself", GroupReferencePrefix+group)
+		}
+
+		err := s.expandGroups(members, append(expanding, group), expanded)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateGroupCycles checks that no group references itself, directly or through
+// other groups, which would otherwise expand forever. The path of the first cycle
+// found is reported. Example: "@@frontend -> @@web -> @@frontend"
+func (s *Spec) validateGroupCycles() error {
+	names := make([]string, 0, len(s.Groups))
+	for name := range s.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// groups which are fully checked and aren't part of a cycle
+	checked := make(map[string]bool, len(s.Groups))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		if i := slices.Index(path, name); i >= 0 {
+			cycle := append(slices.Clone(path[i:]), name)
+			for j := range cycle {
+				cycle[j] = GroupReferencePrefix + cycle[j]
+			}
+
+			return fmt.Errorf("circular group reference: %s", strings.Join(cycle, " -> "))
+		}
+
+		if checked[name] {
+			return nil
+		}
+
+		for _, member := range s.Groups[name] {
+			if group, ok := GroupReference(member); ok {
+				if err := visit(group, append(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+
+		checked[name] = true
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateGroupReferences checks that every group referenced by the groups,
+// overrides, priority owners, and fallback attributions is defined
+func (s *Spec) validateGroupReferences() error {
+	lists := map[string][]string{"attribution-fallback": s.AttributionFallback}
+	for name, members := range s.Groups {
+		lists["groups."+name] = members
+	}
+	for glob, owners := range s.Overrides {
+		lists["overrides."+glob] = owners
+	}
+	for glob, owners := range s.PriorityOwners {
+		lists["priority-owners."+glob] = owners
+	}
+	for i, tier := range s.AttributionFallbackTiers {
+		lists[fmt.Sprintf("attribution-fallback-tiers.%d", i+1)] = tier
+	}
+
+	// sorted so the same error is always reported first
+	keys := make([]string, 0, len(lists))
+	for key := range lists {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, owner := range lists[key] {
+			group, ok := GroupReference(owner)
+			if !ok {
+				continue
+			}
+
+			if _, defined := s.Groups[group]; !defined {
+				return fmt.Errorf("%s references undefined group %q", key, owner)
+			}
+		}
+	}
+
+	return nil
+}
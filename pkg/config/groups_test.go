@@ -0,0 +1,108 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandGroups(t *testing.T) {
+	spec := &Spec{
+		Groups: map[string][]string{
+			"frontend": {"@alice", "bob"},
+			"web":      {"@@frontend", "carol", "@alice"},
+			"loop":     {"dave", "@@loop"},
+		},
+	}
+
+	t.Run("members", func(t *testing.T) {
+		expanded, err := spec.ExpandGroups([]string{"@@frontend", "jpmcb"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice", "bob", "jpmcb"}, expanded)
+	})
+
+	t.Run("nested groups", func(t *testing.T) {
+		expanded, err := spec.ExpandGroups([]string{"bob", "@@web"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bob", "alice", "carol"}, expanded)
+	})
+
+	t.Run("no groups", func(t *testing.T) {
+		expanded, err := (&Spec{}).ExpandGroups([]string{"open-sauced/engineering"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"open-sauced/engineering"}, expanded)
+	})
+
+	t.Run("undefined group", func(t *testing.T) {
+		_, err := spec.ExpandGroups([]string{"@@backend"})
+		require.ErrorContains(t, err, `undefined group "@@backend"`)
+	})
+
+	t.Run("self reference", func(t *testing.T) {
+		_, err := spec.ExpandGroups([]string{"@@loop"})
+		require.ErrorContains(t, err, `group "@@loop" references itself`)
+	})
+}
+
+func TestValidateGroupReferences(t *testing.T) {
+	t.Run("defined", func(t *testing.T) {
+		spec := &Spec{
+			Groups:              map[string][]string{"frontend": {"alice"}, "web": {"@@frontend"}},
+			AttributionFallback: []string{"@@web"},
+		}
+		require.NoError(t, spec.Validate())
+	})
+
+	t.Run("undefined in a group", func(t *testing.T) {
+		spec := &Spec{Groups: map[string][]string{"web": {"@@frontend"}}}
+		require.ErrorContains(t, spec.Validate(), `groups.web references undefined group "@@frontend"`)
+	})
+
+	t.Run("two group cycle", func(t *testing.T) {
+		spec := &Spec{
+			Groups: map[string][]string{
+				"frontend": {"alice", "@@web"},
+				"web":      {"@@frontend"},
+			},
+		}
+		require.ErrorContains(t, spec.Validate(), "circular group reference: @@frontend -> @@web -> @@frontend")
+	})
+
+	t.Run("self reference", func(t *testing.T) {
+		spec := &Spec{Groups: map[string][]string{"loop": {"@@loop"}}}
+		require.ErrorContains(t, spec.Validate(), "circular group reference: @@loop -> @@loop")
+	})
+
+	t.Run("shared nested group", func(t *testing.T) {
+		spec := &Spec{
+			Groups: map[string][]string{
+				"frontend": {"alice"},
+				"web":      {"@@frontend"},
+				"mobile":   {"@@frontend", "@@web"},
+			},
+		}
+		require.NoError(t, spec.Validate())
+	})
+
+	t.Run("undefined in the fallback", func(t *testing.T) {
+		spec := &Spec{AttributionFallback: []string{"@@maintainers"}}
+		require.ErrorContains(t, spec.Validate(), `attribution-fallback references undefined group "@@maintainers"`)
+	})
+
+	t.Run("undefined in a fallback tier", func(t *testing.T) {
+		spec := &Spec{AttributionFallbackTiers: [][]string{{"jpmcb"}, {"@@maintainers"}}}
+		require.ErrorContains(t, spec.Validate(), `attribution-fallback-tiers.2 references undefined group "@@maintainers"`)
+	})
+}
+
+func TestFallbackTiers(t *testing.T) {
+	spec := &Spec{
+		AttributionFallbackTiers: [][]string{{"open-sauced/frontend"}, {"open-sauced/engineering"}},
+		AttributionFallback:      []string{"open-sauced/maintainers"},
+	}
+
+	assert.Equal(t, [][]string{{"open-sauced/frontend"}, {"open-sauced/engineering"}, {"open-sauced/maintainers"}}, spec.FallbackTiers())
+	assert.Len(t, spec.AttributionFallbackTiers, 2)
+	assert.Empty(t, (&Spec{}).FallbackTiers())
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile gets the config with the named profile merged over it. Values set in the
+// profile take precedence over the config's: map entries are merged by key and any
+// other value is only taken from the config when the profile leaves it unset.
+func (s *Spec) Profile(name string) (*Spec, error) {
+	profile, ok := s.Profiles[name]
+	if !ok {
+		if len(s.Profiles) == 0 {
+			return nil, fmt.Errorf("profile %q not found, the config has no profiles", name)
+		}
+
+		return nil, fmt.Errorf("profile %q not found, must be one of: %s", name, strings.Join(s.profileNames(), ", "))
+	}
+
+	merged := profile.mergeOver(s)
+	merged.Profiles = nil
+
+	if err := merged.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid profiles.%s: %w", name, err)
+	}
+
+	return &merged, nil
+}
+
+// validateProfiles checks that each profile is valid once merged over the config
+func (s *Spec) validateProfiles() error {
+	for _, name := range s.profileNames() {
+		if len(s.Profiles[name].Profiles) > 0 {
+			return fmt.Errorf("invalid profiles.%s, profiles can't be nested", name)
+		}
+
+		if _, err := s.Profile(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// profileNames gets the sorted names of the profiles
+func (s *Spec) profileNames() []string {
+	names := make([]string, 0, len(s.Profiles))
+	for name := range s.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
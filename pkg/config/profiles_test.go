@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile(t *testing.T) {
+	t.Parallel()
+
+	configFilePath := filepath.Join(t.TempDir(), ".sauced.yaml")
+	fileContents := `attribution:
+  jpmcb: [john@opensauced.pizza]
+attribution-fallback: [open-sauced/maintainers]
+format: github
+profiles:
+  internal:
+    attribution:
+      brandonroberts: [robertsbt@gmail.com]
+    attribution-fallback: [open-sauced/engineering]
+  public:
+    format: owners`
+	require.NoError(t, os.WriteFile(configFilePath, []byte(fileContents), 0600))
+
+	spec, _, err := LoadConfig(configFilePath)
+	require.NoError(t, err)
+
+	t.Run("Selecting a profile", func(t *testing.T) {
+		t.Parallel()
+
+		internal, err := spec.Profile("internal")
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"jpmcb":          {"john@opensauced.pizza"},
+			"brandonroberts": {"robertsbt@gmail.com"},
+		}, internal.Attributions)
+		assert.Equal(t, []string{"open-sauced/engineering"}, internal.AttributionFallback)
+		assert.Equal(t, "github", internal.Format)
+		assert.Nil(t, internal.Profiles)
+
+		public, err := spec.Profile("public")
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{"jpmcb": {"john@opensauced.pizza"}}, public.Attributions)
+		assert.Equal(t, []string{"open-sauced/maintainers"}, public.AttributionFallback)
+		assert.Equal(t, "owners", public.Format)
+	})
+
+	t.Run("Missing profile", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := spec.Profile("partner")
+		require.EqualError(t, err, `profile "partner" not found, must be one of: internal, public`)
+
+		_, err = (&Spec{}).Profile("partner")
+		require.EqualError(t, err, `profile "partner" not found, the config has no profiles`)
+	})
+
+	t.Run("Invalid profiles", func(t *testing.T) {
+		t.Parallel()
+
+		nested := &Spec{Profiles: map[string]Spec{
+			"internal": {Profiles: map[string]Spec{"team": {}}},
+		}}
+		require.ErrorContains(t, nested.Validate(), "invalid profiles.internal, profiles can't be nested")
+
+		invalid := &Spec{Profiles: map[string]Spec{
+			"internal": {MaxOwners: -1},
+		}}
+		require.ErrorContains(t, invalid.Validate(), "invalid profiles.internal: invalid max-owners")
+	})
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RemoteConfigAuthHeaderEnv is the environment variable whose value, when set, is sent
+// as the "Authorization" header when fetching a remote config. An environment variable
+// is used so secrets don't end up in shell history or the generated file's header.
+const RemoteConfigAuthHeaderEnv = "PIZZA_CONFIG_AUTH_HEADER"
+
+// the content types a remote config may be served with
+var remoteConfigContentTypes = []string{
+	"application/yaml",
+	"application/x-yaml",
+	"text/yaml",
+	"text/x-yaml",
+	"text/plain",
+	"application/octet-stream",
+}
+
+// IsRemotePath returns true if the given config path is an HTTP(S) URL
+func IsRemotePath(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// LoadRemoteConfig fetches the configuration file at the given HTTP(S) URL.
+// Fetched configs are cached in the pizza config directory and reused until
+// they are older than the cache TTL. A cache TTL of 0 always fetches the config.
+//
+// This function returns the config Spec, the URL the spec was loaded from, and an error
+func LoadRemoteConfig(url string, cacheTTL time.Duration) (*Spec, string, error) {
+	configDir, err := GetConfigDirectory()
+	if err != nil {
+		return nil, "", err
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	spec, err := loadRemoteSpec(client, url, os.Getenv(RemoteConfigAuthHeaderEnv), filepath.Join(configDir, "cache"), cacheTTL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return spec, url, nil
+}
+
+func loadRemoteSpec(client *http.Client, url string, authHeader string, cacheDir string, cacheTTL time.Duration) (*Spec, error) {
+	hash := sha256.Sum256([]byte(url))
+	cachePath := filepath.Join(cacheDir, hex.EncodeToString(hash[:])+".yaml")
+
+	data, err := readCachedConfig(cachePath, cacheTTL)
+	cached := err == nil
+	if !cached {
+		data, err = fetchRemoteConfig(client, url, authHeader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config := &Spec{}
+	err = yaml.Unmarshal(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling remote config at: %s - %w", url, err)
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote config at: %s - %w", url, err)
+	}
+
+	// only valid configs are cached, so a broken config is fetched again once it's fixed.
+	// Caching is best effort: a failed write only means the config is fetched again next time
+	if !cached {
+		if err := os.MkdirAll(cacheDir, os.ModePerm); err == nil {
+			_ = os.WriteFile(cachePath, data, 0600)
+		}
+	}
+
+	return config, nil
+}
+
+// readCachedConfig reads a cached config if it exists and is younger than the cache TTL
+func readCachedConfig(cachePath string, cacheTTL time.Duration) ([]byte, error) {
+	info, err := os.Stat(cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Since(info.ModTime()) >= cacheTTL {
+		return nil, fmt.Errorf("cached config at %s is expired", cachePath)
+	}
+
+	return os.ReadFile(cachePath)
+}
+
+func fetchRemoteConfig(client *http.Client, url string, authHeader string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error building request for remote config: %s - %w", url, err)
+	}
+
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching remote config: %s - %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching remote config: %s - unexpected status: %s", url, resp.Status)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !isRemoteConfigContentType(mediaType) {
+			return nil, fmt.Errorf("error fetching remote config: %s - unexpected content type: %s", url, contentType)
+		}
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading remote config: %s - %w", url, err)
+	}
+
+	return data, nil
+}
+
+func isRemoteConfigContentType(mediaType string) bool {
+	for _, contentType := range remoteConfigContentTypes {
+		if mediaType == contentType {
+			return true
+		}
+	}
+
+	return false
+}
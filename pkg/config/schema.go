@@ -0,0 +1,64 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSONSchemaDraft is the JSON schema dialect of the generated config schema
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema generates the JSON schema of the config from the yaml struct tags of the
+// Spec, so it always matches the config the CLI loads. Unknown keys are disallowed in
+// the schema, which lets editors flag typos in key names that loading silently ignores.
+func JSONSchema() map[string]any {
+	schema := schemaOf(reflect.TypeOf(Spec{}), map[reflect.Type]bool{})
+	schema["$schema"] = JSONSchemaDraft
+	schema["title"] = ".sauced.yaml"
+
+	return schema
+}
+
+// schemaOf generates the JSON schema of a Go type. The Spec is the only struct which
+// nests itself, through its profiles, so a struct being generated references the root schema.
+func schemaOf(t reflect.Type, generating map[reflect.Type]bool) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(t.Elem(), generating)
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), generating)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), generating)}
+	case reflect.Struct:
+		if generating[t] {
+			return map[string]any{"$ref": "#"}
+		}
+		generating[t] = true
+		defer delete(generating, t)
+
+		properties := make(map[string]any, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if !field.IsExported() || name == "" || name == "-" {
+				continue
+			}
+
+			properties[name] = schemaOf(field.Type, generating)
+		}
+
+		return map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+	}
+
+	// any value is allowed for types without a JSON schema equivalent
+	return map[string]any{}
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	schema := JSONSchema()
+
+	assert.Equal(t, JSONSchemaDraft, schema["$schema"])
+	assert.Equal(t, false, schema["additionalProperties"])
+
+	properties, ok := schema["properties"].(map[string]any)
+	require.True(t, ok)
+
+	t.Run("every config key", func(t *testing.T) {
+		specType := reflect.TypeOf(Spec{})
+		assert.Len(t, properties, specType.NumField())
+	})
+
+	t.Run("types", func(t *testing.T) {
+		assert.Equal(t, map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		}, properties["attribution"])
+		assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"type": "string"}}, properties["attribution-fallback"])
+		assert.Equal(t, map[string]any{"type": "integer"}, properties["max-owners"])
+		assert.Equal(t, map[string]any{"type": "number"}, properties["min-confidence"])
+		assert.Equal(t, map[string]any{"type": "string"}, properties["format"])
+	})
+
+	t.Run("profiles reference the root schema", func(t *testing.T) {
+		assert.Equal(t, map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"$ref": "#"},
+		}, properties["profiles"])
+	})
+}
@@ -1,5 +1,19 @@
 package config
 
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// The supported Gitea CODEOWNERS pattern styles
+const (
+	GiteaPatternStyleGlob  = "glob"
+	GiteaPatternStyleRegex = "regex"
+)
+
 // The configuration specification
 type Spec struct {
 
@@ -9,7 +23,229 @@ type Spec struct {
 	// "github_username" has 2 emails attributed to them and their work.
 	Attributions map[string][]string `yaml:"attribution"`
 
+	// RegexAttributions are mappings of GitHub usernames/groups to regular expressions
+	// matching the emails attributed to them. They're only used for emails without an
+	// exact attribution. When several usernames match, the first in sorted order wins.
+	// Example: { security: [ "^sec-.*@company\\.com$" ]}
+	RegexAttributions map[string][]string `yaml:"regex-attributions"`
+
+	// Aliases are mappings of GitHub usernames a person no longer uses, i.e., after changing
+	// their username, to their current, canonical username. Contributions attributed to
+	// either are attributed to the canonical username, which owns their files once.
+	// Example: { old-login: new-login }
+	Aliases map[string]string `yaml:"aliases"`
+
 	// AttributionFallback is the default username/group(s) to attribute to the filename
 	// if no other attributions were found.
 	AttributionFallback []string `yaml:"attribution-fallback"`
+
+	// AttributionFallbackTiers are ordered tiers of usernames/groups to escalate through
+	// when no other attributions were found, i.e., a team, then its department. The first
+	// tier with a valid owner is used. The AttributionFallback is the final tier.
+	// Example: [[ open-sauced/frontend ], [ open-sauced/engineering ]]
+	AttributionFallbackTiers [][]string `yaml:"attribution-fallback-tiers"`
+
+	// Groups are named lists of usernames/groups which overrides, priority owners,
+	// and the fallback attribution may reference as "@@name". References are
+	// expanded to the group's members when the output is written.
+	// Example: { frontend: [ "@alice", "@bob" ]} referenced as "@@frontend"
+	Groups map[string][]string `yaml:"groups"`
+
+	// PriorityOwners are mappings of file globs to usernames/groups that are always
+	// listed first in a file's owners whenever they are already one of its owners.
+	// The remaining git derived owners still follow in ranked order.
+	// Example: { "src/**": [ open-sauced/engineering ]}
+	PriorityOwners map[string][]string `yaml:"priority-owners"`
+
+	// Overrides are mappings of file globs to usernames/groups that replace the
+	// computed owners for any matching file. When several globs match a file,
+	// the last glob in sorted order wins.
+	// Example: { "docs/**": [ open-sauced/docs ]}
+	Overrides map[string][]string `yaml:"overrides"`
+
+	// MinOwners is the minimum number of owners each file should have. When too few
+	// contributors qualify, the MinCommits threshold is relaxed to widen the search.
+	// If that still fails, the AttributionFallback is used to fill the gap.
+	MinOwners int `yaml:"min-owners"`
+
+	// MinCommits is the minimum number of commits a contributor must have made
+	// to a file to be attributed as one of its owners.
+	MinCommits int `yaml:"min-commits"`
+
+	// MinConfidence is the minimum share of a file's lines changed, from 0 to 1, its top
+	// ranked author must have made for its contributors to be attributed as its owners.
+	// Files below it use the AttributionFallback instead. Defaults to 0, always attributing.
+	MinConfidence float64 `yaml:"min-confidence"`
+
+	// AllowedAuthors, when set, restricts attribution to commits from authors
+	// whose email or name matches one of these globs. Commits from anyone else are ignored.
+	// Example: [ "*@opensauced.pizza", "jane@example.com" ]
+	AllowedAuthors []string `yaml:"allowed-authors"`
+
+	// InlineOwnersDirective is the directive scanned for at the top of each file
+	// when reading inline owners. Defaults to "pizza-owners:".
+	// Example: "// pizza-owners: @alice @bob"
+	InlineOwnersDirective string `yaml:"inline-owners-directive"`
+
+	// NoParentOwners are directory globs whose OWNERS files don't inherit the owners
+	// of their parent directories. Used when generating a hierarchy of OWNERS files.
+	// Example: [ "docs", "third_party/*" ]
+	NoParentOwners []string `yaml:"no-parent-owners"`
+
+	// TeamMap maps GitHub usernames to the team which owns files in their place with
+	// --use-teams, since teams outlast the individuals on them.
+	// Example: { github_username: "@open-sauced/frontend" }
+	TeamMap map[string]string `yaml:"team-map"`
+
+	// BitbucketIdentities are mappings of GitHub usernames to the identifier BitBucket
+	// code owners expect: an account UUID or email. Used with the "bitbucket" format.
+	// Example: { github_username: "{account-uuid}" }
+	BitbucketIdentities map[string]string `yaml:"bitbucket-identities"`
+
+	// AzureIdentities are mappings of GitHub usernames/teams to the identity Azure DevOps
+	// required reviewer policies expect: a user's email or a "[Project]\Group" group.
+	// Used with the "azure" format, where owners without one fall back to their commit email.
+	// Example: { open-sauced/docs: "[OpenSauced]\\Docs" }
+	AzureIdentities map[string]string `yaml:"azure-identities"`
+
+	// MaxOwners is the default maximum number of owners to attribute to each file.
+	// The --max-owners flag takes precedence.
+	MaxOwners int `yaml:"max-owners"`
+
+	// MaxOwnersPerLine is the maximum number of owners written on a single rule in the
+	// github and gitlab formats, where very long lines may be rejected. The lowest ranked
+	// owners past it are dropped, and 0 doesn't cap them. It's a pointer so an explicit 0
+	// can be told apart from the default when it's unset. The --max-owners-per-line flag takes precedence.
+	MaxOwnersPerLine *int `yaml:"max-owners-per-line"`
+
+	// Format is the default format of the generated file. The --format flag takes precedence.
+	// Example: "owners"
+	Format string `yaml:"format"`
+
+	// OwnersIdentity is the default identity of each owner in an "OWNERS" style file:
+	// "name" (default), "email", or "alias". The --owners-identity flag takes precedence.
+	OwnersIdentity string `yaml:"owners-identity"`
+
+	// OwnersName is the default source of each owner's name in an "OWNERS" style file:
+	// "git" (default), "login", or "display". The --owners-name flag takes precedence.
+	OwnersName string `yaml:"owners-name"`
+
+	// DisplayNames are mappings of GitHub usernames to the name written for them in an
+	// "OWNERS" style file with the "display" owners name, or when they have no git name.
+	// Example: { github_username: "Jane Doe" }
+	DisplayNames map[string]string `yaml:"display-names"`
+
+	// OutputPath is the default directory to create the output file in. Relative paths
+	// are relative to the repository. The --output-path flag takes precedence.
+	// Example: ".github"
+	OutputPath string `yaml:"output-path"`
+
+	// GiteaPatternStyle controls how file patterns are emitted with the "gitea" format:
+	// either as "glob" patterns (default) or as anchored "regex" patterns with a "re:" prefix.
+	GiteaPatternStyle string `yaml:"gitea-pattern-style"`
+
+	// GitLabSections group the rules of a GitLab CODEOWNERS file into sections, each of
+	// which may require a number of approvals from its owners. Each file is written in the
+	// first section with a matching path glob. Used with the "gitlab" format.
+	GitLabSections []GitLabSection `yaml:"gitlab-sections"`
+
+	// Profiles are named configs, selected with --config-env, merged over the rest of
+	// the config. They let one config generate different owners for different contexts,
+	// each with its own attributions, fallback, or format. Profiles can't be nested.
+	// Example: { internal: { attribution-fallback: [ open-sauced/engineering ] } }
+	Profiles map[string]Spec `yaml:"profiles"`
+}
+
+// GitLabSection is a section of a GitLab CODEOWNERS file. Example: { name: Docs,
+// paths: [ "docs/**" ], required-approvals: 2 } is written as "[Docs][2]"
+type GitLabSection struct {
+	// Name is the name of the section
+	Name string `yaml:"name"`
+
+	// Paths are the globs of the files whose rules are written in the section
+	Paths []string `yaml:"paths"`
+
+	// RequiredApprovals is the number of approvals required from the section's owners.
+	// Defaults to GitLab's default of 1 approval when 0.
+	RequiredApprovals int `yaml:"required-approvals"`
+}
+
+// FallbackTiers gets the ordered tiers of fallback owners: the AttributionFallbackTiers
+// followed by the AttributionFallback
+func (s *Spec) FallbackTiers() [][]string {
+	tiers := slices.Clone(s.AttributionFallbackTiers)
+	if len(s.AttributionFallback) > 0 {
+		tiers = append(tiers, s.AttributionFallback)
+	}
+
+	return tiers
+}
+
+// Validate checks the config spec for invalid values
+func (s *Spec) Validate() error {
+	switch s.GiteaPatternStyle {
+	case "", GiteaPatternStyleGlob, GiteaPatternStyleRegex:
+	default:
+		return fmt.Errorf("invalid gitea-pattern-style %q, must be one of: %s, %s", s.GiteaPatternStyle, GiteaPatternStyleGlob, GiteaPatternStyleRegex)
+	}
+
+	if s.MaxOwners < 0 {
+		return fmt.Errorf("invalid max-owners %d, must not be negative", s.MaxOwners)
+	}
+
+	if s.MaxOwnersPerLine != nil && *s.MaxOwnersPerLine < 0 {
+		return fmt.Errorf("invalid max-owners-per-line %d, must not be negative", *s.MaxOwnersPerLine)
+	}
+
+	if s.MinConfidence < 0 || s.MinConfidence > 1 {
+		return fmt.Errorf("invalid min-confidence %g, must be between 0 and 1", s.MinConfidence)
+	}
+
+	for i, section := range s.GitLabSections {
+		if section.Name == "" || strings.ContainsAny(section.Name, "[]\n") {
+			return fmt.Errorf("invalid gitlab-sections.%d name %q, must be non-empty without brackets", i+1, section.Name)
+		}
+
+		if section.RequiredApprovals < 0 {
+			return fmt.Errorf("invalid gitlab-sections.%d required-approvals %d, must not be negative", i+1, section.RequiredApprovals)
+		}
+	}
+
+	if err := s.validateAliases(); err != nil {
+		return err
+	}
+
+	for username, team := range s.TeamMap {
+		if strings.TrimPrefix(team, "@") == "" {
+			return fmt.Errorf("invalid team-map.%s team %q, must not be empty", username, team)
+		}
+	}
+
+	usernames := make([]string, 0, len(s.RegexAttributions))
+	for username := range s.RegexAttributions {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	for _, username := range usernames {
+		for _, pattern := range s.RegexAttributions[username] {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid regex-attributions.%s pattern %q: %w", username, pattern, err)
+			}
+		}
+	}
+
+	if err := s.validateGroupReferences(); err != nil {
+		return err
+	}
+
+	if err := s.validateGroupCycles(); err != nil {
+		return err
+	}
+
+	if err := s.validateProfiles(); err != nil {
+		return err
+	}
+
+	return nil
 }
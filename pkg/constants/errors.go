@@ -0,0 +1,37 @@
+package constants
+
+const (
+	ErrorFormatText = "text"
+	ErrorFormatJSON = "json"
+)
+
+const (
+	ErrorCodeUnknown = "unknown"
+	ErrorCodeConfig  = "config"
+	ErrorCodeGit     = "git"
+	ErrorCodePath    = "path"
+	ErrorCodeOutput  = "output"
+
+	// ErrorCodeEmpty is the error of --fail-on-empty when every file was filtered out
+	ErrorCodeEmpty = "empty"
+
+	// ErrorCodeStale is the error of --check-against-remote when the remote file is out of date
+	ErrorCodeStale = "stale"
+
+	// ErrorCodeLint is the error of --lint when the linted file has any findings
+	ErrorCodeLint = "lint"
+)
+
+// Exit codes of the CLI. Each error code has a distinct exit code so scripts and CI
+// can branch on the kind of failure. These are a stable contract: never renumber them.
+const (
+	ExitCodeSuccess = 0
+	ExitCodeUnknown = 1
+	ExitCodeConfig  = 2
+	ExitCodeGit     = 3
+	ExitCodePath    = 4
+	ExitCodeOutput  = 5
+	ExitCodeEmpty   = 6
+	ExitCodeStale   = 7
+	ExitCodeLint    = 8
+)
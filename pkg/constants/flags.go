@@ -3,7 +3,9 @@ package constants
 const (
 	FlagNameBeta      = "beta"
 	FlagNameEndpoint  = "endpoint"
+	FlagNameErrFormat = "error-format"
 	FlagNameFile      = "file"
+	FlagNameLogFormat = "log-format"
 	FlagNameOutput    = "output"
 	FlagNameRange     = "range"
 	FlagNameTelemetry = "disable-telemetry"
@@ -0,0 +1,160 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jpmcb/gopherlogs"
+	"github.com/jpmcb/gopherlogs/pkg/colors"
+)
+
+// The formats logs can be written in
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// NewLogger builds the logger for the log format. Text logs are written to stdout,
+// stylized unless tty is disabled. JSON logs are written to stderr, one entry per line.
+// Text is the default when the format is empty.
+func NewLogger(format string, verbosity int, ttyDisabled bool) (gopherlogs.Logger, error) {
+	switch format {
+	case LogFormatText, "":
+		return gopherlogs.NewLogger(
+			gopherlogs.WithLogVerbosity(verbosity),
+			gopherlogs.WithTty(!ttyDisabled),
+		)
+	case LogFormatJSON:
+		return NewJSONLogger(os.Stderr, verbosity), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q, must be one of: %s, %s", format, LogFormatText, LogFormatJSON)
+	}
+}
+
+// jsonLogEntry is a log message written by the JSONLogger
+type jsonLogEntry struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// JSONLogger is a gopherlogs.Logger which writes each log message as a JSON object
+// on its own line, for log aggregation. Styles are ignored and progress isn't animated.
+type JSONLogger struct {
+	// the writer and its lock are shared by the loggers derived with V and Style
+	output io.Writer
+	mu     *sync.Mutex
+
+	verbosity int
+	logLevel  int
+}
+
+// NewJSONLogger creates a JSONLogger writing the messages at or below the verbosity to w
+func NewJSONLogger(w io.Writer, verbosity int) *JSONLogger {
+	return &JSONLogger{
+		output:    w,
+		mu:        &sync.Mutex{},
+		verbosity: verbosity,
+		logLevel:  gopherlogs.DefaultLogLevel,
+	}
+}
+
+// levelName names the level of a message. Warnings and errors are named
+// as such regardless of the verbosity they're logged at.
+func (l *JSONLogger) levelName(severity string) string {
+	if severity != "" {
+		return severity
+	}
+
+	switch l.logLevel {
+	case LogError:
+		return "error"
+	case LogWarn:
+		return "warn"
+	case LogDebug:
+		return "debug"
+	default:
+		return "info"
+	}
+}
+
+func (l *JSONLogger) log(severity, message string) {
+	if l.logLevel > l.verbosity {
+		return
+	}
+
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return
+	}
+
+	entry, err := json.Marshal(jsonLogEntry{
+		Time:    time.Now().UTC().Format(time.RFC3339),
+		Level:   l.levelName(severity),
+		Message: message,
+	})
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.output, "%s\n", entry)
+}
+
+func (l *JSONLogger) Event(_, message string) {
+	l.log("", message)
+}
+
+func (l *JSONLogger) Eventf(_, message string, args ...interface{}) {
+	l.log("", fmt.Sprintf(message, args...))
+}
+
+func (l *JSONLogger) Info(message string) {
+	l.log("", message)
+}
+
+func (l *JSONLogger) Infof(message string, args ...interface{}) {
+	l.log("", fmt.Sprintf(message, args...))
+}
+
+func (l *JSONLogger) Warn(message string) {
+	l.log("warn", message)
+}
+
+func (l *JSONLogger) Warnf(message string, args ...interface{}) {
+	l.log("warn", fmt.Sprintf(message, args...))
+}
+
+func (l *JSONLogger) Error(message string) {
+	l.log("error", message)
+}
+
+func (l *JSONLogger) Errorf(message string, args ...interface{}) {
+	l.log("error", fmt.Sprintf(message, args...))
+}
+
+func (l *JSONLogger) ReplaceLinef(message string, args ...interface{}) {
+	l.log("", fmt.Sprintf(message, args...))
+}
+
+// AnimateProgressWithOptions returns immediately since progress animations would only add noise
+func (l *JSONLogger) AnimateProgressWithOptions(_ ...gopherlogs.AnimatorOption) {}
+
+func (l *JSONLogger) V(level int) gopherlogs.Logger {
+	return &JSONLogger{
+		output:    l.output,
+		mu:        l.mu,
+		verbosity: l.verbosity,
+		logLevel:  level,
+	}
+}
+
+func (l *JSONLogger) Style(_ int, _ colors.Attribute) gopherlogs.Logger {
+	return l
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jpmcb/gopherlogs/pkg/colors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewJSONLogger(&buf, LogInfo)
+
+	logger.V(LogInfo).Style(0, colors.FgGreen).Infof("Finished generating file: %s\n", "CODEOWNERS")
+	logger.V(LogInfo).Style(0, colors.FgYellow).Warnf("Could not score commit %s\n", "abc123")
+	logger.V(LogDebug).Style(0, colors.FgBlue).Infof("Looking back %d days\n", 90)
+	logger.V(LogError).Errorf("Could not open %s\n", "repo")
+	logger.V(LogInfo).Info("\n")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var entries []jsonLogEntry
+	for _, line := range lines {
+		var entry jsonLogEntry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		assert.NotEmpty(t, entry.Time)
+		entry.Time = ""
+		entries = append(entries, entry)
+	}
+
+	assert.Equal(t, []jsonLogEntry{
+		{Level: "info", Message: "Finished generating file: CODEOWNERS"},
+		{Level: "warn", Message: "Could not score commit abc123"},
+		{Level: "error", Message: "Could not open repo"},
+	}, entries)
+}
+
+func TestJSONLoggerDebug(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewJSONLogger(&buf, LogDebug)
+
+	logger.V(LogDebug).Infof("Looking back %d days\n", 90)
+	assert.Contains(t, buf.String(), `"level":"debug","message":"Looking back 90 days"`)
+}
+
+func TestNewLogger(t *testing.T) {
+	_, err := NewLogger(LogFormatText, LogInfo, true)
+	require.NoError(t, err)
+
+	logger, err := NewLogger(LogFormatJSON, LogInfo, false)
+	require.NoError(t, err)
+	assert.IsType(t, &JSONLogger{}, logger)
+
+	_, err = NewLogger("xml", LogInfo, false)
+	require.Error(t, err)
+}
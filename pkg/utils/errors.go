@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+)
+
+// CLIError is a structured error with a stable code and the offending path
+// and/or field. It is serialized when running with "--error-format json" so that
+// CI systems can surface actionable failures.
+type CLIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Path    string `json:"path,omitempty"`
+	Field   string `json:"field,omitempty"`
+
+	err error
+}
+
+// NewCLIError wraps the given error as a CLIError with the given code and offending path
+func NewCLIError(code string, path string, err error) *CLIError {
+	return &CLIError{
+		Code:    code,
+		Message: err.Error(),
+		Path:    path,
+		err:     err,
+	}
+}
+
+// WithField sets the offending field of the error
+func (e *CLIError) WithField(field string) *CLIError {
+	e.Field = field
+	return e
+}
+
+func (e *CLIError) Error() string {
+	return e.Message
+}
+
+func (e *CLIError) Unwrap() error {
+	return e.err
+}
+
+// exitCodes maps each error code to the CLI's exit code for it
+var exitCodes = map[string]int{
+	constants.ErrorCodeUnknown: constants.ExitCodeUnknown,
+	constants.ErrorCodeConfig:  constants.ExitCodeConfig,
+	constants.ErrorCodeGit:     constants.ExitCodeGit,
+	constants.ErrorCodePath:    constants.ExitCodePath,
+	constants.ErrorCodeOutput:  constants.ExitCodeOutput,
+	constants.ErrorCodeEmpty:   constants.ExitCodeEmpty,
+	constants.ErrorCodeStale:   constants.ExitCodeStale,
+	constants.ErrorCodeLint:    constants.ExitCodeLint,
+}
+
+// ExitCode gets the exit code of the CLI for the given error: success for no error,
+// the exit code of a CLIError's code, or the unknown exit code for any other error.
+func ExitCode(err error) int {
+	if err == nil {
+		return constants.ExitCodeSuccess
+	}
+
+	var cliErr *CLIError
+	if !errors.As(err, &cliErr) {
+		return constants.ExitCodeUnknown
+	}
+
+	code, ok := exitCodes[cliErr.Code]
+	if !ok {
+		return constants.ExitCodeUnknown
+	}
+
+	return code
+}
+
+// WriteError writes the error to the writer in the given error format.
+// Errors which are not a CLIError are serialized with an unknown error code.
+func WriteError(w io.Writer, err error, format string) {
+	if format != constants.ErrorFormatJSON {
+		fmt.Fprintf(w, "Error: %s\n", err.Error())
+		return
+	}
+
+	var cliErr *CLIError
+	if !errors.As(err, &cliErr) {
+		cliErr = NewCLIError(constants.ErrorCodeUnknown, "", err)
+	} else {
+		// keep any wrapping context from callers in the message
+		cliErr = &CLIError{
+			Code:    cliErr.Code,
+			Message: err.Error(),
+			Path:    cliErr.Path,
+			Field:   cliErr.Field,
+		}
+	}
+
+	output, marshalErr := json.Marshal(cliErr)
+	if marshalErr != nil {
+		fmt.Fprintf(w, "Error: %s\n", err.Error())
+		return
+	}
+
+	fmt.Fprintln(w, string(output))
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-sauced/pizza-cli/v2/pkg/constants"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"success", nil, constants.ExitCodeSuccess},
+		{"unstructured error", errors.New("boom"), constants.ExitCodeUnknown},
+		{"unknown", NewCLIError(constants.ErrorCodeUnknown, "", errors.New("boom")), constants.ExitCodeUnknown},
+		{"bad config", NewCLIError(constants.ErrorCodeConfig, ".sauced.yaml", errors.New("invalid")), constants.ExitCodeConfig},
+		{"git error", NewCLIError(constants.ErrorCodeGit, "/repo", errors.New("not a repository")), constants.ExitCodeGit},
+		{"bad path", NewCLIError(constants.ErrorCodePath, "missing", errors.New("does not exist")), constants.ExitCodePath},
+		{"output error", NewCLIError(constants.ErrorCodeOutput, "CODEOWNERS", errors.New("permission denied")), constants.ExitCodeOutput},
+		{"empty output", NewCLIError(constants.ErrorCodeEmpty, "", errors.New("every file was filtered out")), constants.ExitCodeEmpty},
+		{"stale output", NewCLIError(constants.ErrorCodeStale, "open-sauced/pizza-cli", errors.New("out of date")), constants.ExitCodeStale},
+		{"lint findings", NewCLIError(constants.ErrorCodeLint, "CODEOWNERS", errors.New("found 1 issues")), constants.ExitCodeLint},
+		{"wrapped", fmt.Errorf("generating: %w", NewCLIError(constants.ErrorCodeGit, "", errors.New("boom"))), constants.ExitCodeGit},
+		{"unmapped code", NewCLIError("new-code", "", errors.New("boom")), constants.ExitCodeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ExitCode(tt.err))
+		})
+	}
+}
+
+func TestExitCodesAreDistinct(t *testing.T) {
+	seen := make(map[int]string, len(exitCodes))
+	for code, exitCode := range exitCodes {
+		assert.NotContains(t, seen, exitCode, "error codes %s and %s share an exit code", code, seen[exitCode])
+		assert.NotEqual(t, constants.ExitCodeSuccess, exitCode)
+		seen[exitCode] = code
+	}
+}